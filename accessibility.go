@@ -0,0 +1,58 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// applyTheme selects the color palette before any widget is created
+func applyTheme(name string) error {
+	switch name {
+	case "default":
+	case "high-contrast":
+		// Pure black and white with saturated accents; fields keep black text
+		// on a bright background so they read well in any terminal scheme
+		bgPrimary = tcell.ColorBlack
+		bgSecondary = tcell.NewHexColor(0x262626)
+		bgAccent = tcell.ColorWhite
+		textPrimary = tcell.ColorWhite
+		textSecondary = tcell.NewHexColor(0xd7d7d7)
+		textAccent = tcell.NewHexColor(0xffff00)
+		accentOrange = tcell.NewHexColor(0xffff00)
+		accentTeal = tcell.NewHexColor(0x00ffff)
+		accentGreen = tcell.NewHexColor(0x00ff00)
+		accentRed = tcell.NewHexColor(0xff5f5f)
+		accentYellow = tcell.NewHexColor(0xffff00)
+	default:
+		return fmt.Errorf("unknown theme %q: must be 'default' or 'high-contrast'", name)
+	}
+	applyCustomTheme()
+	return nil
+}
+
+// newDataTable creates a selectable table, without borders for screen
+// readers; see ui.NewDataTable
+func newDataTable() *tview.Table {
+	return ui.NewDataTable(*screenReader)
+}
+
+// rowPrefix describes a row's position for screen readers, e.g. "Item 3 of 15: "
+func rowPrefix(noun string, row, total int) string {
+	return ui.RowPrefix(*screenReader, noun, row, total)
+}
+
+// labeled prefixes a cell value with its column name in screen reader mode
+func labeled(column, value string) string {
+	return ui.Labeled(*screenReader, column, value)
+}
+
+// linesPerRow is the number of screen lines a table row takes up
+func linesPerRow() int {
+	if *screenReader {
+		return 1
+	}
+	return 2 // Borders take every other line
+}
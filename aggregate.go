@@ -0,0 +1,197 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// aggregateTopValues is how many of the most frequent values the stats of
+// an attribute list
+const aggregateTopValues = 10
+
+// itemAttributes lists the attributes of any of the items, sorted by name
+func itemAttributes(rawItems []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	for _, item := range rawItems {
+		for attr := range item {
+			seen[attr] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// valueType names the DynamoDB type of a raw item value
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "Null"
+	case string:
+		return "String"
+	case bool:
+		return "Boolean"
+	case []interface{}:
+		return "List"
+	case map[string]interface{}:
+		return "Map"
+	case []byte:
+		return "Binary"
+	case aws.StringSet:
+		return "String Set"
+	case aws.NumberSet:
+		return "Number Set"
+	case aws.BinarySet:
+		return "Binary Set"
+	}
+	if _, ok := numericValue(v); ok {
+		return "Number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// aggregateValue is a value counted by an aggregate: its type and its raw
+// text, so 1 and "1" stay apart
+type aggregateValue struct {
+	kind string
+	text string
+}
+
+// newAggregateValue is the aggregateValue of a raw item value
+func newAggregateValue(v interface{}) aggregateValue {
+	if v == nil {
+		return aggregateValue{kind: valueType(v), text: "NULL"}
+	}
+	return aggregateValue{kind: valueType(v), text: ui.RawValueString(v)}
+}
+
+// aggregate summarizes the values of an attribute across items
+type aggregate struct {
+	attr    string
+	items   int                    // Items looked at
+	present int                    // Items holding the attribute, NULL included
+	types   map[string]int         // Items by the type of their value
+	values  map[aggregateValue]int // Items by value
+	numbers distribution
+}
+
+// newAggregate collects the values of attr over the items
+func newAggregate(rawItems []map[string]interface{}, attr string) aggregate {
+	a := aggregate{
+		attr:    attr,
+		items:   len(rawItems),
+		types:   make(map[string]int),
+		values:  make(map[aggregateValue]int),
+		numbers: newDistribution(rawItems, attr),
+	}
+	for _, item := range rawItems {
+		v, ok := item[attr]
+		if !ok {
+			continue
+		}
+		a.present++
+		value := newAggregateValue(v)
+		a.types[value.kind]++
+		a.values[value]++
+	}
+	return a
+}
+
+// topValues lists the n most frequent values, the most frequent first and
+// equally frequent ones by value
+func (a aggregate) topValues(n int) []aggregateValue {
+	values := slices.Collect(maps.Keys(a.values))
+	slices.SortFunc(values, func(x, y aggregateValue) int {
+		if a.values[x] != a.values[y] {
+			return a.values[y] - a.values[x]
+		}
+		if x.text != y.text {
+			return strings.Compare(x.text, y.text)
+		}
+		return strings.Compare(x.kind, y.kind)
+	})
+	return values[:min(n, len(values))]
+}
+
+// text lays the stats out for the stats page
+func (a aggregate) text() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "  %-16s %s\n", label, value)
+	}
+	share := func(n int) string {
+		if a.items == 0 {
+			return formatNumber(int64(n))
+		}
+		return fmt.Sprintf("%s (%s%%)", formatNumber(int64(n)), formatStat(float64(n)*100/float64(a.items)))
+	}
+	fmt.Fprintf(&b, "[#ff9500]%s[white] over %s loaded items\n\n", tview.Escape(a.attr), formatNumber(int64(a.items)))
+	row("Count", share(a.present))
+	row("Missing", share(a.items-a.present))
+	row("Distinct", formatNumber(int64(len(a.values))))
+	var types []string
+	for _, t := range slices.Sorted(maps.Keys(a.types)) {
+		types = append(types, fmt.Sprintf("%s %s", t, formatNumber(int64(a.types[t]))))
+	}
+	if len(types) > 0 {
+		row("Types", strings.Join(types, ", "))
+	}
+
+	if values := a.numbers.values; len(values) > 0 {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		b.WriteString("\n[#ff9500]Numbers[white]\n")
+		row("Count", formatNumber(int64(len(values))))
+		row("Min", formatStat(values[0]))
+		row("Max", formatStat(values[len(values)-1]))
+		row("Sum", formatStat(sum))
+		row("Average", formatStat(sum/float64(len(values))))
+		row("Median", formatStat(a.numbers.quantile(0.5)))
+	}
+
+	if top := a.topValues(aggregateTopValues); len(top) > 0 {
+		fmt.Fprintf(&b, "\n[#ff9500]Top %d values[white]\n", len(top))
+		for _, value := range top {
+			text := tview.Escape(ui.TruncateCell(value.text, ui.MaxCellLength))
+			if len(a.types) > 1 {
+				text += fmt.Sprintf(" [#b8b8b8](%s)[white]", value.kind)
+			}
+			fmt.Fprintf(&b, "  %-16s %s\n", share(a.values[value]), text)
+		}
+	}
+	return b.String()
+}
+
+// showAggregate shows the stats of an attribute over the loaded items
+func showAggregate(app *tview.Application, pages *tview.Pages, title string, a aggregate) {
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Stats: %s (↑/↓: scroll | ESC: close)", tview.Escape(title)))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetText(a.text())
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("aggregate")
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("aggregate", flex, true, true)
+	app.SetFocus(text)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxCompletions bounds the attribute names offered at once
+const maxCompletions = 10
+
+// knownAttributes are the attribute names of the items read from each table
+// this session, by table name. A table that was sampled without finding any
+// has an empty set, so it isn't sampled again.
+var knownAttributes = make(map[string]map[string]bool)
+
+// noteAttributes remembers the attribute names of items read from a table
+func noteAttributes(table string, items []map[string]interface{}) {
+	names := knownAttributes[table]
+	if names == nil {
+		names = make(map[string]bool)
+		knownAttributes[table] = names
+	}
+	for _, item := range items {
+		for attr := range item {
+			names[attr] = true
+		}
+	}
+}
+
+// attributeNames returns the key attributes of a table and its indexes, and
+// the attributes seen in its items, sorted
+func attributeNames(tableInfo aws.TableInfo) []string {
+	seen := make(map[string]bool)
+	for attr := range tableInfo.AttributeTypes {
+		seen[attr] = true
+	}
+	for attr := range knownAttributes[tableInfo.Name] {
+		seen[attr] = true
+	}
+	names := make([]string, 0, len(seen))
+	for attr := range seen {
+		names = append(names, attr)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sampleAttributes reads one page of a table in the background to learn its
+// attribute names, unless items of it were read already. found runs on the
+// UI goroutine once they are known.
+func sampleAttributes(client *aws.Client, pageName string, tableName string, found func()) {
+	if _, ok := knownAttributes[tableName]; ok {
+		return
+	}
+	knownAttributes[tableName] = make(map[string]bool) // Sampled once, even if it fails
+	tasks.Go(tasks.group(pageName), func(ctx context.Context) func() {
+		sample, err := client.Scan(ctx, aws.ScanParams{TableName: tableName}, nil)
+		return func() {
+			if err != nil {
+				return // Completion falls back to the key attributes
+			}
+			noteAttributes(tableName, sample.RawItems)
+			found()
+		}
+	})
+}
+
+// attributeWordStart is where the attribute name being typed at the end of
+// text starts, after the last space, parenthesis, comma or operator
+func attributeWordStart(text string) int {
+	return strings.LastIndexAny(text, " (),=<>!") + 1
+}
+
+// completeAttributes offers attribute names for the name being typed at the
+// end of a field: names starting with it first, then names containing it,
+// ignoring case. Picking one with Enter or Tab replaces what was typed of it.
+func completeAttributes(field *tview.InputField, names func() []string) {
+	field.SetAutocompleteUseTags(false)
+	field.SetAutocompleteFunc(func(text string) []string {
+		typed := text[attributeWordStart(text):]
+		if typed == "" {
+			return nil
+		}
+		word := strings.ToLower(typed)
+		var prefixed, containing []string
+		for _, name := range names() {
+			lower := strings.ToLower(name)
+			switch {
+			case name == typed:
+				continue // Typed in full
+			case strings.HasPrefix(lower, word):
+				prefixed = append(prefixed, name)
+			case strings.Contains(lower, word):
+				containing = append(containing, name)
+			}
+		}
+		entries := append(prefixed, containing...)
+		return entries[:min(len(entries), maxCompletions)]
+	})
+	field.SetAutocompletedFunc(func(name string, index, source int) bool {
+		if source == tview.AutocompletedNavigate {
+			return false
+		}
+		text := field.GetText()
+		field.SetText(text[:attributeWordStart(text)] + name)
+		return true
+	})
+}
+
+// completeTableAttributes offers the attribute names of a table in a field
+// of its query form, sampling a page of the table when the field is focused
+// before any of its items were read
+func completeTableAttributes(field *tview.InputField, client *aws.Client, tableInfo aws.TableInfo) {
+	completeAttributes(field, func() []string { return attributeNames(tableInfo) })
+	field.SetFocusFunc(func() {
+		sampleAttributes(client, "tableaction", tableInfo.Name, func() {
+			if field.HasFocus() {
+				field.Autocomplete()
+			}
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxAttributeNameLength is the longest attribute name shown in a column
+// header or field cell; longer names are shortened in the middle, where
+// generated names tend to repeat themselves least
+const maxAttributeNameLength = 32
+
+// middleTruncate shortens s to n characters by replacing its middle with an
+// ellipsis, keeping the start and the end
+func middleTruncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	head := (n - 1) / 2
+	tail := n - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
+// columnTitle is how an attribute of a table is shown: its display name if
+// one was given, otherwise the name, shortened unless a screen reader reads
+// it out
+func columnTitle(tableName, attr string) string {
+	if alias := userSettings.ColumnNames[tableName][attr]; alias != "" {
+		return alias
+	}
+	if *screenReader {
+		return attr
+	}
+	return middleTruncate(attr, maxAttributeNameLength)
+}
+
+// fullNameNote is the status line text naming an attribute shown under
+// another title, or empty when the title is the name
+func fullNameNote(tableName, attr string) string {
+	return ui.FullNameNote(columnTitle(tableName, attr), attr)
+}
+
+// showColumnRename asks for the display name of an attribute, kept in the
+// config file. The data is untouched; an empty name restores the attribute
+// name.
+func showColumnRename(app *tview.Application, pages *tview.Pages, tableName, attr string, renamed func()) {
+	form := tview.NewForm()
+	form.AddTextView("Attribute", tview.Escape(attr), 0, 3, true, false)
+	form.AddInputField("Display as", userSettings.ColumnNames[tableName][attr], 36, nil, nil)
+	form.AddButton("Save", func() {
+		alias := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		pages.RemovePage("columnrename")
+		if alias == "" || alias == attr {
+			delete(userSettings.ColumnNames[tableName], attr)
+			if len(userSettings.ColumnNames[tableName]) == 0 {
+				delete(userSettings.ColumnNames, tableName)
+			}
+		} else {
+			if userSettings.ColumnNames == nil {
+				userSettings.ColumnNames = make(map[string]map[string]string)
+			}
+			if userSettings.ColumnNames[tableName] == nil {
+				userSettings.ColumnNames[tableName] = make(map[string]string)
+			}
+			userSettings.ColumnNames[tableName][attr] = alias
+		}
+		renamed()
+		if err := saveSettings(userSettings); err != nil {
+			showMessageModal(pages, "configerror", fmt.Sprintf("Column renamed for this session, but saving it failed: %v", err))
+		}
+	})
+	showFormPrompt(app, pages, "columnrename", "Rename Column", form)
+	form.SetFocus(1)
+	app.SetFocus(form)
+}
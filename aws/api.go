@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// TableLister lists the tables of an account and describes them
+type TableLister interface {
+	ListTables(ctx context.Context, progress func(loaded []TableInfo)) ([]TableInfo, error)
+	DescribeTable(ctx context.Context, name string) (TableInfo, error)
+}
+
+// Querier reads the items of a partition, one page at a time
+type Querier interface {
+	Query(ctx context.Context, params QueryParams, page *PageToken) (QueryResult, error)
+}
+
+// Scanner reads a whole table or index, one page at a time
+type Scanner interface {
+	Scan(ctx context.Context, params ScanParams, page *PageToken) (QueryResult, error)
+}
+
+// PartitionReader is what QueryPages reads through: the table's key schema,
+// which tells items apart, and its pages
+type PartitionReader interface {
+	TableLister
+	Querier
+}
+
+// Client reads through all three, whether it talks to DynamoDB or to the
+// in-memory tables of NewFakeClient
+var (
+	_ TableLister = (*Client)(nil)
+	_ Querier     = (*Client)(nil)
+	_ Scanner     = (*Client)(nil)
+)
+
+// dynamoDB is the part of the DynamoDB API a Client uses. It is the SDK's
+// client, or fakeDynamoDB.
+type dynamoDB interface {
+	Options() dynamodb.Options
+	ListTables(context.Context, *dynamodb.ListTablesInput, ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error)
+	DescribeTable(context.Context, *dynamodb.DescribeTableInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(context.Context, *dynamodb.DeleteTableInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	DescribeTimeToLive(context.Context, *dynamodb.DescribeTimeToLiveInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	DescribeContinuousBackups(context.Context, *dynamodb.DescribeContinuousBackupsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeContinuousBackupsOutput, error)
+	ListTagsOfResource(context.Context, *dynamodb.ListTagsOfResourceInput, ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error)
+	DescribeLimits(context.Context, *dynamodb.DescribeLimitsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeLimitsOutput, error)
+	Query(context.Context, *dynamodb.QueryInput, ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	GetItem(context.Context, *dynamodb.GetItemInput, ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	BatchGetItem(context.Context, *dynamodb.BatchGetItemInput, ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	PutItem(context.Context, *dynamodb.PutItemInput, ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(context.Context, *dynamodb.DeleteItemInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(context.Context, *dynamodb.BatchWriteItemInput, ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	ExportTableToPointInTime(context.Context, *dynamodb.ExportTableToPointInTimeInput, ...func(*dynamodb.Options)) (*dynamodb.ExportTableToPointInTimeOutput, error)
+	DescribeExport(context.Context, *dynamodb.DescribeExportInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeExportOutput, error)
+}
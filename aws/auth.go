@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// roleSessionName identifies the tool's sessions in CloudTrail when assuming a role
+const roleSessionName = "ddb-explorer"
+
+// expiredTokenCodes are the API error codes returned when the request was
+// signed with temporary credentials that are no longer valid
+var expiredTokenCodes = map[string]bool{
+	"ExpiredToken":          true,
+	"ExpiredTokenException": true,
+	"RequestExpired":        true,
+	"TokenRefreshRequired":  true,
+}
+
+// IsExpiredTokenError reports whether err was caused by an expired SSO
+// session or expired temporary (assumed role) credentials
+func IsExpiredTokenError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var tokenErr *ssocreds.InvalidTokenError
+	if errors.As(err, &tokenErr) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return expiredTokenCodes[apiErr.ErrorCode()]
+	}
+	return false
+}
+
+// assumeRoleCredentials returns a cached credentials provider that assumes
+// the configured role using the profile's credentials as the source
+func assumeRoleCredentials(cfg aws.Config, opts clientOptions) aws.CredentialsProvider {
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), opts.roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+		if opts.mfaSerial != "" {
+			o.SerialNumber = aws.String(opts.mfaSerial)
+			o.TokenProvider = opts.mfaTokenProvider
+		}
+	})
+	return aws.NewCredentialsCache(provider)
+}
+
+// Profile returns the AWS profile the client was created with
+func (c *Client) Profile() string {
+	return c.profile
+}
+
+// RoleARN returns the cross-account role assumed by the client, if any
+func (c *Client) RoleARN() string {
+	return c.opts.roleARN
+}
+
+// MFASerial returns the MFA device required to assume the client's role, if any
+func (c *Client) MFASerial() string {
+	return c.opts.mfaSerial
+}
+
+// UsesSSO reports whether the client's profile authenticates through AWS SSO
+func (c *Client) UsesSSO() bool {
+	sc, err := config.LoadSharedConfigProfile(context.TODO(), c.profile)
+	if err != nil {
+		return false
+	}
+	return sc.SSOSessionName != "" || sc.SSOStartURL != ""
+}
+
+// SSOLoginCommand returns the AWS CLI command that re-runs the SSO login for the client's profile
+func (c *Client) SSOLoginCommand() *exec.Cmd {
+	return exec.Command("aws", "sso", "login", "--profile", c.profile)
+}
+
+// RefreshCredentials reloads the AWS configuration for the client's profile,
+// picking up credentials renewed outside the application (e.g. after an SSO login)
+func (c *Client) RefreshCredentials() error {
+	if _, ok := c.api().(*fakeDynamoDB); ok {
+		return nil // In-memory tables have no credentials to expire
+	}
+	svc, err := newDynamoDBClient(c.profile, c.opts)
+	if err != nil {
+		return fmt.Errorf("failed to refresh credentials: %w", err)
+	}
+	c.svcMu.Lock()
+	c.svc = svc
+	c.svcMu.Unlock()
+	return nil
+}
+
+// AccountID returns the AWS account the client's credentials belong to,
+// which is the assumed role's account when a role ARN is set
+func (c *Client) AccountID(ctx context.Context) (string, error) {
+	o := c.api().Options()
+	stsClient := sts.New(sts.Options{Region: o.Region, Credentials: o.Credentials})
+	out, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return aws.ToString(out.Account), nil
+}
@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// capacityMeter adds up the read capacity consumed by a client's requests,
+// which may run concurrently
+type capacityMeter struct {
+	mu    sync.Mutex
+	total float64
+}
+
+// record adds the capacity units reported by a response and returns them
+func (m *capacityMeter) record(consumed ...types.ConsumedCapacity) float64 {
+	units := 0.0
+	for _, cc := range consumed {
+		units += aws.ToFloat64(cc.CapacityUnits)
+	}
+	m.mu.Lock()
+	m.total += units
+	m.mu.Unlock()
+	return units
+}
+
+// SessionCapacity returns the read capacity units consumed by queries, scans
+// and item fetches since the client was created
+func (c *Client) SessionCapacity() float64 {
+	c.capacity.mu.Lock()
+	defer c.capacity.mu.Unlock()
+	return c.capacity.total
+}
+
+// consumedCapacity turns the optional ConsumedCapacity of a single-table
+// response into a list for capacityMeter.record
+func consumedCapacity(cc *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if cc == nil {
+		return nil
+	}
+	return []types.ConsumedCapacity{*cc}
+}
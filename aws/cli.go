@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// cliOption is an option of an AWS CLI command. An empty value writes the
+// option alone, like --consistent-read.
+type cliOption struct {
+	name  string
+	value string
+}
+
+// shellQuote quotes a value for POSIX shells, unless it needs no quoting
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.:/@=+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cliCommand writes an aws dynamodb command, one option per line, followed
+// by the profile, region and endpoint of the client
+func (c *Client) cliCommand(operation string, options []cliOption) string {
+	if c.profile != "" {
+		options = append(options, cliOption{"profile", c.profile})
+	}
+	if region := c.Region(); region != "" {
+		options = append(options, cliOption{"region", region})
+	}
+	if endpoint := c.Endpoint(); endpoint != "" {
+		options = append(options, cliOption{"endpoint-url", endpoint})
+	}
+	lines := []string{"aws dynamodb " + operation}
+	for _, o := range options {
+		if o.value == "" {
+			lines = append(lines, "--"+o.name)
+		} else {
+			lines = append(lines, "--"+o.name+" "+shellQuote(o.value))
+		}
+	}
+	return strings.Join(lines, " \\\n    ")
+}
+
+// cliJSON writes a value as compact JSON, leaving characters such as < and &
+// as they are for readability
+func cliJSON(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v) // Names and typed values always encode
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// expressionOptions are the options of the expressions shared by queries
+// and scans, in the order the CLI documents them
+func expressionOptions(filter, projection *string, names map[string]string, values map[string]types.AttributeValue) []cliOption {
+	var options []cliOption
+	if filter != nil {
+		options = append(options, cliOption{"filter-expression", *filter})
+	}
+	if projection != nil {
+		options = append(options, cliOption{"projection-expression", *projection})
+	}
+	if len(names) > 0 {
+		options = append(options, cliOption{"expression-attribute-names", cliJSON(names)})
+	}
+	if len(values) > 0 {
+		options = append(options, cliOption{"expression-attribute-values", cliJSON(typedItemJSON(values))})
+	}
+	return options
+}
+
+// ReadRequest is the request a query or scan sends, as built by the
+// client, for writing it out as a command or code
+type ReadRequest struct {
+	client *Client
+	query  *dynamodb.QueryInput // Set for a query
+	scan   *dynamodb.ScanInput  // Set for a scan
+}
+
+// QueryRequest builds the request of a query's first page
+func (c *Client) QueryRequest(params QueryParams) (ReadRequest, error) {
+	input, err := c.queryInput(params)
+	return ReadRequest{client: c, query: input}, err
+}
+
+// ScanRequest builds the request of a scan's first page
+func (c *Client) ScanRequest(params ScanParams) (ReadRequest, error) {
+	input, err := c.scanInput(params)
+	return ReadRequest{client: c, scan: input}, err
+}
+
+// RawRequest builds the request of a raw query or scan's first page
+func (c *Client) RawRequest(params RawParams) (ReadRequest, error) {
+	queryInput, scanInput, err := c.rawInput(params)
+	return ReadRequest{client: c, query: queryInput, scan: scanInput}, err
+}
+
+// CLICommand writes the request as the aws dynamodb command that sends it.
+// The CLI follows pagination by itself, so the command reads every page.
+func (r ReadRequest) CLICommand() string {
+	if r.query != nil {
+		return r.client.queryCommand(r.query)
+	}
+	return r.client.scanCommand(r.scan)
+}
+
+// queryCommand writes the CLI command of a query request
+func (c *Client) queryCommand(in *dynamodb.QueryInput) string {
+	options := []cliOption{{"table-name", aws.ToString(in.TableName)}}
+	if in.IndexName != nil {
+		options = append(options, cliOption{"index-name", *in.IndexName})
+	}
+	options = append(options, cliOption{"key-condition-expression", aws.ToString(in.KeyConditionExpression)})
+	options = append(options, expressionOptions(in.FilterExpression, in.ProjectionExpression, in.ExpressionAttributeNames, in.ExpressionAttributeValues)...)
+	if in.ScanIndexForward != nil && !*in.ScanIndexForward {
+		options = append(options, cliOption{"no-scan-index-forward", ""})
+	}
+	if aws.ToBool(in.ConsistentRead) {
+		options = append(options, cliOption{"consistent-read", ""})
+	}
+	options = append(options, cliOption{"return-consumed-capacity", string(in.ReturnConsumedCapacity)})
+	return c.cliCommand("query", options)
+}
+
+// scanCommand writes the CLI command of a scan request
+func (c *Client) scanCommand(in *dynamodb.ScanInput) string {
+	options := []cliOption{{"table-name", aws.ToString(in.TableName)}}
+	if in.IndexName != nil {
+		options = append(options, cliOption{"index-name", *in.IndexName})
+	}
+	options = append(options, expressionOptions(in.FilterExpression, in.ProjectionExpression, in.ExpressionAttributeNames, in.ExpressionAttributeValues)...)
+	if aws.ToBool(in.ConsistentRead) {
+		options = append(options, cliOption{"consistent-read", ""})
+	}
+	options = append(options, cliOption{"return-consumed-capacity", string(in.ReturnConsumedCapacity)})
+	return c.cliCommand("scan", options)
+}
@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCLICommandBindsBothBoundsOfBetween(t *testing.T) {
+	client, err := NewFakeClient("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := client.QueryRequest(QueryParams{
+		TableName:      "events",
+		PartitionKey:   "device",
+		PartitionValue: "a",
+		SortKey:        "ts",
+		SortValue:      "20",
+		SortValueEnd:   "30",
+		SortKeyType:    "N",
+		Condition:      "between",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	command := request.CLICommand()
+	for _, want := range []string{
+		`--key-condition-expression '#pk = :pk AND #sk BETWEEN :sk AND :sk2'`,
+		`":sk":{"N":"20"}`,
+		`":sk2":{"N":"30"}`,
+	} {
+		if !strings.Contains(command, want) {
+			t.Errorf("command lacks %s:\n%s", want, command)
+		}
+	}
+	for name, snippet := range map[string]string{"Go": request.GoSnippet(), "Python": request.PythonSnippet()} {
+		if !strings.Contains(snippet, `":sk2"`) {
+			t.Errorf("%s snippet doesn't bind :sk2:\n%s", name, snippet)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go"
+)
+
+// The CloudWatch SDK module isn't a dependency, so metrics are read with
+// signed requests to the GetMetricData action of the CloudWatch query API,
+// reusing the DynamoDB client's credentials, region and HTTP client.
+const (
+	cloudWatchVersion    = "2010-08-01"
+	maxQueriesPerRequest = 500 // GetMetricData limit per request
+)
+
+// metricQuery selects one DynamoDB metric of a table (and optionally an index)
+type metricQuery struct {
+	id         string
+	metricName string
+	tableName  string
+	indexName  string
+	stat       string // Sum, Average, Maximum, ...
+	period     time.Duration
+}
+
+// metricPoint is a single datapoint returned by GetMetricData
+type metricPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// getMetricDataResponse is the XML body of a GetMetricData response
+type getMetricDataResponse struct {
+	Results []struct {
+		ID         string   `xml:"Id"`
+		Timestamps []string `xml:"Timestamps>member"`
+		Values     []string `xml:"Values>member"`
+	} `xml:"GetMetricDataResult>MetricDataResults>member"`
+	NextToken string `xml:"GetMetricDataResult>NextToken"`
+}
+
+// cloudWatchErrorResponse is the XML body of a failed query API request
+type cloudWatchErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// getMetricData runs the queries between start and end and returns the
+// datapoints of each query by id, oldest first
+func (c *Client) getMetricData(ctx context.Context, queries []metricQuery, start, end time.Time) (map[string][]metricPoint, error) {
+	points := make(map[string][]metricPoint, len(queries))
+	for first := 0; first < len(queries); first += maxQueriesPerRequest {
+		last := first + maxQueriesPerRequest
+		if last > len(queries) {
+			last = len(queries)
+		}
+
+		form := url.Values{}
+		form.Set("Action", "GetMetricData")
+		form.Set("Version", cloudWatchVersion)
+		form.Set("StartTime", start.UTC().Format(time.RFC3339))
+		form.Set("EndTime", end.UTC().Format(time.RFC3339))
+		form.Set("ScanBy", "TimestampAscending")
+		for i, q := range queries[first:last] {
+			prefix := fmt.Sprintf("MetricDataQueries.member.%d.", i+1)
+			form.Set(prefix+"Id", q.id)
+			form.Set(prefix+"ReturnData", "true")
+			form.Set(prefix+"MetricStat.Metric.Namespace", "AWS/DynamoDB")
+			form.Set(prefix+"MetricStat.Metric.MetricName", q.metricName)
+			form.Set(prefix+"MetricStat.Metric.Dimensions.member.1.Name", "TableName")
+			form.Set(prefix+"MetricStat.Metric.Dimensions.member.1.Value", q.tableName)
+			if q.indexName != "" {
+				form.Set(prefix+"MetricStat.Metric.Dimensions.member.2.Name", "GlobalSecondaryIndexName")
+				form.Set(prefix+"MetricStat.Metric.Dimensions.member.2.Value", q.indexName)
+			}
+			form.Set(prefix+"MetricStat.Period", strconv.Itoa(int(q.period.Seconds())))
+			form.Set(prefix+"MetricStat.Stat", q.stat)
+		}
+
+		for {
+			var resp getMetricDataResponse
+			if err := c.cloudWatchCall(ctx, form, &resp); err != nil {
+				return nil, err
+			}
+			for _, r := range resp.Results {
+				for i := range r.Timestamps {
+					if i >= len(r.Values) {
+						break
+					}
+					ts, err := time.Parse(time.RFC3339, r.Timestamps[i])
+					if err != nil {
+						continue
+					}
+					v, err := strconv.ParseFloat(r.Values[i], 64)
+					if err != nil {
+						continue
+					}
+					points[r.ID] = append(points[r.ID], metricPoint{Timestamp: ts, Value: v})
+				}
+			}
+			if resp.NextToken == "" {
+				break
+			}
+			form.Set("NextToken", resp.NextToken)
+		}
+		form.Del("NextToken")
+	}
+
+	for id := range points {
+		sort.Slice(points[id], func(i, j int) bool {
+			return points[id][i].Timestamp.Before(points[id][j].Timestamp)
+		})
+	}
+	return points, nil
+}
+
+// cloudWatchCall signs and sends a CloudWatch query API request and decodes the XML response into out
+func (c *Client) cloudWatchCall(ctx context.Context, form url.Values, out interface{}) error {
+	opts := c.api().Options()
+	endpoint := fmt.Sprintf("https://monitoring.%s.amazonaws.com/", opts.Region)
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds, err := opts.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+	hash := sha256.Sum256([]byte(body))
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "monitoring", opts.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign CloudWatch request: %w", err)
+	}
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr cloudWatchErrorResponse
+		if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			// Returned as a smithy API error so expired credentials are recognised
+			return &smithy.GenericAPIError{Code: apiErr.Code, Message: apiErr.Message}
+		}
+		return fmt.Errorf("CloudWatch request failed: %s", resp.Status)
+	}
+	return xml.Unmarshal(data, out)
+}
+
+// TableActivity is the consumed capacity of a table over the last day
+type TableActivity struct {
+	Reads  []float64 // Consumed read capacity units per hour, oldest first
+	Writes []float64 // Consumed write capacity units per hour, oldest first
+}
+
+// activityHours is the number of hourly buckets in a TableActivity
+const activityHours = 24
+
+// TableActivity returns the hourly consumed read and write capacity of each
+// table over the last 24 hours. Hours without datapoints count as zero.
+func (c *Client) TableActivity(ctx context.Context, tableNames []string) (map[string]TableActivity, error) {
+	end := time.Now().Truncate(time.Hour).Add(time.Hour)
+	start := end.Add(-activityHours * time.Hour)
+
+	queries := make([]metricQuery, 0, 2*len(tableNames))
+	for i, name := range tableNames {
+		queries = append(queries,
+			metricQuery{id: fmt.Sprintf("r%d", i), metricName: "ConsumedReadCapacityUnits", tableName: name, stat: "Sum", period: time.Hour},
+			metricQuery{id: fmt.Sprintf("w%d", i), metricName: "ConsumedWriteCapacityUnits", tableName: name, stat: "Sum", period: time.Hour},
+		)
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := func(series []metricPoint) []float64 {
+		hours := make([]float64, activityHours)
+		for _, p := range series {
+			if h := int(p.Timestamp.Sub(start) / time.Hour); h >= 0 && h < activityHours {
+				hours[h] += p.Value
+			}
+		}
+		return hours
+	}
+
+	activity := make(map[string]TableActivity, len(tableNames))
+	for i, name := range tableNames {
+		activity[name] = TableActivity{
+			Reads:  bucket(points[fmt.Sprintf("r%d", i)]),
+			Writes: bucket(points[fmt.Sprintf("w%d", i)]),
+		}
+	}
+	return activity, nil
+}
+
+// IndexUsage is the consumed capacity of a global secondary index over a number of days
+type IndexUsage struct {
+	Reads    []float64 // Consumed read capacity units per day, oldest first
+	Writes   []float64 // Consumed write capacity units per day, oldest first
+	LastRead time.Time // Start of the last day with reads, zero if there were none
+}
+
+// IndexUtilization returns the daily consumed read and write capacity of
+// each global secondary index of a table over the last days days. LSIs
+// share the table's capacity and have no metrics of their own.
+func (c *Client) IndexUtilization(ctx context.Context, table TableInfo, days int) (map[string]IndexUsage, error) {
+	const day = 24 * time.Hour
+	end := time.Now().UTC().Truncate(day).Add(day)
+	start := end.Add(-time.Duration(days) * day)
+
+	indexes := table.GlobalIndexes()
+	queries := make([]metricQuery, 0, 2*len(indexes))
+	for i, idx := range indexes {
+		queries = append(queries,
+			metricQuery{id: fmt.Sprintf("r%d", i), metricName: "ConsumedReadCapacityUnits", tableName: table.Name, indexName: idx.Name, stat: "Sum", period: day},
+			metricQuery{id: fmt.Sprintf("w%d", i), metricName: "ConsumedWriteCapacityUnits", tableName: table.Name, indexName: idx.Name, stat: "Sum", period: day},
+		)
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := func(series []metricPoint) []float64 {
+		daily := make([]float64, days)
+		for _, p := range series {
+			if d := int(p.Timestamp.Sub(start) / day); d >= 0 && d < days {
+				daily[d] += p.Value
+			}
+		}
+		return daily
+	}
+
+	usage := make(map[string]IndexUsage, len(indexes))
+	for i, idx := range indexes {
+		u := IndexUsage{
+			Reads:  bucket(points[fmt.Sprintf("r%d", i)]),
+			Writes: bucket(points[fmt.Sprintf("w%d", i)]),
+		}
+		for d := days - 1; d >= 0; d-- {
+			if u.Reads[d] > 0 {
+				u.LastRead = start.Add(time.Duration(d) * day)
+				break
+			}
+		}
+		usage[idx.Name] = u
+	}
+	return usage, nil
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// gzipJSONCodec handles binary attributes holding gzip-compressed JSON
+type gzipJSONCodec struct{}
+
+// Name implements AttributeDecoder
+func (gzipJSONCodec) Name() string {
+	return "gzip-json"
+}
+
+// Decode gunzips the value and parses the result as JSON
+func (gzipJSONCodec) Decode(b []byte) (interface{}, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse decompressed JSON: %w", err)
+	}
+	return v, nil
+}
+
+// Encode implements AttributeEncoder by re-compressing the edited value
+func (gzipJSONCodec) Encode(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SetCompressedAttributes configures the binary attributes that hold
+// gzip-compressed JSON. Each entry is either an attribute name, which applies
+// to every table, or a "table.attribute" pair scoped to a single table.
+func (c *Client) SetCompressedAttributes(attrs []string) {
+	for _, a := range attrs {
+		a = strings.TrimSpace(a)
+		if a != "" {
+			c.RegisterDecoder(a, gzipJSONCodec{})
+		}
+	}
+}
+
+// CompressJSON marshals v to JSON and gzip-compresses it into a binary attribute value
+func CompressJSON(v interface{}) (types.AttributeValue, error) {
+	b, err := gzipJSONCodec{}.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AttributeValueMemberB{Value: b}, nil
+}
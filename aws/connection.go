@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// defaultRegion is used when neither --region nor the connection screen
+// chose one
+const defaultRegion = "us-east-1"
+
+// Regions are the regions offered by the connection screen, most used first
+var Regions = []string{
+	"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+	"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-north-1", "eu-south-1",
+	"ap-south-1", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
+	"ca-central-1", "sa-east-1", "me-south-1", "af-south-1", "il-central-1",
+}
+
+// connectTimeout bounds a connection test, which otherwise keeps retrying
+// unreachable endpoints for a long time
+const connectTimeout = 10 * time.Second
+
+// WithRegion sets the region to connect to instead of us-east-1
+func WithRegion(region string) Option {
+	return func(o *clientOptions) {
+		o.region = region
+	}
+}
+
+// WithEndpoint sends requests to the given URL instead of the regional
+// DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local
+func WithEndpoint(url string) Option {
+	return func(o *clientOptions) {
+		o.endpoint = url
+	}
+}
+
+// Region returns the region the client connects to
+func (c *Client) Region() string {
+	return c.api().Options().Region
+}
+
+// Endpoint returns the custom endpoint the client connects to, empty for
+// the regional endpoint
+func (c *Client) Endpoint() string {
+	return c.opts.endpoint
+}
+
+// CheckConnection creates a client and tests it, giving up after a few
+// seconds. The client is returned together with the number of tables on the
+// first ListTables page, which shows the credentials can see something.
+func CheckConnection(ctx context.Context, profile string, options ...Option) (*Client, int, error) {
+	client, err := NewClient(profile, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	result, err := client.svc.ListTables(ctx, &dynamodb.ListTablesInput{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return client, len(result.TableNames), nil
+}
+
+// Profiles lists the profiles defined in the shared config and credentials
+// files, sorted by name. Files that don't exist are skipped.
+func Profiles() []string {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	seen := make(map[string]bool)
+	// The config file names profiles "[profile NAME]", except for default
+	for _, f := range []struct {
+		path   string
+		prefix string
+	}{{configFile, "profile "}, {credentialsFile, ""}} {
+		file, err := os.Open(f.path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+				continue
+			}
+			section := strings.TrimSpace(line[1 : len(line)-1])
+			if name, ok := strings.CutPrefix(section, f.prefix); ok && (f.prefix == "" || name != "") {
+				seen[strings.TrimSpace(name)] = true
+			} else if section == "default" {
+				seen[section] = true
+			}
+		}
+		file.Close()
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for p := range seen {
+		profiles = append(profiles, p)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
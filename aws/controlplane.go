@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DynamoDB throttles control-plane requests (DescribeTable, ListTables,
+// ListTagsOfResource, ...) at around 10 per second per account. Every
+// feature that loads metadata goes through one scheduler per client, so the
+// table list, the limits panel and the inventory export can't starve each
+// other or trip the account limit.
+const (
+	controlPlaneRate = 10          // Requests per second
+	describeCacheTTL = time.Minute // How long a DescribeTable result is reused
+)
+
+// controlPlane paces control-plane requests and caches DescribeTable results
+type controlPlane struct {
+	mu       sync.Mutex
+	next     time.Time // Earliest start of the next request
+	cache    map[string]cachedTable
+	inflight map[string]*describeCall
+}
+
+// cachedTable is a DescribeTable result and when it was fetched
+type cachedTable struct {
+	info      TableInfo
+	fetchedAt time.Time
+}
+
+// describeCall is a DescribeTable request shared by every caller asking for
+// the same table while it runs
+type describeCall struct {
+	done chan struct{}
+	info TableInfo
+	err  error
+}
+
+// wait blocks until the next control-plane request may start
+func (p *controlPlane) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(time.Second / controlPlaneRate)
+	p.mu.Unlock()
+	return sleepContext(ctx, start.Sub(now))
+}
+
+// describeTable returns the metadata of a table, from the cache when it was
+// described within describeCacheTTL. Concurrent requests for the same table
+// share a single DescribeTable call.
+func (c *Client) describeTable(ctx context.Context, name string) (TableInfo, error) {
+	p := &c.control
+	for {
+		p.mu.Lock()
+		if cached, ok := p.cache[name]; ok && time.Since(cached.fetchedAt) < describeCacheTTL {
+			p.mu.Unlock()
+			return cached.info, nil
+		}
+		if call, ok := p.inflight[name]; ok {
+			p.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return TableInfo{}, ctx.Err()
+			}
+			// The caller that started the request gave up; try again with our context
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.info, call.err
+		}
+
+		call := &describeCall{done: make(chan struct{})}
+		if p.inflight == nil {
+			p.inflight = make(map[string]*describeCall)
+			p.cache = make(map[string]cachedTable)
+		}
+		p.inflight[name] = call
+		p.mu.Unlock()
+
+		call.info, call.err = c.getTableInfo(ctx, name)
+
+		p.mu.Lock()
+		delete(p.inflight, name)
+		if call.err == nil {
+			p.cache[name] = cachedTable{info: call.info, fetchedAt: time.Now()}
+		}
+		p.mu.Unlock()
+		close(call.done)
+		return call.info, call.err
+	}
+}
+
+// store caches a description of a table obtained outside describeTable
+func (p *controlPlane) store(info TableInfo) {
+	p.mu.Lock()
+	if p.cache == nil {
+		p.inflight = make(map[string]*describeCall)
+		p.cache = make(map[string]cachedTable)
+	}
+	p.cache[info.Name] = cachedTable{info: info, fetchedAt: time.Now()}
+	p.mu.Unlock()
+}
+
+// forget drops the cached description of a table
+func (p *controlPlane) forget(name string) {
+	p.mu.Lock()
+	delete(p.cache, name)
+	p.mu.Unlock()
+}
+
+// isContextError reports whether err comes from a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchWriteItems is the BatchWriteItem limit per request
+const maxBatchWriteItems = 25
+
+// WriteProgress counts the batch writes of a copy or import
+type WriteProgress struct {
+	Written            int64   // Items written to the target table
+	WriteUnits         float64 // Write capacity consumed on the target
+	UnprocessedRetries int     // Batches retried because the target was throttled
+}
+
+// CopyProgress reports how far a table copy got
+type CopyProgress struct {
+	Scanned   int64   // Items read from the source table
+	ReadUnits float64 // Read capacity consumed on the source
+	WriteProgress
+}
+
+// CountItems counts the items of a table with a full Select=COUNT scan,
+// which reads the whole table but transfers no items. It returns the count
+// and the read capacity used.
+func (c *Client) CountItems(ctx context.Context, tableName string, progress func(count int64)) (int64, float64, error) {
+	input := &dynamodb.ScanInput{
+		TableName:              aws.String(tableName),
+		Select:                 types.SelectCount,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	var count int64
+	var consumed float64
+	for {
+		result, err := c.api().Scan(ctx, input)
+		if err != nil {
+			return 0, 0, c.tableError(tableName, err)
+		}
+		count += int64(result.Count)
+		consumed += c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+		if progress != nil {
+			progress(count)
+		}
+		if result.LastEvaluatedKey == nil {
+			return count, consumed, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// CheckCopyTarget verifies that items of the source table can be written to
+// the target table of another client: it must exist and have the same key
+// schema
+func (c *Client) CheckCopyTarget(ctx context.Context, source string, target *Client, targetTable string) error {
+	src, err := c.describeTable(ctx, source)
+	if err != nil {
+		return err
+	}
+	dst, err := target.describeTable(ctx, targetTable)
+	if err != nil {
+		return err
+	}
+	if src.PartitionKey != dst.PartitionKey || src.SortKey != dst.SortKey {
+		return fmt.Errorf("key schema of %s (%s) doesn't match %s (%s)",
+			targetTable, keySchemaText(dst), source, keySchemaText(src))
+	}
+	return nil
+}
+
+// CopyRequests returns the calls a copy of source to targetTable starts
+// with, serialized like the requests of a WritePlan: the Scan of the source
+// and the BatchWriteItem of the first batch, whose items are read from the
+// source now. Later batches are the same call with the items that follow.
+func (c *Client) CopyRequests(ctx context.Context, source, targetTable string) ([]SerializedRequest, error) {
+	input := copyScanInput(source)
+	scan, err := serializeRequest("Scan", map[string]interface{}{
+		"TableName":              source,
+		"ReturnConsumedCapacity": input.ReturnConsumedCapacity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	requests := []SerializedRequest{scan}
+
+	input.Limit = aws.Int32(maxBatchWriteItems)
+	result, err := c.api().Scan(ctx, input)
+	if err != nil {
+		return nil, c.tableError(source, err)
+	}
+	c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+	if len(result.Items) == 0 {
+		return requests, nil
+	}
+	puts := make([]interface{}, len(result.Items))
+	for i, item := range result.Items {
+		puts[i] = map[string]interface{}{"PutRequest": map[string]interface{}{"Item": typedItemJSON(item)}}
+	}
+	write, err := serializeRequest("BatchWriteItem", map[string]interface{}{
+		"RequestItems":           map[string]interface{}{targetTable: puts},
+		"ReturnConsumedCapacity": types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(requests, write), nil
+}
+
+// copyScanInput is the scan a copy reads the source table with
+func copyScanInput(source string) *dynamodb.ScanInput {
+	return &dynamodb.ScanInput{
+		TableName:              aws.String(source),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+}
+
+// keySchemaText names the key attributes of a table
+func keySchemaText(t TableInfo) string {
+	if t.SortKey == "" {
+		return t.PartitionKey
+	}
+	return t.PartitionKey + ", " + t.SortKey
+}
+
+// CopyTable scans the source table and writes every item to targetTable
+// through the target client, which may use another profile or region.
+// Items are copied as read, so binary and set attributes arrive unchanged;
+// items with the same key in the target are overwritten. maxWriteUnits
+// limits the write capacity used per second on the target. progress is
+// called after every batch; on error the progress so far is returned.
+func (c *Client) CopyTable(ctx context.Context, source string, target *Client, targetTable string, maxWriteUnits float64, progress func(CopyProgress)) (CopyProgress, error) {
+	var p CopyProgress
+	limiter := newWriteLimiter(maxWriteUnits)
+	input := copyScanInput(source)
+	for {
+		result, err := c.api().Scan(ctx, input)
+		if err != nil {
+			return p, c.tableError(source, err)
+		}
+		p.Scanned += int64(len(result.Items))
+		p.ReadUnits += c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+
+		for start := 0; start < len(result.Items); start += maxBatchWriteItems {
+			batch := result.Items[start:min(start+maxBatchWriteItems, len(result.Items))]
+			var units float64
+			for _, item := range batch {
+				units += writeUnits(item)
+			}
+			if err := limiter.wait(ctx, units); err != nil {
+				return p, err
+			}
+			if err := target.batchWrite(ctx, targetTable, batch, &p.WriteProgress); err != nil {
+				return p, err
+			}
+			if progress != nil {
+				progress(p)
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			return p, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// batchWrite puts up to 25 items, retrying unprocessed items with backoff
+func (c *Client) batchWrite(ctx context.Context, tableName string, items []map[string]types.AttributeValue, p *WriteProgress) error {
+	requests := make([]types.WriteRequest, len(items))
+	for i, item := range items {
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+	}
+	requestItems := map[string][]types.WriteRequest{tableName: requests}
+	backoff := 50 * time.Millisecond
+	for {
+		result, err := c.api().BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems:           requestItems,
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		})
+		if err != nil {
+			return c.tableError(tableName, err)
+		}
+		for _, cc := range result.ConsumedCapacity {
+			p.WriteUnits += aws.ToFloat64(cc.CapacityUnits)
+		}
+		unprocessed := len(result.UnprocessedItems[tableName])
+		p.Written += int64(len(requestItems[tableName]) - unprocessed)
+		if unprocessed == 0 {
+			return nil
+		}
+		// Unprocessed items are usually caused by throttling, so back off before retrying
+		p.UnprocessedRetries++
+		requestItems = result.UnprocessedItems
+		if err := sleepContext(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, 5*time.Second)
+	}
+}
+
+// writeUnits is the write capacity a put of item consumes: one unit per
+// started KB
+func writeUnits(item map[string]types.AttributeValue) float64 {
+	return float64(max(1, (itemSize(item)+1023)/1024))
+}
+
+// writeLimiter spaces out writes so they stay under a number of write
+// units per second
+type writeLimiter struct {
+	perSecond float64
+	next      time.Time // Earliest time the next batch may be sent
+}
+
+func newWriteLimiter(perSecond float64) *writeLimiter {
+	return &writeLimiter{perSecond: perSecond}
+}
+
+// wait blocks until units may be written. A limit of 0 doesn't limit.
+func (l *writeLimiter) wait(ctx context.Context, units float64) error {
+	if l.perSecond <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	if err := sleepContext(ctx, l.next.Sub(now)); err != nil {
+		return err
+	}
+	l.next = l.next.Add(time.Duration(units / l.perSecond * float64(time.Second)))
+	return nil
+}
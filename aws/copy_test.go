@@ -0,0 +1,35 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestCopyRequestsShowTheFirstBatch(t *testing.T) {
+	client := binaryKeyClient(t)
+	requests, err := client.CopyRequests(context.Background(), "blobs", "blobs-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0].Operation != "Scan" || requests[1].Operation != "BatchWriteItem" {
+		t.Fatalf("got requests %+v, want a Scan and a BatchWriteItem", requests)
+	}
+	var write struct {
+		RequestItems map[string][]struct {
+			PutRequest struct {
+				Item map[string]map[string]interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(requests[1].Body), &write); err != nil {
+		t.Fatal(err)
+	}
+	puts := write.RequestItems["blobs-copy"]
+	if len(puts) != 3 {
+		t.Fatalf("the batch writes %d items to blobs-copy, want 3", len(puts))
+	}
+	if id := puts[0].PutRequest.Item["id"]["B"]; id != "AQE=" {
+		t.Errorf("the first item's id is %v, want the base64 of its bytes", puts[0].PutRequest.Item["id"])
+	}
+}
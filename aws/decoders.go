@@ -0,0 +1,269 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// AttributeDecoder decodes the bytes of a binary attribute into a
+// JSON-compatible value so it can be displayed as structured data
+type AttributeDecoder interface {
+	Name() string
+	Decode(b []byte) (interface{}, error)
+}
+
+// AttributeEncoder is implemented by decoders that can turn an edited value
+// back into the stored binary form
+type AttributeEncoder interface {
+	Encode(v interface{}) ([]byte, error)
+}
+
+// RegisterDecoder assigns a decoder to a binary attribute. attr is either an
+// attribute name, which applies to every table, or a "table.attribute" pair.
+func (c *Client) RegisterDecoder(attr string, d AttributeDecoder) {
+	if c.decoders == nil {
+		c.decoders = make(map[string]AttributeDecoder)
+	}
+	c.decoders[attr] = d
+}
+
+// decoderFor returns the decoder registered for the attribute, preferring a table-scoped entry
+func (c *Client) decoderFor(tableName, attr string) AttributeDecoder {
+	if d, ok := c.decoders[tableName+"."+attr]; ok {
+		return d
+	}
+	return c.decoders[attr]
+}
+
+// DecoderSpec parses a decoder specification of the form
+//
+//	ATTR=gzip-json
+//	ATTR=proto:DESCRIPTOR_SET:MESSAGE
+//	ATTR=avro:SCHEMA_FILE
+//
+// and returns the attribute it applies to together with the loaded decoder.
+func DecoderSpec(spec string) (string, AttributeDecoder, error) {
+	attr, def, ok := strings.Cut(spec, "=")
+	if !ok || attr == "" {
+		return "", nil, fmt.Errorf("invalid decoder %q: expected ATTR=KIND[:ARGS]", spec)
+	}
+
+	// Paths may hold colons themselves, as in C:\schemas\x.proto, so only the
+	// kind before the first colon and the message name after the last one
+	// are split off
+	kind, args, _ := strings.Cut(def, ":")
+	switch kind {
+	case "gzip-json":
+		return attr, gzipJSONCodec{}, nil
+	case "proto":
+		i := strings.LastIndex(args, ":")
+		if i <= 0 || i == len(args)-1 {
+			return "", nil, fmt.Errorf("invalid decoder %q: expected proto:DESCRIPTOR_SET:MESSAGE", spec)
+		}
+		d, err := NewProtoDecoder(args[:i], args[i+1:])
+		return attr, d, err
+	case "avro":
+		if args == "" {
+			return "", nil, fmt.Errorf("invalid decoder %q: expected avro:SCHEMA_FILE", spec)
+		}
+		d, err := NewAvroDecoder(args)
+		return attr, d, err
+	default:
+		return "", nil, fmt.Errorf("invalid decoder %q: unknown kind %q", spec, kind)
+	}
+}
+
+// ProtoDecoder decodes protobuf-encoded attributes using a compiled descriptor set
+type ProtoDecoder struct {
+	desc protoreflect.MessageDescriptor
+}
+
+// NewProtoDecoder loads a FileDescriptorSet (as produced by
+// protoc --include_imports --descriptor_set_out) and looks up the message type
+func NewProtoDecoder(descriptorSetPath, messageName string) (*ProtoDecoder, error) {
+	data, err := os.ReadFile(descriptorSetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %w", descriptorSetPath, err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load descriptor set %s: %w", descriptorSetPath, err)
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, fmt.Errorf("message %s not found in %s: %w", messageName, descriptorSetPath, err)
+	}
+	msg, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", messageName)
+	}
+	return &ProtoDecoder{desc: msg}, nil
+}
+
+// Name implements AttributeDecoder
+func (p *ProtoDecoder) Name() string {
+	return "proto:" + string(p.desc.FullName())
+}
+
+// Decode unmarshals the message and converts it to JSON-compatible values
+func (p *ProtoDecoder) Decode(b []byte) (interface{}, error) {
+	msg := dynamicpb.NewMessage(p.desc)
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", p.desc.FullName(), err)
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Encode implements AttributeEncoder
+func (p *ProtoDecoder) Encode(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(p.desc)
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", p.desc.FullName(), err)
+	}
+	return proto.Marshal(msg)
+}
+
+// AvroDecoder decodes Avro binary-encoded attributes using an .avsc schema
+type AvroDecoder struct {
+	codec *goavro.Codec
+}
+
+// NewAvroDecoder loads the Avro schema from an .avsc file
+func NewAvroDecoder(schemaPath string) (*AvroDecoder, error) {
+	schema, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Avro schema: %w", err)
+	}
+
+	codec, err := goavro.NewCodec(string(schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Avro schema %s: %w", schemaPath, err)
+	}
+	return &AvroDecoder{codec: codec}, nil
+}
+
+// Name implements AttributeDecoder
+func (a *AvroDecoder) Name() string {
+	return "avro"
+}
+
+// Decode reads a single Avro datum and converts it to JSON-compatible values
+func (a *AvroDecoder) Decode(b []byte) (interface{}, error) {
+	native, _, err := a.codec.NativeFromBinary(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Avro datum: %w", err)
+	}
+
+	data, err := a.codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Encode implements AttributeEncoder
+func (a *AvroDecoder) Encode(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	native, _, err := a.codec.NativeFromTextual(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Avro datum: %w", err)
+	}
+	return a.codec.BinaryFromNative(nil, native)
+}
+
+// EncodeAttribute converts an edited Go value back into an attribute value.
+// A value is only re-encoded when old, the value it replaces, is binary and
+// was decoded by the attribute's decoder; a string or number attribute of
+// the same name, or a binary value that failed to decode, is written as is.
+func (c *Client) EncodeAttribute(tableName, attr string, old types.AttributeValue, v interface{}) (types.AttributeValue, error) {
+	b, ok := old.(*types.AttributeValueMemberB)
+	d := c.decoderFor(tableName, attr)
+	if !ok || d == nil {
+		return interfaceToAttributeValue(v)
+	}
+	if _, err := d.Decode(b.Value); err != nil {
+		return interfaceToAttributeValue(v)
+	}
+	enc, ok := d.(AttributeEncoder)
+	if !ok {
+		return nil, fmt.Errorf("attribute %s uses %s which cannot be re-encoded", attr, d.Name())
+	}
+	encoded, err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AttributeValueMemberB{Value: encoded}, nil
+}
+
+// convertItem converts a DynamoDB item into its display and raw representations,
+// decoding binary attributes with a registered decoder so they render as readable JSON.
+func (c *Client) convertItem(tableName string, item map[string]types.AttributeValue) (map[string]interface{}, map[string]interface{}) {
+	display := make(map[string]interface{})
+	raw := make(map[string]interface{})
+	for k, v := range item {
+		display[k] = formatAttributeValue(v)
+		raw[k] = attributeValueToInterface(v)
+
+		b, ok := v.(*types.AttributeValueMemberB)
+		if !ok {
+			continue
+		}
+		d := c.decoderFor(tableName, k)
+		if d == nil {
+			continue
+		}
+		decoded, err := d.Decode(b.Value)
+		if err != nil {
+			// Keep the binary placeholder but note why decoding failed
+			display[k] = fmt.Sprintf("%s (%s decode failed: %v)", display[k], d.Name(), err)
+			continue
+		}
+		raw[k] = decoded
+		if compact, err := json.Marshal(decoded); err == nil {
+			display[k] = string(compact)
+		}
+	}
+	return display, raw
+}
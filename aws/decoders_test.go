@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestDecoderSpecKeepsColonsInPaths(t *testing.T) {
+	tests := []struct {
+		spec string
+		path string // The file the decoder tried to read
+	}{
+		{`payload=proto:C:\schemas\x.pb:shop.Order`, `C:\schemas\x.pb`},
+		{`payload=proto:/schemas/x.pb:shop.Order`, `/schemas/x.pb`},
+		{`payload=avro:C:\schemas\x.avsc`, `C:\schemas\x.avsc`},
+	}
+	for _, tt := range tests {
+		_, _, err := DecoderSpec(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.path) {
+			t.Errorf("DecoderSpec(%q) = %v, want an error reading %s", tt.spec, err, tt.path)
+		}
+	}
+}
+
+func TestDecoderSpecRejectsMalformedSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"payload",
+		"=gzip-json",
+		"payload=proto:x.pb",
+		"payload=proto:x.pb:",
+		"payload=avro",
+		"payload=xml:x.xsd",
+	} {
+		if _, _, err := DecoderSpec(spec); err == nil || !strings.Contains(err.Error(), "invalid decoder") {
+			t.Errorf("DecoderSpec(%q) = %v, want an invalid decoder error", spec, err)
+		}
+	}
+	attr, d, err := DecoderSpec("payload=gzip-json")
+	if err != nil || attr != "payload" || d == nil {
+		t.Errorf(`DecoderSpec("payload=gzip-json") = %q, %v, %v`, attr, d, err)
+	}
+}
+
+func TestEncodeAttributeOnlyReencodesDecodedBinaries(t *testing.T) {
+	compressed, err := gzipJSONCodec{}.Encode(map[string]interface{}{"v": "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFakeClient("test", []FakeTable{{
+		Name:         "docs",
+		PartitionKey: "id",
+		Items: []map[string]interface{}{
+			{"id": "gzip", "payload": compressed},
+			{"id": "text", "payload": "plain text"},
+			{"id": "broken", "payload": []byte("not gzip")},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetCompressedAttributes([]string{"payload"})
+
+	items, err := client.ScanForEdit(context.Background(), "docs", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		id := item.Values["id"].(string)
+		switch id {
+		case "gzip":
+			item.Values["payload"] = map[string]interface{}{"v": "new"}
+		case "text":
+			item.Values["payload"] = "edited text"
+		case "broken":
+			item.Values["payload"] = []byte("still not gzip")
+		}
+		av, err := client.editedItem("docs", item)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		switch payload := av["payload"].(type) {
+		case *types.AttributeValueMemberB:
+			if id == "text" {
+				t.Errorf("the string payload was written as binary")
+			}
+			if id == "broken" && string(payload.Value) != "still not gzip" {
+				t.Errorf("the payload that failed to decode was re-encoded to %q", payload.Value)
+			}
+			if id == "gzip" {
+				if decoded, err := (gzipJSONCodec{}).Decode(payload.Value); err != nil || decoded.(map[string]interface{})["v"] != "new" {
+					t.Errorf("the decoded payload was written as %v, %v", decoded, err)
+				}
+			}
+		case *types.AttributeValueMemberS:
+			if id != "text" || payload.Value != "edited text" {
+				t.Errorf("%s: payload written as the string %q", id, payload.Value)
+			}
+		default:
+			t.Errorf("%s: payload written as %T", id, payload)
+		}
+	}
+
+	imported, err := client.importedItem(ImportParams{TableName: "docs"}, []byte(`{"id": "new", "payload": "plain text"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := imported["payload"].(*types.AttributeValueMemberS); !ok {
+		t.Errorf("an imported string payload was written as %T", imported["payload"])
+	}
+}
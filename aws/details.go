@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableDetails is the full configuration of a table for the detail page:
+// the DescribeTable output beyond what TableInfo keeps, and its tags
+type TableDetails struct {
+	TableInfo
+	StreamLabel        string
+	TableClass         string // STANDARD or STANDARD_INFREQUENT_ACCESS
+	DeletionProtection bool
+	Encryption         string // KMS with the key ARN, or empty for the AWS owned key
+	MaxReadUnits       int64  // On-demand read request unit limit, 0 when not set
+	MaxWriteUnits      int64  // On-demand write request unit limit, 0 when not set
+	Replicas           []string
+	Tags               map[string]string
+}
+
+// DescribeTableDetails describes a table together with its tags. The table
+// is described afresh rather than from the cache, which also updates it.
+func (c *Client) DescribeTableDetails(ctx context.Context, name string) (TableDetails, error) {
+	if err := c.control.wait(ctx); err != nil {
+		return TableDetails{}, err
+	}
+	result, err := c.api().DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &name})
+	if err != nil {
+		return TableDetails{}, c.tableError(name, err)
+	}
+	table := result.Table
+	details := TableDetails{
+		TableInfo:          tableInfoFrom(table),
+		StreamLabel:        aws.ToString(table.LatestStreamLabel),
+		TableClass:         string(types.TableClassStandard),
+		DeletionProtection: aws.ToBool(table.DeletionProtectionEnabled),
+	}
+
+	if tc := table.TableClassSummary; tc != nil && tc.TableClass != "" {
+		details.TableClass = string(tc.TableClass)
+	}
+	if sse := table.SSEDescription; sse != nil && sse.Status == types.SSEStatusEnabled {
+		details.Encryption = string(sse.SSEType)
+		if arn := aws.ToString(sse.KMSMasterKeyArn); arn != "" {
+			details.Encryption += " (" + arn + ")"
+		}
+	}
+	if odt := table.OnDemandThroughput; odt != nil {
+		details.MaxReadUnits = aws.ToInt64(odt.MaxReadRequestUnits)
+		details.MaxWriteUnits = aws.ToInt64(odt.MaxWriteRequestUnits)
+	}
+	for _, r := range table.Replicas {
+		details.Replicas = append(details.Replicas, fmt.Sprintf("%s (%s)", aws.ToString(r.RegionName), r.ReplicaStatus))
+	}
+
+	if err := c.describeTTL(ctx, &details.TableInfo); err != nil {
+		return TableDetails{}, err
+	}
+	c.control.store(details.TableInfo)
+
+	if details.Tags, err = c.tableTags(ctx, details.ARN); err != nil {
+		return TableDetails{}, fmt.Errorf("failed to list tags of %s: %w", name, err)
+	}
+	return details, nil
+}
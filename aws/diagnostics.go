@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// RequestDiagnostics describes one DynamoDB request of a read: how long it
+// took and every attempt the SDK made to send it
+type RequestDiagnostics struct {
+	Operation string        // Query, Scan or BatchGetItem
+	Duration  time.Duration // From the first attempt to the parsed response, including retry backoff
+	Attempts  []AttemptDiagnostics
+}
+
+// Retries is the number of attempts after the first
+func (r RequestDiagnostics) Retries() int {
+	return max(len(r.Attempts)-1, 0)
+}
+
+// AttemptDiagnostics times one HTTP round trip of a request
+type AttemptDiagnostics struct {
+	Reused  bool          // Sent over an open connection, so without DNS, connect and TLS
+	DNS     time.Duration // Name lookup
+	Connect time.Duration // TCP connect
+	TLS     time.Duration // TLS handshake
+	Latency time.Duration // From sending the request to the last byte of the response
+	Bytes   int64         // Response body bytes received
+	Status  int           // HTTP status, 0 when no response arrived
+}
+
+// diagnostics collects the requests made with a context from
+// withDiagnostics. The attempts of a request are filled in by the HTTP
+// client, partly from the goroutines of the transport, hence the lock.
+type diagnostics struct {
+	mu       sync.Mutex
+	requests []*requestRecord
+}
+
+type requestRecord struct {
+	RequestDiagnostics
+	attempts []*AttemptDiagnostics
+}
+
+type diagnosticsKey struct{}
+type requestKey struct{}
+
+// withDiagnostics returns a context whose DynamoDB requests are recorded
+func withDiagnostics(ctx context.Context) (context.Context, *diagnostics) {
+	d := &diagnostics{}
+	return context.WithValue(ctx, diagnosticsKey{}, d), d
+}
+
+// result copies the requests recorded so far
+func (d *diagnostics) result() []RequestDiagnostics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	requests := make([]RequestDiagnostics, len(d.requests))
+	for i, r := range d.requests {
+		requests[i] = r.RequestDiagnostics
+		requests[i].Attempts = make([]AttemptDiagnostics, len(r.attempts))
+		for j, a := range r.attempts {
+			requests[i].Attempts[j] = *a
+		}
+	}
+	return requests
+}
+
+// addDiagnosticsMiddleware times every operation sent with a context from
+// withDiagnostics and marks its context for diagnosticsHTTPClient. It runs
+// before the retry loop, so an operation is recorded once however many
+// attempts it takes.
+func addDiagnosticsMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ReadDiagnostics", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		d, ok := ctx.Value(diagnosticsKey{}).(*diagnostics)
+		if !ok {
+			return next.HandleInitialize(ctx, in)
+		}
+		r := &requestRecord{RequestDiagnostics: RequestDiagnostics{Operation: middleware.GetOperationName(ctx)}}
+		d.mu.Lock()
+		d.requests = append(d.requests, r)
+		d.mu.Unlock()
+
+		start := time.Now()
+		out, metadata, err := next.HandleInitialize(context.WithValue(ctx, requestKey{}, r), in)
+		d.mu.Lock()
+		r.Duration = time.Since(start)
+		d.mu.Unlock()
+		return out, metadata, err
+	}), middleware.After)
+}
+
+// httpClient is the HTTP client interface of the SDK
+type httpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// diagnosticsHTTPClient records connection setup, latency and size of every
+// attempt of the operations addDiagnosticsMiddleware marked
+type diagnosticsHTTPClient struct {
+	next httpClient
+}
+
+func (c diagnosticsHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	d, _ := req.Context().Value(diagnosticsKey{}).(*diagnostics)
+	r, ok := req.Context().Value(requestKey{}).(*requestRecord)
+	if d == nil || !ok {
+		return c.next.Do(req)
+	}
+	a := &AttemptDiagnostics{}
+	d.mu.Lock()
+	r.attempts = append(r.attempts, a)
+	d.mu.Unlock()
+
+	// Timestamps are guarded by the lock as well, since dialing reports from
+	// its own goroutine
+	start := time.Now()
+	var dnsStart, connectStart, tlsStart time.Time
+	since := func(t time.Time) time.Duration {
+		if t.IsZero() {
+			return 0
+		}
+		return time.Since(t)
+	}
+	locked := func(f func()) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		f()
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { locked(func() { dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { locked(func() { a.DNS = since(dnsStart) }) },
+		ConnectStart: func(string, string) {
+			locked(func() { connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			locked(func() { a.Connect = since(connectStart) })
+		},
+		TLSHandshakeStart: func() { locked(func() { tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			locked(func() { a.TLS = since(tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) { locked(func() { a.Reused = info.Reused }) },
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			locked(func() { start = time.Now() })
+		},
+	}
+
+	resp, err := c.next.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		locked(func() { a.Latency = time.Since(start) })
+		return resp, err
+	}
+	locked(func() { a.Status = resp.StatusCode })
+	resp.Body = &countingBody{ReadCloser: resp.Body, d: d, a: a, start: &start}
+	return resp, nil
+}
+
+// countingBody counts the bytes of a response body and completes the
+// latency of its attempt once the body was read or closed
+type countingBody struct {
+	io.ReadCloser
+	d     *diagnostics
+	a     *AttemptDiagnostics
+	start *time.Time
+	done  bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.d.mu.Lock()
+	b.a.Bytes += int64(n)
+	if err == io.EOF {
+		b.finish()
+	}
+	b.d.mu.Unlock()
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.d.mu.Lock()
+	b.finish()
+	b.d.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+// finish sets the latency on the first call; the lock must be held
+func (b *countingBody) finish() {
+	if !b.done {
+		b.a.Latency = time.Since(*b.start)
+		b.done = true
+	}
+}
@@ -2,10 +2,15 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -15,26 +20,134 @@ import (
 
 // Client wraps the DynamoDB client
 type Client struct {
-	svc *dynamodb.Client
+	svcMu    sync.RWMutex // Guards svc, which RefreshCredentials replaces
+	svc      dynamoDB
+	profile  string
+	opts     clientOptions
+	decoders map[string]AttributeDecoder
+	capacity capacityMeter
+	control  controlPlane
+}
+
+// api is the DynamoDB client requests are sent through. Background reads
+// call it while RefreshCredentials may be replacing it on the UI goroutine.
+func (c *Client) api() dynamoDB {
+	c.svcMu.RLock()
+	defer c.svcMu.RUnlock()
+	return c.svc
+}
+
+// clientOptions holds the optional connection settings applied by Option
+type clientOptions struct {
+	roleARN          string
+	mfaSerial        string
+	mfaTokenProvider func() (string, error)
+	throttleHandler  func(ThrottleEvent)
+	region           string // Empty for defaultRegion
+	endpoint         string // Empty for the regional endpoint
+	pageSize         int    // Items read per Query or Scan request, 0 for DefaultPageSize
+	logger           *slog.Logger // Nil to log nothing
+}
+
+// Option configures optional connection settings for NewClient
+type Option func(*clientOptions)
+
+// DefaultPageSize is the number of items a query or scan reads per request
+// unless WithPageSize says otherwise
+const DefaultPageSize = 15
+
+// WithPageSize sets the number of items a query or scan reads per request
+func WithPageSize(n int) Option {
+	return func(o *clientOptions) {
+		o.pageSize = n
+	}
+}
+
+// pageLimit is the Limit of a Query or Scan request
+func (c *Client) pageLimit() *int32 {
+	n := int32(DefaultPageSize)
+	if c.opts.pageSize > 0 {
+		n = int32(c.opts.pageSize)
+	}
+	return &n
+}
+
+// WithRoleARN makes the client assume the given IAM role via STS before
+// accessing DynamoDB, allowing tables in other accounts to be browsed
+func WithRoleARN(roleARN string) Option {
+	return func(o *clientOptions) {
+		o.roleARN = roleARN
+	}
+}
+
+// WithMFA sets the MFA device serial used when assuming a role and the
+// function that prompts the user for the current token code
+func WithMFA(serial string, tokenProvider func() (string, error)) Option {
+	return func(o *clientOptions) {
+		o.mfaSerial = serial
+		o.mfaTokenProvider = tokenProvider
+	}
 }
 
 // NewClient creates a new DynamoDB client with the given profile
-func NewClient(profile string) (*Client, error) {
+func NewClient(profile string, options ...Option) (*Client, error) {
+	var opts clientOptions
+	for _, o := range options {
+		o(&opts)
+	}
+
+	svc, err := newDynamoDBClient(profile, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{svc: svc, profile: profile, opts: opts}, nil
+}
+
+// newDynamoDBClient loads the AWS config for the profile and builds the service client
+func newDynamoDBClient(profile string, opts clientOptions) (*dynamodb.Client, error) {
+	region := opts.region
+	if region == "" {
+		region = defaultRegion
+	}
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
 		config.WithSharedConfigProfile(profile),
-		config.WithRegion("us-east-1"), // TODO: make configurable
+		config.WithRegion(region),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config with profile %s: %w", profile, err)
 	}
 
-	svc := dynamodb.NewFromConfig(cfg)
-	return &Client{svc: svc}, nil
+	if opts.roleARN != "" {
+		cfg.Credentials = assumeRoleCredentials(cfg, opts)
+	}
+	notify := opts.throttleHandler
+	if opts.logger != nil {
+		notify = func(e ThrottleEvent) {
+			opts.logger.Info("throttled", "attempt", e.Attempt, "delay", e.Delay, "error", e.Err.Error())
+			if opts.throttleHandler != nil {
+				opts.throttleHandler(e)
+			}
+		}
+	}
+	cfg.Retryer = func() aws.Retryer {
+		return newRetryer(notify)
+	}
+
+	return dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if opts.endpoint != "" {
+			o.BaseEndpoint = aws.String(opts.endpoint)
+		}
+		o.HTTPClient = diagnosticsHTTPClient{next: o.HTTPClient}
+		o.APIOptions = append(o.APIOptions, addDiagnosticsMiddleware)
+		if opts.logger != nil {
+			o.APIOptions = append(o.APIOptions, loggingMiddleware(opts.logger))
+		}
+	}), nil
 }
 
 // TestConnection tests the connection by listing tables
-func (c *Client) TestConnection() error {
-	_, err := c.svc.ListTables(context.TODO(), &dynamodb.ListTablesInput{})
+func (c *Client) TestConnection(ctx context.Context) error {
+	_, err := c.api().ListTables(ctx, &dynamodb.ListTablesInput{})
 	if err != nil {
 		return fmt.Errorf("failed to list tables: %w", err)
 	}
@@ -43,48 +156,206 @@ func (c *Client) TestConnection() error {
 
 // TableInfo holds table metadata
 type TableInfo struct {
-	Name         string
-	Status       string
-	ItemCount    int64
-	SizeBytes    int64
-	PartitionKey string
-	SortKey      string
-	SchemaFields []string
-}
-
-// ListTables returns a list of table info
-func (c *Client) ListTables() ([]TableInfo, error) {
-	result, err := c.svc.ListTables(context.TODO(), &dynamodb.ListTablesInput{})
-	if err != nil {
-		return nil, err
+	Name           string
+	ARN            string
+	Status         string
+	ItemCount      int64
+	SizeBytes      int64
+	PartitionKey   string
+	SortKey        string
+	SchemaFields   []string
+	Indexes        []IndexInfo
+	CreatedAt      time.Time
+	Described      bool              // False for tables listed by name only, before DescribeTable ran
+	BillingMode    string            // PROVISIONED or PAY_PER_REQUEST
+	ReadCapacity   int64             // Provisioned read capacity units, 0 for on-demand tables
+	WriteCapacity  int64             // Provisioned write capacity units, 0 for on-demand tables
+	StreamView     string            // View type of the table's stream, empty when streams are off
+	StreamARN      string            // Latest stream of the table, set while streams are on
+	TTLAttribute   string            // Attribute holding the expiry time, empty when TTL was never enabled
+	TTLStatus      string            // ENABLED, DISABLED, ENABLING or DISABLING
+	AttributeTypes map[string]string // S, N or B for every key attribute of the table and its indexes
+}
+
+// IndexInfo holds secondary index metadata
+type IndexInfo struct {
+	Name             string
+	Status           string   // CREATING, UPDATING, DELETING or ACTIVE
+	PartitionKey     string
+	SortKey          string
+	ProjectionType   string   // ALL, KEYS_ONLY or INCLUDE
+	NonKeyAttributes []string // Extra attributes projected by an INCLUDE index
+	ReadCapacity     int64    // Provisioned read capacity units, 0 for on-demand tables
+	WriteCapacity    int64    // Provisioned write capacity units, 0 for on-demand tables
+	ItemCount        int64    // Approximate, updated by DynamoDB every six hours
+	SizeBytes        int64    // Approximate, updated by DynamoDB every six hours
+	Local            bool     // An LSI: same partition key as the table, shares its capacity
+}
+
+// Index returns the secondary index with the given name
+func (t TableInfo) Index(name string) (IndexInfo, bool) {
+	for _, idx := range t.Indexes {
+		if idx.Name == name {
+			return idx, true
+		}
 	}
+	return IndexInfo{}, false
+}
 
+// GlobalIndexes returns the GSIs of the table
+func (t TableInfo) GlobalIndexes() []IndexInfo {
+	var global []IndexInfo
+	for _, idx := range t.Indexes {
+		if !idx.Local {
+			global = append(global, idx)
+		}
+	}
+	return global
+}
+
+// LocalIndexes returns the LSIs of the table
+func (t TableInfo) LocalIndexes() []IndexInfo {
+	var local []IndexInfo
+	for _, idx := range t.Indexes {
+		if idx.Local {
+			local = append(local, idx)
+		}
+	}
+	return local
+}
+
+// ProjectsAll reports whether the index carries every attribute of the base table items
+func (i IndexInfo) ProjectsAll() bool {
+	return i.ProjectionType == "" || i.ProjectionType == string(types.ProjectionTypeAll)
+}
+
+// ListTables returns a list of table info, following ListTables pagination
+// until every table name has been fetched. If progress is not nil it is called
+// with the tables loaded so far after each page is described.
+func (c *Client) ListTables(ctx context.Context, progress func(loaded []TableInfo)) ([]TableInfo, error) {
 	var tables []TableInfo
-	for _, name := range result.TableNames {
-		info, err := c.getTableInfo(name)
+	input := &dynamodb.ListTablesInput{}
+	for {
+		if err := c.control.wait(ctx); err != nil {
+			return nil, err
+		}
+		result, err := c.api().ListTables(ctx, input)
 		if err != nil {
-			// Skip tables with errors, or return partial
-			continue
+			return nil, err
 		}
-		tables = append(tables, info)
-	}
 
-	// Sort by ItemCount descending
-	sort.Slice(tables, func(i, j int) bool {
-		return tables[i].ItemCount > tables[j].ItemCount
-	})
+		for _, name := range result.TableNames {
+			info, err := c.describeTable(ctx, name)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Skip tables with errors, or return partial
+				continue
+			}
+			tables = append(tables, info)
+		}
+
+		// Sort by ItemCount descending
+		sort.Slice(tables, func(i, j int) bool {
+			return tables[i].ItemCount > tables[j].ItemCount
+		})
+
+		if result.LastEvaluatedTableName == nil {
+			break
+		}
+		if progress != nil {
+			progress(append([]TableInfo(nil), tables...))
+		}
+		input.ExclusiveStartTableName = result.LastEvaluatedTableName
+	}
 
 	return tables, nil
 }
 
+// ListTableNames returns the names of all tables without describing them,
+// for callers that load table metadata lazily
+func (c *Client) ListTableNames(ctx context.Context) ([]string, error) {
+	var names []string
+	input := &dynamodb.ListTablesInput{}
+	for {
+		if err := c.control.wait(ctx); err != nil {
+			return nil, err
+		}
+		result, err := c.api().ListTables(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, result.TableNames...)
+
+		if result.LastEvaluatedTableName == nil {
+			return names, nil
+		}
+		input.ExclusiveStartTableName = result.LastEvaluatedTableName
+	}
+}
+
+// DescribeTable returns the metadata of a single table. Results are cached
+// for a minute and requests are paced to the control-plane rate limit.
+func (c *Client) DescribeTable(ctx context.Context, name string) (TableInfo, error) {
+	return c.describeTable(ctx, name)
+}
+
+// RefreshTable describes a table again rather than reusing a recent
+// description, for following its item count and size
+func (c *Client) RefreshTable(ctx context.Context, name string) (TableInfo, error) {
+	c.control.forget(name)
+	return c.describeTable(ctx, name)
+}
+
+// DeleteTable deletes a table and every item in it. DynamoDB removes the
+// table in the background; it is listed as DELETING until then.
+func (c *Client) DeleteTable(ctx context.Context, name string) error {
+	if err := c.control.wait(ctx); err != nil {
+		return err
+	}
+	if _, err := c.api().DeleteTable(ctx, deleteTableInput(name)); err != nil {
+		return c.tableError(name, err)
+	}
+	c.control.forget(name)
+	return nil
+}
+
+// deleteTableInput is the request DeleteTable sends
+func deleteTableInput(name string) *dynamodb.DeleteTableInput {
+	return &dynamodb.DeleteTableInput{TableName: aws.String(name)}
+}
+
+// DeleteTableRequest returns the request DeleteTable sends, serialized like
+// the requests of a WritePlan
+func DeleteTableRequest(name string) (SerializedRequest, error) {
+	input := deleteTableInput(name)
+	return serializeRequest("DeleteTable", map[string]interface{}{"TableName": aws.ToString(input.TableName)})
+}
+
 // QueryResult holds query results
 type QueryResult struct {
 	Items             []map[string]interface{}
 	RawItems          []map[string]interface{} // Structured data for JSON viewing
-	LastEvaluatedKey map[string]interface{}
+	NextPage         *PageToken // Cursor for the following page, nil on the last page
+	SizeBytes        int64      // Approximate stored size of the items
+	ConsumedCapacity float64    // Read capacity units consumed by the requests
+	Sources          []string // Per-item origin for merged results (e.g. index names)
+	Duplicates       int      // Items dropped because an earlier page of the same read returned them
+	Count            int      // Items the queries or scans returned, after their filter
+	ScannedCount     int      // Items the queries or scans read, before their filter
+	Requests         []RequestDiagnostics // DynamoDB requests of the read, in the order they were sent
 }
 
-// Query executes a query on the table (first batch only)
+// StringSet is a string set (SS) of a raw item. Sets have types of their own
+// so they can be told from lists and written back as the sets they were.
+type StringSet []string
+
+// NumberSet is a number set (NS) of a raw item, its members kept exact
+type NumberSet []json.Number
+
+// BinarySet is a binary set (BS) of a raw item
+type BinarySet [][]byte
 
 // attributeValueToInterface converts a DynamoDB attribute value to Go native types
 func attributeValueToInterface(v types.AttributeValue) interface{} {
@@ -92,14 +363,12 @@ func attributeValueToInterface(v types.AttributeValue) interface{} {
 	case *types.AttributeValueMemberS:
 		return val.Value
 	case *types.AttributeValueMemberN:
-		// Try to parse as int, fallback to string
+		// Integers that fit become int64; anything else is kept as the exact
+		// stored digits, since a float64 can't hold DynamoDB's 38 digits
 		if i, err := strconv.ParseInt(val.Value, 10, 64); err == nil {
 			return i
 		}
-		if f, err := strconv.ParseFloat(val.Value, 64); err == nil {
-			return f
-		}
-		return val.Value
+		return json.Number(val.Value)
 	case *types.AttributeValueMemberBOOL:
 		return val.Value
 	case *types.AttributeValueMemberNULL:
@@ -117,22 +386,78 @@ func attributeValueToInterface(v types.AttributeValue) interface{} {
 		}
 		return m
 	case *types.AttributeValueMemberSS:
-		return val.Value
+		return StringSet(val.Value)
 	case *types.AttributeValueMemberNS:
-		return val.Value
-	case *types.AttributeValueMemberBS:
-		strs := make([]string, len(val.Value))
-		for i, b := range val.Value {
-			strs[i] = fmt.Sprintf("<binary: %d bytes>", len(b))
+		set := make(NumberSet, len(val.Value))
+		for i, n := range val.Value {
+			set[i] = json.Number(n)
 		}
-		return strs
+		return set
+	case *types.AttributeValueMemberBS:
+		return BinarySet(val.Value)
 	case *types.AttributeValueMemberB:
-		return fmt.Sprintf("<binary: %d bytes>", len(val.Value))
+		return val.Value
 	default:
 		return "unknown"
 	}
 }
 
+// interfaceToAttributeValue converts a Go native value back to a DynamoDB attribute value
+func interfaceToAttributeValue(v interface{}) (types.AttributeValue, error) {
+	switch val := v.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case string:
+		return &types.AttributeValueMemberS{Value: val}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: val}, nil
+	case int:
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(val)}, nil
+	case int64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(val, 10)}, nil
+	case float64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(val, 'f', -1, 64)}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: val.String()}, nil
+	case []byte:
+		return &types.AttributeValueMemberB{Value: val}, nil
+	case []string:
+		return &types.AttributeValueMemberSS{Value: val}, nil
+	case StringSet:
+		return &types.AttributeValueMemberSS{Value: val}, nil
+	case NumberSet:
+		members := make([]string, len(val))
+		for i, n := range val {
+			members[i] = n.String()
+		}
+		return &types.AttributeValueMemberNS{Value: members}, nil
+	case BinarySet:
+		return &types.AttributeValueMemberBS{Value: val}, nil
+	case []interface{}:
+		list := make([]types.AttributeValue, len(val))
+		for i, elem := range val {
+			av, err := interfaceToAttributeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case map[string]interface{}:
+		m := make(map[string]types.AttributeValue, len(val))
+		for k, elem := range val {
+			av, err := interfaceToAttributeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = av
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
 // formatAttributeValue formats a DynamoDB attribute value
 func formatAttributeValue(v types.AttributeValue) string {
 	switch val := v.(type) {
@@ -155,13 +480,13 @@ func formatAttributeValue(v types.AttributeValue) string {
 	case *types.AttributeValueMemberL:
 		strs := make([]string, len(val.Value))
 		for i, av := range val.Value {
-			strs[i] = formatAttributeValue(av)
+			strs[i] = formatNestedValue(av)
 		}
 		return fmt.Sprintf("[%s]", strings.Join(strs, ", "))
 	case *types.AttributeValueMemberM:
 		var pairs []string
 		for k, av := range val.Value {
-			pairs = append(pairs, fmt.Sprintf("%s: %s", k, formatAttributeValue(av)))
+			pairs = append(pairs, fmt.Sprintf("%s: %s", k, formatNestedValue(av)))
 		}
 		return fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
 	case *types.AttributeValueMemberNULL:
@@ -173,33 +498,74 @@ func formatAttributeValue(v types.AttributeValue) string {
 	}
 }
 
-func (c *Client) Query(tableName, partitionKey, partitionValue, sortKey, sortValue, condition string, exclusiveStartKey map[string]interface{}) (QueryResult, error) {
-	limit := int32(15) // Load batch of 15 items
+// formatNestedValue formats a value inside a list or map, where an empty
+// string would otherwise leave nothing between the separators
+func formatNestedValue(v types.AttributeValue) string {
+	if s, ok := v.(*types.AttributeValueMemberS); ok && s.Value == "" {
+		return `""`
+	}
+	return formatAttributeValue(v)
+}
+
+// QueryParams describes a key condition query against a table or one of its indexes
+type QueryParams struct {
+	TableName        string
+	IndexName        string // Empty to query the base table
+	PartitionKey     string
+	PartitionValue   string
+	PartitionKeyType string // S, N or B from AttributeDefinitions; S when empty
+	SortKey          string
+	SortValue        string
+	SortValueEnd     string // Upper bound of a between condition
+	SortKeyType      string
+	Condition        string
+	Descending       bool // Return items in descending sort key order (ScanIndexForward=false)
+	FilterAttribute  string // Empty to return every item the key condition reads
+	FilterCondition  string // One of FilterConditions
+	FilterValue      string
+}
+
+// keyValue converts a key value entered as text to the type of its key
+// attribute: numbers as written, binary values from base64
+func keyValue(value, attrType string) (types.AttributeValue, error) {
+	switch attrType {
+	case "N":
+		return &types.AttributeValueMemberN{Value: strings.TrimSpace(value)}, nil
+	case "B":
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("binary key values are entered as base64: %w", err)
+		}
+		return &types.AttributeValueMemberB{Value: b}, nil
+	}
+	return &types.AttributeValueMemberS{Value: value}, nil
+}
+
+// queryInput builds the request of a query without a starting page
+func (c *Client) queryInput(params QueryParams) (*dynamodb.QueryInput, error) {
+	tableName := params.TableName
+	sortKey, sortValue, condition := params.SortKey, params.SortValue, params.Condition
+	pk, err := keyValue(params.PartitionValue, params.PartitionKeyType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", params.PartitionKey, err)
+	}
 	input := &dynamodb.QueryInput{
 		TableName: &tableName,
-		Limit:     &limit,
+		Limit:     c.pageLimit(),
 		KeyConditionExpression: aws.String(fmt.Sprintf("#pk = :pk")),
 		ExpressionAttributeNames: map[string]string{
-			"#pk": partitionKey,
+			"#pk": params.PartitionKey,
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":pk": &types.AttributeValueMemberS{Value: partitionValue},
+			":pk": pk,
 		},
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
-
-	if exclusiveStartKey != nil {
-		// Convert map to AttributeValue map
-		exclKey := make(map[string]types.AttributeValue)
-		for k, v := range exclusiveStartKey {
-			switch val := v.(type) {
-			case string:
-				exclKey[k] = &types.AttributeValueMemberS{Value: val}
-			case int64:
-				exclKey[k] = &types.AttributeValueMemberN{Value: strconv.FormatInt(val, 10)}
-			// Add more types if needed
-			}
-		}
-		input.ExclusiveStartKey = exclKey
+	if params.IndexName != "" {
+		input.IndexName = aws.String(params.IndexName)
+	}
+	if params.Descending {
+		input.ScanIndexForward = aws.Bool(false)
 	}
 
 	if sortKey != "" && sortValue != "" {
@@ -218,104 +584,323 @@ func (c *Client) Query(tableName, partitionKey, partitionValue, sortKey, sortVal
 		case ">=":
 			input.KeyConditionExpression = aws.String("#pk = :pk AND #sk >= :sk")
 		case "between":
-			// For between, need two values, but for now assume single
+			if params.SortValueEnd == "" {
+				return nil, fmt.Errorf("between needs a second value for %s", sortKey)
+			}
 			input.KeyConditionExpression = aws.String("#pk = :pk AND #sk BETWEEN :sk AND :sk2")
-			// TODO: handle between properly
+			sk2, err := keyValue(params.SortValueEnd, params.SortKeyType)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", sortKey, err)
+			}
+			input.ExpressionAttributeValues[":sk2"] = sk2
 		}
 		input.ExpressionAttributeNames["#sk"] = sortKey
-		input.ExpressionAttributeValues[":sk"] = &types.AttributeValueMemberS{Value: sortValue}
+		sk, err := keyValue(sortValue, params.SortKeyType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sortKey, err)
+		}
+		input.ExpressionAttributeValues[":sk"] = sk
 	}
+	if params.FilterAttribute != "" {
+		if err := CheckFilter(params.FilterCondition, params.FilterValue); err != nil {
+			return nil, err
+		}
+		expr, names, values := filterExpression(params.FilterAttribute, params.FilterCondition, params.FilterValue)
+		input.FilterExpression = aws.String(expr)
+		for k, v := range names {
+			input.ExpressionAttributeNames[k] = v
+		}
+		for k, v := range values {
+			input.ExpressionAttributeValues[k] = v
+		}
+	}
+	return input, nil
+}
 
-	result, err := c.svc.Query(context.TODO(), input)
+// Query executes a query on the table or index, one batch at a time. A nil
+// page reads the first batch.
+func (c *Client) Query(ctx context.Context, params QueryParams, page *PageToken) (QueryResult, error) {
+	tableName := params.TableName
+	input, err := c.queryInput(params)
 	if err != nil {
 		return QueryResult{}, err
 	}
+	input.ExclusiveStartKey = page.exclusiveStartKey()
+
+	ctx, diag := withDiagnostics(ctx)
+	result, err := c.api().Query(ctx, input)
+	if err != nil {
+		return QueryResult{}, c.tableError(tableName, err)
+	}
 
 	// Convert items (formatted strings for display)
 	items := make([]map[string]interface{}, len(result.Items))
 	rawItems := make([]map[string]interface{}, len(result.Items))
+	var size int64
 	for i, item := range result.Items {
-		items[i] = make(map[string]interface{})
-		rawItems[i] = make(map[string]interface{})
-		for k, v := range item {
-			items[i][k] = formatAttributeValue(v)
-			rawItems[i][k] = attributeValueToInterface(v)
-		}
+		items[i], rawItems[i] = c.convertItem(tableName, item)
+		size += itemSize(item)
 	}
 
-	// Convert LastEvaluatedKey
-	var lastKey map[string]interface{}
-	if result.LastEvaluatedKey != nil {
-		lastKey = make(map[string]interface{})
-		for k, v := range result.LastEvaluatedKey {
-			lastKey[k] = formatAttributeValue(v)
-		}
-	}
+	return QueryResult{
+		Items:            items,
+		RawItems:         rawItems,
+		NextPage:         newPageToken(result.LastEvaluatedKey),
+		SizeBytes:        size,
+		Count:            int(result.Count),
+		ScannedCount:     int(result.ScannedCount),
+		ConsumedCapacity: c.capacity.record(consumedCapacity(result.ConsumedCapacity)...),
+		Requests:         diag.result(),
+	}, nil
+}
 
-	return QueryResult{Items: items, RawItems: rawItems, LastEvaluatedKey: lastKey}, nil
+// ScanParams describes a scan of a table or one of its indexes, optionally
+// filtered on a single attribute
+type ScanParams struct {
+	TableName       string
+	IndexName       string // Empty to scan the base table
+	FilterAttribute string // Empty to return every item
+	FilterCondition string // One of FilterConditions
+	FilterValue     string // Unused by attribute_exists and attribute_not_exists
 }
 
-// Scan executes a scan on the table
-func (c *Client) Scan(tableName string, exclusiveStartKey map[string]interface{}) (QueryResult, error) {
-	limit := int32(15) // Load batch of 15 items
+// filterExpression builds the FilterExpression of a scan
+func (p ScanParams) filterExpression() (string, map[string]string, map[string]types.AttributeValue, error) {
+	if err := CheckFilter(p.FilterCondition, p.FilterValue); err != nil {
+		return "", nil, nil, err
+	}
+	expr, names, values := filterExpression(p.FilterAttribute, p.FilterCondition, p.FilterValue)
+	return expr, names, values, nil
+}
+
+// scanInput builds the request of a scan without a starting page
+func (c *Client) scanInput(params ScanParams) (*dynamodb.ScanInput, error) {
 	input := &dynamodb.ScanInput{
-		TableName: &tableName,
-		Limit:     &limit,
-	}
-
-	if exclusiveStartKey != nil {
-		// Convert map to AttributeValue map
-		exclKey := make(map[string]types.AttributeValue)
-		for k, v := range exclusiveStartKey {
-			switch val := v.(type) {
-			case string:
-				exclKey[k] = &types.AttributeValueMemberS{Value: val}
-			case int64:
-				exclKey[k] = &types.AttributeValueMemberN{Value: strconv.FormatInt(val, 10)}
-			// Add more types if needed
-			}
+		TableName:              aws.String(params.TableName),
+		Limit:                  c.pageLimit(),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	if params.IndexName != "" {
+		input.IndexName = aws.String(params.IndexName)
+	}
+	if params.FilterAttribute != "" {
+		expr, names, values, err := params.filterExpression()
+		if err != nil {
+			return nil, err
 		}
-		input.ExclusiveStartKey = exclKey
+		input.FilterExpression = aws.String(expr)
+		input.ExpressionAttributeNames = names
+		input.ExpressionAttributeValues = values
 	}
+	return input, nil
+}
 
-	result, err := c.svc.Scan(context.TODO(), input)
+// Scan executes a scan on the table or index
+func (c *Client) Scan(ctx context.Context, params ScanParams, page *PageToken) (QueryResult, error) {
+	tableName := params.TableName
+	input, err := c.scanInput(params)
 	if err != nil {
 		return QueryResult{}, err
 	}
+	input.ExclusiveStartKey = page.exclusiveStartKey()
+
+	ctx, diag := withDiagnostics(ctx)
+	result, err := c.api().Scan(ctx, input)
+	if err != nil {
+		return QueryResult{}, c.tableError(tableName, err)
+	}
 
 	// Convert items (formatted strings for display)
 	items := make([]map[string]interface{}, len(result.Items))
 	rawItems := make([]map[string]interface{}, len(result.Items))
+	var size int64
 	for i, item := range result.Items {
-		items[i] = make(map[string]interface{})
-		rawItems[i] = make(map[string]interface{})
-		for k, v := range item {
-			items[i][k] = formatAttributeValue(v)
-			rawItems[i][k] = attributeValueToInterface(v)
+		items[i], rawItems[i] = c.convertItem(tableName, item)
+		size += itemSize(item)
+	}
+
+	return QueryResult{
+		Items:            items,
+		RawItems:         rawItems,
+		NextPage:         newPageToken(result.LastEvaluatedKey),
+		SizeBytes:        size,
+		Count:            int(result.Count),
+		ScannedCount:     int(result.ScannedCount),
+		ConsumedCapacity: c.capacity.record(consumedCapacity(result.ConsumedCapacity)...),
+		Requests:         diag.result(),
+	}, nil
+}
+
+// BatchGetItems fetches the full items for the given primary keys from the
+// base table. The result is aligned with keys; items that no longer exist are
+// returned as nil maps.
+func (c *Client) BatchGetItems(ctx context.Context, tableName string, keys []map[string]interface{}) (QueryResult, error) {
+	const batchSize = 100 // BatchGetItem limit per request
+
+	items := make([]map[string]interface{}, len(keys))
+	rawItems := make([]map[string]interface{}, len(keys))
+	var consumed float64
+	ctx, diag := withDiagnostics(ctx)
+
+	// Index requested keys by a stable signature so responses can be matched back
+	positions := make(map[string][]int)
+	avKeys := make([]map[string]types.AttributeValue, len(keys))
+	for i, key := range keys {
+		avKey := make(map[string]types.AttributeValue, len(key))
+		for k, v := range key {
+			av, err := interfaceToAttributeValue(v)
+			if err != nil {
+				return QueryResult{}, fmt.Errorf("invalid key attribute %s: %w", k, err)
+			}
+			avKey[k] = av
+		}
+		avKeys[i] = avKey
+		sig := keySignature(avKey, avKey)
+		positions[sig] = append(positions[sig], i)
+	}
+
+	for start := 0; start < len(avKeys); start += batchSize {
+		end := start + batchSize
+		if end > len(avKeys) {
+			end = len(avKeys)
+		}
+
+		requestItems := map[string]types.KeysAndAttributes{
+			tableName: {Keys: avKeys[start:end]},
+		}
+		backoff := 50 * time.Millisecond
+		for len(requestItems) > 0 {
+			result, err := c.api().BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems:           requestItems,
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil {
+				return QueryResult{}, c.tableError(tableName, err)
+			}
+			consumed += c.capacity.record(result.ConsumedCapacity...)
+
+			for _, item := range result.Responses[tableName] {
+				display, raw := c.convertItem(tableName, item)
+				for _, pos := range positions[keySignature(avKeys[start], item)] {
+					items[pos] = display
+					rawItems[pos] = raw
+				}
+			}
+			requestItems = result.UnprocessedKeys
+			if len(requestItems) > 0 {
+				// Unprocessed keys are usually caused by throttling, so back off before retrying
+				if err := sleepContext(ctx, backoff); err != nil {
+					return QueryResult{}, err
+				}
+				backoff *= 2
+			}
 		}
 	}
 
-	// Convert LastEvaluatedKey
-	var lastKey map[string]interface{}
-	if result.LastEvaluatedKey != nil {
-		lastKey = make(map[string]interface{})
-		for k, v := range result.LastEvaluatedKey {
-			lastKey[k] = formatAttributeValue(v)
+	return QueryResult{Items: items, RawItems: rawItems, ConsumedCapacity: consumed, Requests: diag.result()}, nil
+}
+
+// GetItem reads the item with the given primary key with a strongly
+// consistent read, so a write acknowledged just before is seen. The result
+// holds the item, or no items when there is none with that key.
+func (c *Client) GetItem(ctx context.Context, tableName string, key map[string]interface{}) (QueryResult, error) {
+	avKey := make(map[string]types.AttributeValue, len(key))
+	for k, v := range key {
+		av, err := interfaceToAttributeValue(v)
+		if err != nil {
+			return QueryResult{}, fmt.Errorf("invalid key attribute %s: %w", k, err)
 		}
+		avKey[k] = av
+	}
+
+	ctx, diag := withDiagnostics(ctx)
+	result, err := c.api().GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:              &tableName,
+		Key:                    avKey,
+		ConsistentRead:         aws.Bool(true),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return QueryResult{}, c.tableError(tableName, err)
 	}
 
-	return QueryResult{Items: items, RawItems: rawItems, LastEvaluatedKey: lastKey}, nil
+	page := QueryResult{
+		ConsumedCapacity: c.capacity.record(consumedCapacity(result.ConsumedCapacity)...),
+		Requests:         diag.result(),
+	}
+	if result.Item != nil {
+		display, raw := c.convertItem(tableName, result.Item)
+		page.Items = []map[string]interface{}{display}
+		page.RawItems = []map[string]interface{}{raw}
+	}
+	return page, nil
 }
 
-func (c *Client) getTableInfo(name string) (TableInfo, error) {
-	result, err := c.svc.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{
+// sleepContext waits for d, returning early with the context's error if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// keySignature builds a comparable string from the attributes of item named in
+// key. It is their typed JSON, so a string never matches a number and binary
+// values are compared by their bytes rather than their length.
+func keySignature(key, item map[string]types.AttributeValue) string {
+	typed := make(map[string]interface{}, len(key))
+	for k := range key {
+		typed[k] = typedJSON(item[k])
+	}
+	b, _ := json.Marshal(typed) // Typed values always marshal; keys come out sorted
+	return string(b)
+}
+
+// getTableInfo describes a table, bypassing the cache of describeTable
+func (c *Client) getTableInfo(ctx context.Context, name string) (TableInfo, error) {
+	if err := c.control.wait(ctx); err != nil {
+		return TableInfo{}, err
+	}
+	result, err := c.api().DescribeTable(ctx, &dynamodb.DescribeTableInput{
 		TableName: &name,
 	})
 	if err != nil {
+		return TableInfo{}, c.tableError(name, err)
+	}
+	info := tableInfoFrom(result.Table)
+	if err := c.describeTTL(ctx, &info); err != nil {
 		return TableInfo{}, err
 	}
+	return info, nil
+}
 
-	table := result.Table
+// describeTTL adds the TTL setting of a table. It is left empty when the
+// credentials lack dynamodb:DescribeTimeToLive, which shouldn't keep the
+// table from being listed and queried.
+func (c *Client) describeTTL(ctx context.Context, info *TableInfo) error {
+	if err := c.control.wait(ctx); err != nil {
+		return err
+	}
+	result, err := c.api().DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: &info.Name})
+	if err != nil {
+		if isContextError(err) {
+			return err
+		}
+		return nil
+	}
+	if d := result.TimeToLiveDescription; d != nil {
+		info.TTLAttribute = aws.ToString(d.AttributeName)
+		info.TTLStatus = string(d.TimeToLiveStatus)
+	}
+	return nil
+}
+
+// tableInfoFrom converts a DescribeTable result
+func tableInfoFrom(table *types.TableDescription) TableInfo {
 	var partitionKey, sortKey string
 	schemaFields := make(map[string]bool)
 
@@ -333,12 +918,55 @@ func (c *Client) getTableInfo(name string) (TableInfo, error) {
 	}
 
 	// GSI key schemas
+	var indexes []IndexInfo
 	for _, gsi := range table.GlobalSecondaryIndexes {
+		idx := IndexInfo{Name: aws.ToString(gsi.IndexName), Status: string(gsi.IndexStatus)}
 		for _, ks := range gsi.KeySchema {
 			if ks.AttributeName != nil {
 				schemaFields[*ks.AttributeName] = true
+				switch ks.KeyType {
+				case "HASH":
+					idx.PartitionKey = *ks.AttributeName
+				case "RANGE":
+					idx.SortKey = *ks.AttributeName
+				}
+			}
+		}
+		if gsi.Projection != nil {
+			idx.ProjectionType = string(gsi.Projection.ProjectionType)
+			idx.NonKeyAttributes = gsi.Projection.NonKeyAttributes
+		}
+		if pt := gsi.ProvisionedThroughput; pt != nil {
+			idx.ReadCapacity = aws.ToInt64(pt.ReadCapacityUnits)
+			idx.WriteCapacity = aws.ToInt64(pt.WriteCapacityUnits)
+		}
+		idx.ItemCount = aws.ToInt64(gsi.ItemCount)
+		idx.SizeBytes = aws.ToInt64(gsi.IndexSizeBytes)
+		indexes = append(indexes, idx)
+	}
+
+	// LSI key schemas, after the GSIs. LSIs are created with the table and
+	// have no status of their own.
+	for _, lsi := range table.LocalSecondaryIndexes {
+		idx := IndexInfo{
+			Name:         aws.ToString(lsi.IndexName),
+			Status:       string(types.IndexStatusActive),
+			PartitionKey: partitionKey,
+			ItemCount:    aws.ToInt64(lsi.ItemCount),
+			SizeBytes:    aws.ToInt64(lsi.IndexSizeBytes),
+			Local:        true,
+		}
+		for _, ks := range lsi.KeySchema {
+			if ks.AttributeName != nil && ks.KeyType == types.KeyTypeRange {
+				schemaFields[*ks.AttributeName] = true
+				idx.SortKey = *ks.AttributeName
 			}
 		}
+		if lsi.Projection != nil {
+			idx.ProjectionType = string(lsi.Projection.ProjectionType)
+			idx.NonKeyAttributes = lsi.Projection.NonKeyAttributes
+		}
+		indexes = append(indexes, idx)
 	}
 
 	// Convert map to slice
@@ -347,13 +975,39 @@ func (c *Client) getTableInfo(name string) (TableInfo, error) {
 		fields = append(fields, f)
 	}
 
-	return TableInfo{
-		Name:         name,
+	info := TableInfo{
+		Name:         aws.ToString(table.TableName),
 		Status:       string(table.TableStatus),
 		ItemCount:    *table.ItemCount,
 		SizeBytes:    *table.TableSizeBytes,
 		PartitionKey: partitionKey,
 		SortKey:      sortKey,
 		SchemaFields: fields,
-	}, nil
+		Indexes:      indexes,
+		Described:    true,
+	}
+	if len(table.AttributeDefinitions) > 0 {
+		info.AttributeTypes = make(map[string]string, len(table.AttributeDefinitions))
+		for _, def := range table.AttributeDefinitions {
+			info.AttributeTypes[aws.ToString(def.AttributeName)] = string(def.AttributeType)
+		}
+	}
+	if table.CreationDateTime != nil {
+		info.CreatedAt = *table.CreationDateTime
+	}
+	if pt := table.ProvisionedThroughput; pt != nil {
+		info.ReadCapacity = aws.ToInt64(pt.ReadCapacityUnits)
+		info.WriteCapacity = aws.ToInt64(pt.WriteCapacityUnits)
+	}
+	info.ARN = aws.ToString(table.TableArn)
+	// Tables created before on-demand existed have no billing mode summary
+	info.BillingMode = string(types.BillingModeProvisioned)
+	if table.BillingModeSummary != nil {
+		info.BillingMode = string(table.BillingModeSummary.BillingMode)
+	}
+	if ss := table.StreamSpecification; ss != nil && aws.ToBool(ss.StreamEnabled) {
+		info.StreamView = string(ss.StreamViewType)
+		info.StreamARN = aws.ToString(table.LatestStreamArn)
+	}
+	return info
 }
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestKeySignatureTellsValuesApart(t *testing.T) {
+	sig := func(v types.AttributeValue) string {
+		key := map[string]types.AttributeValue{"pk": v}
+		return keySignature(key, key)
+	}
+	pairs := [][2]types.AttributeValue{
+		{&types.AttributeValueMemberB{Value: []byte{1, 2}}, &types.AttributeValueMemberB{Value: []byte{3, 4}}},
+		{&types.AttributeValueMemberS{Value: "1"}, &types.AttributeValueMemberN{Value: "1"}},
+		{&types.AttributeValueMemberS{Value: "a|b"}, &types.AttributeValueMemberS{Value: "a"}},
+	}
+	for _, p := range pairs {
+		if sig(p[0]) == sig(p[1]) {
+			t.Errorf("%#v and %#v have the same signature %s", p[0], p[1], sig(p[0]))
+		}
+	}
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberB{Value: []byte{1, 2}}}
+	item := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberB{Value: []byte{1, 2}}, "other": &types.AttributeValueMemberS{Value: "x"}}
+	if keySignature(key, key) != keySignature(key, item) {
+		t.Error("an item doesn't match its own key")
+	}
+}
+
+// binaryKeyClient is a client over a table keyed by binary values of the
+// same length
+func binaryKeyClient(t *testing.T) *Client {
+	t.Helper()
+	client, err := NewFakeClient("test", []FakeTable{{
+		Name:           "blobs",
+		PartitionKey:   "id",
+		AttributeTypes: map[string]string{"id": "B"},
+		Items: []map[string]interface{}{
+			{"id": []byte{1, 1}, "name": "first"},
+			{"id": []byte{2, 2}, "name": "second"},
+			{"id": []byte{3, 3}, "name": "third"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestBatchGetItemsMatchesBinaryKeys(t *testing.T) {
+	client := binaryKeyClient(t)
+	keys := []map[string]interface{}{
+		{"id": []byte{3, 3}},
+		{"id": []byte{9, 9}}, // No such item
+		{"id": []byte{1, 1}},
+	}
+	result, err := client.BatchGetItems(context.Background(), "blobs", keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"third", "", "first"}
+	for i, name := range want {
+		raw := result.RawItems[i]
+		if name == "" {
+			if raw != nil {
+				t.Errorf("key %d: got %v, want no item", i, raw)
+			}
+			continue
+		}
+		if raw["name"] != name || !bytes.Equal(raw["id"].([]byte), keys[i]["id"].([]byte)) {
+			t.Errorf("key %d: got %v, want %s", i, raw, name)
+		}
+	}
+}
+
+func TestQueryBetweenReadsBothBounds(t *testing.T) {
+	client, err := NewFakeClient("test", []FakeTable{{
+		Name:           "events",
+		PartitionKey:   "device",
+		SortKey:        "ts",
+		AttributeTypes: map[string]string{"ts": "N"},
+		Items: []map[string]interface{}{
+			{"device": "a", "ts": int64(10)},
+			{"device": "a", "ts": int64(20)},
+			{"device": "a", "ts": int64(30)},
+			{"device": "a", "ts": int64(40)},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := QueryParams{
+		TableName:      "events",
+		PartitionKey:   "device",
+		PartitionValue: "a",
+		SortKey:        "ts",
+		SortValue:      "20",
+		SortValueEnd:   "30",
+		SortKeyType:    "N",
+		Condition:      "between",
+	}
+	result, err := client.Query(context.Background(), params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RawItems) != 2 {
+		t.Errorf("read %v, want the items at 20 and 30", result.RawItems)
+	}
+
+	params.SortValueEnd = ""
+	if _, err := client.Query(context.Background(), params, nil); err == nil {
+		t.Error("between without a second value was sent")
+	}
+}
@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// typedJSON converts an attribute value to the typed JSON form the DynamoDB
+// API and the AWS CLI use, e.g. {"N": "42"}. Binary values become base64
+// when marshaled.
+func typedJSON(v types.AttributeValue) interface{} {
+	switch val := v.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]interface{}{"S": val.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]interface{}{"N": val.Value}
+	case *types.AttributeValueMemberB:
+		return map[string]interface{}{"B": val.Value}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]interface{}{"BOOL": val.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]interface{}{"NULL": val.Value}
+	case *types.AttributeValueMemberSS:
+		return map[string]interface{}{"SS": val.Value}
+	case *types.AttributeValueMemberNS:
+		return map[string]interface{}{"NS": val.Value}
+	case *types.AttributeValueMemberBS:
+		return map[string]interface{}{"BS": val.Value}
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, len(val.Value))
+		for i, av := range val.Value {
+			list[i] = typedJSON(av)
+		}
+		return map[string]interface{}{"L": list}
+	case *types.AttributeValueMemberM:
+		return map[string]interface{}{"M": typedItemJSON(val.Value)}
+	default:
+		return nil
+	}
+}
+
+// typedItemJSON converts an item or key to typed JSON
+func typedItemJSON(item map[string]types.AttributeValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(item))
+	for k, av := range item {
+		m[k] = typedJSON(av)
+	}
+	return m
+}
+
+// fromTypedJSON parses an attribute value in typed JSON, the inverse of
+// typedJSON. Binary values are base64 encoded.
+func fromTypedJSON(data json.RawMessage) (types.AttributeValue, error) {
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, err
+	}
+	if len(typed) != 1 {
+		return nil, fmt.Errorf("typed value must have exactly one type key, got %d", len(typed))
+	}
+	for typ, value := range typed {
+		switch typ {
+		case "S":
+			var v string
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberS{Value: v}, err
+		case "N":
+			var v string
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberN{Value: v}, err
+		case "B":
+			var v []byte
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberB{Value: v}, err
+		case "BOOL":
+			var v bool
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberBOOL{Value: v}, err
+		case "NULL":
+			var v bool
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberNULL{Value: v}, err
+		case "SS":
+			var v []string
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberSS{Value: v}, err
+		case "NS":
+			var v []string
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberNS{Value: v}, err
+		case "BS":
+			var v [][]byte
+			err := json.Unmarshal(value, &v)
+			return &types.AttributeValueMemberBS{Value: v}, err
+		case "L":
+			var raw []json.RawMessage
+			if err := json.Unmarshal(value, &raw); err != nil {
+				return nil, err
+			}
+			list := make([]types.AttributeValue, len(raw))
+			for i, r := range raw {
+				av, err := fromTypedJSON(r)
+				if err != nil {
+					return nil, err
+				}
+				list[i] = av
+			}
+			return &types.AttributeValueMemberL{Value: list}, nil
+		case "M":
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(value, &raw); err != nil {
+				return nil, err
+			}
+			m, err := fromTypedItemJSON(raw)
+			return &types.AttributeValueMemberM{Value: m}, err
+		default:
+			return nil, fmt.Errorf("unknown attribute type %q", typ)
+		}
+	}
+	return nil, nil // Unreachable, typed has one key
+}
+
+// fromTypedItemJSON parses an item or key in typed JSON
+func fromTypedItemJSON(raw map[string]json.RawMessage) (map[string]types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, len(raw))
+	for k, r := range raw {
+		av, err := fromTypedJSON(r)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", k, err)
+		}
+		item[k] = av
+	}
+	return item, nil
+}
@@ -0,0 +1,345 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// EditableItem is an item loaded for editing. Values holds the attributes as
+// JSON-compatible values; the attribute values as read are kept so attributes
+// that weren't changed are written back untouched, including binary attributes
+// and sets, which come back from JSON as strings and lists.
+type EditableItem struct {
+	Values   map[string]interface{}
+	original map[string]types.AttributeValue
+}
+
+// NewEditableItem creates an item that doesn't exist in the table yet
+func NewEditableItem(values map[string]interface{}) EditableItem {
+	return EditableItem{Values: values}
+}
+
+// Exists reports whether the item was loaded from the table
+func (e EditableItem) Exists() bool {
+	return e.original != nil
+}
+
+// sameValue compares two JSON-compatible values by their JSON encoding
+func sameValue(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// ScanForEdit reads every item of a table for editing. It fails rather than
+// returning a partial result when the table holds more than maxItems items.
+func (c *Client) ScanForEdit(ctx context.Context, tableName string, maxItems int) ([]EditableItem, error) {
+	var items []EditableItem
+	input := &dynamodb.ScanInput{
+		TableName:              &tableName,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	for {
+		result, err := c.api().Scan(ctx, input)
+		if err != nil {
+			return nil, c.tableError(tableName, err)
+		}
+		c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+		for _, item := range result.Items {
+			_, raw := c.convertItem(tableName, item)
+			items = append(items, EditableItem{Values: raw, original: item})
+		}
+		if len(items) > maxItems {
+			return nil, fmt.Errorf("%s has more than %d items", tableName, maxItems)
+		}
+		if result.LastEvaluatedKey == nil {
+			return items, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// GetItemForEdit reads one item for editing with a consistent read, so the
+// edit starts from what is stored now. It fails when there is no item with
+// the key.
+func (c *Client) GetItemForEdit(ctx context.Context, tableName string, key map[string]interface{}) (EditableItem, error) {
+	avKey := make(map[string]types.AttributeValue, len(key))
+	for k, v := range key {
+		av, err := interfaceToAttributeValue(v)
+		if err != nil {
+			return EditableItem{}, fmt.Errorf("invalid key attribute %s: %w", k, err)
+		}
+		avKey[k] = av
+	}
+	result, err := c.api().GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:              &tableName,
+		Key:                    avKey,
+		ConsistentRead:         aws.Bool(true),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return EditableItem{}, c.tableError(tableName, err)
+	}
+	c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+	if result.Item == nil {
+		return EditableItem{}, fmt.Errorf("the item is no longer in %s", tableName)
+	}
+	_, raw := c.convertItem(tableName, result.Item)
+	return EditableItem{Values: raw, original: result.Item}, nil
+}
+
+// maxConcurrentWrites bounds how many writes of a plan are sent at once
+const maxConcurrentWrites = 10
+
+// WritePlan is the set of writes that applies a round of edits to a table.
+// It is built before anything is written so the exact requests can be
+// reviewed first. Each write is its own PutItem or DeleteItem call asking for
+// the old item image (ReturnValues=ALL_OLD), which BatchWriteItem can't return.
+type WritePlan struct {
+	tableName string
+	requests  []types.WriteRequest
+}
+
+// Len returns the number of put and delete requests in the plan
+func (p WritePlan) Len() int {
+	return len(p.requests)
+}
+
+// SerializedRequest is a write of a plan as sent to the API
+type SerializedRequest struct {
+	Operation string // PutItem, DeleteItem, or the call of another write
+	Body      string // Indented JSON request body
+}
+
+// Requests returns every call the plan makes with its body as indented JSON,
+// in the typed form the API receives. A body can be replayed with
+// aws dynamodb put-item (or delete-item) --cli-input-json.
+func (p WritePlan) Requests() ([]SerializedRequest, error) {
+	serialized := make([]SerializedRequest, len(p.requests))
+	for i, r := range p.requests {
+		op := "PutItem"
+		body := map[string]interface{}{"TableName": p.tableName, "ReturnValues": "ALL_OLD"}
+		if r.PutRequest != nil {
+			body["Item"] = typedItemJSON(r.PutRequest.Item)
+		} else {
+			op = "DeleteItem"
+			body["Key"] = typedItemJSON(r.DeleteRequest.Key)
+		}
+		r, err := serializeRequest(op, body)
+		if err != nil {
+			return nil, err
+		}
+		serialized[i] = r
+	}
+	return serialized, nil
+}
+
+// serializeRequest indents the JSON body of a call
+func serializeRequest(op string, body map[string]interface{}) (SerializedRequest, error) {
+	b, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return SerializedRequest{}, err
+	}
+	return SerializedRequest{Operation: op, Body: string(b)}, nil
+}
+
+// WriteResult is the outcome of one write of a plan
+type WriteResult struct {
+	Key map[string]interface{} // Key of the written item
+	Old map[string]interface{} // Item stored before the write, nil if there was none
+	New map[string]interface{} // Item as written, nil for deletes
+}
+
+// PlanEdits turns puts and deletes into write requests. Deleted items are
+// identified by the key they were loaded with. A put of a loaded item whose
+// key was edited also deletes the item stored under the old key.
+func (c *Client) PlanEdits(tableName string, keyAttrs []string, puts, deletes []EditableItem) (WritePlan, error) {
+	plan := WritePlan{tableName: tableName}
+	deletes = append([]EditableItem(nil), deletes...)
+	putKeys := make(map[string]bool)
+	for _, item := range puts {
+		av, err := c.editedItem(tableName, item)
+		if err != nil {
+			return WritePlan{}, err
+		}
+		newKey := keyOf(av, keyAttrs)
+		putKeys[keySignature(newKey, newKey)] = true
+		plan.requests = append(plan.requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+
+		if item.original != nil {
+			oldKey := keyOf(item.original, keyAttrs)
+			if keySignature(oldKey, oldKey) != keySignature(newKey, newKey) {
+				deletes = append(deletes, item)
+			}
+		}
+	}
+	for _, item := range deletes {
+		if item.original == nil {
+			continue
+		}
+		key := keyOf(item.original, keyAttrs)
+		// A put to the same key replaces the item anyway, and a batch can't touch a key twice
+		if putKeys[keySignature(key, key)] {
+			continue
+		}
+		plan.requests = append(plan.requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+	return plan, nil
+}
+
+// ApplyWritePlan sends the plan's writes and returns what each one replaced.
+// On error the results of the writes that completed are returned with it.
+func (c *Client) ApplyWritePlan(ctx context.Context, plan WritePlan, keyAttrs []string) ([]WriteResult, error) {
+	results := make([]WriteResult, len(plan.requests))
+	errs := make([]error, len(plan.requests))
+
+	slots := make(chan struct{}, maxConcurrentWrites)
+	var wg sync.WaitGroup
+	for i, r := range plan.requests {
+		wg.Add(1)
+		go func(i int, r types.WriteRequest) {
+			defer RecoverPanic()
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			var old map[string]types.AttributeValue
+			var key map[string]types.AttributeValue
+			if r.PutRequest != nil {
+				out, err := c.api().PutItem(ctx, &dynamodb.PutItemInput{
+					TableName:    &plan.tableName,
+					Item:         r.PutRequest.Item,
+					ReturnValues: types.ReturnValueAllOld,
+				})
+				if err != nil {
+					errs[i] = c.tableError(plan.tableName, err)
+					return
+				}
+				old, key = out.Attributes, keyOf(r.PutRequest.Item, keyAttrs)
+				_, results[i].New = c.convertItem(plan.tableName, r.PutRequest.Item)
+			} else {
+				out, err := c.api().DeleteItem(ctx, &dynamodb.DeleteItemInput{
+					TableName:    &plan.tableName,
+					Key:          r.DeleteRequest.Key,
+					ReturnValues: types.ReturnValueAllOld,
+				})
+				if err != nil {
+					errs[i] = c.tableError(plan.tableName, err)
+					return
+				}
+				old, key = out.Attributes, r.DeleteRequest.Key
+			}
+			_, results[i].Key = c.convertItem(plan.tableName, key)
+			if old != nil {
+				_, results[i].Old = c.convertItem(plan.tableName, old)
+			}
+		}(i, r)
+	}
+	wg.Wait()
+
+	var done []WriteResult
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		done = append(done, results[i])
+	}
+	if len(failed) > 0 {
+		return done, fmt.Errorf("%d of %d writes failed: %w", len(failed), len(plan.requests), failed[0])
+	}
+	return done, nil
+}
+
+// keyOf extracts the key attributes of an item
+func keyOf(item map[string]types.AttributeValue, keyAttrs []string) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(keyAttrs))
+	for _, k := range keyAttrs {
+		key[k] = item[k]
+	}
+	return key
+}
+
+// editedItem converts an edited item back to attribute values, keeping the
+// original value of every attribute that wasn't changed
+func (c *Client) editedItem(tableName string, item EditableItem) (map[string]types.AttributeValue, error) {
+	var raw map[string]interface{}
+	if item.original != nil {
+		_, raw = c.convertItem(tableName, item.original)
+	}
+
+	av := make(map[string]types.AttributeValue, len(item.Values))
+	for k, v := range item.Values {
+		if old, ok := raw[k]; ok && sameValue(old, v) {
+			av[k] = item.original[k]
+			continue
+		}
+		if set, ok, err := editedSet(item.original[k], v); ok || err != nil {
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", k, err)
+			}
+			av[k] = set
+			continue
+		}
+		converted, err := c.EncodeAttribute(tableName, k, item.original[k], v)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", k, err)
+		}
+		av[k] = converted
+	}
+	return av, nil
+}
+
+// editedSet keeps a string or number set a set when it was edited. Sets are
+// edited as JSON arrays, which would otherwise be written back as lists. ok
+// is false when old isn't a set or v no longer holds the set's element type,
+// in which case the value is written as edited.
+func editedSet(old types.AttributeValue, v interface{}) (types.AttributeValue, bool, error) {
+	list, isList := v.([]interface{})
+	if !isList {
+		return nil, false, nil
+	}
+	switch old.(type) {
+	case *types.AttributeValueMemberSS:
+		values := make([]string, len(list))
+		for i, elem := range list {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, false, nil
+			}
+			values[i] = s
+		}
+		if len(values) == 0 {
+			return nil, true, fmt.Errorf("a string set can't be empty; remove the attribute instead")
+		}
+		return &types.AttributeValueMemberSS{Value: values}, true, nil
+	case *types.AttributeValueMemberNS:
+		values := make([]string, len(list))
+		for i, elem := range list {
+			// Number sets are shown as strings to keep every digit
+			switch n := elem.(type) {
+			case json.Number:
+				values[i] = n.String()
+			case string:
+				if _, err := json.Number(n).Float64(); err != nil {
+					return nil, false, nil
+				}
+				values[i] = n
+			default:
+				return nil, false, nil
+			}
+		}
+		if len(values) == 0 {
+			return nil, true, fmt.Errorf("a number set can't be empty; remove the attribute instead")
+		}
+		return &types.AttributeValueMemberNS{Value: values}, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestPlanEditsTellsBinaryKeysApart(t *testing.T) {
+	client := binaryKeyClient(t)
+	ctx := context.Background()
+	items, err := client.ScanForEdit(ctx, "blobs", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]EditableItem)
+	for _, item := range items {
+		byName[item.Values["name"].(string)] = item
+	}
+
+	// Keep second, delete first: both keys are two bytes long
+	second := byName["second"]
+	second.Values["name"] = "second, edited"
+	plan, err := client.PlanEdits("blobs", []string{"id"}, []EditableItem{second}, []EditableItem{byName["first"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests, err := plan.Requests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0].Operation != "PutItem" || requests[1].Operation != "DeleteItem" {
+		t.Fatalf("got requests %+v, want a PutItem and a DeleteItem", requests)
+	}
+
+	if _, err := client.ApplyWritePlan(ctx, plan, []string{"id"}); err != nil {
+		t.Fatal(err)
+	}
+	left, err := client.ScanForEdit(ctx, "blobs", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, item := range left {
+		names[item.Values["name"].(string)] = true
+		if bytes.Equal(item.Values["id"].([]byte), []byte{1, 1}) {
+			t.Error("the deleted item is still in the table")
+		}
+	}
+	if len(left) != 2 || !names["second, edited"] || !names["third"] {
+		t.Errorf("table holds %v, want the edited second item and the third", names)
+	}
+}
@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxExportSegments bounds the parallel scan segments of an export
+const MaxExportSegments = 64
+
+// ExportParams describes a full table export
+type ExportParams struct {
+	Scan     ScanParams // Table, index and optional filter
+	Segments int        // Parallel scan segments; 1 scans sequentially
+	Typed    bool       // Items in the typed JSON of the DynamoDB API, e.g. {"N": "42"}, instead of plain values
+}
+
+// ExportProgress reports how far an export got
+type ExportProgress struct {
+	Scanned   int64   // Items read, before the filter
+	Exported  int64   // Items passed to emit
+	ReadUnits float64 // Read capacity consumed
+}
+
+// ExportTable scans a whole table or index, following pagination in every
+// segment, and passes each item to emit. emit and progress are never called
+// concurrently. The first error stops every segment.
+func (c *Client) ExportTable(ctx context.Context, params ExportParams, emit func(item map[string]interface{}) error, progress func(ExportProgress)) (ExportProgress, error) {
+	segments := max(params.Segments, 1)
+	if segments > MaxExportSegments {
+		return ExportProgress{}, fmt.Errorf("at most %d segments are supported", MaxExportSegments)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex // Guards p and serializes emit and progress
+	var p ExportProgress
+	errs := make([]error, segments)
+	var wg sync.WaitGroup
+	for segment := range segments {
+		wg.Add(1)
+		go func(segment int) {
+			defer RecoverPanic()
+			defer wg.Done()
+			errs[segment] = c.exportSegment(ctx, params, segment, segments, func(result *dynamodb.ScanOutput) error {
+				mu.Lock()
+				defer mu.Unlock()
+				p.Scanned += int64(result.ScannedCount)
+				p.ReadUnits += c.capacity.record(consumedCapacity(result.ConsumedCapacity)...)
+				for _, item := range result.Items {
+					var v map[string]interface{}
+					if params.Typed {
+						v = typedItemJSON(item)
+					} else {
+						_, v = c.convertItem(params.Scan.TableName, item)
+					}
+					if err := emit(v); err != nil {
+						return err
+					}
+					p.Exported++
+				}
+				if progress != nil {
+					progress(p)
+				}
+				return nil
+			})
+			if errs[segment] != nil {
+				cancel()
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	// Report the error that stopped the export rather than the cancellations
+	// it caused in the other segments
+	for _, err := range errs {
+		if err != nil && !isContextError(err) {
+			return p, err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// exportSegment scans one segment page by page
+func (c *Client) exportSegment(ctx context.Context, params ExportParams, segment, segments int, page func(*dynamodb.ScanOutput) error) error {
+	scan := params.Scan
+	input := &dynamodb.ScanInput{
+		TableName:              aws.String(scan.TableName),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	if segments > 1 {
+		input.Segment = aws.Int32(int32(segment))
+		input.TotalSegments = aws.Int32(int32(segments))
+	}
+	if scan.IndexName != "" {
+		input.IndexName = aws.String(scan.IndexName)
+	}
+	if scan.FilterAttribute != "" {
+		expr, names, values, err := scan.filterExpression()
+		if err != nil {
+			return err
+		}
+		input.FilterExpression = aws.String(expr)
+		input.ExpressionAttributeNames = names
+		input.ExpressionAttributeValues = values
+	}
+	for {
+		result, err := c.api().Scan(ctx, input)
+		if err != nil {
+			return c.tableError(scan.TableName, err)
+		}
+		if err := page(result); err != nil {
+			return err
+		}
+		if result.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,970 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// FakeTable is a table of NewFakeClient with the items it starts with
+type FakeTable struct {
+	Name           string
+	PartitionKey   string
+	SortKey        string            // Empty for a table without one
+	AttributeTypes map[string]string // S, N or B of the key attributes, S when not given
+	Indexes        []FakeIndex
+	TTLAttribute   string // Empty when TTL is off
+	Tags           map[string]string
+
+	// Items are plain values, typed the way BatchGetItems types keys:
+	// strings, numbers, booleans, nil, []byte, []string, lists and maps
+	Items []map[string]interface{}
+}
+
+// FakeIndex is a global secondary index of a FakeTable. It projects every
+// attribute, and like a real GSI only holds the items with its key
+// attributes.
+type FakeIndex struct {
+	Name         string
+	PartitionKey string
+	SortKey      string
+}
+
+// NewFakeClient creates a client whose DynamoDB is a set of in-memory
+// tables, for running without credentials. Queries, scans, key conditions,
+// filters, pagination and writes behave like DynamoDB's for the
+// expressions the client sends; consumed capacity is estimated from item
+// sizes. Exports to S3, streams and CloudWatch metrics aren't available.
+func NewFakeClient(profile string, tables []FakeTable, options ...Option) (*Client, error) {
+	var opts clientOptions
+	for _, o := range options {
+		o(&opts)
+	}
+	f := &fakeDynamoDB{region: opts.region, tables: make(map[string]*fakeTable), created: time.Now()}
+	if f.region == "" {
+		f.region = defaultRegion
+	}
+	for _, t := range tables {
+		ft := &fakeTable{FakeTable: t}
+		ft.Items = nil
+		for i, item := range t.Items {
+			av, err := interfaceToAttributeValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d of %s: %w", i+1, t.Name, err)
+			}
+			m := av.(*types.AttributeValueMemberM).Value
+			if err := ft.checkKey(m); err != nil {
+				return nil, fmt.Errorf("item %d of %s: %w", i+1, t.Name, err)
+			}
+			ft.put(m)
+		}
+		f.tables[t.Name] = ft
+	}
+	return &Client{svc: f, profile: profile, opts: opts}, nil
+}
+
+// fakeDynamoDB serves the DynamoDB API of a Client from memory
+type fakeDynamoDB struct {
+	mu      sync.Mutex
+	region  string
+	tables  map[string]*fakeTable
+	created time.Time
+}
+
+type fakeTable struct {
+	FakeTable
+	items []map[string]types.AttributeValue // In the order they were first put
+}
+
+// fakeError is an error as DynamoDB would return it
+func fakeError(code, format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{Code: code, Message: fmt.Sprintf(format, args...), Fault: smithy.FaultClient}
+}
+
+func (f *fakeDynamoDB) table(name *string) (*fakeTable, error) {
+	t, ok := f.tables[aws.ToString(name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found: Table: " + aws.ToString(name) + " not found")}
+	}
+	return t, nil
+}
+
+// keyAttrs are the primary key attributes of the table
+func (t *fakeTable) keyAttrs() []string {
+	if t.SortKey == "" {
+		return []string{t.PartitionKey}
+	}
+	return []string{t.PartitionKey, t.SortKey}
+}
+
+func (t *fakeTable) attrType(attr string) string {
+	if typ := t.AttributeTypes[attr]; typ != "" {
+		return typ
+	}
+	return "S"
+}
+
+// presentAttrs returns the attributes of item named by attrs that it has
+func presentAttrs(item map[string]types.AttributeValue, attrs ...string) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(attrs))
+	for _, attr := range attrs {
+		if v, ok := item[attr]; ok {
+			key[attr] = v
+		}
+	}
+	return key
+}
+
+// checkKey verifies that an item has the table's key attributes, typed as
+// declared
+func (t *fakeTable) checkKey(item map[string]types.AttributeValue) error {
+	for _, attr := range t.keyAttrs() {
+		v, ok := item[attr]
+		if !ok {
+			return fakeError("ValidationException", "One or more parameter values were invalid: Missing the key %s in the item", attr)
+		}
+		if attributeType(v) != t.attrType(attr) {
+			return fakeError("ValidationException", "One or more parameter values were invalid: Type mismatch for key %s expected: %s actual: %s", attr, t.attrType(attr), attributeType(v))
+		}
+	}
+	return nil
+}
+
+// find returns the position of the item with the key, or -1
+func (t *fakeTable) find(key map[string]types.AttributeValue) int {
+	attrs := presentAttrs(key, t.keyAttrs()...)
+	sig := keySignature(attrs, key)
+	for i, item := range t.items {
+		if keySignature(attrs, item) == sig {
+			return i
+		}
+	}
+	return -1
+}
+
+// put adds an item or replaces the one with its key, returning the old one
+func (t *fakeTable) put(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	item = maps.Clone(item)
+	if i := t.find(item); i >= 0 {
+		old := t.items[i]
+		t.items[i] = item
+		return old
+	}
+	t.items = append(t.items, item)
+	return nil
+}
+
+// remove deletes the item with the key, returning it
+func (t *fakeTable) remove(key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	i := t.find(key)
+	if i < 0 {
+		return nil
+	}
+	old := t.items[i]
+	t.items = slices.Delete(t.items, i, i+1)
+	return old
+}
+
+// target returns the partition and sort key of the table or one of its
+// indexes
+func (t *fakeTable) target(indexName *string) (partitionKey, sortKey string, err error) {
+	if aws.ToString(indexName) == "" {
+		return t.PartitionKey, t.SortKey, nil
+	}
+	for _, idx := range t.Indexes {
+		if idx.Name == *indexName {
+			return idx.PartitionKey, idx.SortKey, nil
+		}
+	}
+	return "", "", fakeError("ValidationException", "The table does not have the specified index: %s", *indexName)
+}
+
+// attributeType is the DynamoDB type name of a value
+func attributeType(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	}
+	return ""
+}
+
+// compareValues orders two strings, numbers or binary values of the same
+// type. ok is false for other values.
+func compareValues(a, b types.AttributeValue) (cmp int, ok bool) {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Compare(x.Value, y.Value), true
+		}
+	case *types.AttributeValueMemberN:
+		if y, ok := b.(*types.AttributeValueMemberN); ok {
+			nx, okx := new(big.Float).SetString(x.Value)
+			ny, oky := new(big.Float).SetString(y.Value)
+			if okx && oky {
+				return nx.Cmp(ny), true
+			}
+		}
+	case *types.AttributeValueMemberB:
+		if y, ok := b.(*types.AttributeValueMemberB); ok {
+			return bytes.Compare(x.Value, y.Value), true
+		}
+	}
+	return 0, false
+}
+
+// equalValues compares values of any type
+func equalValues(a, b types.AttributeValue) bool {
+	if cmp, ok := compareValues(a, b); ok {
+		return cmp == 0
+	}
+	return attributeType(a) == attributeType(b) && formatAttributeValue(a) == formatAttributeValue(b)
+}
+
+// fakeCondition is one comparison of a key condition or filter expression
+type fakeCondition struct {
+	op     string // A comparison, BETWEEN, IN or a function name
+	attr   string
+	values []types.AttributeValue
+	size   bool // Compares size(attr) rather than the attribute
+	not    bool // Negated with NOT
+}
+
+var (
+	fakeAnd         = regexp.MustCompile(`(?i)\s+AND\s+`)
+	fakeFunction    = regexp.MustCompile(`^(\w+)\(\s*([#\w.]+)\s*(?:,\s*(:\w+)\s*)?\)$`)
+	fakeBetween     = regexp.MustCompile(`(?i)^([#\w.]+)\s+BETWEEN\s+(:\w+)$`)
+	fakeComparison  = regexp.MustCompile(`^([#\w.]+)\s*(=|<>|<=|>=|<|>)\s*(:\w+)$`)
+	fakePlaceholder = regexp.MustCompile(`^(:\w+)$`)
+	fakeNot         = regexp.MustCompile(`(?i)^NOT\s+(.+)$`)
+	fakeSize        = regexp.MustCompile(`^size\(\s*([#\w.]+)\s*\)\s*(=|<>|<=|>=|<|>)\s*(:\w+)$`)
+	fakeIn          = regexp.MustCompile(`(?i)^([#\w.]+)\s+IN\s*\(\s*(:\w+(?:\s*,\s*:\w+)*)\s*\)$`)
+	fakeListSep     = regexp.MustCompile(`\s*,\s*`)
+)
+
+// parseConditions reads an expression of comparisons joined by AND, which
+// is every expression the client builds
+func parseConditions(expr string, names map[string]string, values map[string]types.AttributeValue) ([]fakeCondition, error) {
+	name := func(s string) (string, error) {
+		if !strings.HasPrefix(s, "#") {
+			return s, nil
+		}
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		return "", fakeError("ValidationException", "Value provided in ExpressionAttributeNames unused in expressions: An expression attribute name used in the document path is not defined; attribute name: %s", s)
+	}
+	value := func(s string) (types.AttributeValue, error) {
+		if v, ok := values[s]; ok {
+			return v, nil
+		}
+		return nil, fakeError("ValidationException", "Invalid expression: An expression attribute value used in expression is not defined; attribute value: %s", s)
+	}
+
+	var conditions []fakeCondition
+	clauses := fakeAnd.Split(strings.TrimSpace(expr), -1)
+	for i := 0; i < len(clauses); i++ {
+		clause := strings.TrimSpace(clauses[i])
+		var c fakeCondition
+		var attr string
+		var refs []string
+		if m := fakeNot.FindStringSubmatch(clause); m != nil {
+			c.not, clause = true, strings.TrimSpace(m[1])
+		}
+		if m := fakeFunction.FindStringSubmatch(clause); m != nil {
+			c.op, attr = m[1], m[2]
+			if m[3] != "" {
+				refs = []string{m[3]}
+			}
+		} else if m := fakeBetween.FindStringSubmatch(clause); m != nil && i+1 < len(clauses) {
+			upper := fakePlaceholder.FindStringSubmatch(strings.TrimSpace(clauses[i+1]))
+			if upper == nil {
+				return nil, fakeError("ValidationException", "Invalid expression: Syntax error near BETWEEN in %q", expr)
+			}
+			c.op, attr, refs = "BETWEEN", m[1], []string{m[2], upper[1]}
+			i++
+		} else if m := fakeComparison.FindStringSubmatch(clause); m != nil {
+			c.op, attr, refs = m[2], m[1], []string{m[3]}
+		} else if m := fakeSize.FindStringSubmatch(clause); m != nil {
+			c.op, attr, refs, c.size = m[2], m[1], []string{m[3]}, true
+		} else if m := fakeIn.FindStringSubmatch(clause); m != nil {
+			c.op, attr, refs = "IN", m[1], fakeListSep.Split(m[2], -1)
+		} else {
+			return nil, fakeError("ValidationException", "Invalid expression: the in-memory tables don't support %q", clause)
+		}
+		var err error
+		if c.attr, err = name(attr); err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			v, err := value(ref)
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// fakeSizeOf is what size() returns for a value: the length of a string or
+// binary value, or the number of elements of a set, list or map
+func fakeSizeOf(v types.AttributeValue) (int, bool) {
+	switch x := v.(type) {
+	case *types.AttributeValueMemberS:
+		return len(x.Value), true
+	case *types.AttributeValueMemberB:
+		return len(x.Value), true
+	case *types.AttributeValueMemberSS:
+		return len(x.Value), true
+	case *types.AttributeValueMemberNS:
+		return len(x.Value), true
+	case *types.AttributeValueMemberBS:
+		return len(x.Value), true
+	case *types.AttributeValueMemberL:
+		return len(x.Value), true
+	case *types.AttributeValueMemberM:
+		return len(x.Value), true
+	}
+	return 0, false
+}
+
+// matches evaluates a condition on an item
+func (c fakeCondition) matches(item map[string]types.AttributeValue) bool {
+	if c.not {
+		c.not = false
+		return !c.matches(item)
+	}
+	v, ok := item[c.attr]
+	if c.size {
+		// size() of a missing attribute or a number fails the comparison
+		n, sized := fakeSizeOf(v)
+		if !ok || !sized {
+			return false
+		}
+		v, ok = &types.AttributeValueMemberN{Value: strconv.Itoa(n)}, true
+	}
+	switch c.op {
+	case "attribute_exists":
+		return ok
+	case "attribute_not_exists":
+		return !ok
+	case "<>":
+		return !ok || !equalValues(v, c.values[0])
+	}
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "begins_with":
+		switch x := v.(type) {
+		case *types.AttributeValueMemberS:
+			prefix, ok := c.values[0].(*types.AttributeValueMemberS)
+			return ok && strings.HasPrefix(x.Value, prefix.Value)
+		case *types.AttributeValueMemberB:
+			prefix, ok := c.values[0].(*types.AttributeValueMemberB)
+			return ok && bytes.HasPrefix(x.Value, prefix.Value)
+		}
+		return false
+	case "contains":
+		switch x := v.(type) {
+		case *types.AttributeValueMemberS:
+			sub, ok := c.values[0].(*types.AttributeValueMemberS)
+			return ok && strings.Contains(x.Value, sub.Value)
+		case *types.AttributeValueMemberSS:
+			s, ok := c.values[0].(*types.AttributeValueMemberS)
+			return ok && slices.Contains(x.Value, s.Value)
+		case *types.AttributeValueMemberNS:
+			for _, n := range x.Value {
+				if equalValues(&types.AttributeValueMemberN{Value: n}, c.values[0]) {
+					return true
+				}
+			}
+		case *types.AttributeValueMemberL:
+			for _, e := range x.Value {
+				if equalValues(e, c.values[0]) {
+					return true
+				}
+			}
+		}
+		return false
+	case "attribute_type":
+		name, ok := c.values[0].(*types.AttributeValueMemberS)
+		return ok && attributeType(v) == name.Value
+	case "IN":
+		return slices.ContainsFunc(c.values, func(w types.AttributeValue) bool { return equalValues(v, w) })
+	case "=":
+		return equalValues(v, c.values[0])
+	case "BETWEEN":
+		lo, okLo := compareValues(v, c.values[0])
+		hi, okHi := compareValues(v, c.values[1])
+		return okLo && okHi && lo >= 0 && hi <= 0
+	}
+	cmp, ok := compareValues(v, c.values[0])
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// fakeFilter parses an optional filter expression
+func fakeFilter(expr *string, names map[string]string, values map[string]types.AttributeValue) ([]fakeCondition, error) {
+	if aws.ToString(expr) == "" {
+		return nil, nil
+	}
+	return parseConditions(*expr, names, values)
+}
+
+// fakeProject keeps the attributes a projection expression names. Only
+// top-level attributes are supported.
+func fakeProject(items []map[string]types.AttributeValue, expr *string, names map[string]string) ([]map[string]types.AttributeValue, error) {
+	if aws.ToString(expr) == "" {
+		return items, nil
+	}
+	var attrs []string
+	for _, path := range strings.Split(*expr, ",") {
+		path = strings.TrimSpace(path)
+		if strings.ContainsAny(path, ".[") {
+			return nil, fakeError("ValidationException", "Invalid ProjectionExpression: the in-memory tables don't support the document path %q", path)
+		}
+		if strings.HasPrefix(path, "#") {
+			name, ok := names[path]
+			if !ok {
+				return nil, fakeError("ValidationException", "Invalid ProjectionExpression: An expression attribute name used in the document path is not defined; attribute name: %s", path)
+			}
+			path = name
+		}
+		attrs = append(attrs, path)
+	}
+	projected := make([]map[string]types.AttributeValue, len(items))
+	for i, item := range items {
+		projected[i] = make(map[string]types.AttributeValue, len(attrs))
+		for _, attr := range attrs {
+			if v, ok := item[attr]; ok {
+				projected[i][attr] = v
+			}
+		}
+	}
+	return projected, nil
+}
+
+func matchesAll(conditions []fakeCondition, item map[string]types.AttributeValue) bool {
+	for _, c := range conditions {
+		if !c.matches(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeRead is what a page of a query or scan read
+type fakeRead struct {
+	items   []map[string]types.AttributeValue // Those that passed the filter
+	scanned int
+	bytes   int64
+	lastKey map[string]types.AttributeValue
+}
+
+// readPage reads the candidate items after startKey the way DynamoDB does:
+// up to limit items are read, then filtered. A start key that is no longer
+// among the candidates ends the read. keyAttrs are the key attributes of the
+// table and of the index read, which LastEvaluatedKey holds.
+func (t *fakeTable) readPage(candidates []map[string]types.AttributeValue, startKey map[string]types.AttributeValue, limit *int32, filter []fakeCondition, keyAttrs []string) fakeRead {
+	start := 0
+	if len(startKey) > 0 {
+		key := presentAttrs(startKey, t.keyAttrs()...)
+		sig := keySignature(key, startKey)
+		start = len(candidates)
+		for i, item := range candidates {
+			if keySignature(key, item) == sig {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := len(candidates)
+	if limit != nil && int(*limit) < end-start {
+		end = start + int(*limit)
+	}
+
+	var r fakeRead
+	for _, item := range candidates[start:end] {
+		r.scanned++
+		r.bytes += itemSize(item)
+		if matchesAll(filter, item) {
+			r.items = append(r.items, maps.Clone(item))
+		}
+	}
+	if end < len(candidates) && end > start {
+		r.lastKey = presentAttrs(candidates[end-1], keyAttrs...)
+	}
+	return r
+}
+
+// readCapacity estimates the read units of a read: 4 KB units, halved for
+// eventually consistent reads
+func readCapacity(table string, bytes int64, consistent bool) *types.ConsumedCapacity {
+	units := math.Max(1, math.Ceil(float64(bytes)/4096))
+	if !consistent {
+		units /= 2
+	}
+	return &types.ConsumedCapacity{TableName: aws.String(table), CapacityUnits: aws.Float64(units)}
+}
+
+// writeCapacity estimates the write units of writing an item: 1 KB units
+func writeCapacity(table string, item map[string]types.AttributeValue) *types.ConsumedCapacity {
+	units := math.Max(1, math.Ceil(float64(itemSize(item))/1024))
+	return &types.ConsumedCapacity{TableName: aws.String(table), CapacityUnits: aws.Float64(units)}
+}
+
+// indexed returns the items of the table, or those with the index's key
+// attributes when reading an index
+func (t *fakeTable) indexed(partitionKey, sortKey string) []map[string]types.AttributeValue {
+	var items []map[string]types.AttributeValue
+	for _, item := range t.items {
+		if _, ok := item[partitionKey]; !ok {
+			continue
+		}
+		if _, ok := item[sortKey]; sortKey != "" && !ok {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// errNoCredentials fails the requests signed outside the DynamoDB API, to
+// CloudWatch, DynamoDB Streams and STS
+var errNoCredentials = errors.New("the in-memory tables have no AWS credentials: metrics, streams and the account ID aren't available")
+
+func (f *fakeDynamoDB) Options() dynamodb.Options {
+	return dynamodb.Options{
+		Region: f.region,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, errNoCredentials
+		}),
+	}
+}
+
+func (f *fakeDynamoDB) ListTables(ctx context.Context, in *dynamodb.ListTablesInput, _ ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := slices.Sorted(maps.Keys(f.tables))
+	if start := aws.ToString(in.ExclusiveStartTableName); start != "" {
+		i, _ := slices.BinarySearch(names, start)
+		for i < len(names) && names[i] <= start {
+			i++
+		}
+		names = names[i:]
+	}
+	limit := 100
+	if in.Limit != nil {
+		limit = int(*in.Limit)
+	}
+	out := &dynamodb.ListTablesOutput{}
+	if len(names) > limit {
+		names = names[:limit]
+		out.LastEvaluatedTableName = aws.String(names[limit-1])
+	}
+	out.TableNames = names
+	return out, nil
+}
+
+func (f *fakeDynamoDB) DescribeTable(ctx context.Context, in *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.DescribeTableOutput{Table: f.describe(t)}, nil
+}
+
+// describe builds the description DynamoDB would return for a table
+func (f *fakeDynamoDB) describe(t *fakeTable) *types.TableDescription {
+	defined := make(map[string]bool)
+	var definitions []types.AttributeDefinition
+	define := func(attr string) {
+		if attr != "" && !defined[attr] {
+			defined[attr] = true
+			definitions = append(definitions, types.AttributeDefinition{AttributeName: aws.String(attr), AttributeType: types.ScalarAttributeType(t.attrType(attr))})
+		}
+	}
+	keySchema := func(partitionKey, sortKey string) []types.KeySchemaElement {
+		define(partitionKey)
+		schema := []types.KeySchemaElement{{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash}}
+		if sortKey != "" {
+			define(sortKey)
+			schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange})
+		}
+		return schema
+	}
+	size := func(items []map[string]types.AttributeValue) int64 {
+		var n int64
+		for _, item := range items {
+			n += itemSize(item)
+		}
+		return n
+	}
+
+	desc := &types.TableDescription{
+		TableName:          aws.String(t.Name),
+		TableArn:           aws.String(fmt.Sprintf("arn:aws:dynamodb:%s:000000000000:table/%s", f.region, t.Name)),
+		TableStatus:        types.TableStatusActive,
+		KeySchema:          keySchema(t.PartitionKey, t.SortKey),
+		ItemCount:          aws.Int64(int64(len(t.items))),
+		TableSizeBytes:     aws.Int64(size(t.items)),
+		CreationDateTime:   aws.Time(f.created),
+		BillingModeSummary: &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+	}
+	for _, idx := range t.Indexes {
+		items := t.indexed(idx.PartitionKey, idx.SortKey)
+		desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{
+			IndexName:      aws.String(idx.Name),
+			IndexArn:       aws.String(aws.ToString(desc.TableArn) + "/index/" + idx.Name),
+			IndexStatus:    types.IndexStatusActive,
+			KeySchema:      keySchema(idx.PartitionKey, idx.SortKey),
+			Projection:     &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			ItemCount:      aws.Int64(int64(len(items))),
+			IndexSizeBytes: aws.Int64(size(items)),
+		})
+	}
+	desc.AttributeDefinitions = definitions
+	return desc
+}
+
+func (f *fakeDynamoDB) DeleteTable(ctx context.Context, in *dynamodb.DeleteTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	desc := f.describe(t)
+	desc.TableStatus = types.TableStatusDeleting
+	delete(f.tables, t.Name)
+	return &dynamodb.DeleteTableOutput{TableDescription: desc}, nil
+}
+
+func (f *fakeDynamoDB) DescribeTimeToLive(ctx context.Context, in *dynamodb.DescribeTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	ttl := &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+	if t.TTLAttribute != "" {
+		ttl = &types.TimeToLiveDescription{AttributeName: aws.String(t.TTLAttribute), TimeToLiveStatus: types.TimeToLiveStatusEnabled}
+	}
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: ttl}, nil
+}
+
+func (f *fakeDynamoDB) DescribeContinuousBackups(ctx context.Context, in *dynamodb.DescribeContinuousBackupsInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.table(in.TableName); err != nil {
+		return nil, err
+	}
+	return &dynamodb.DescribeContinuousBackupsOutput{ContinuousBackupsDescription: &types.ContinuousBackupsDescription{
+		ContinuousBackupsStatus:        types.ContinuousBackupsStatusDisabled,
+		PointInTimeRecoveryDescription: &types.PointInTimeRecoveryDescription{PointInTimeRecoveryStatus: types.PointInTimeRecoveryStatusDisabled},
+	}}, nil
+}
+
+func (f *fakeDynamoDB) ListTagsOfResource(ctx context.Context, in *dynamodb.ListTagsOfResourceInput, _ ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(in.ResourceArn)
+	name = name[strings.LastIndex(name, "/")+1:]
+	t, err := f.table(&name)
+	if err != nil {
+		return nil, err
+	}
+	out := &dynamodb.ListTagsOfResourceOutput{}
+	for _, k := range slices.Sorted(maps.Keys(t.Tags)) {
+		out.Tags = append(out.Tags, types.Tag{Key: aws.String(k), Value: aws.String(t.Tags[k])})
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) DescribeLimits(ctx context.Context, in *dynamodb.DescribeLimitsInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeLimitsOutput, error) {
+	// DynamoDB's default quotas
+	return &dynamodb.DescribeLimitsOutput{
+		AccountMaxReadCapacityUnits:  aws.Int64(80000),
+		AccountMaxWriteCapacityUnits: aws.Int64(80000),
+		TableMaxReadCapacityUnits:    aws.Int64(40000),
+		TableMaxWriteCapacityUnits:   aws.Int64(40000),
+	}, nil
+}
+
+func (f *fakeDynamoDB) Query(ctx context.Context, in *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	partitionKey, sortKey, err := t.target(in.IndexName)
+	if err != nil {
+		return nil, err
+	}
+	keyConditions, err := parseConditions(aws.ToString(in.KeyConditionExpression), in.ExpressionAttributeNames, in.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	hasPartition := false
+	for _, c := range keyConditions {
+		switch {
+		case c.attr == partitionKey && c.op == "=":
+			hasPartition = true
+		case c.attr == sortKey && sortKey != "":
+		default:
+			return nil, fakeError("ValidationException", "Query condition missed key schema element: %s", partitionKey)
+		}
+		for _, v := range c.values {
+			if attributeType(v) != t.attrType(c.attr) {
+				return nil, fakeError("ValidationException", "One or more parameter values were invalid: Condition parameter type does not match schema type")
+			}
+		}
+	}
+	if !hasPartition {
+		return nil, fakeError("ValidationException", "Query condition missed key schema element: %s", partitionKey)
+	}
+	filter, err := fakeFilter(in.FilterExpression, in.ExpressionAttributeNames, in.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []map[string]types.AttributeValue
+	for _, item := range t.indexed(partitionKey, sortKey) {
+		if matchesAll(keyConditions, item) {
+			candidates = append(candidates, item)
+		}
+	}
+	if sortKey != "" {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			cmp, _ := compareValues(candidates[i][sortKey], candidates[j][sortKey])
+			return cmp < 0
+		})
+	}
+	if in.ScanIndexForward != nil && !*in.ScanIndexForward {
+		slices.Reverse(candidates)
+	}
+
+	r := t.readPage(candidates, in.ExclusiveStartKey, in.Limit, filter, append(t.keyAttrs(), partitionKey, sortKey))
+	out := &dynamodb.QueryOutput{
+		Count:            int32(len(r.items)),
+		ScannedCount:     int32(r.scanned),
+		LastEvaluatedKey: r.lastKey,
+		ConsumedCapacity: readCapacity(t.Name, r.bytes, aws.ToBool(in.ConsistentRead)),
+	}
+	if in.Select != types.SelectCount {
+		if out.Items, err = fakeProject(r.items, in.ProjectionExpression, in.ExpressionAttributeNames); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) Scan(ctx context.Context, in *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	partitionKey, sortKey, err := t.target(in.IndexName)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := fakeFilter(in.FilterExpression, in.ExpressionAttributeNames, in.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := t.indexed(partitionKey, sortKey)
+	if in.TotalSegments != nil && *in.TotalSegments > 1 {
+		var segment []map[string]types.AttributeValue
+		for i, item := range candidates {
+			if int32(i)%*in.TotalSegments == aws.ToInt32(in.Segment) {
+				segment = append(segment, item)
+			}
+		}
+		candidates = segment
+	}
+
+	r := t.readPage(candidates, in.ExclusiveStartKey, in.Limit, filter, append(t.keyAttrs(), partitionKey, sortKey))
+	out := &dynamodb.ScanOutput{
+		Count:            int32(len(r.items)),
+		ScannedCount:     int32(r.scanned),
+		LastEvaluatedKey: r.lastKey,
+		ConsumedCapacity: readCapacity(t.Name, r.bytes, aws.ToBool(in.ConsistentRead)),
+	}
+	if in.Select != types.SelectCount {
+		if out.Items, err = fakeProject(r.items, in.ProjectionExpression, in.ExpressionAttributeNames); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) GetItem(ctx context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkKey(in.Key); err != nil {
+		return nil, err
+	}
+	out := &dynamodb.GetItemOutput{ConsumedCapacity: readCapacity(t.Name, 0, aws.ToBool(in.ConsistentRead))}
+	if i := t.find(in.Key); i >= 0 {
+		out.Item = maps.Clone(t.items[i])
+		out.ConsumedCapacity = readCapacity(t.Name, itemSize(out.Item), aws.ToBool(in.ConsistentRead))
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) BatchGetItem(ctx context.Context, in *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := &dynamodb.BatchGetItemOutput{Responses: make(map[string][]map[string]types.AttributeValue)}
+	for name, request := range in.RequestItems {
+		t, err := f.table(&name)
+		if err != nil {
+			return nil, err
+		}
+		var size int64
+		for _, key := range request.Keys {
+			if err := t.checkKey(key); err != nil {
+				return nil, err
+			}
+			if i := t.find(key); i >= 0 {
+				out.Responses[name] = append(out.Responses[name], maps.Clone(t.items[i]))
+				size += itemSize(t.items[i])
+			}
+		}
+		out.ConsumedCapacity = append(out.ConsumedCapacity, *readCapacity(name, size, aws.ToBool(request.ConsistentRead)))
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) PutItem(ctx context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkKey(in.Item); err != nil {
+		return nil, err
+	}
+	old := t.put(in.Item)
+	out := &dynamodb.PutItemOutput{ConsumedCapacity: writeCapacity(t.Name, in.Item)}
+	if in.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = old
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(ctx context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, err := f.table(in.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkKey(in.Key); err != nil {
+		return nil, err
+	}
+	old := t.remove(in.Key)
+	out := &dynamodb.DeleteItemOutput{ConsumedCapacity: writeCapacity(t.Name, old)}
+	if in.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = old
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(ctx context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := &dynamodb.BatchWriteItemOutput{}
+	for name, requests := range in.RequestItems {
+		t, err := f.table(&name)
+		if err != nil {
+			return nil, err
+		}
+		var units float64
+		for _, r := range requests {
+			switch {
+			case r.PutRequest != nil:
+				if err := t.checkKey(r.PutRequest.Item); err != nil {
+					return nil, err
+				}
+				t.put(r.PutRequest.Item)
+				units += aws.ToFloat64(writeCapacity(name, r.PutRequest.Item).CapacityUnits)
+			case r.DeleteRequest != nil:
+				if err := t.checkKey(r.DeleteRequest.Key); err != nil {
+					return nil, err
+				}
+				units += aws.ToFloat64(writeCapacity(name, t.remove(r.DeleteRequest.Key)).CapacityUnits)
+			}
+		}
+		out.ConsumedCapacity = append(out.ConsumedCapacity, types.ConsumedCapacity{TableName: aws.String(name), CapacityUnits: aws.Float64(units)})
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) ExportTableToPointInTime(ctx context.Context, in *dynamodb.ExportTableToPointInTimeInput, _ ...func(*dynamodb.Options)) (*dynamodb.ExportTableToPointInTimeOutput, error) {
+	return nil, fakeError("UnsupportedOperationException", "in-memory tables can't be exported to S3")
+}
+
+func (f *fakeDynamoDB) DescribeExport(ctx context.Context, in *dynamodb.DescribeExportInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeExportOutput, error) {
+	return nil, fakeError("ExportNotFoundException", "Export not found: %s", aws.ToString(in.ExportArn))
+}
@@ -0,0 +1,194 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FetchBudget bounds how much a multi-page read returns. Zero fields are unlimited.
+type FetchBudget struct {
+	MaxItems int
+	MaxBytes int64 // Approximate stored size of the items, see itemSize
+}
+
+// exhausted reports whether result has used up the budget
+func (b FetchBudget) exhausted(result QueryResult) bool {
+	return (b.MaxItems > 0 && len(result.Items) >= b.MaxItems) ||
+		(b.MaxBytes > 0 && result.SizeBytes >= b.MaxBytes)
+}
+
+// itemDeduper drops items that were already collected by a multi-page read,
+// by their primary key. Pages that overlap, as can happen when a page is read
+// again after a retry, would otherwise count items twice in exports,
+// snapshots and aggregates.
+type itemDeduper struct {
+	primaryKey []string
+	seen       map[string]bool
+}
+
+// newItemDeduper looks up the primary key of the table, which identifies an
+// item even when reading an index
+func newItemDeduper(ctx context.Context, tables TableLister, tableName string) (*itemDeduper, error) {
+	table, err := tables.DescribeTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	primaryKey := []string{table.PartitionKey}
+	if table.SortKey != "" {
+		primaryKey = append(primaryKey, table.SortKey)
+	}
+	return &itemDeduper{primaryKey: primaryKey, seen: make(map[string]bool)}, nil
+}
+
+// add appends the items of page that weren't collected yet to all and counts
+// the others in all.Duplicates
+func (d *itemDeduper) add(all *QueryResult, page QueryResult) {
+	for i, raw := range page.RawItems {
+		sig := d.signature(raw)
+		if d.seen[sig] {
+			all.Duplicates++
+			continue
+		}
+		d.seen[sig] = true
+		all.Items = append(all.Items, page.Items[i])
+		all.RawItems = append(all.RawItems, raw)
+	}
+}
+
+// signature identifies an item by the typed JSON of its primary key, so the
+// string "1" and the number 1 are different keys, and so are key values that
+// only read the same when joined
+func (d *itemDeduper) signature(raw map[string]interface{}) string {
+	key := make(map[string]types.AttributeValue, len(d.primaryKey))
+	for _, attr := range d.primaryKey {
+		av, err := interfaceToAttributeValue(raw[attr])
+		if err != nil {
+			// Key attributes are strings, numbers or binary; anything else is
+			// at least told apart by its Go type
+			av = &types.AttributeValueMemberS{Value: fmt.Sprintf("%T %v", raw[attr], raw[attr])}
+		}
+		key[attr] = av
+	}
+	return keySignature(key, key)
+}
+
+// QueryPages runs a query through r from page (nil for the start) and
+// follows pagination until every page was read or the budget is used up.
+// Whole pages are kept, so the result may exceed the budget by up to one
+// page; NextPage is set when the read stopped early. Items returned twice
+// are only kept once and counted in Duplicates. partial, when set, is called
+// with the items read so far after every page but the last.
+func QueryPages(ctx context.Context, r PartitionReader, params QueryParams, page *PageToken, budget FetchBudget, partial func(QueryResult)) (QueryResult, error) {
+	dedup, err := newItemDeduper(ctx, r, params.TableName)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	var all QueryResult
+	for {
+		result, err := r.Query(ctx, params, page)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		dedup.add(&all, result)
+		all.SizeBytes += result.SizeBytes
+		all.ConsumedCapacity += result.ConsumedCapacity
+		all.Count += result.Count
+		all.ScannedCount += result.ScannedCount
+		all.Requests = append(all.Requests, result.Requests...)
+		all.NextPage = result.NextPage
+
+		if result.NextPage == nil || budget.exhausted(all) {
+			return all, nil
+		}
+		if partial != nil {
+			progress := all
+			progress.NextPage = nil // Not known until the read stops
+			partial(progress)
+		}
+		page = result.NextPage
+	}
+}
+
+// QueryUpTo runs a query, following pagination until maxItems items were read
+func (c *Client) QueryUpTo(ctx context.Context, params QueryParams, maxItems int) (QueryResult, error) {
+	return QueryPages(ctx, c, params, nil, FetchBudget{MaxItems: maxItems}, nil)
+}
+
+// ScanSample scans a table through s from its start until n items were read
+// or the table ends, keeping exactly n items of the last page
+func ScanSample(ctx context.Context, s Scanner, tableName string, n int) (QueryResult, error) {
+	var sample QueryResult
+	var page *PageToken
+	for len(sample.RawItems) < n {
+		result, err := s.Scan(ctx, ScanParams{TableName: tableName}, page)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		take := min(len(result.RawItems), n-len(sample.RawItems))
+		sample.Items = append(sample.Items, result.Items[:take]...)
+		sample.RawItems = append(sample.RawItems, result.RawItems[:take]...)
+		sample.ConsumedCapacity += result.ConsumedCapacity
+		if result.NextPage == nil {
+			break
+		}
+		page = result.NextPage
+	}
+	return sample, nil
+}
+
+// itemSize approximates the stored size of an item the way DynamoDB counts
+// it: attribute name lengths plus the size of each value
+func itemSize(item map[string]types.AttributeValue) int64 {
+	var size int64
+	for name, v := range item {
+		size += int64(len(name)) + attributeSize(v)
+	}
+	return size
+}
+
+// attributeSize approximates the stored size of a single attribute value
+func attributeSize(v types.AttributeValue) int64 {
+	switch val := v.(type) {
+	case *types.AttributeValueMemberS:
+		return int64(len(val.Value))
+	case *types.AttributeValueMemberN:
+		return int64(len(val.Value)+1)/2 + 1
+	case *types.AttributeValueMemberB:
+		return int64(len(val.Value))
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		var size int64
+		for _, s := range val.Value {
+			size += int64(len(s))
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		var size int64
+		for _, n := range val.Value {
+			size += int64(len(n)+1)/2 + 1
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		var size int64
+		for _, b := range val.Value {
+			size += int64(len(b))
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := int64(3) // List overhead plus one byte per element
+		for _, elem := range val.Value {
+			size += 1 + attributeSize(elem)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := int64(3)
+		for name, elem := range val.Value {
+			size += 1 + int64(len(name)) + attributeSize(elem)
+		}
+		return size
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// testPage is a QueryResult of raw items, displayed the same
+func testPage(rawItems ...map[string]interface{}) QueryResult {
+	return QueryResult{Items: rawItems, RawItems: rawItems}
+}
+
+func TestItemDeduperKeepsDistinctKeys(t *testing.T) {
+	d := &itemDeduper{primaryKey: []string{"pk", "sk"}, seen: make(map[string]bool)}
+	var all QueryResult
+	d.add(&all, testPage(
+		map[string]interface{}{"pk": "a|b", "sk": "c"},
+		map[string]interface{}{"pk": "a", "sk": "b|c"},
+		map[string]interface{}{"pk": "1", "sk": "x"},
+		map[string]interface{}{"pk": int64(1), "sk": "x"},
+		map[string]interface{}{"pk": json.Number("1.5"), "sk": "x"},
+		map[string]interface{}{"pk": []byte{1}, "sk": "x"},
+		map[string]interface{}{"pk": []byte{2}, "sk": "x"},
+	))
+	if len(all.RawItems) != 7 || all.Duplicates != 0 {
+		t.Errorf("kept %d items with %d duplicates, want 7 and none", len(all.RawItems), all.Duplicates)
+	}
+}
+
+func TestItemDeduperDropsRepeatedItems(t *testing.T) {
+	d := &itemDeduper{primaryKey: []string{"pk", "sk"}, seen: make(map[string]bool)}
+	var all QueryResult
+	d.add(&all, testPage(
+		map[string]interface{}{"pk": "a", "sk": int64(1), "v": "first"},
+		map[string]interface{}{"pk": "a", "sk": int64(2)},
+	))
+	d.add(&all, testPage(
+		map[string]interface{}{"pk": "a", "sk": int64(2)}, // Read again
+		map[string]interface{}{"pk": "a", "sk": int64(1), "v": "later"},
+		map[string]interface{}{"pk": []byte{1}, "sk": int64(1)},
+		map[string]interface{}{"pk": []byte{1}, "sk": int64(1)},
+	))
+	if len(all.RawItems) != 3 || all.Duplicates != 3 {
+		t.Fatalf("kept %d items with %d duplicates, want 3 and 3", len(all.RawItems), all.Duplicates)
+	}
+	if all.RawItems[0]["v"] != "first" {
+		t.Errorf("kept %v, want the item read first", all.RawItems[0])
+	}
+}
+
+// eventsClient is a client over one partition of five items, read two at a
+// time
+func eventsClient(t *testing.T) *Client {
+	t.Helper()
+	var items []map[string]interface{}
+	for ts := int64(1); ts <= 5; ts++ {
+		items = append(items, map[string]interface{}{"device": "a", "ts": ts})
+	}
+	client, err := NewFakeClient("test", []FakeTable{{
+		Name:           "events",
+		PartitionKey:   "device",
+		SortKey:        "ts",
+		AttributeTypes: map[string]string{"ts": "N"},
+		Items:          items,
+	}}, WithPageSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+var eventsQuery = QueryParams{TableName: "events", PartitionKey: "device", PartitionValue: "a"}
+
+func TestQueryPagesReadsEveryPage(t *testing.T) {
+	var partials []int
+	result, err := QueryPages(context.Background(), eventsClient(t), eventsQuery, nil, FetchBudget{}, func(r QueryResult) {
+		partials = append(partials, len(r.RawItems))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RawItems) != 5 || result.NextPage != nil {
+		t.Errorf("read %d items with a next page %v, want all 5 and none", len(result.RawItems), result.NextPage)
+	}
+	if len(partials) != 2 || partials[0] != 2 || partials[1] != 4 {
+		t.Errorf("partial results of %v items, want 2 and 4", partials)
+	}
+}
+
+func TestQueryPagesStopsAtTheBudget(t *testing.T) {
+	client := eventsClient(t)
+	ctx := context.Background()
+	result, err := QueryPages(ctx, client, eventsQuery, nil, FetchBudget{MaxItems: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.RawItems) != 4 || result.NextPage == nil {
+		t.Fatalf("read %d items with a next page %v, want the 2 whole pages that hold 3 and a next page", len(result.RawItems), result.NextPage)
+	}
+
+	rest, err := QueryPages(ctx, client, eventsQuery, result.NextPage, FetchBudget{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest.RawItems) != 1 || rest.RawItems[0]["ts"] != int64(5) {
+		t.Errorf("carrying on read %v, want the last item", rest.RawItems)
+	}
+}
+
+// scriptedReader returns its pages in turn whatever page is asked for, for
+// reads whose pages overlap
+type scriptedReader struct {
+	pages []QueryResult
+	reads int
+}
+
+func (r *scriptedReader) ListTables(ctx context.Context, progress func(loaded []TableInfo)) ([]TableInfo, error) {
+	return nil, nil
+}
+
+func (r *scriptedReader) DescribeTable(ctx context.Context, name string) (TableInfo, error) {
+	return TableInfo{Name: name, PartitionKey: "device", SortKey: "ts"}, nil
+}
+
+func (r *scriptedReader) Query(ctx context.Context, params QueryParams, page *PageToken) (QueryResult, error) {
+	result := r.pages[r.reads]
+	r.reads++
+	if r.reads < len(r.pages) {
+		result.NextPage = newPageToken(map[string]types.AttributeValue{"device": &types.AttributeValueMemberS{Value: "a"}})
+	}
+	return result, nil
+}
+
+func TestQueryPagesDropsItemsOfOverlappingPages(t *testing.T) {
+	r := &scriptedReader{pages: []QueryResult{
+		testPage(map[string]interface{}{"device": "a", "ts": int64(1)}, map[string]interface{}{"device": "a", "ts": int64(2)}),
+		testPage(map[string]interface{}{"device": "a", "ts": int64(2)}, map[string]interface{}{"device": "a", "ts": int64(3)}),
+	}}
+	result, err := QueryPages(context.Background(), r, eventsQuery, nil, FetchBudget{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 2 || len(result.RawItems) != 3 || result.Duplicates != 1 {
+		t.Errorf("read %d pages into %d items with %d duplicates, want 2 pages, 3 items and 1 duplicate", r.reads, len(result.RawItems), result.Duplicates)
+	}
+}
+
+func TestScanSampleKeepsExactlyN(t *testing.T) {
+	client := eventsClient(t)
+	for n, want := range map[int]int{1: 1, 3: 3, 10: 5} {
+		sample, err := ScanSample(context.Background(), client, "events", n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sample.RawItems) != want || len(sample.Items) != want {
+			t.Errorf("sample of %d holds %d items, want %d", n, len(sample.RawItems), want)
+		}
+	}
+}
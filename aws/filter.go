@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FilterConditions are the comparisons a filter on one attribute supports.
+// The size conditions compare the size of the attribute: the length of a
+// string or binary value, or the number of elements of a set, list or map.
+var FilterConditions = []string{
+	"=", "<>", "<", "<=", ">", ">=",
+	"begins_with", "contains", "not_contains", "IN",
+	"attribute_exists", "attribute_not_exists", "attribute_type",
+	"size =", "size <>", "size <", "size <=", "size >", "size >=",
+}
+
+// TypeNames are the attribute types attribute_type compares with
+var TypeNames = []string{"S", "N", "B", "BOOL", "NULL", "SS", "NS", "BS", "L", "M"}
+
+// maxInValues is the most values DynamoDB accepts in an IN list
+const maxInValues = 100
+
+// FilterTakesValue reports whether a filter condition compares the attribute
+// with a value
+func FilterTakesValue(condition string) bool {
+	return condition != "attribute_exists" && condition != "attribute_not_exists"
+}
+
+// inValues splits the comma-separated values of an IN filter
+func inValues(value string) []string {
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// CheckFilter reports why a filter can't be sent: an unknown condition, a
+// size that isn't a whole number, an unknown attribute type, or an IN list
+// that is empty or too long
+func CheckFilter(condition, value string) error {
+	switch {
+	case !slices.Contains(FilterConditions, condition):
+		return fmt.Errorf("unknown filter condition %q", condition)
+	case strings.HasPrefix(condition, "size "):
+		if _, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err != nil {
+			return fmt.Errorf("size is compared with a whole number, not %q", value)
+		}
+	case condition == "attribute_type":
+		if !slices.Contains(TypeNames, strings.TrimSpace(value)) {
+			return fmt.Errorf("attribute_type takes one of %s, not %q", strings.Join(TypeNames, ", "), value)
+		}
+	case condition == "IN":
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("IN needs a comma-separated list of values")
+		}
+		if n := len(inValues(value)); n > maxInValues {
+			return fmt.Errorf("IN takes at most %d values, not %d", maxInValues, n)
+		}
+	}
+	return nil
+}
+
+// FilterText writes a filter the way DynamoDB expressions read, e.g.
+// "size(tags) > 3" or "status IN (open, held)"
+func FilterText(attr, condition, value string) string {
+	switch condition {
+	case "begins_with", "contains", "not_contains", "attribute_type":
+		return fmt.Sprintf("%s(%s, %s)", condition, attr, value)
+	case "attribute_exists", "attribute_not_exists":
+		return fmt.Sprintf("%s(%s)", condition, attr)
+	case "IN":
+		return fmt.Sprintf("%s IN (%s)", attr, strings.Join(inValues(value), ", "))
+	}
+	if op, ok := strings.CutPrefix(condition, "size "); ok {
+		return fmt.Sprintf("size(%s) %s %s", attr, op, value)
+	}
+	return attr + " " + condition + " " + value
+}
+
+// filterExpression builds the FilterExpression of a filter on one attribute.
+// Values are compared as strings, sizes as numbers.
+func filterExpression(attr, condition, value string) (string, map[string]string, map[string]types.AttributeValue) {
+	names := map[string]string{"#f": attr}
+	values := map[string]types.AttributeValue{":f": &types.AttributeValueMemberS{Value: value}}
+	switch condition {
+	case "begins_with", "contains":
+		return fmt.Sprintf("%s(#f, :f)", condition), names, values
+	case "not_contains":
+		return "NOT contains(#f, :f)", names, values
+	case "attribute_type":
+		values[":f"] = &types.AttributeValueMemberS{Value: strings.TrimSpace(value)}
+		return "attribute_type(#f, :f)", names, values
+	case "attribute_exists", "attribute_not_exists":
+		return fmt.Sprintf("%s(#f)", condition), names, nil
+	case "IN":
+		values = make(map[string]types.AttributeValue)
+		var placeholders []string
+		for i, v := range inValues(value) {
+			p := fmt.Sprintf(":f%d", i)
+			values[p] = &types.AttributeValueMemberS{Value: v}
+			placeholders = append(placeholders, p)
+		}
+		return fmt.Sprintf("#f IN (%s)", strings.Join(placeholders, ", ")), names, values
+	}
+	if op, ok := strings.CutPrefix(condition, "size "); ok {
+		values[":f"] = &types.AttributeValueMemberN{Value: strings.TrimSpace(value)}
+		return fmt.Sprintf("size(#f) %s :f", op), names, values
+	}
+	return fmt.Sprintf("#f %s :f", condition), names, values
+}
@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxImportLine bounds one line of an import; items are at most 400 KB,
+// which base64 and typed JSON can double
+const maxImportLine = 4 << 20
+
+// ImportParams describes loading items into a table from JSON lines
+type ImportParams struct {
+	TableName     string
+	Typed         bool    // Lines in the typed JSON of the DynamoDB API, as an export with Typed writes them
+	DryRun        bool    // Check every item without writing any
+	MaxWriteUnits float64 // Write capacity used per second; 0 doesn't limit
+}
+
+// ImportProgress reports how far an import got
+type ImportProgress struct {
+	Items        int64   // Items read from the input
+	PlannedUnits float64 // Write capacity the items read need, from their size
+	WriteProgress
+}
+
+// ImportItems reads one JSON item per line from r and puts them into a table
+// with the batch writer of CopyTable, so unprocessed items are retried with
+// backoff. Plain values are encoded like edited items, re-encoding
+// attributes that have a registered decoder. Blank lines are skipped; a line
+// that isn't an item with the table's key stops the import with its line
+// number. Items with the same key as an existing item replace it. A dry run
+// reads and checks the whole input but writes nothing. progress is called
+// after every batch; on error the progress so far is returned.
+func (c *Client) ImportItems(ctx context.Context, params ImportParams, r io.Reader, progress func(ImportProgress)) (ImportProgress, error) {
+	var p ImportProgress
+	table, err := c.describeTable(ctx, params.TableName)
+	if err != nil {
+		return p, err
+	}
+	keyAttrs := []string{table.PartitionKey}
+	if table.SortKey != "" {
+		keyAttrs = append(keyAttrs, table.SortKey)
+	}
+
+	limiter := newWriteLimiter(params.MaxWriteUnits)
+	var batch []map[string]types.AttributeValue
+	var batchUnits float64
+	batchKeys := make(map[string]bool)
+	flush := func() error {
+		if len(batch) > 0 && !params.DryRun {
+			if err := limiter.wait(ctx, batchUnits); err != nil {
+				return err
+			}
+			if err := c.batchWrite(ctx, params.TableName, batch, &p.WriteProgress); err != nil {
+				return err
+			}
+		}
+		batch, batchUnits = batch[:0], 0
+		clear(batchKeys)
+		if progress != nil {
+			progress(p)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		item, err := c.importedItem(params, text)
+		if err == nil {
+			err = checkKey(item, keyAttrs)
+		}
+		if err != nil {
+			return p, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// BatchWriteItem rejects a batch that puts the same key twice, so a
+		// repeated key starts a new batch and the later line wins
+		key := keyOf(item, keyAttrs)
+		signature := keySignature(key, item)
+		if len(batch) == maxBatchWriteItems || batchKeys[signature] {
+			if err := flush(); err != nil {
+				return p, err
+			}
+		}
+		units := writeUnits(item)
+		batch = append(batch, item)
+		batchUnits += units
+		batchKeys[signature] = true
+		p.Items++
+		p.PlannedUnits += units
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return p, fmt.Errorf("line %d: longer than %d MB", line+1, maxImportLine>>20)
+		}
+		return p, err
+	}
+	return p, flush()
+}
+
+// importedItem parses one line of an import
+func (c *Client) importedItem(params ImportParams, line []byte) (map[string]types.AttributeValue, error) {
+	if params.Typed {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, fmt.Errorf("not a JSON object: %w", err)
+		}
+		return fromTypedItemJSON(raw)
+	}
+	var values map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber() // Keeps numbers exact, as DynamoDB stores them
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %w", err)
+	}
+	item := make(map[string]types.AttributeValue, len(values))
+	for k, v := range values {
+		av, err := interfaceToAttributeValue(v) // Decoders only re-encode values read from the table
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", k, err)
+		}
+		item[k] = av
+	}
+	return item, nil
+}
+
+// checkKey verifies that an item has every key attribute, as a string,
+// number or binary
+func checkKey(item map[string]types.AttributeValue, keyAttrs []string) error {
+	for _, k := range keyAttrs {
+		switch item[k].(type) {
+		case *types.AttributeValueMemberS, *types.AttributeValueMemberN, *types.AttributeValueMemberB:
+		case nil:
+			return fmt.Errorf("missing key attribute %s", k)
+		default:
+			return fmt.Errorf("key attribute %s must be a string, number or binary", k)
+		}
+	}
+	return nil
+}
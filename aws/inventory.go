@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// InventoryEntry is a table's metadata together with the settings that
+// DescribeTable doesn't return
+type InventoryEntry struct {
+	TableInfo
+	Tags                map[string]string
+	PointInTimeRecovery bool
+}
+
+// Inventory describes every table of the account and region, including its
+// tags and point-in-time recovery status. If progress is not nil it is
+// called after each table with the number of tables done so far.
+func (c *Client) Inventory(ctx context.Context, progress func(done, total int)) ([]InventoryEntry, error) {
+	tables, err := c.ListTables(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]InventoryEntry, len(tables))
+	for i, t := range tables {
+		entries[i].TableInfo = t
+		if entries[i].Tags, err = c.tableTags(ctx, t.ARN); err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", t.Name, err)
+		}
+		if entries[i].PointInTimeRecovery, err = c.pointInTimeRecovery(ctx, t.Name); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i+1, len(tables))
+		}
+	}
+	return entries, nil
+}
+
+// pointInTimeRecovery reports whether point-in-time recovery is enabled on a table
+func (c *Client) pointInTimeRecovery(ctx context.Context, name string) (bool, error) {
+	if err := c.control.wait(ctx); err != nil {
+		return false, err
+	}
+	backups, err := c.api().DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{
+		TableName: aws.String(name),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe backups of %s: %w", name, err)
+	}
+	cb := backups.ContinuousBackupsDescription
+	return cb != nil && cb.PointInTimeRecoveryDescription != nil &&
+		cb.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled, nil
+}
+
+// tableTags returns the tags of a table, following ListTagsOfResource pagination
+func (c *Client) tableTags(ctx context.Context, arn string) (map[string]string, error) {
+	tags := make(map[string]string)
+	input := &dynamodb.ListTagsOfResourceInput{ResourceArn: aws.String(arn)}
+	for {
+		if err := c.control.wait(ctx); err != nil {
+			return nil, err
+		}
+		result, err := c.api().ListTagsOfResource(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range result.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if result.NextToken == nil {
+			return tags, nil
+		}
+		input.NextToken = result.NextToken
+	}
+}
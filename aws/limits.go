@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Default DynamoDB service quotas that DescribeLimits doesn't report. Both
+// can be raised per account and region through Service Quotas.
+const (
+	DefaultMaxTables               = 2500 // Tables per account and region
+	DefaultMaxConcurrentOperations = 500  // Tables and indexes being created, updated or deleted at once
+)
+
+// AccountLimits holds the provisioned capacity quotas returned by DescribeLimits
+type AccountLimits struct {
+	AccountMaxReadCapacity  int64 // Across all tables and GSIs of the account in this region
+	AccountMaxWriteCapacity int64
+	TableMaxReadCapacity    int64 // For a single table or GSI
+	TableMaxWriteCapacity   int64
+}
+
+// AccountLimits returns the capacity quotas of the account in the client's region
+func (c *Client) AccountLimits(ctx context.Context) (AccountLimits, error) {
+	if err := c.control.wait(ctx); err != nil {
+		return AccountLimits{}, err
+	}
+	result, err := c.api().DescribeLimits(ctx, &dynamodb.DescribeLimitsInput{})
+	if err != nil {
+		return AccountLimits{}, err
+	}
+	return AccountLimits{
+		AccountMaxReadCapacity:  aws.ToInt64(result.AccountMaxReadCapacityUnits),
+		AccountMaxWriteCapacity: aws.ToInt64(result.AccountMaxWriteCapacityUnits),
+		TableMaxReadCapacity:    aws.ToInt64(result.TableMaxReadCapacityUnits),
+		TableMaxWriteCapacity:   aws.ToInt64(result.TableMaxWriteCapacityUnits),
+	}, nil
+}
+
+// AccountUsage is how much of the account quotas a set of tables uses
+type AccountUsage struct {
+	Tables        int
+	Operations    int   // Tables and GSIs in a CREATING, UPDATING or DELETING state
+	ReadCapacity  int64 // Provisioned read capacity of all tables and GSIs
+	WriteCapacity int64
+	TableMaxRead  int64 // Highest provisioned read capacity of a single table or GSI
+	TableMaxWrite int64
+}
+
+// UsageOf sums up the account quota usage of described tables
+func UsageOf(tables []TableInfo) AccountUsage {
+	inProgress := func(status string) bool {
+		return status == "CREATING" || status == "UPDATING" || status == "DELETING"
+	}
+	usage := AccountUsage{Tables: len(tables)}
+	add := func(status string, read, write int64) {
+		if inProgress(status) {
+			usage.Operations++
+		}
+		usage.ReadCapacity += read
+		usage.WriteCapacity += write
+		usage.TableMaxRead = max(usage.TableMaxRead, read)
+		usage.TableMaxWrite = max(usage.TableMaxWrite, write)
+	}
+	for _, t := range tables {
+		add(t.Status, t.ReadCapacity, t.WriteCapacity)
+		for _, idx := range t.Indexes {
+			add(idx.Status, idx.ReadCapacity, idx.WriteCapacity)
+		}
+	}
+	return usage
+}
@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// WithLogger logs every DynamoDB request at debug level, with its
+// operation, table, duration, attempts and consumed capacity, failed
+// requests as warnings and throttling retries as info
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// loggingMiddleware logs each operation once, after the retry loop
+func loggingMiddleware(logger *slog.Logger) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RequestLogging", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+
+			attrs := []slog.Attr{
+				slog.String("operation", middleware.GetOperationName(ctx)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if table := stringField(in.Parameters, "TableName"); table != "" {
+				attrs = append(attrs, slog.String("table", table))
+			}
+			if index := stringField(in.Parameters, "IndexName"); index != "" {
+				attrs = append(attrs, slog.String("index", index))
+			}
+			if results, ok := retry.GetAttemptResults(metadata); ok {
+				attrs = append(attrs, slog.Int("attempts", len(results.Results)))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelWarn, "request failed", attrs...)
+				return out, metadata, err
+			}
+			if units, ok := outputCapacity(out.Result); ok {
+				attrs = append(attrs, slog.Float64("capacity", units))
+			}
+			if count, ok := intField(out.Result, "Count"); ok {
+				attrs = append(attrs, slog.Int64("count", count))
+			}
+			logger.LogAttrs(ctx, slog.LevelDebug, "request", attrs...)
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+// stringField reads a *string field of an operation's input, such as
+// TableName, which most inputs share but under no common interface
+func stringField(v interface{}, name string) string {
+	f := structField(v, name)
+	if !f.IsValid() {
+		return ""
+	}
+	if s, ok := f.Interface().(*string); ok {
+		return aws.ToString(s)
+	}
+	return ""
+}
+
+// intField reads an int32 field of an operation's output, such as Count
+func intField(v interface{}, name string) (int64, bool) {
+	f := structField(v, name)
+	if !f.IsValid() {
+		return 0, false
+	}
+	if n, ok := f.Interface().(int32); ok {
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// outputCapacity sums the consumed capacity of an operation's output, which
+// is a single value or one per table depending on the operation
+func outputCapacity(v interface{}) (float64, bool) {
+	f := structField(v, "ConsumedCapacity")
+	if !f.IsValid() {
+		return 0, false
+	}
+	switch cc := f.Interface().(type) {
+	case *types.ConsumedCapacity:
+		if cc == nil {
+			return 0, false
+		}
+		return aws.ToFloat64(cc.CapacityUnits), true
+	case []types.ConsumedCapacity:
+		if len(cc) == 0 {
+			return 0, false
+		}
+		var units float64
+		for _, c := range cc {
+			units += aws.ToFloat64(c.CapacityUnits)
+		}
+		return units, true
+	}
+	return 0, false
+}
+
+// structField returns the named field of a pointer to a struct, or the zero
+// Value
+func structField(v interface{}, name string) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return rv.Elem().FieldByName(name)
+}
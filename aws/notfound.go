@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableNotFoundError reports that a request failed with
+// ResourceNotFoundException: the table, or the index it used, no longer
+// exists. Table names the table the request was for.
+type TableNotFoundError struct {
+	Table string
+	Err   error
+}
+
+func (e *TableNotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TableNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// MissingTable returns the table a failed request was for when it failed
+// because the table or index no longer exists
+func MissingTable(err error) (string, bool) {
+	var notFound *TableNotFoundError
+	if errors.As(err, &notFound) {
+		return notFound.Table, true
+	}
+	return "", false
+}
+
+// tableError marks ResourceNotFoundException errors of a request on table
+// and drops the table's cached description, which is now stale
+func (c *Client) tableError(table string, err error) error {
+	var rnf *types.ResourceNotFoundException
+	if !errors.As(err, &rnf) {
+		return err
+	}
+	c.control.forget(table)
+	return &TableNotFoundError{Table: table, Err: err}
+}
@@ -0,0 +1,151 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PageToken is a pagination cursor holding the LastEvaluatedKey of a query or
+// scan exactly as DynamoDB returned it. Pass it back to Query or Scan to read
+// the next page; String and ParsePageToken save and restore it as text.
+type PageToken struct {
+	key map[string]types.AttributeValue
+}
+
+// newPageToken wraps a LastEvaluatedKey, returning nil when there are no more pages
+func newPageToken(key map[string]types.AttributeValue) *PageToken {
+	if len(key) == 0 {
+		return nil
+	}
+	return &PageToken{key: key}
+}
+
+// exclusiveStartKey returns the key to resume from, nil for the first page
+func (t *PageToken) exclusiveStartKey() map[string]types.AttributeValue {
+	if t == nil {
+		return nil
+	}
+	return t.key
+}
+
+// tokenValue is the serialized form of a key attribute. Key attributes can
+// only be strings, numbers or binary, which is all a token needs to carry.
+type tokenValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// String encodes the token as URL-safe base64 text
+func (t *PageToken) String() string {
+	if t == nil {
+		return ""
+	}
+	values := make(map[string]tokenValue, len(t.key))
+	for k, v := range t.key {
+		switch val := v.(type) {
+		case *types.AttributeValueMemberS:
+			values[k] = tokenValue{S: &val.Value}
+		case *types.AttributeValueMemberN:
+			values[k] = tokenValue{N: &val.Value}
+		case *types.AttributeValueMemberB:
+			values[k] = tokenValue{B: val.Value}
+		}
+	}
+	data, _ := json.Marshal(values) // Strings and byte slices always marshal
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageToken restores a token saved with PageToken.String. An empty
+// string yields a nil token, which starts from the first page.
+func ParsePageToken(s string) (*PageToken, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+	var values map[string]tokenValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+
+	key := make(map[string]types.AttributeValue, len(values))
+	for k, v := range values {
+		switch {
+		case v.S != nil:
+			key[k] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[k] = &types.AttributeValueMemberN{Value: *v.N}
+		case v.B != nil:
+			key[k] = &types.AttributeValueMemberB{Value: v.B}
+		default:
+			return nil, fmt.Errorf("invalid page token: attribute %s has no value", k)
+		}
+	}
+	return newPageToken(key), nil
+}
+
+// JSON writes the key the token resumes after as typed JSON, the form the
+// AWS CLI takes for --exclusive-start-key, e.g. {"id":{"S":"u-0015"}}
+func (t *PageToken) JSON() string {
+	if t == nil {
+		return ""
+	}
+	return cliJSON(typedItemJSON(t.key))
+}
+
+// Summary lists the key attributes the token resumes after for display,
+// e.g. "id=u-0015, ts=1700000000"
+func (t *PageToken) Summary() string {
+	if t == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(t.key))
+	for _, k := range slices.Sorted(maps.Keys(t.key)) {
+		var value string
+		switch val := t.key[k].(type) {
+		case *types.AttributeValueMemberS:
+			value = val.Value
+		case *types.AttributeValueMemberN:
+			value = val.Value
+		case *types.AttributeValueMemberB:
+			value = base64.StdEncoding.EncodeToString(val.Value)
+		}
+		parts = append(parts, k+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseCursor reads a cursor entered by hand: an ExclusiveStartKey as typed
+// JSON, as JSON writes it, or a token saved with String. Blank text yields
+// a nil token, which starts from the first page.
+func ParseCursor(s string) (*PageToken, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return ParsePageToken(s)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf(`cursor must be a JSON object of key attributes, e.g. {"id": {"S": "u-0015"}}: %w`, err)
+	}
+	key, err := fromTypedItemJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cursor: %w", err)
+	}
+	for k, v := range key {
+		switch v.(type) {
+		case *types.AttributeValueMemberS, *types.AttributeValueMemberN, *types.AttributeValueMemberB:
+		default:
+			return nil, fmt.Errorf("cursor attribute %s must be a string, number or binary key value", k)
+		}
+	}
+	return newPageToken(key), nil
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RawParams is a query or scan written as DynamoDB expressions, which are
+// sent as they are. Names and Values hold the JSON objects the AWS CLI takes
+// for --expression-attribute-names and --expression-attribute-values, e.g.
+// {"#s": "status"} and {":s": {"S": "open"}}.
+type RawParams struct {
+	TableName      string
+	IndexName      string // Empty to read the base table
+	KeyCondition   string // KeyConditionExpression; empty to scan
+	Filter         string // FilterExpression, optional
+	Projection     string // ProjectionExpression, optional
+	Names          string // ExpressionAttributeNames as JSON, optional
+	Values         string // ExpressionAttributeValues as typed JSON, optional
+	Descending     bool   // ScanIndexForward=false, queries only
+	ConsistentRead bool
+}
+
+// IsQuery reports whether the read is a query rather than a scan
+func (p RawParams) IsQuery() bool {
+	return strings.TrimSpace(p.KeyCondition) != ""
+}
+
+// Attributes parses the expression attribute names and values. Empty
+// fields give nil maps, since DynamoDB rejects empty ones.
+func (p RawParams) Attributes() (map[string]string, map[string]types.AttributeValue, error) {
+	var names map[string]string
+	if strings.TrimSpace(p.Names) != "" {
+		if err := json.Unmarshal([]byte(p.Names), &names); err != nil {
+			return nil, nil, fmt.Errorf("attribute names must be a JSON object of strings, e.g. {\"#s\": \"status\"}: %w", err)
+		}
+	}
+	var values map[string]types.AttributeValue
+	if strings.TrimSpace(p.Values) != "" {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(p.Values), &raw); err != nil {
+			return nil, nil, fmt.Errorf("attribute values must be a JSON object, e.g. {\":s\": {\"S\": \"open\"}}: %w", err)
+		}
+		var err error
+		if values, err = fromTypedItemJSON(raw); err != nil {
+			return nil, nil, fmt.Errorf("attribute values: %w", err)
+		}
+	}
+	if len(names) == 0 {
+		names = nil
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	return names, values, nil
+}
+
+// optionalString is nil for blank text, which the API takes as not given
+func optionalString(s string) *string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// rawInput builds the request of a raw read without a starting page: a
+// query when there is a key condition, otherwise a scan
+func (c *Client) rawInput(params RawParams) (*dynamodb.QueryInput, *dynamodb.ScanInput, error) {
+	names, values, err := params.Attributes()
+	if err != nil {
+		return nil, nil, err
+	}
+	if params.IsQuery() {
+		input := &dynamodb.QueryInput{
+			TableName:                 aws.String(params.TableName),
+			IndexName:                 optionalString(params.IndexName),
+			KeyConditionExpression:    aws.String(params.KeyCondition),
+			FilterExpression:          optionalString(params.Filter),
+			ProjectionExpression:      optionalString(params.Projection),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+			Limit:                     c.pageLimit(),
+			ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+		}
+		if params.Descending {
+			input.ScanIndexForward = aws.Bool(false)
+		}
+		if params.ConsistentRead {
+			input.ConsistentRead = aws.Bool(true)
+		}
+		return input, nil, nil
+	}
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(params.TableName),
+		IndexName:                 optionalString(params.IndexName),
+		FilterExpression:          optionalString(params.Filter),
+		ProjectionExpression:      optionalString(params.Projection),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		Limit:                     c.pageLimit(),
+		ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+	}
+	if params.ConsistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	return nil, input, nil
+}
+
+// RawRead runs a query, or a scan when there is no key condition, with the
+// expressions as written, one page at a time. A nil page reads the first
+// page.
+func (c *Client) RawRead(ctx context.Context, params RawParams, page *PageToken) (QueryResult, error) {
+	queryInput, scanInput, err := c.rawInput(params)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	tableName := params.TableName
+	var rawItems []map[string]types.AttributeValue
+	var lastKey map[string]types.AttributeValue
+	var capacity *types.ConsumedCapacity
+	var count, scanned int32
+
+	ctx, diag := withDiagnostics(ctx)
+	if queryInput != nil {
+		queryInput.ExclusiveStartKey = page.exclusiveStartKey()
+		result, err := c.api().Query(ctx, queryInput)
+		if err != nil {
+			return QueryResult{}, c.tableError(tableName, err)
+		}
+		rawItems, lastKey, capacity = result.Items, result.LastEvaluatedKey, result.ConsumedCapacity
+		count, scanned = result.Count, result.ScannedCount
+	} else {
+		scanInput.ExclusiveStartKey = page.exclusiveStartKey()
+		result, err := c.api().Scan(ctx, scanInput)
+		if err != nil {
+			return QueryResult{}, c.tableError(tableName, err)
+		}
+		rawItems, lastKey, capacity = result.Items, result.LastEvaluatedKey, result.ConsumedCapacity
+		count, scanned = result.Count, result.ScannedCount
+	}
+
+	items := make([]map[string]interface{}, len(rawItems))
+	converted := make([]map[string]interface{}, len(rawItems))
+	var size int64
+	for i, item := range rawItems {
+		items[i], converted[i] = c.convertItem(tableName, item)
+		size += itemSize(item)
+	}
+	return QueryResult{
+		Items:            items,
+		RawItems:         converted,
+		NextPage:         newPageToken(lastKey),
+		SizeBytes:        size,
+		Count:            int(count),
+		ScannedCount:     int(scanned),
+		ConsumedCapacity: c.capacity.record(consumedCapacity(capacity)...),
+		Requests:         diag.result(),
+	}, nil
+}
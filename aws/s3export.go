@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ExportFormats are the formats an S3 export can write, the default first
+var ExportFormats = []string{string(types.ExportFormatDynamodbJson), string(types.ExportFormatIon)}
+
+// S3Export is the state of a table export to S3
+type S3Export struct {
+	ARN            string
+	Table          string
+	Status         string // IN_PROGRESS, COMPLETED or FAILED
+	Bucket         string
+	Prefix         string
+	Format         string
+	ExportTime     time.Time // Point in time the export is consistent to
+	StartTime      time.Time
+	EndTime        time.Time // Zero while in progress
+	ItemCount      int64
+	BilledBytes    int64
+	Manifest       string // S3 key of the manifest listing the data files, set once completed
+	FailureMessage string
+}
+
+// Done reports whether the export finished, successfully or not
+func (e S3Export) Done() bool {
+	return e.Status != string(types.ExportStatusInProgress)
+}
+
+// ExportTableToS3 starts a full export of a table's current state to an S3
+// bucket. DynamoDB reads the table from its point-in-time recovery backups
+// rather than the table itself, so no read capacity is used, but PITR must
+// be enabled. The export runs in the background for minutes to hours; follow
+// it with DescribeS3Export.
+func (c *Client) ExportTableToS3(ctx context.Context, tableName, bucket, prefix, format string) (S3Export, error) {
+	table, err := c.describeTable(ctx, tableName)
+	if err != nil {
+		return S3Export{}, err
+	}
+	pitr, err := c.pointInTimeRecovery(ctx, tableName)
+	if err != nil {
+		return S3Export{}, err
+	}
+	if !pitr {
+		return S3Export{}, fmt.Errorf("point-in-time recovery is not enabled on %s, which exports to S3 need", tableName)
+	}
+
+	if err := c.control.wait(ctx); err != nil {
+		return S3Export{}, err
+	}
+	input := &dynamodb.ExportTableToPointInTimeInput{
+		TableArn:     aws.String(table.ARN),
+		S3Bucket:     aws.String(bucket),
+		ExportFormat: types.ExportFormat(format),
+	}
+	if prefix != "" {
+		input.S3Prefix = aws.String(prefix)
+	}
+	result, err := c.api().ExportTableToPointInTime(ctx, input)
+	if err != nil {
+		return S3Export{}, c.tableError(tableName, err)
+	}
+	return s3ExportFrom(tableName, result.ExportDescription), nil
+}
+
+// DescribeS3Export returns the current state of an export
+func (c *Client) DescribeS3Export(ctx context.Context, export S3Export) (S3Export, error) {
+	if err := c.control.wait(ctx); err != nil {
+		return S3Export{}, err
+	}
+	result, err := c.api().DescribeExport(ctx, &dynamodb.DescribeExportInput{ExportArn: aws.String(export.ARN)})
+	if err != nil {
+		return S3Export{}, err
+	}
+	return s3ExportFrom(export.Table, result.ExportDescription), nil
+}
+
+// s3ExportFrom converts an export description
+func s3ExportFrom(tableName string, d *types.ExportDescription) S3Export {
+	if d == nil {
+		return S3Export{Table: tableName}
+	}
+	return S3Export{
+		ARN:            aws.ToString(d.ExportArn),
+		Table:          tableName,
+		Status:         string(d.ExportStatus),
+		Bucket:         aws.ToString(d.S3Bucket),
+		Prefix:         aws.ToString(d.S3Prefix),
+		Format:         string(d.ExportFormat),
+		ExportTime:     aws.ToTime(d.ExportTime),
+		StartTime:      aws.ToTime(d.StartTime),
+		EndTime:        aws.ToTime(d.EndTime),
+		ItemCount:      aws.ToInt64(d.ItemCount),
+		BilledBytes:    aws.ToInt64(d.BilledSizeBytes),
+		Manifest:       aws.ToString(d.ExportManifest),
+		FailureMessage: aws.ToString(d.FailureMessage),
+	}
+}
@@ -0,0 +1,147 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxShards bounds the number of suffixes a shard pattern may expand to
+const maxShards = 256
+
+// shardRange matches the {FIRST..LAST} placeholder of a shard pattern
+var shardRange = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// ShardPattern describes a write-sharded partition key: one logical key
+// stored under a numbered suffix, such as USER#123#shard0 to USER#123#shard15
+// for the pattern "#shard{0..15}"
+type ShardPattern struct {
+	before, after string // Suffix text around the shard number
+	first, last   int
+}
+
+// ParseShardPattern reads a suffix pattern containing a single {FIRST..LAST} range
+func ParseShardPattern(s string) (ShardPattern, error) {
+	loc := shardRange.FindStringSubmatchIndex(s)
+	if loc == nil || len(shardRange.FindAllString(s, -1)) != 1 {
+		return ShardPattern{}, fmt.Errorf("invalid shard pattern %q: needs one {FIRST..LAST} range, e.g. #shard{0..15}", s)
+	}
+	first, _ := strconv.Atoi(s[loc[2]:loc[3]])
+	last, _ := strconv.Atoi(s[loc[4]:loc[5]])
+	if last < first || last-first+1 > maxShards {
+		return ShardPattern{}, fmt.Errorf("invalid shard pattern %q: range must be ascending and at most %d shards", s, maxShards)
+	}
+	return ShardPattern{before: s[:loc[0]], after: s[loc[1]:], first: first, last: last}, nil
+}
+
+// ShardSpec parses a --shards flag value of the form TABLE=PATTERN or
+// TABLE.INDEX=PATTERN and returns the target and the pattern
+func ShardSpec(spec string) (target string, pattern ShardPattern, err error) {
+	target, raw, ok := strings.Cut(spec, "=")
+	if !ok || target == "" {
+		return "", ShardPattern{}, fmt.Errorf("invalid shard spec %q: expected TABLE=PATTERN or TABLE.INDEX=PATTERN", spec)
+	}
+	pattern, err = ParseShardPattern(raw)
+	return target, pattern, err
+}
+
+// String returns the pattern as written
+func (p ShardPattern) String() string {
+	return fmt.Sprintf("%s{%d..%d}%s", p.before, p.first, p.last, p.after)
+}
+
+// Count returns the number of shards
+func (p ShardPattern) Count() int {
+	return p.last - p.first + 1
+}
+
+// Suffixes returns the suffix of every shard, in shard order
+func (p ShardPattern) Suffixes() []string {
+	suffixes := make([]string, 0, p.Count())
+	for n := p.first; n <= p.last; n++ {
+		suffixes = append(suffixes, p.before+strconv.Itoa(n)+p.after)
+	}
+	return suffixes
+}
+
+// ShardedQuery runs params once per shard of the partition value,
+// concurrently, reading up to maxItems items from each. The items are merged
+// in sort key order (descending if params.Descending) and Sources holds the
+// shard suffix each item was stored under.
+func (c *Client) ShardedQuery(ctx context.Context, params QueryParams, pattern ShardPattern, maxItems int) (QueryResult, error) {
+	suffixes := pattern.Suffixes()
+	queries := make([]QueryParams, len(suffixes))
+	for i, suffix := range suffixes {
+		queries[i] = params
+		queries[i].PartitionValue = params.PartitionValue + suffix
+	}
+	result, err := c.FanOutQuery(ctx, queries, suffixes, maxItems)
+	if err != nil || params.SortKey == "" {
+		return result, err
+	}
+
+	order := make([]int, len(result.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		cmp := compareKeyValues(result.RawItems[order[i]][params.SortKey], result.RawItems[order[j]][params.SortKey])
+		if params.Descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	sorted := QueryResult{ConsumedCapacity: result.ConsumedCapacity, Count: result.Count, ScannedCount: result.ScannedCount, Requests: result.Requests}
+	for _, i := range order {
+		sorted.Items = append(sorted.Items, result.Items[i])
+		sorted.RawItems = append(sorted.RawItems, result.RawItems[i])
+		sorted.Sources = append(sorted.Sources, result.Sources[i])
+	}
+	return sorted, nil
+}
+
+// compareKeyValues orders raw key attribute values the way DynamoDB orders
+// sort keys: numerically, by string or by bytes
+func compareKeyValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case int64, float64, json.Number:
+		af, _ := toFloat(av)
+		if bf, ok := toFloat(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if bs, ok := b.(string); ok {
+			return strings.Compare(av, bs)
+		}
+	case []byte:
+		if bb, ok := b.([]byte); ok {
+			return bytes.Compare(av, bb)
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// toFloat converts a raw number value to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,264 @@
+package aws
+
+import (
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// snippetField is a named argument of a request in a code snippet, with its
+// value already written in the snippet's language
+type snippetField struct {
+	name  string
+	value string
+}
+
+// snippetFields lists the arguments of the request, in the order the API
+// documents them. The page size and consumed capacity are left to the caller,
+// since they belong to how the explorer reads rather than to the read.
+func (r ReadRequest) snippetFields(str func(string) string, yes func(bool) string, names func(map[string]string) string, values func(map[string]types.AttributeValue) string) []snippetField {
+	var table, index, keyCondition, filter, projection *string
+	var attrNames map[string]string
+	var attrValues map[string]types.AttributeValue
+	var forward, consistent *bool
+	if in := r.query; in != nil {
+		table, index, keyCondition, filter, projection = in.TableName, in.IndexName, in.KeyConditionExpression, in.FilterExpression, in.ProjectionExpression
+		attrNames, attrValues, forward, consistent = in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ScanIndexForward, in.ConsistentRead
+	} else {
+		in := r.scan
+		table, index, filter, projection = in.TableName, in.IndexName, in.FilterExpression, in.ProjectionExpression
+		attrNames, attrValues, consistent = in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ConsistentRead
+	}
+
+	fields := []snippetField{{"TableName", str(aws.ToString(table))}}
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{
+		{"IndexName", index},
+		{"KeyConditionExpression", keyCondition},
+		{"FilterExpression", filter},
+		{"ProjectionExpression", projection},
+	} {
+		if f.value != nil {
+			fields = append(fields, snippetField{f.name, str(*f.value)})
+		}
+	}
+	if len(attrNames) > 0 {
+		fields = append(fields, snippetField{"ExpressionAttributeNames", names(attrNames)})
+	}
+	if len(attrValues) > 0 {
+		fields = append(fields, snippetField{"ExpressionAttributeValues", values(attrValues)})
+	}
+	if forward != nil && !*forward {
+		fields = append(fields, snippetField{"ScanIndexForward", yes(false)})
+	}
+	if aws.ToBool(consistent) {
+		fields = append(fields, snippetField{"ConsistentRead", yes(true)})
+	}
+	return fields
+}
+
+// operation is the API name of the request, Query or Scan
+func (r ReadRequest) operation() string {
+	if r.query != nil {
+		return "Query"
+	}
+	return "Scan"
+}
+
+// GoSnippet writes the request as aws-sdk-go-v2 code building the input and
+// sending it with a dynamodb.Client named client
+func (r ReadRequest) GoSnippet() string {
+	yes := func(b bool) string { return fmt.Sprintf("aws.Bool(%t)", b) }
+	str := func(s string) string { return "aws.String(" + strconv.Quote(s) + ")" }
+	names := func(m map[string]string) string {
+		var b strings.Builder
+		b.WriteString("map[string]string{\n")
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			fmt.Fprintf(&b, "%q: %q,\n", k, m[k])
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "input := &dynamodb.%sInput{\n", r.operation())
+	for _, f := range r.snippetFields(str, yes, names, goItem) {
+		fmt.Fprintf(&b, "%s: %s,\n", f.name, f.value)
+	}
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "output, err := client.%s(ctx, input)\n", r.operation())
+	b.WriteString("// Set input.ExclusiveStartKey to output.LastEvaluatedKey to read the next page\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String() // Unreachable: every value above is quoted
+	}
+	return string(src)
+}
+
+// goItem writes attribute values as a Go map literal
+func goItem(item map[string]types.AttributeValue) string {
+	var b strings.Builder
+	b.WriteString("map[string]types.AttributeValue{\n")
+	for _, k := range slices.Sorted(maps.Keys(item)) {
+		fmt.Fprintf(&b, "%q: %s,\n", k, goValue(item[k]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// goValue writes an attribute value as a Go literal of its member type
+func goValue(v types.AttributeValue) string {
+	quoteAll := func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, s := range values {
+			quoted[i] = strconv.Quote(s)
+		}
+		return strings.Join(quoted, ", ")
+	}
+	switch val := v.(type) {
+	case *types.AttributeValueMemberS:
+		return fmt.Sprintf("&types.AttributeValueMemberS{Value: %q}", val.Value)
+	case *types.AttributeValueMemberN:
+		return fmt.Sprintf("&types.AttributeValueMemberN{Value: %q}", val.Value)
+	case *types.AttributeValueMemberB:
+		return fmt.Sprintf("&types.AttributeValueMemberB{Value: []byte(%q)}", val.Value)
+	case *types.AttributeValueMemberBOOL:
+		return fmt.Sprintf("&types.AttributeValueMemberBOOL{Value: %t}", val.Value)
+	case *types.AttributeValueMemberNULL:
+		return fmt.Sprintf("&types.AttributeValueMemberNULL{Value: %t}", val.Value)
+	case *types.AttributeValueMemberSS:
+		return "&types.AttributeValueMemberSS{Value: []string{" + quoteAll(val.Value) + "}}"
+	case *types.AttributeValueMemberNS:
+		return "&types.AttributeValueMemberNS{Value: []string{" + quoteAll(val.Value) + "}}"
+	case *types.AttributeValueMemberBS:
+		items := make([]string, len(val.Value))
+		for i, bin := range val.Value {
+			items[i] = fmt.Sprintf("[]byte(%q)", bin)
+		}
+		return "&types.AttributeValueMemberBS{Value: [][]byte{" + strings.Join(items, ", ") + "}}"
+	case *types.AttributeValueMemberL:
+		items := make([]string, len(val.Value))
+		for i, av := range val.Value {
+			items[i] = goValue(av)
+		}
+		return "&types.AttributeValueMemberL{Value: []types.AttributeValue{" + strings.Join(items, ", ") + "}}"
+	case *types.AttributeValueMemberM:
+		return "&types.AttributeValueMemberM{Value: " + goItem(val.Value) + "}"
+	}
+	return "nil"
+}
+
+// PythonSnippet writes the request as a boto3 call on a DynamoDB client
+// named client
+func (r ReadRequest) PythonSnippet() string {
+	yes := func(b bool) string {
+		if b {
+			return "True"
+		}
+		return "False"
+	}
+	names := func(m map[string]string) string {
+		entries := make([]string, 0, len(m))
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			entries = append(entries, pythonString(k)+": "+pythonString(m[k]))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "response = client.%s(\n", strings.ToLower(r.operation()))
+	for _, f := range r.snippetFields(pythonString, yes, names, pythonItem) {
+		fmt.Fprintf(&b, "    %s=%s,\n", f.name, f.value)
+	}
+	b.WriteString(")\n")
+	b.WriteString("# Pass response[\"LastEvaluatedKey\"] as ExclusiveStartKey to read the next page\n")
+	return b.String()
+}
+
+// pythonString writes a string literal. JSON strings are valid Python.
+func pythonString(s string) string {
+	return cliJSON(s)
+}
+
+// pythonBytes writes a bytes literal, escaping all but printable ASCII
+func pythonBytes(data []byte) string {
+	var b strings.Builder
+	b.WriteString("b'")
+	for _, c := range data {
+		switch {
+		case c == '\\' || c == '\'':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= ' ' && c <= '~':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
+
+// pythonItem writes attribute values as a dict of typed values, the form the
+// low-level boto3 client takes
+func pythonItem(item map[string]types.AttributeValue) string {
+	entries := make([]string, 0, len(item))
+	for _, k := range slices.Sorted(maps.Keys(item)) {
+		entries = append(entries, pythonString(k)+": "+pythonValue(item[k]))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// pythonValue writes an attribute value as a typed dict
+func pythonValue(v types.AttributeValue) string {
+	list := func(values []string, literal func(string) string) string {
+		items := make([]string, len(values))
+		for i, s := range values {
+			items[i] = literal(s)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	switch val := v.(type) {
+	case *types.AttributeValueMemberS:
+		return `{"S": ` + pythonString(val.Value) + "}"
+	case *types.AttributeValueMemberN:
+		return `{"N": ` + pythonString(val.Value) + "}"
+	case *types.AttributeValueMemberB:
+		return `{"B": ` + pythonBytes(val.Value) + "}"
+	case *types.AttributeValueMemberBOOL:
+		if val.Value {
+			return `{"BOOL": True}`
+		}
+		return `{"BOOL": False}`
+	case *types.AttributeValueMemberNULL:
+		return `{"NULL": True}`
+	case *types.AttributeValueMemberSS:
+		return `{"SS": ` + list(val.Value, pythonString) + "}"
+	case *types.AttributeValueMemberNS:
+		return `{"NS": ` + list(val.Value, pythonString) + "}"
+	case *types.AttributeValueMemberBS:
+		items := make([]string, len(val.Value))
+		for i, bin := range val.Value {
+			items[i] = pythonBytes(bin)
+		}
+		return `{"BS": [` + strings.Join(items, ", ") + "]}"
+	case *types.AttributeValueMemberL:
+		items := make([]string, len(val.Value))
+		for i, av := range val.Value {
+			items[i] = pythonValue(av)
+		}
+		return `{"L": [` + strings.Join(items, ", ") + "]}"
+	case *types.AttributeValueMemberM:
+		return `{"M": ` + pythonItem(val.Value) + "}"
+	}
+	return "None"
+}
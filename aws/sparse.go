@@ -0,0 +1,69 @@
+package aws
+
+import "sort"
+
+// SparseIndexHint is an index that holds every item a scan filter can match.
+// An index only contains the items that have its key attributes, so when the
+// filter compares one of them, items missing from the index can't match and
+// reading the index instead of the table returns the same items.
+type SparseIndexHint struct {
+	Index IndexInfo
+	// Query is set when the filter is an equality on the index partition
+	// key, so a query reads just the matching items. Otherwise the index is
+	// scanned with the same filter.
+	Query bool
+}
+
+// QueryParams returns the query that replaces the scan for a Query hint
+func (h SparseIndexHint) QueryParams(scan ScanParams) QueryParams {
+	return QueryParams{
+		TableName:      scan.TableName,
+		IndexName:      h.Index.Name,
+		PartitionKey:   h.Index.PartitionKey,
+		PartitionValue: scan.FilterValue,
+	}
+}
+
+// ScanParams returns the index scan that replaces the table scan
+func (h SparseIndexHint) ScanParams(scan ScanParams) ScanParams {
+	scan.IndexName = h.Index.Name
+	return scan
+}
+
+// SparseIndexFor finds the best index to read instead of scanning the table
+// with the given filter. Indexes that can be queried come first, then the
+// smallest. ok is false when no index holds every matching item.
+func SparseIndexFor(table TableInfo, scan ScanParams) (hint SparseIndexHint, ok bool) {
+	// Every other condition is false for items without the attribute
+	if scan.IndexName != "" || scan.FilterAttribute == "" || scan.FilterCondition == "attribute_not_exists" || scan.FilterCondition == "not_contains" {
+		return SparseIndexHint{}, false
+	}
+
+	var hints []SparseIndexHint
+	for _, idx := range table.Indexes {
+		if idx.Status != "ACTIVE" {
+			continue
+		}
+		switch scan.FilterAttribute {
+		case idx.PartitionKey:
+			// Every item has the table's partition key, so an LSI isn't
+			// sparse on it
+			if idx.Local {
+				continue
+			}
+			hints = append(hints, SparseIndexHint{Index: idx, Query: scan.FilterCondition == "="})
+		case idx.SortKey:
+			hints = append(hints, SparseIndexHint{Index: idx})
+		}
+	}
+	if len(hints) == 0 {
+		return SparseIndexHint{}, false
+	}
+	sort.SliceStable(hints, func(i, j int) bool {
+		if hints[i].Query != hints[j].Query {
+			return hints[i].Query
+		}
+		return hints[i].Index.SizeBytes < hints[j].Index.SizeBytes
+	})
+	return hints[0], true
+}
@@ -0,0 +1,283 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go"
+)
+
+// Like CloudWatch, the DynamoDB Streams SDK module isn't a dependency: its
+// JSON API is called with signed requests using the DynamoDB client's
+// credentials, region and HTTP client.
+const (
+	streamsTargetPrefix = "DynamoDBStreams_20120810."
+	maxRecordsPerShard  = 1000 // GetRecords limit per request
+)
+
+// StreamRecord is a change to an item read from a table's stream
+type StreamRecord struct {
+	EventName      string    // INSERT, MODIFY or REMOVE
+	Time           time.Time // Approximate time of the change, to the second
+	SequenceNumber string
+	Shard          string
+	Keys           map[string]interface{}
+	OldImage       map[string]interface{} // Nil unless the stream carries old images
+	NewImage       map[string]interface{} // Nil unless the stream carries new images
+}
+
+// streamRecordJSON is a record of a GetRecords response
+type streamRecordJSON struct {
+	EventName string `json:"eventName"`
+	Dynamodb  struct {
+		ApproximateCreationDateTime float64                    `json:"ApproximateCreationDateTime"`
+		SequenceNumber              string                     `json:"SequenceNumber"`
+		Keys                        map[string]json.RawMessage `json:"Keys"`
+		OldImage                    map[string]json.RawMessage `json:"OldImage"`
+		NewImage                    map[string]json.RawMessage `json:"NewImage"`
+	} `json:"dynamodb"`
+}
+
+// streamShardJSON is a shard of a DescribeStream response
+type streamShardJSON struct {
+	ShardID             string `json:"ShardId"`
+	ParentShardID       string `json:"ParentShardId"`
+	SequenceNumberRange struct {
+		EndingSequenceNumber string `json:"EndingSequenceNumber"`
+	} `json:"SequenceNumberRange"`
+}
+
+// StreamTail follows a table's stream from the moment it was started,
+// reading every open shard and the shards that replace them
+type StreamTail struct {
+	c         *Client
+	table     string
+	streamARN string
+	iterators map[string]string // Shard iterator by shard ID, for shards being read
+	seen      map[string]bool   // Shards that were or are being read
+}
+
+// TailStream starts following the stream of a table at its latest records
+func (c *Client) TailStream(ctx context.Context, table TableInfo) (*StreamTail, error) {
+	if table.StreamARN == "" {
+		return nil, fmt.Errorf("%s has no stream enabled", table.Name)
+	}
+	t := &StreamTail{
+		c:         c,
+		table:     table.Name,
+		streamARN: table.StreamARN,
+		iterators: make(map[string]string),
+		seen:      make(map[string]bool),
+	}
+	shards, err := t.describeShards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shards {
+		t.seen[s.ShardID] = true
+		if s.SequenceNumberRange.EndingSequenceNumber != "" {
+			continue // Closed, holds only records from before the tail started
+		}
+		if err := t.startShard(ctx, s.ShardID, "LATEST"); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Shards returns the number of shards being read
+func (t *StreamTail) Shards() int {
+	return len(t.iterators)
+}
+
+// Poll returns the records written since the previous poll, oldest first.
+// When a shard closes, the shards that replace it are read from their start.
+func (t *StreamTail) Poll(ctx context.Context) ([]StreamRecord, error) {
+	var records []StreamRecord
+	closed := false
+	for shard, iterator := range t.iterators {
+		var resp struct {
+			Records           []streamRecordJSON `json:"Records"`
+			NextShardIterator *string            `json:"NextShardIterator"`
+		}
+		err := t.c.streamsCall(ctx, "GetRecords", map[string]interface{}{
+			"ShardIterator": iterator,
+			"Limit":         maxRecordsPerShard,
+		}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Records {
+			record, err := t.convertRecord(shard, r)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+		if resp.NextShardIterator == nil {
+			delete(t.iterators, shard)
+			closed = true
+		} else {
+			t.iterators[shard] = *resp.NextShardIterator
+		}
+	}
+
+	if closed || len(t.iterators) == 0 {
+		shards, err := t.describeShards(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range shards {
+			if t.seen[s.ShardID] {
+				continue
+			}
+			t.seen[s.ShardID] = true
+			if err := t.startShard(ctx, s.ShardID, "TRIM_HORIZON"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records, nil
+}
+
+// convertRecord turns a stream record into JSON-compatible images
+func (t *StreamTail) convertRecord(shard string, r streamRecordJSON) (StreamRecord, error) {
+	secs, frac := math.Modf(r.Dynamodb.ApproximateCreationDateTime)
+	record := StreamRecord{
+		EventName:      r.EventName,
+		Time:           time.Unix(int64(secs), int64(frac*1e9)),
+		SequenceNumber: r.Dynamodb.SequenceNumber,
+		Shard:          shard,
+	}
+	images := []struct {
+		typed map[string]json.RawMessage
+		dst   *map[string]interface{}
+	}{
+		{r.Dynamodb.Keys, &record.Keys},
+		{r.Dynamodb.OldImage, &record.OldImage},
+		{r.Dynamodb.NewImage, &record.NewImage},
+	}
+	for _, img := range images {
+		if img.typed == nil {
+			continue
+		}
+		item, err := fromTypedItemJSON(img.typed)
+		if err != nil {
+			return StreamRecord{}, fmt.Errorf("invalid stream record %s: %w", r.Dynamodb.SequenceNumber, err)
+		}
+		_, *img.dst = t.c.convertItem(t.table, item)
+	}
+	return record, nil
+}
+
+// describeShards lists every shard of the stream, following pagination
+func (t *StreamTail) describeShards(ctx context.Context) ([]streamShardJSON, error) {
+	var shards []streamShardJSON
+	input := map[string]interface{}{"StreamArn": t.streamARN}
+	for {
+		if err := t.c.control.wait(ctx); err != nil {
+			return nil, err
+		}
+		var resp struct {
+			StreamDescription struct {
+				Shards               []streamShardJSON `json:"Shards"`
+				LastEvaluatedShardID string            `json:"LastEvaluatedShardId"`
+			} `json:"StreamDescription"`
+		}
+		if err := t.c.streamsCall(ctx, "DescribeStream", input, &resp); err != nil {
+			return nil, err
+		}
+		shards = append(shards, resp.StreamDescription.Shards...)
+		if resp.StreamDescription.LastEvaluatedShardID == "" {
+			return shards, nil
+		}
+		input["ExclusiveStartShardId"] = resp.StreamDescription.LastEvaluatedShardID
+	}
+}
+
+// startShard gets an iterator for a shard at the given position
+func (t *StreamTail) startShard(ctx context.Context, shard, iteratorType string) error {
+	var resp struct {
+		ShardIterator string `json:"ShardIterator"`
+	}
+	err := t.c.streamsCall(ctx, "GetShardIterator", map[string]interface{}{
+		"StreamArn":         t.streamARN,
+		"ShardId":           shard,
+		"ShardIteratorType": iteratorType,
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	t.iterators[shard] = resp.ShardIterator
+	return nil
+}
+
+// streamsCall signs and sends a DynamoDB Streams API request and decodes
+// the JSON response into out
+func (c *Client) streamsCall(ctx context.Context, operation string, input, out interface{}) error {
+	opts := c.api().Options()
+	endpoint := fmt.Sprintf("https://streams.dynamodb.%s.amazonaws.com/", opts.Region)
+	if opts.BaseEndpoint != nil {
+		endpoint = *opts.BaseEndpoint // DynamoDB Local serves streams on its own endpoint
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", streamsTargetPrefix+operation)
+
+	creds, err := opts.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "dynamodb", opts.Region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign %s request: %w", operation, err)
+	}
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Type != "" {
+			// The type is namespaced, e.g. com.amazonaws.dynamodb.v20120810#ExpiredIteratorException
+			code := apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+			// Returned as a smithy API error so expired credentials are recognised
+			return &smithy.GenericAPIError{Code: code, Message: apiErr.Message}
+		}
+		return fmt.Errorf("%s request failed: %s", operation, resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// Retry policy for throttled requests. Throttling on a busy or
+// under-provisioned table can last a while, so retry longer than the SDK
+// default of 3 attempts and don't give up early because of the client side
+// retry quota.
+const (
+	maxRetryAttempts = 10
+	maxRetryBackoff  = 20 * time.Second
+)
+
+// ThrottleEvent describes a throttled request that is about to be retried
+type ThrottleEvent struct {
+	Attempt int           // Attempt that was throttled, starting at 1
+	Delay   time.Duration // Backoff before the next attempt
+	Err     error         // Throttling error returned by DynamoDB
+}
+
+// WithThrottleHandler registers a function that is called from the request's
+// goroutine each time a throttled request is retried
+func WithThrottleHandler(handler func(ThrottleEvent)) Option {
+	return func(o *clientOptions) {
+		o.throttleHandler = handler
+	}
+}
+
+// throttleRetryer wraps the standard retryer to report throttling retries
+type throttleRetryer struct {
+	aws.RetryerV2
+	notify func(ThrottleEvent)
+}
+
+// newRetryer builds the retryer used by the DynamoDB client
+func newRetryer(notify func(ThrottleEvent)) aws.Retryer {
+	standard := retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = maxRetryAttempts
+		o.MaxBackoff = maxRetryBackoff
+		o.RateLimiter = ratelimit.None
+	})
+	return throttleRetryer{RetryerV2: standard, notify: notify}
+}
+
+// RetryDelay implements aws.Retryer, reporting the delay of throttled attempts
+func (r throttleRetryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	delay, delayErr := r.RetryerV2.RetryDelay(attempt, err)
+	if delayErr == nil && r.notify != nil && IsThrottlingError(err) {
+		r.notify(ThrottleEvent{Attempt: attempt, Delay: delay, Err: err})
+	}
+	return delay, delayErr
+}
+
+// IsThrottlingError reports whether err is a throttling error such as
+// ProvisionedThroughputExceededException
+func IsThrottlingError(err error) bool {
+	return retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// UnionQuery runs several queries concurrently and merges their items into a
+// single result, de-duplicated by the attributes in primaryKey. Each query
+// follows pagination until maxItems items were read. Sources holds, for every
+// merged item, the comma-separated labels of the queries that returned it.
+func (c *Client) UnionQuery(ctx context.Context, queries []QueryParams, primaryKey []string, maxItems int) (QueryResult, error) {
+	results, err := c.queryAll(ctx, queries, maxItems, func(i int) string { return queryLabel(queries[i]) })
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	var merged QueryResult
+	positions := make(map[string]int)
+	for i, result := range results {
+		label := queryLabel(queries[i])
+		merged.ConsumedCapacity += result.ConsumedCapacity
+		merged.Duplicates += result.Duplicates
+		merged.Count += result.Count
+		merged.ScannedCount += result.ScannedCount
+		merged.Requests = append(merged.Requests, result.Requests...)
+
+		for j, item := range result.Items {
+			parts := make([]string, len(primaryKey))
+			for k, attr := range primaryKey {
+				parts[k] = fmt.Sprintf("%v", item[attr])
+			}
+			sig := strings.Join(parts, "|")
+
+			if pos, ok := positions[sig]; ok {
+				merged.Sources[pos] += ", " + label
+				continue
+			}
+			positions[sig] = len(merged.Items)
+			merged.Items = append(merged.Items, item)
+			merged.RawItems = append(merged.RawItems, result.RawItems[j])
+			merged.Sources = append(merged.Sources, label)
+		}
+	}
+	return merged, nil
+}
+
+// FanOutQuery runs the same kind of query against several tables or
+// partition keys, such as the date-sharded tables of a family, and
+// concatenates their items in the order of queries. Each query reads up to
+// maxItems items and Sources holds the label of the query every item came
+// from, labels[i] naming queries[i].
+func (c *Client) FanOutQuery(ctx context.Context, queries []QueryParams, labels []string, maxItems int) (QueryResult, error) {
+	results, err := c.queryAll(ctx, queries, maxItems, func(i int) string { return labels[i] })
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	var merged QueryResult
+	for i, result := range results {
+		merged.Items = append(merged.Items, result.Items...)
+		merged.RawItems = append(merged.RawItems, result.RawItems...)
+		merged.ConsumedCapacity += result.ConsumedCapacity
+		merged.Duplicates += result.Duplicates
+		merged.Count += result.Count
+		merged.ScannedCount += result.ScannedCount
+		merged.Requests = append(merged.Requests, result.Requests...)
+		for range result.Items {
+			merged.Sources = append(merged.Sources, labels[i])
+		}
+	}
+	return merged, nil
+}
+
+// maxConcurrentQueries bounds how many queries of a union or fan-out run at once
+const maxConcurrentQueries = 10
+
+// RecoverPanic is deferred at the top of the goroutines the package starts.
+// It does nothing by default, so panics crash the program as usual; the
+// explorer sets it to restore the terminal first.
+var RecoverPanic = func() {}
+
+// queryAll runs queries concurrently, each reading up to maxItems items. The
+// first error is returned prefixed with label(i) of its query.
+func (c *Client) queryAll(ctx context.Context, queries []QueryParams, maxItems int, label func(i int) string) ([]QueryResult, error) {
+	results := make([]QueryResult, len(queries))
+	errs := make([]error, len(queries))
+
+	slots := make(chan struct{}, maxConcurrentQueries)
+	var wg sync.WaitGroup
+	for i, params := range queries {
+		wg.Add(1)
+		go func(i int, params QueryParams) {
+			defer RecoverPanic()
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i], errs[i] = c.QueryUpTo(ctx, params, maxItems)
+		}(i, params)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", label(i), err)
+		}
+	}
+	return results, nil
+}
+
+// queryLabel names the table or index a query targets
+func queryLabel(params QueryParams) string {
+	if params.IndexName != "" {
+		return params.IndexName
+	}
+	return "table"
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// binaryViewLimit is how many bytes of a value the binary viewer lays out;
+// copying and saving always take the whole value
+const binaryViewLimit = 16 * 1024
+
+// binaryBlobs is the bytes of a binary or binary set value
+func binaryBlobs(v interface{}) ([][]byte, bool) {
+	switch b := v.(type) {
+	case []byte:
+		return [][]byte{b}, true
+	case aws.BinarySet:
+		return b, true
+	}
+	return nil, false
+}
+
+// binaryText lays out the values of a binary attribute as base64 and as a
+// hex dump with offsets, each element of a set in turn
+func binaryText(blobs [][]byte) string {
+	var b strings.Builder
+	for i, blob := range blobs {
+		if len(blobs) > 1 {
+			fmt.Fprintf(&b, "[#ff9500]Element %d of %d[white]\n\n", i+1, len(blobs))
+		}
+		shown := blob[:min(len(blob), binaryViewLimit)]
+		fmt.Fprintf(&b, "[#ff9500]Base64[white] (%s bytes)\n", formatNumber(int64(len(blob))))
+		b.WriteString(base64.StdEncoding.EncodeToString(shown))
+		b.WriteString("\n\n[#ff9500]Hex[white]\n")
+		b.WriteString(tview.Escape(hex.Dump(shown)))
+		if len(shown) < len(blob) {
+			fmt.Fprintf(&b, "[#b8b8b8]... %s more bytes, save the value to see them[white]\n", formatNumber(int64(len(blob)-len(shown))))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// binaryFileName names the file a binary attribute of an item is saved to
+// after the item's key and the attribute, as the item view names items
+func binaryFileName(tableInfo aws.TableInfo, rawItem map[string]interface{}, attr string) string {
+	name := fmt.Sprintf("%v", rawItem[tableInfo.PartitionKey])
+	if tableInfo.SortKey != "" {
+		name += fmt.Sprintf("_%v", rawItem[tableInfo.SortKey])
+	}
+	return name + "_" + attr
+}
+
+// saveBinary writes the bytes of a binary attribute to name.bin in the
+// working directory, or each element of a set to name_1.bin, name_2.bin...
+func saveBinary(pages *tview.Pages, name string, blobs [][]byte) {
+	name = strings.NewReplacer("/", "_", " ", "_", ":", "_").Replace(name)
+	var files []string
+	for i, blob := range blobs {
+		filename := name + ".bin"
+		if len(blobs) > 1 {
+			filename = fmt.Sprintf("%s_%d.bin", name, i+1)
+		}
+		if err := os.WriteFile(filename, blob, 0644); err != nil {
+			showMessageModal(pages, "saveerror", fmt.Sprintf("Error writing file: %v", err))
+			return
+		}
+		files = append(files, filename)
+	}
+	status.notify(fmt.Sprintf("Saved to %s", tview.Escape(strings.Join(files, ", "))), noticeDuration)
+}
+
+// showBinaryView shows the bytes of a binary attribute, or of each element
+// of a binary set. name is what a saved value's file is named after.
+func showBinaryView(app *tview.Application, pages *tview.Pages, title, name string, blobs [][]byte) {
+	size := 0
+	for _, blob := range blobs {
+		size += len(blob)
+	}
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("%s: %s bytes (↑/↓: scroll | %s: copy base64 | %s: save | ESC: close)",
+			tview.Escape(title), formatNumber(int64(size)), keyLabel("binary.copy"), keyLabel("binary.save")))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(binaryText(blobs))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("binaryview")
+			return nil
+		}
+		if keyPressed(event, "binary.copy") {
+			encoded := make([]string, len(blobs))
+			for i, blob := range blobs {
+				encoded[i] = base64.StdEncoding.EncodeToString(blob)
+			}
+			copyReadText(pages, strings.Join(encoded, "\n"), "the value as base64")
+			return nil
+		}
+		if keyPressed(event, "binary.save") {
+			saveBinary(pages, name, blobs)
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("binaryview", flex, true, true)
+	app.SetFocus(text)
+}
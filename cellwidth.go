@@ -0,0 +1,71 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// cellWidthStep is how much + and - widen or narrow a column
+const cellWidthStep = 10
+
+// cellWidth is how many characters of a value a results column of the
+// table shows before cutting it short. A width set with +/- comes first,
+// then columnWidths of config.yaml, then its cellWidth, then
+// ui.MaxCellLength.
+func cellWidth(table, attr string) int {
+	if width, ok := userSettings.ColumnWidths[table][attr]; ok {
+		return width
+	}
+	if width, ok := configDefaults.ColumnWidths[table][attr]; ok {
+		return width
+	}
+	if configDefaults.CellWidth > 0 {
+		return configDefaults.CellWidth
+	}
+	return ui.MaxCellLength
+}
+
+// setCellWidth keeps the width of a column of the table and writes the
+// config file
+func setCellWidth(table, attr string, width int) error {
+	if userSettings.ColumnWidths == nil {
+		userSettings.ColumnWidths = make(map[string]map[string]int)
+	}
+	if userSettings.ColumnWidths[table] == nil {
+		userSettings.ColumnWidths[table] = make(map[string]int)
+	}
+	userSettings.ColumnWidths[table][attr] = width
+	return saveSettings(userSettings)
+}
+
+// showCellValue shows the full value of a results cell, wrapped
+func showCellValue(app *tview.Application, pages *tview.Pages, title, value string) {
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("%s (↑/↓: scroll | %s: copy | ESC: close)", tview.Escape(title), keyLabel("cell.copy")))
+	text := tview.NewTextView().
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(value)
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("cellvalue")
+			return nil
+		}
+		if keyPressed(event, "cell.copy") {
+			copyReadText(pages, value, "the value")
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("cellvalue", flex, true, true)
+	app.SetFocus(text)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// appScreen is the screen the application draws on, captured on every draw
+// since tview doesn't expose it
+var appScreen tcell.Screen
+
+// clipboardTimeout bounds a clipboard tool that doesn't return, e.g. xclip
+// without a running X server
+const clipboardTimeout = 2 * time.Second
+
+// copyToClipboard puts text on the system clipboard, both through the
+// terminal (OSC 52), which reaches the local clipboard over SSH, and through
+// the clipboard tool of the OS when there is one. It reports false when
+// neither was possible; terminals without OSC 52 support ignore the request
+// silently.
+func copyToClipboard(text string) bool {
+	copied := nativeCopy(text)
+	if appScreen != nil {
+		appScreen.SetClipboard([]byte(text))
+		copied = true
+	}
+	return copied
+}
+
+// nativeCopy pipes text into the first clipboard tool found on the PATH
+func nativeCopy(text string) bool {
+	var tools [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		tools = [][]string{{"pbcopy"}}
+	case "windows":
+		tools = [][]string{{"clip.exe"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			tools = append(tools, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			tools = append(tools, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+		}
+	}
+	for _, tool := range tools {
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+		cmd := exec.CommandContext(ctx, path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		err = cmd.Run()
+		cancel()
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
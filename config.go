@@ -0,0 +1,111 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// settings is the user state kept in the config file between runs
+type settings struct {
+	// Columns holds the result columns chosen with Ctrl+C, by table name.
+	// They come before the columns preset in config.yaml.
+	Columns map[string][]string `json:"columns,omitempty"`
+
+	// ColumnNames holds display names of attributes, by table name and
+	// attribute; they only change how results are shown
+	ColumnNames map[string]map[string]string `json:"columnNames,omitempty"`
+
+	// Tiers maps AWS profile names and account IDs to dev, staging or
+	// prod, which picks the accent color of the UI
+	Tiers map[string]string `json:"tiers,omitempty"`
+
+	// LegacyTiers is Tiers under its old name. It is read into Tiers and
+	// not written again, leaving environments to the named environments
+	// of config.yaml.
+	LegacyTiers map[string]string `json:"environments,omitempty"`
+
+	// SavedQueries holds the queries saved from the Query tab, by table name
+	SavedQueries map[string][]savedQuery `json:"savedQueries,omitempty"`
+
+	// Cursors holds the pagination cursors saved from results pages, by
+	// table name
+	Cursors map[string][]savedCursor `json:"cursors,omitempty"`
+
+	// Sessions holds where the last session with each profile left off, by
+	// profile name
+	Sessions map[string]sessionState `json:"sessions,omitempty"`
+
+	// ColumnWidths holds the widths of result columns set with +/-, by
+	// table name and attribute. They come before the widths of config.yaml.
+	ColumnWidths map[string]map[string]int `json:"columnWidths,omitempty"`
+
+	// Settings holds the preferences of the results and item views
+	ui.Settings
+}
+
+// userSettings is loaded at startup and saved whenever it changes
+var userSettings settings
+
+// configPath returns the location of the config file, e.g.
+// ~/.config/ddb-explorer/config.json on Linux
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ddb-explorer", "config.json"), nil
+}
+
+// loadSettings reads the config file. A missing file yields empty settings.
+func loadSettings() (settings, error) {
+	var s settings
+	path, err := configPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if s.Tiers == nil {
+		s.Tiers = s.LegacyTiers
+	}
+	s.LegacyTiers = nil
+	if s.ResultsEnter != "" && !slices.Contains(ui.EnterActions, s.ResultsEnter) {
+		return s, fmt.Errorf("invalid resultsEnter %q in %s: use %s", s.ResultsEnter, path, strings.Join(ui.EnterActions, ", "))
+	}
+	return s, nil
+}
+
+// saveSettings writes the config file, replacing it in one step so a crash
+// can't leave it half written
+func saveSettings(s settings) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxConcurrentConnectionTests bounds how many profiles are tested at once
+const maxConcurrentConnectionTests = 4
+
+// connectionTest is the outcome of testing a profile
+type connectionTest struct {
+	running bool
+	client  *aws.Client
+	tables  int
+	err     error
+}
+
+// chooseConnection shows the connection screen after the configured profile
+// failed to connect. It lists every profile of the shared config files with
+// the result of connecting to the chosen region and endpoint, and returns
+// the client of the combination the user picks, or nil when they quit.
+func chooseConnection(profile, region, endpoint string, clientOpts []aws.Option, cause error) *aws.Client {
+	app := tview.NewApplication()
+	pages := tview.NewPages()
+
+	// Role MFA codes are asked for on this screen while it runs
+	previousPrompt := mfaPrompt
+	mfaPrompt = func() (string, error) {
+		return promptMFATokenModal(app, pages)
+	}
+	defer func() { mfaPrompt = previousPrompt }()
+
+	profiles := aws.Profiles()
+	if !slices.Contains(profiles, profile) {
+		profiles = append([]string{profile}, profiles...)
+	}
+	regions := aws.Regions
+	if region == "" {
+		region = regions[0]
+	}
+	if !slices.Contains(regions, region) {
+		regions = append([]string{region}, regions...)
+	}
+
+	var chosen *aws.Client
+	results := make(map[string]*connectionTest)
+	generation := 0 // Bumped when the region or endpoint changes, to drop stale results
+
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetText(fmt.Sprintf("[#ff453a]Could not connect with profile %s: %s[white]\n"+
+			"Pick a profile that works (Enter: connect | %s: test all | Tab: region and endpoint, ESC back | %s/ESC: quit)",
+			tview.Escape(profile), tview.Escape(cause.Error()), keyLabel("connection.testAll"), keyLabel("connection.quit")))
+
+	list := newDataTable().
+		SetFixed(1, 0)
+	render := func() {
+		selected, _ := list.GetSelection()
+		list.Clear()
+		for col, name := range []string{"Profile", "Connection"} {
+			list.SetCell(0, col, tview.NewTableCell(name).
+				SetTextColor(tview.Styles.SecondaryTextColor).
+				SetSelectable(false).
+				SetAlign(tview.AlignCenter))
+		}
+		for i, p := range profiles {
+			row := i + 1
+			text, color := "not tested", textSecondary
+			if r := results[p]; r != nil {
+				switch {
+				case r.running:
+					text, color = "testing...", accentYellow
+				case r.err != nil:
+					text, color = tview.Escape(r.err.Error()), accentRed
+				default:
+					text, color = fmt.Sprintf("connected, %s tables", formatNumber(int64(r.tables))), accentGreen
+					if r.tables == 100 {
+						text = "connected, 100+ tables"
+					}
+				}
+			}
+			list.SetCell(row, 0, tview.NewTableCell(rowPrefix("Profile", row, len(profiles))+tview.Escape(p)))
+			list.SetCell(row, 1, tview.NewTableCell(labeled("connection", text)).SetTextColor(color))
+		}
+		list.Select(max(selected, 1), 0)
+	}
+
+	form := tview.NewForm().
+		SetHorizontal(true)
+	form.SetLabelColor(textSecondary).
+		SetFieldBackgroundColor(accentOrange).
+		SetFieldTextColor(tcell.NewHexColor(0x121212))
+
+	settings := func() []aws.Option {
+		_, r := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		opts := append(slices.Clone(clientOpts), aws.WithRegion(r))
+		if e := form.GetFormItem(1).(*tview.InputField).GetText(); e != "" {
+			opts = append(opts, aws.WithEndpoint(e))
+		}
+		return opts
+	}
+
+	// Tests still running when the screen closes are cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, maxConcurrentConnectionTests)
+
+	// test connects with a profile in the background. With connect set, the
+	// screen closes with that client as soon as the test succeeds.
+	test := func(p string, connect bool) {
+		r := &connectionTest{running: true}
+		results[p] = r
+		gen := generation
+		opts := settings()
+		go func() {
+			defer recoverPanic()
+			sem <- struct{}{}
+			client, tables, err := aws.CheckConnection(ctx, p, opts...)
+			<-sem
+			app.QueueUpdateDraw(func() {
+				if gen != generation {
+					return
+				}
+				r.running, r.client, r.tables, r.err = false, client, tables, err
+				render()
+				if connect && err == nil {
+					chosen = client
+					app.Stop()
+				}
+			})
+		}()
+		render()
+	}
+	testAll := func() {
+		for _, p := range profiles {
+			test(p, false)
+		}
+	}
+	reset := func() {
+		generation++
+		clear(results)
+		render()
+	}
+
+	form.AddDropDown("Region", regions, slices.Index(regions, region), func(string, int) {
+		reset()
+	})
+	form.AddInputField("Endpoint", endpoint, 40, nil, func(string) {
+		reset()
+	})
+	form.GetFormItem(1).(*tview.InputField).
+		SetPlaceholder("regional endpoint, or e.g. http://localhost:8000").
+		SetPlaceholderTextColor(tcell.NewHexColor(0x404040))
+	form.SetCancelFunc(func() {
+		app.SetFocus(list)
+	})
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyESC || keyPressed(event, "connection.quit"):
+			app.Stop()
+			return nil
+		case event.Key() == tcell.KeyTab:
+			app.SetFocus(form)
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			row, _ := list.GetSelection()
+			if row < 1 || row > len(profiles) {
+				return nil
+			}
+			p := profiles[row-1]
+			if r := results[p]; r != nil && !r.running && r.err == nil {
+				chosen = r.client
+				app.Stop()
+				return nil
+			}
+			test(p, true)
+			return nil
+		case keyPressed(event, "connection.testAll"):
+			reset()
+			testAll()
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 3, 0, false).
+		AddItem(form, 3, 0, false).
+		AddItem(list, 0, 1, true)
+	flex.SetBorder(true).
+		SetTitle(" Connect to DynamoDB ")
+	pages.AddPage("connection", flex, true, true)
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return handleKeyHelp(app, pages, event)
+	})
+	enableMouse(app, pages)
+
+	render()
+	// Testing every profile at once would ask for an MFA code per profile
+	if *mfaSerial == "" {
+		testAll()
+	}
+	if err := runApp(app.SetRoot(pages, true).SetFocus(list)); err != nil {
+		fmt.Printf("Error running connection screen: %v\n", err)
+		return nil
+	}
+	return chosen
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// defaultCopyWriteUnits is the write capacity a copy uses per second unless
+// told otherwise, low enough not to starve a small provisioned table
+const defaultCopyWriteUnits = 100
+
+// copyTarget is where a table copy writes to
+type copyTarget struct {
+	profile    string
+	region     string
+	table      string
+	writeUnits float64 // Per second, 0 for no limit
+}
+
+// newCopyTargetClient connects to the target of a copy. The session's role
+// isn't assumed for the target, but a custom endpoint is kept so copies
+// between DynamoDB Local tables work.
+func newCopyTargetClient(source *aws.Client, target copyTarget) (*aws.Client, error) {
+	opts := []aws.Option{
+		aws.WithRegion(target.region),
+		aws.WithThrottleHandler(func(e aws.ThrottleEvent) {
+			throttleHandler(e)
+		}),
+	}
+	if source.Endpoint() != "" {
+		opts = append(opts, aws.WithEndpoint(source.Endpoint()))
+	}
+	return aws.NewClient(target.profile, opts...)
+}
+
+// showCopyTableForm starts the copy workflow for a table: where to copy it
+// to, with a dry run that counts its items first
+func showCopyTableForm(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string) {
+	profiles := aws.Profiles()
+	if !slices.Contains(profiles, client.Profile()) {
+		profiles = append([]string{client.Profile()}, profiles...)
+	}
+
+	form := tview.NewForm()
+	form.AddDropDown("Target profile", profiles, slices.Index(profiles, client.Profile()), nil)
+	form.AddInputField("Target region", client.Region(), 20, nil, nil)
+	form.AddInputField("Target table", tableName, 36, nil, nil)
+	form.AddInputField("Max WCU/s", strconv.Itoa(defaultCopyWriteUnits), 10, tview.InputFieldInteger, nil)
+
+	target := func() (copyTarget, error) {
+		_, profile := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		t := copyTarget{
+			profile: profile,
+			region:  strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText()),
+			table:   strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText()),
+		}
+		if t.region == "" || t.table == "" {
+			return t, fmt.Errorf("target region and table are required")
+		}
+		if t.profile == client.Profile() && t.region == client.Region() && t.table == tableName {
+			return t, fmt.Errorf("the target is the source table itself")
+		}
+		if units := form.GetFormItem(3).(*tview.InputField).GetText(); units != "" {
+			n, err := strconv.Atoi(units)
+			if err != nil || n < 0 {
+				return t, fmt.Errorf("invalid Max WCU/s %q", units)
+			}
+			t.writeUnits = float64(n)
+		}
+		return t, nil
+	}
+
+	form.AddButton("Count Items", func() {
+		t, _ := target()
+		countCopyItems(app, pages, client, tableName, t.writeUnits)
+	})
+	form.AddButton("Copy", func() {
+		t, err := target()
+		if err != nil {
+			showMessageModal(pages, "copyerror", err.Error())
+			return
+		}
+		pages.RemovePage("copyform")
+		checkCopyTarget(app, pages, client, tableName, t)
+	})
+	showFormPrompt(app, pages, "copyform", "Copy "+tableName, form)
+}
+
+// countCopyItems is the dry run of a copy: it counts the items of the
+// source table and estimates how long writing them takes
+func countCopyItems(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, writeUnits float64) {
+	ctx := showLoadingModal(pages, "copycounting", fmt.Sprintf("Counting the items of %s...", tableName))
+
+	var count int64
+	var consumed float64
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		count, consumed, err = client.CountItems(ctx, tableName, nil)
+		return err
+	}, func(err error) {
+		pages.RemovePage("copycounting")
+		if err != nil {
+			showMessageModal(pages, "copyerror", fmt.Sprintf("Count error: %v", err))
+			return
+		}
+		text := fmt.Sprintf("%s holds %s items (counted with a full scan using %s RCU).",
+			tableName, formatNumber(count), formatCapacity(consumed))
+		if writeUnits > 0 && count > 0 {
+			// Items of up to 1 KB take one write unit each
+			at := time.Duration(float64(count) / writeUnits * float64(time.Second))
+			text += fmt.Sprintf(" At %s WCU per second, copying takes at least %s.", formatNumber(int64(writeUnits)), roughDuration(at))
+		}
+		showMessageModal(pages, "copycount", text)
+	})
+}
+
+// checkCopyTarget connects to the target, checks its key schema matches and
+// asks for confirmation before copying, showing the requests the copy
+// starts with
+func checkCopyTarget(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, t copyTarget) {
+	ctx := showLoadingModal(pages, "copychecking", fmt.Sprintf("Checking %s with profile %s...", t.table, t.profile))
+
+	var targetClient *aws.Client
+	var requests []aws.SerializedRequest
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		if targetClient, err = newCopyTargetClient(client, t); err != nil {
+			return err
+		}
+		if err := client.CheckCopyTarget(ctx, tableName, targetClient, t.table); err != nil {
+			return err
+		}
+		requests, err = client.CopyRequests(ctx, tableName, t.table)
+		return err
+	}, func(err error) {
+		pages.RemovePage("copychecking")
+		if err != nil {
+			showMessageModal(pages, "copyerror", fmt.Sprintf("Copy target error: %v", err))
+			return
+		}
+
+		text := fmt.Sprintf("Copy every item of %s (profile %s, %s) to %s (profile %s, %s)? Items with the same key are overwritten.",
+			tableName, client.Profile(), client.Region(), t.table, t.profile, t.region)
+		if env, _ := resolveEnvironment(userSettings.Tiers, t.profile, ""); env == "prod" {
+			text = "[#ff453a]The target is a PROD table.[white] " + tview.Escape(text)
+		} else {
+			text = tview.Escape(text)
+		}
+		limit := "with no throughput limit"
+		if t.writeUnits > 0 {
+			limit = fmt.Sprintf("at up to %s WCU/s", formatNumber(int64(t.writeUnits)))
+		}
+		lines := []string{text, "", fmt.Sprintf("[#b8b8b8]The scan continues from each page's LastEvaluatedKey, and every further batch of up to 25 items is written with the same BatchWriteItem call, %s.[white]", limit)}
+		showRequestReview(app, pages, "copyconfirm", "Copy "+tableName+" to "+t.table, lines, requests, "Copy", func() {
+			pages.RemovePage("copyconfirm")
+			createCopyPage(app, pages, client, tableName, targetClient, t)
+		})
+	})
+}
+
+// createCopyPage runs a copy and shows its progress. Closing the page stops
+// the copy after the batch being written.
+func createCopyPage(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, targetClient *aws.Client, t copyTarget) {
+	text := tview.NewTextView().
+		SetDynamicColors(true)
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+
+	started := time.Now()
+	var progress aws.CopyProgress
+	state := "[#ffd60a]copying[white]"
+	limit := "no limit"
+	if t.writeUnits > 0 {
+		limit = fmt.Sprintf("up to %s WCU/s", formatNumber(int64(t.writeUnits)))
+	}
+	render := func() {
+		header.SetText(fmt.Sprintf("Copy %s to %s: %s (ESC: close, stops the copy)", tview.Escape(tableName), tview.Escape(t.table), state))
+		elapsed := time.Since(started)
+		rate := 0.0
+		if secs := elapsed.Seconds(); secs > 0 {
+			rate = float64(progress.Written) / secs
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "\n  %-18s %s (profile %s, %s)\n", "From", tview.Escape(tableName), tview.Escape(client.Profile()), client.Region())
+		fmt.Fprintf(&b, "  %-18s %s (profile %s, %s)\n", "To", tview.Escape(t.table), tview.Escape(t.profile), tview.Escape(t.region))
+		fmt.Fprintf(&b, "  %-18s %s\n\n", "Throughput", limit)
+		fmt.Fprintf(&b, "  %-18s %s\n", "Items read", formatNumber(progress.Scanned))
+		fmt.Fprintf(&b, "  %-18s %s (%.0f items/s)\n", "Items written", formatNumber(progress.Written), rate)
+		fmt.Fprintf(&b, "  %-18s %s RCU\n", "Read capacity", formatCapacity(progress.ReadUnits))
+		fmt.Fprintf(&b, "  %-18s %s WCU\n", "Write capacity", formatCapacity(progress.WriteUnits))
+		if progress.UnprocessedRetries > 0 {
+			fmt.Fprintf(&b, "  %-18s %s batches retried after throttling\n", "Throttled", formatNumber(int64(progress.UnprocessedRetries)))
+		}
+		fmt.Fprintf(&b, "  %-18s %s\n", "Elapsed", elapsed.Round(time.Second))
+		text.SetText(b.String())
+	}
+	render()
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("tablecopy")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("tablecopy")
+	pages.AddPage("tablecopy", flex, true, true)
+	app.SetFocus(text)
+
+	group := tasks.group("tablecopy")
+	tasks.Go(group, func(ctx context.Context) func() {
+		final, err := client.CopyTable(ctx, tableName, targetClient, t.table, t.writeUnits, func(p aws.CopyProgress) {
+			app.QueueUpdateDraw(func() {
+				if ctx.Err() == nil {
+					progress = p
+					render()
+				}
+			})
+		})
+		return func() {
+			progress = final
+			if err != nil {
+				state = fmt.Sprintf("[#ff453a]failed: %s[white]", tview.Escape(err.Error()))
+			} else {
+				state = "[#30d158]done[white]"
+			}
+			render()
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxSavedCursors is how many cursors are kept per table; saving another
+// drops the oldest
+const maxSavedCursors = 20
+
+// savedCursor is a pagination cursor saved under a name from a results page,
+// for resuming a long pagination session later
+type savedCursor struct {
+	Name   string `json:"name"`
+	Cursor string `json:"cursor"` // ExclusiveStartKey as typed JSON
+}
+
+// saveCursor stores c with the table's saved cursors, replacing the one with
+// the same name, and writes the config file
+func saveCursor(table string, c savedCursor) error {
+	if userSettings.Cursors == nil {
+		userSettings.Cursors = make(map[string][]savedCursor)
+	}
+	cursors := slices.DeleteFunc(userSettings.Cursors[table], func(s savedCursor) bool {
+		return s.Name == c.Name
+	})
+	cursors = append(cursors, c)
+	if len(cursors) > maxSavedCursors {
+		cursors = cursors[len(cursors)-maxSavedCursors:]
+	}
+	userSettings.Cursors[table] = cursors
+	return saveSettings(userSettings)
+}
+
+// showCursorPrompt asks for the cursor to read a page from, filled with the
+// one the page in view started from. Cursors saved for the table can be
+// picked, and the one entered copied or saved. jump receives the cursor;
+// nil reads the first page.
+func showCursorPrompt(app *tview.Application, pages *tview.Pages, tableName, pageLabel string, current *aws.PageToken, jump func(*aws.PageToken)) {
+	form := tview.NewForm()
+	saved := userSettings.Cursors[tableName]
+	if len(saved) > 0 {
+		names := []string{"(none)"}
+		for i := len(saved) - 1; i >= 0; i-- { // Newest first
+			names = append(names, saved[i].Name)
+		}
+		form.AddDropDown("Saved", names, 0, nil)
+	}
+	form.AddInputField("Start after", current.JSON(), 0, nil, nil)
+	field := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+	field.SetPlaceholder("first page")
+	field.SetPlaceholderTextColor(textSecondary)
+	if len(saved) > 0 {
+		form.GetFormItem(0).(*tview.DropDown).SetSelectedFunc(func(option string, optionIndex int) {
+			if optionIndex > 0 {
+				field.SetText(saved[len(saved)-optionIndex].Cursor)
+			}
+		})
+	}
+
+	// parse reads the cursor entered, showing why when it can't
+	parse := func() (*aws.PageToken, bool) {
+		token, err := aws.ParseCursor(field.GetText())
+		if err != nil {
+			showMessageModal(pages, "cursorerror", fmt.Sprintf("Invalid cursor: %v", err))
+			return nil, false
+		}
+		return token, true
+	}
+	form.AddButton("Go", func() {
+		if token, ok := parse(); ok {
+			pages.RemovePage("cursor")
+			jump(token)
+		}
+	})
+	form.AddButton("Save", func() {
+		token, ok := parse()
+		if !ok {
+			return
+		}
+		if token == nil {
+			showMessageModal(pages, "cursorerror", "The first page has no cursor.")
+			return
+		}
+		nameForm := tview.NewForm()
+		nameForm.AddInputField("Name", pageLabel, 36, nil, nil)
+		nameForm.AddButton("Save", func() {
+			name := strings.TrimSpace(nameForm.GetFormItem(0).(*tview.InputField).GetText())
+			if name == "" {
+				return
+			}
+			pages.RemovePage("savecursor")
+			if err := saveCursor(tableName, savedCursor{Name: name, Cursor: token.JSON()}); err != nil {
+				showMessageModal(pages, "cursorerror", fmt.Sprintf("Error saving the cursor: %v", err))
+				return
+			}
+			status.notify("Saved cursor "+tview.Escape(name), noticeDuration)
+			app.SetFocus(form)
+		})
+		showFormPrompt(app, pages, "savecursor", "Save Cursor", nameForm)
+	})
+	form.AddButton("Copy", func() {
+		if token, ok := parse(); ok {
+			if token == nil {
+				showMessageModal(pages, "cursorerror", "The first page has no cursor.")
+				return
+			}
+			if !copyToClipboard(token.JSON()) {
+				showMessageModal(pages, "cursorerror", "The clipboard isn't available yet, try again.")
+				return
+			}
+			status.notify("Copied the cursor", noticeDuration)
+		}
+	})
+	form.SetFocus(form.GetFormItemIndex("Start after"))
+	showFormPrompt(app, pages, "cursor", "Go to Cursor", form)
+}
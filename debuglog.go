@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appLog is the structured log of the explorer. The TUI owns the terminal,
+// so it goes to a file, and nowhere unless --log-file or --debug is given.
+var appLog = slog.New(slog.DiscardHandler)
+
+// logging reports whether appLog writes anywhere
+var logging bool
+
+// defaultLogPath is where --debug logs without --log-file: debug.log next
+// to the config file
+func defaultLogPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "debug.log"), nil
+}
+
+// openLog starts appLog as JSON lines appended to the log file: errors,
+// throttling and sessions, and with --debug every DynamoDB request. It
+// returns the file's path, empty when logging is off.
+func openLog() (string, error) {
+	path := *logFile
+	if path == "" && !*debugLog {
+		return "", nil
+	}
+	if path == "" {
+		var err error
+		if path, err = defaultLogPath(); err != nil {
+			return "", fmt.Errorf("failed to locate the log file: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open log file: %w", err)
+	}
+	level := slog.LevelInfo
+	if *debugLog {
+		level = slog.LevelDebug
+	}
+	appLog = slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level}))
+	logging = true
+	return path, nil
+}
+
+// logShownError logs a message modal that reports an error, which is how
+// the TUI surfaces most failures
+func logShownError(pageName, text string) {
+	if logging && strings.HasSuffix(pageName, "error") {
+		appLog.Warn("error shown", "page", pageName, "message", text)
+	}
+}
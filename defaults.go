@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"ddb-explorer/ui"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileDefaults are the startup defaults of config.yaml. Unlike the settings
+// of config.json, which the explorer writes itself, this file is written by
+// hand and only read.
+type fileDefaults struct {
+	Profile  string `yaml:"profile"`
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint"`
+	PageSize int    `yaml:"pageSize"`
+
+	// RoleARN and MFASerial are the role assumed on every start, like
+	// --role-arn and --mfa-serial
+	RoleARN   string `yaml:"roleArn"`
+	MFASerial string `yaml:"mfaSerial"`
+
+	Theme    string `yaml:"theme"`
+	ReadOnly bool   `yaml:"readOnly"`
+	NoMouse  bool   `yaml:"noMouse"`
+
+	// AutoRefresh is how often the table list is described again
+	AutoRefresh time.Duration `yaml:"autoRefresh"`
+
+	// WatchInterval is the time between reads of a watched results page
+	WatchInterval time.Duration `yaml:"watchInterval"`
+
+	// Columns are the result columns shown per table until others are
+	// chosen with Ctrl+C, which config.json keeps
+	Columns map[string][]string `yaml:"columns"`
+
+	// CellWidth is how many characters of a value result columns show
+	// before cutting it short, 50 unless set
+	CellWidth int `yaml:"cellWidth"`
+
+	// ColumnWidths sets CellWidth for single columns, by table name and
+	// attribute, unless a width was set with +/-, which config.json keeps
+	ColumnWidths map[string]map[string]int `yaml:"columnWidths"`
+
+	// Env is the named environment used unless --env picks another
+	Env string `yaml:"env"`
+
+	// Environments are named connections, selected with --env
+	Environments map[string]namedEnvironment `yaml:"environments"`
+
+	// DualWrites pairs each table being migrated with the table it is
+	// dual-written to, for the dual-write check
+	DualWrites map[string]string `yaml:"dualWrites"`
+
+	// Keys remaps actions to other keys, e.g. results.nextPage: Ctrl+F
+	Keys map[string]keyList `yaml:"keys"`
+}
+
+// namedEnvironment is a connection opened by name: a profile with the
+// region, endpoint and role to use. Tier (dev, staging or prod) picks the
+// frame color; without it the tiers of config.json apply.
+type namedEnvironment struct {
+	Profile   string `yaml:"profile"`
+	Region    string `yaml:"region"`
+	Endpoint  string `yaml:"endpoint"`
+	RoleARN   string `yaml:"roleArn"`
+	MFASerial string `yaml:"mfaSerial"`
+	Tier      string `yaml:"tier"`
+}
+
+// configDefaults is loaded at startup, before flags are applied
+var configDefaults fileDefaults
+
+// defaultsPath returns the location of config.yaml, next to the settings
+// file, e.g. ~/.config/ddb-explorer/config.yaml on Linux
+func defaultsPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "config.yaml"), nil
+}
+
+// loadDefaults reads config.yaml. A missing file yields no defaults; unknown
+// keys are reported so a typo doesn't go unnoticed.
+func loadDefaults() (fileDefaults, error) {
+	var d fileDefaults
+	path, err := defaultsPath()
+	if err != nil {
+		return d, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&d); err != nil && !errors.Is(err, io.EOF) {
+		return d, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if d.PageSize < 0 {
+		return d, fmt.Errorf("invalid pageSize %d in %s: must be positive", d.PageSize, path)
+	}
+	if d.CellWidth != 0 && d.CellWidth < ui.MinCellWidth {
+		return d, fmt.Errorf("invalid cellWidth %d in %s: must be at least %d", d.CellWidth, path, ui.MinCellWidth)
+	}
+	for table, widths := range d.ColumnWidths {
+		for attr, width := range widths {
+			if width < ui.MinCellWidth {
+				return d, fmt.Errorf("invalid width %d of %s.%s in %s: must be at least %d", width, table, attr, path, ui.MinCellWidth)
+			}
+		}
+	}
+	for name, env := range d.Environments {
+		if env.Tier != "" && !slices.Contains(environments, env.Tier) {
+			return d, fmt.Errorf("invalid tier %q of environment %s in %s: must be dev, staging or prod", env.Tier, name, path)
+		}
+	}
+	return d, nil
+}
+
+// environmentNames lists the named environments, sorted
+func (d fileDefaults) environmentNames() []string {
+	names := make([]string, 0, len(d.Environments))
+	for name := range d.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyDefaults sets the flags that weren't given on the command line to
+// the values of config.yaml, so they are validated like flags. The settings
+// of the named environment chosen with --env or env come before the file's
+// plain defaults.
+func applyDefaults(d fileDefaults) error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	values := map[string]string{
+		"profile":    d.Profile,
+		"region":     d.Region,
+		"endpoint":   d.Endpoint,
+		"role-arn":   d.RoleARN,
+		"mfa-serial": d.MFASerial,
+		"theme":      d.Theme,
+	}
+	if *envName == "" {
+		*envName = d.Env
+	}
+	if *envName != "" {
+		env, ok := d.Environments[*envName]
+		if !ok {
+			if len(d.Environments) == 0 {
+				return fmt.Errorf("unknown environment %q: config.yaml defines no environments", *envName)
+			}
+			return fmt.Errorf("unknown environment %q: config.yaml defines %s", *envName, strings.Join(d.environmentNames(), ", "))
+		}
+		for name, value := range map[string]string{
+			"profile":    env.Profile,
+			"region":     env.Region,
+			"endpoint":   env.Endpoint,
+			"role-arn":   env.RoleARN,
+			"mfa-serial": env.MFASerial,
+		} {
+			if value != "" {
+				values[name] = value
+			}
+		}
+	}
+	if d.PageSize > 0 {
+		values["page-size"] = strconv.Itoa(d.PageSize)
+	}
+	if d.AutoRefresh > 0 {
+		values["auto-refresh"] = d.AutoRefresh.String()
+	}
+	if d.WatchInterval > 0 {
+		values["watch-interval"] = d.WatchInterval.String()
+	}
+	if d.ReadOnly {
+		values["read-only"] = "true"
+	}
+	if d.NoMouse {
+		values["no-mouse"] = "true"
+	}
+	for name, value := range values {
+		if value == "" || given[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s in config.yaml: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// showDeleteTablePrompt asks for the exact table name before deleting a
+// table, showing the request that deletes it; the Delete button stays
+// disabled until the name was typed. deleted is called once DynamoDB
+// accepted the request.
+func showDeleteTablePrompt(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, prod bool, deleted func()) {
+	request, err := aws.DeleteTableRequest(tableName)
+	if err != nil {
+		showMessageModal(pages, "deletetableerror", fmt.Sprintf("Cannot serialize the request: %v", err))
+		return
+	}
+	text := fmt.Sprintf("[#ff453a]This permanently deletes %s and every item in it.[white] Type the table name to confirm.", tview.Escape(tableName))
+	if prod {
+		text = "[#ff453a::b]PROD table.[white::-] " + text
+	}
+	text += fmt.Sprintf("\n\n[#b8b8b8]Request sent to DynamoDB:[white]\n%s %s", request.Operation, tview.Escape(request.Body))
+
+	form := tview.NewForm()
+	form.AddTextView("", text, 0, 8, true, false)
+	form.AddInputField("Table name", "", 36, nil, nil)
+	form.AddButton("Delete", func() {
+		if form.GetFormItem(1).(*tview.InputField).GetText() != tableName {
+			return
+		}
+		pages.RemovePage("deletetable")
+		deleteTable(app, pages, client, tableName, deleted)
+	})
+	form.AddButton("Copy Request", func() {
+		if copyToClipboard(request.Operation + " " + request.Body) {
+			status.notify("Request copied to the clipboard", noticeDuration)
+		} else {
+			showMessageModal(pages, "deletetablecopied", "The clipboard is not available yet.")
+		}
+	})
+	deleteButton := form.GetButton(0)
+	deleteButton.SetDisabled(true)
+	form.GetFormItem(1).(*tview.InputField).SetChangedFunc(func(typed string) {
+		deleteButton.SetDisabled(typed != tableName)
+	})
+	showFormPrompt(app, pages, "deletetable", "Delete "+tableName, form)
+	// Start in the name field rather than on the explanation
+	form.SetFocus(1)
+	app.SetFocus(form)
+}
+
+// deleteTable sends the DeleteTable request
+func deleteTable(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, deleted func()) {
+	ctx := showLoadingModal(pages, "deletingtable", fmt.Sprintf("Deleting %s...", tableName))
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		return client.DeleteTable(ctx, tableName)
+	}, func(err error) {
+		pages.RemovePage("deletingtable")
+		if err != nil {
+			showMessageModal(pages, "deletetableerror", fmt.Sprintf("Delete error: %v", err))
+			return
+		}
+		deleted()
+		showMessageModal(pages, "deletetabledone", fmt.Sprintf("%s is being deleted. DynamoDB removes it in the background.", tableName))
+	})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// demoStart anchors the generated dates, so every --demo run shows the same
+// data and screenshots can be retaken
+var demoStart = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+var (
+	demoFirstNames = []string{"Ada", "Grace", "Alan", "Edsger", "Barbara", "Ken", "Margaret", "Linus", "Frances", "Dennis", "Radia", "Guido"}
+	demoLastNames  = []string{"Lovelace", "Hopper", "Turing", "Dijkstra", "Liskov", "Thompson", "Hamilton", "Torvalds", "Allen", "Ritchie", "Perlman", "Rossum"}
+	demoCities     = []struct{ city, country string }{{"Lisbon", "PT"}, {"Berlin", "DE"}, {"Austin", "US"}, {"Osaka", "JP"}, {"Toronto", "CA"}, {"Nairobi", "KE"}}
+	demoCategories = []string{"books", "games", "garden", "kitchen", "music", "tools"}
+	demoStatuses   = []string{"PENDING", "PAID", "SHIPPED", "DELIVERED", "CANCELLED"}
+	demoEventTypes = []string{"heartbeat", "reading", "reading", "reading", "alert", "reboot"}
+)
+
+// demoTables generates the tables of --demo: users, products, orders and
+// device events, with secondary indexes, sort keys of both types, TTL,
+// binary and nested attributes for every view to show something
+func demoTables() []aws.FakeTable {
+	rng := rand.New(rand.NewPCG(2026, 3))
+	pick := func(values []string) string { return values[rng.IntN(len(values))] }
+	money := func(lo, hi float64) float64 { return math.Round((lo+rng.Float64()*(hi-lo))*100) / 100 }
+
+	users := aws.FakeTable{
+		Name:         "users",
+		PartitionKey: "userId",
+		Indexes:      []aws.FakeIndex{{Name: "email-index", PartitionKey: "email"}},
+		Tags:         map[string]string{"env": "demo", "team": "identity"},
+	}
+	for i := 1; i <= 120; i++ {
+		first, last := pick(demoFirstNames), pick(demoLastNames)
+		home := demoCities[rng.IntN(len(demoCities))]
+		user := map[string]interface{}{
+			"userId":     fmt.Sprintf("u-%04d", i),
+			"name":       first + " " + last,
+			"email":      fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i),
+			"plan":       []string{"free", "free", "pro", "team"}[rng.IntN(4)],
+			"active":     rng.IntN(5) > 0,
+			"loginCount": rng.IntN(500),
+			"signedUpAt": demoStart.AddDate(0, 0, -rng.IntN(700)).Format(time.RFC3339),
+			"address":    map[string]interface{}{"city": home.city, "country": home.country},
+			"preferences": map[string]interface{}{
+				"theme":      []string{"dark", "light"}[rng.IntN(2)],
+				"newsletter": rng.IntN(2) == 0,
+			},
+		}
+		if rng.IntN(4) == 0 {
+			user["roles"] = []string{"admin", "billing"}[:1+rng.IntN(2)]
+		}
+		users.Items = append(users.Items, user)
+	}
+
+	products := aws.FakeTable{
+		Name:           "products",
+		PartitionKey:   "sku",
+		AttributeTypes: map[string]string{"price": "N"},
+		Indexes:        []aws.FakeIndex{{Name: "category-price-index", PartitionKey: "category", SortKey: "price"}},
+		Tags:           map[string]string{"env": "demo", "team": "catalog"},
+	}
+	prices := make(map[string]float64)
+	for i := 1; i <= 60; i++ {
+		category := pick(demoCategories)
+		sku := fmt.Sprintf("%s-%03d", strings.ToUpper(category[:3]), i)
+		prices[sku] = money(3, 250)
+		product := map[string]interface{}{
+			"sku":      sku,
+			"name":     fmt.Sprintf("%s item %d", strings.ToUpper(category[:1])+category[1:], i),
+			"category": category,
+			"price":    prices[sku],
+			"stock":    rng.IntN(300),
+		}
+		if rng.IntN(6) == 0 {
+			product["discontinued"] = true
+		}
+		products.Items = append(products.Items, product)
+	}
+	skus := make([]string, 0, len(products.Items))
+	for _, p := range products.Items {
+		skus = append(skus, p["sku"].(string))
+	}
+
+	orders := aws.FakeTable{
+		Name:         "orders",
+		PartitionKey: "customerId",
+		SortKey:      "orderId",
+		Indexes:      []aws.FakeIndex{{Name: "status-index", PartitionKey: "status", SortKey: "createdAt"}},
+		Tags:         map[string]string{"env": "demo", "team": "checkout"},
+	}
+	for i := 1; i <= 400; i++ {
+		created := demoStart.Add(-time.Duration(rng.IntN(180*24)) * time.Hour)
+		var lines []interface{}
+		var total float64
+		for range 1 + rng.IntN(4) {
+			sku, qty := pick(skus), 1+rng.IntN(3)
+			lines = append(lines, map[string]interface{}{"sku": sku, "qty": qty, "price": prices[sku]})
+			total += prices[sku] * float64(qty)
+		}
+		orders.Items = append(orders.Items, map[string]interface{}{
+			"customerId": fmt.Sprintf("u-%04d", 1+rng.IntN(40)), // Few customers, so partitions hold many orders
+			"orderId":    fmt.Sprintf("%s#%05d", created.Format("2006-01-02"), i),
+			"status":     pick(demoStatuses),
+			"createdAt":  created.Format(time.RFC3339),
+			"total":      math.Round(total*100) / 100,
+			"currency":   "EUR",
+			"lines":      lines,
+		})
+	}
+
+	events := aws.FakeTable{
+		Name:           "device-events",
+		PartitionKey:   "deviceId",
+		SortKey:        "ts",
+		AttributeTypes: map[string]string{"ts": "N"},
+		TTLAttribute:   "expiresAt",
+		Tags:           map[string]string{"env": "demo", "team": "iot"},
+	}
+	for d := 1; d <= 12; d++ {
+		at := demoStart.Add(-48 * time.Hour)
+		for range 80 {
+			at = at.Add(time.Duration(30+rng.IntN(600)) * time.Second)
+			event := map[string]interface{}{
+				"deviceId":  fmt.Sprintf("sensor-%02d", d),
+				"ts":        at.Unix(),
+				"type":      pick(demoEventTypes),
+				"expiresAt": at.AddDate(0, 0, 30).Unix(),
+			}
+			if event["type"] == "reading" || event["type"] == "alert" {
+				event["payload"] = map[string]interface{}{
+					"temperature": math.Round((18+rng.NormFloat64()*4)*10) / 10,
+					"battery":     100 - rng.IntN(60),
+				}
+			}
+			if event["type"] == "reboot" {
+				// What the device kept of its last run, for the binary viewer
+				event["crashDump"] = []byte(fmt.Sprintf("\x7fDMP\x01\x00sensor-%02d watchdog reset at %d\x00\xde\xad\xbe\xef", d, at.Unix()))
+			}
+			events.Items = append(events.Items, event)
+		}
+	}
+
+	return []aws.FakeTable{users, products, orders, events}
+}
+
+// newDemoClient creates the client of --demo over freshly generated tables.
+// Of the connection options only the region applies.
+func newDemoClient(region string) (*aws.Client, error) {
+	clientOpts := sessionClientOptions("", "")
+	if region != "" {
+		clientOpts = append(clientOpts, aws.WithRegion(region))
+	}
+	return aws.NewFakeClient("demo", demoTables(), clientOpts...)
+}
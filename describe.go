@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"ddb-explorer/aws"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tableDescription is the metadata of a table as the describe subcommand
+// writes it. Field names are the same in JSON and YAML.
+type tableDescription struct {
+	Name               string             `json:"name" yaml:"name"`
+	ARN                string             `json:"arn" yaml:"arn"`
+	Status             string             `json:"status" yaml:"status"`
+	CreatedAt          time.Time          `json:"createdAt" yaml:"createdAt"`
+	ItemCount          int64              `json:"itemCount" yaml:"itemCount"`
+	SizeBytes          int64              `json:"sizeBytes" yaml:"sizeBytes"`
+	PartitionKey       keyDescription     `json:"partitionKey" yaml:"partitionKey"`
+	SortKey            *keyDescription    `json:"sortKey,omitempty" yaml:"sortKey,omitempty"`
+	Billing            billingDescription `json:"billing" yaml:"billing"`
+	TableClass         string             `json:"tableClass" yaml:"tableClass"`
+	GlobalIndexes      []indexDescription `json:"globalIndexes" yaml:"globalIndexes"`
+	LocalIndexes       []indexDescription `json:"localIndexes" yaml:"localIndexes"`
+	TTL                *ttlDescription    `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Stream             *streamDescription `json:"stream,omitempty" yaml:"stream,omitempty"`
+	DeletionProtection bool               `json:"deletionProtection" yaml:"deletionProtection"`
+	Encryption         string             `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+	Replicas           []string           `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	Tags               map[string]string  `json:"tags" yaml:"tags"`
+}
+
+// keyDescription is a key attribute with its type: S, N or B
+type keyDescription struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+}
+
+// billingDescription is the billing mode with the capacity that applies to
+// it: provisioned units, or the optional on-demand maximums
+type billingDescription struct {
+	Mode          string `json:"mode" yaml:"mode"`
+	ReadCapacity  int64  `json:"readCapacity,omitempty" yaml:"readCapacity,omitempty"`
+	WriteCapacity int64  `json:"writeCapacity,omitempty" yaml:"writeCapacity,omitempty"`
+	MaxReadUnits  int64  `json:"maxReadUnits,omitempty" yaml:"maxReadUnits,omitempty"`
+	MaxWriteUnits int64  `json:"maxWriteUnits,omitempty" yaml:"maxWriteUnits,omitempty"`
+}
+
+// indexDescription is a global or local secondary index
+type indexDescription struct {
+	Name             string          `json:"name" yaml:"name"`
+	Status           string          `json:"status" yaml:"status"`
+	PartitionKey     keyDescription  `json:"partitionKey" yaml:"partitionKey"`
+	SortKey          *keyDescription `json:"sortKey,omitempty" yaml:"sortKey,omitempty"`
+	Projection       string          `json:"projection" yaml:"projection"`
+	NonKeyAttributes []string        `json:"nonKeyAttributes,omitempty" yaml:"nonKeyAttributes,omitempty"`
+	ReadCapacity     int64           `json:"readCapacity,omitempty" yaml:"readCapacity,omitempty"`
+	WriteCapacity    int64           `json:"writeCapacity,omitempty" yaml:"writeCapacity,omitempty"`
+	ItemCount        int64           `json:"itemCount" yaml:"itemCount"`
+	SizeBytes        int64           `json:"sizeBytes" yaml:"sizeBytes"`
+}
+
+// ttlDescription is the TTL setting of a table that ever had TTL enabled
+type ttlDescription struct {
+	Attribute string `json:"attribute" yaml:"attribute"`
+	Status    string `json:"status" yaml:"status"`
+}
+
+// streamDescription is the latest stream of a table with streams on
+type streamDescription struct {
+	ViewType string `json:"viewType" yaml:"viewType"`
+	ARN      string `json:"arn" yaml:"arn"`
+	Label    string `json:"label" yaml:"label"`
+}
+
+// runDescribe implements the describe subcommand. It describes one table
+// with its TTL setting and tags, like the details page (i), and writes the
+// result as JSON or YAML to stdout.
+func runDescribe(args []string) int {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	profile := fs.String("profile", "dev", "AWS profile to use")
+	roleARN := fs.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+	mfaSerial := fs.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+	tableName := fs.String("table", "", "Table to describe (required)")
+	output := fs.String("output", "json", "Output format: json or yaml")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ddb-explorer describe --table TABLE [--output json|yaml] [OPTIONS]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 || *tableName == "" {
+		fs.Usage()
+		return 1
+	}
+	if *output != "json" && *output != "yaml" {
+		fmt.Fprintf(os.Stderr, "Invalid output: %s. Must be 'json' or 'yaml'\n", *output)
+		return 1
+	}
+
+	client, err := newHeadlessClient(*profile, *roleARN, *mfaSerial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create AWS client: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	details, err := client.DescribeTableDetails(ctx, *tableName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to describe %s: %v\n", *tableName, err)
+		return 1
+	}
+	if err := writeTableDescription(os.Stdout, *output, newTableDescription(details)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write description: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// newTableDescription arranges the details of a table for the describe
+// subcommand. Lists are empty rather than missing so scripts can iterate
+// them.
+func newTableDescription(d aws.TableDetails) tableDescription {
+	key := func(attr string) keyDescription {
+		return keyDescription{Name: attr, Type: d.AttributeTypes[attr]}
+	}
+	sortKey := func(attr string) *keyDescription {
+		if attr == "" {
+			return nil
+		}
+		k := key(attr)
+		return &k
+	}
+	indexes := func(list []aws.IndexInfo) []indexDescription {
+		described := make([]indexDescription, len(list))
+		for i, idx := range list {
+			described[i] = indexDescription{
+				Name:             idx.Name,
+				Status:           idx.Status,
+				PartitionKey:     key(idx.PartitionKey),
+				SortKey:          sortKey(idx.SortKey),
+				Projection:       idx.ProjectionType,
+				NonKeyAttributes: idx.NonKeyAttributes,
+				ReadCapacity:     idx.ReadCapacity,
+				WriteCapacity:    idx.WriteCapacity,
+				ItemCount:        idx.ItemCount,
+				SizeBytes:        idx.SizeBytes,
+			}
+		}
+		return described
+	}
+
+	desc := tableDescription{
+		Name:         d.Name,
+		ARN:          d.ARN,
+		Status:       d.Status,
+		CreatedAt:    d.CreatedAt.UTC(),
+		ItemCount:    d.ItemCount,
+		SizeBytes:    d.SizeBytes,
+		PartitionKey: key(d.PartitionKey),
+		SortKey:      sortKey(d.SortKey),
+		Billing: billingDescription{
+			Mode:          d.BillingMode,
+			ReadCapacity:  d.ReadCapacity,
+			WriteCapacity: d.WriteCapacity,
+			MaxReadUnits:  d.MaxReadUnits,
+			MaxWriteUnits: d.MaxWriteUnits,
+		},
+		TableClass:         d.TableClass,
+		GlobalIndexes:      indexes(d.GlobalIndexes()),
+		LocalIndexes:       indexes(d.LocalIndexes()),
+		DeletionProtection: d.DeletionProtection,
+		Encryption:         d.Encryption,
+		Replicas:           d.Replicas,
+		Tags:               d.Tags,
+	}
+	if desc.Tags == nil {
+		desc.Tags = map[string]string{}
+	}
+	if d.TTLAttribute != "" {
+		desc.TTL = &ttlDescription{Attribute: d.TTLAttribute, Status: d.TTLStatus}
+	}
+	if d.StreamView != "" {
+		desc.Stream = &streamDescription{ViewType: d.StreamView, ARN: d.StreamARN, Label: d.StreamLabel}
+	}
+	return desc
+}
+
+// writeTableDescription writes a description as indented JSON or YAML
+func writeTableDescription(w io.Writer, format string, desc tableDescription) error {
+	if format == "yaml" {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(desc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(desc)
+}
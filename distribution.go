@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// sparkLevels are the bars of a distribution histogram, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// distributionBuckets is the number of bars of a distribution histogram
+const distributionBuckets = 24
+
+// numericValue returns a raw item value as a number, if it is one
+func numericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// numericAttributes lists the attributes holding a number in at least one
+// of the items, sorted by name
+func numericAttributes(rawItems []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var attrs []string
+	for _, item := range rawItems {
+		for attr, v := range item {
+			if _, ok := numericValue(v); ok && !seen[attr] {
+				seen[attr] = true
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+// distribution summarizes the numeric values of an attribute across items
+type distribution struct {
+	attr     string
+	items    int       // Items looked at
+	values   []float64 // Numeric values, sorted
+	outliers int       // Values more than 1.5 interquartile ranges outside the middle half
+}
+
+// newDistribution collects the numeric values of attr; items that lack it
+// or hold another type are skipped
+func newDistribution(rawItems []map[string]interface{}, attr string) distribution {
+	d := distribution{attr: attr, items: len(rawItems)}
+	for _, item := range rawItems {
+		if v, ok := numericValue(item[attr]); ok {
+			d.values = append(d.values, v)
+		}
+	}
+	sort.Float64s(d.values)
+	if len(d.values) >= 4 {
+		q1, q3 := d.quantile(0.25), d.quantile(0.75)
+		fence := 1.5 * (q3 - q1)
+		for _, v := range d.values {
+			if v < q1-fence || v > q3+fence {
+				d.outliers++
+			}
+		}
+	}
+	return d
+}
+
+// quantile interpolates between the closest values, e.g. 0.5 for the median
+func (d distribution) quantile(q float64) float64 {
+	pos := q * float64(len(d.values)-1)
+	lower := int(math.Floor(pos))
+	upper := min(lower+1, len(d.values)-1)
+	return d.values[lower] + (d.values[upper]-d.values[lower])*(pos-float64(lower))
+}
+
+// histogram counts the values into equal-width buckets from min to max
+func (d distribution) histogram(buckets int) []int {
+	counts := make([]int, buckets)
+	low, high := d.values[0], d.values[len(d.values)-1]
+	for _, v := range d.values {
+		i := 0
+		if high > low {
+			i = min(int((v-low)/(high-low)*float64(buckets)), buckets-1)
+		}
+		counts[i]++
+	}
+	return counts
+}
+
+// sparkline draws counts as bars scaled to the largest; empty buckets stay
+// blank so gaps between clusters show
+func sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	bars := make([]rune, len(counts))
+	top := len(sparkLevels) - 1
+	for i, c := range counts {
+		bars[i] = ' '
+		if c > 0 {
+			bars[i] = sparkLevels[min(max(c*top/peak, 0), top)]
+		}
+	}
+	return string(bars)
+}
+
+// formatStat formats a value of a distribution: whole numbers with digit
+// grouping, others with up to two decimals
+func formatStat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return formatNumber(int64(v))
+	}
+	text := strconv.FormatFloat(v, 'f', 2, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return strings.Replace(text, ".", displayLocale.decimal, 1)
+}
+
+// text is the one-line summary shown above the results
+func (d distribution) text() string {
+	name := tview.Escape(d.attr)
+	if len(d.values) == 0 {
+		return fmt.Sprintf("[#ff9500]%s[white]: no numeric values in the %s loaded items (Ctrl+K: change)", name, formatNumber(int64(d.items)))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[#ff9500]%s[white] (%s of %s loaded items): min %s ", name,
+		formatNumber(int64(len(d.values))), formatNumber(int64(d.items)), formatStat(d.values[0]))
+	if !*screenReader {
+		fmt.Fprintf(&b, "[#30d158]%s[white] ", sparkline(d.histogram(distributionBuckets)))
+	}
+	fmt.Fprintf(&b, "max %s | median %s", formatStat(d.values[len(d.values)-1]), formatStat(d.quantile(0.5)))
+	switch {
+	case d.outliers == 1:
+		b.WriteString(" | [#ffd60a]1 outlier[white]")
+	case d.outliers > 1:
+		fmt.Fprintf(&b, " | [#ffd60a]%s outliers[white]", formatNumber(int64(d.outliers)))
+	}
+	b.WriteString(" (Ctrl+K: change)")
+	return b.String()
+}
+
+// showAttributePicker lets the user pick an attribute, starting on current.
+// A none entry, when given, comes first and picks the empty name.
+func showAttributePicker(app *tview.Application, pages *tview.Pages, title string, attrs []string, current, none string, pick func(attr string)) {
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+	if none != "" {
+		list.AddItem(none, "", 0, func() {
+			pages.RemovePage("attributepicker")
+			pick("")
+		})
+	}
+	for _, attr := range attrs {
+		list.AddItem(tview.Escape(attr), "", 0, func() {
+			pages.RemovePage("attributepicker")
+			pick(attr)
+		})
+		if attr == current {
+			list.SetCurrentItem(list.GetItemCount() - 1)
+		}
+	}
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("attributepicker")
+			return nil
+		}
+		return event
+	})
+	list.SetBorder(true).
+		SetBorderColor(accentOrange).
+		SetTitle(" " + title + " ").
+		SetTitleColor(accentOrange)
+
+	// Center the picker over the results
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, min(list.GetItemCount(), 15)+2, 0, true).
+			AddItem(nil, 0, 1, false), 40, 0, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage("attributepicker", modal, true, true)
+	app.SetFocus(list)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// dualWritePair is a table being migrated and the table it is dual-written to
+type dualWritePair struct {
+	oldTable, newTable string
+}
+
+// dualWritePairs holds the pairs of --dual-write and config.yaml under the
+// names of both of their tables
+var dualWritePairs = make(map[string]dualWritePair)
+
+// dualWriteSpec parses a --dual-write value: OLD=NEW
+func dualWriteSpec(spec string) (oldTable, newTable string, err error) {
+	oldTable, newTable, ok := strings.Cut(spec, "=")
+	if !ok || oldTable == "" || newTable == "" {
+		return "", "", fmt.Errorf("invalid --dual-write %q: expected OLD=NEW", spec)
+	}
+	return oldTable, newTable, nil
+}
+
+// addDualWrite pairs two tables. A table can only be in one pair.
+func addDualWrite(oldTable, newTable string) error {
+	if oldTable == newTable {
+		return fmt.Errorf("invalid dual-write pair %s=%s: the tables must differ", oldTable, newTable)
+	}
+	pair := dualWritePair{oldTable: oldTable, newTable: newTable}
+	for _, name := range []string{oldTable, newTable} {
+		if p, ok := dualWritePairs[name]; ok && p != pair {
+			return fmt.Errorf("invalid dual-write pair %s=%s: %s is already paired as %s=%s", oldTable, newTable, name, p.oldTable, p.newTable)
+		}
+	}
+	dualWritePairs[oldTable] = pair
+	dualWritePairs[newTable] = pair
+	return nil
+}
+
+// loadDualWrites pairs the tables of --dual-write, or without the flag those
+// of dualWrites in config.yaml
+func loadDualWrites() error {
+	if len(dualWriteSpecs) == 0 {
+		oldTables := make([]string, 0, len(configDefaults.DualWrites))
+		for oldTable := range configDefaults.DualWrites {
+			oldTables = append(oldTables, oldTable)
+		}
+		sort.Strings(oldTables)
+		for _, oldTable := range oldTables {
+			if err := addDualWrite(oldTable, configDefaults.DualWrites[oldTable]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, spec := range dualWriteSpecs {
+		oldTable, newTable, err := dualWriteSpec(spec)
+		if err == nil {
+			err = addDualWrite(oldTable, newTable)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyValueOf converts a key value typed as text to the attribute type of the
+// key: numbers are kept exact and binary values are read as base64
+func keyValueOf(text, attrType string) (interface{}, error) {
+	switch attrType {
+	case "N":
+		if !fitsKeyType(text, "N") {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return json.Number(strings.TrimSpace(text)), nil
+	case "B":
+		b, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not base64", text)
+		}
+		return b, nil
+	}
+	return text, nil
+}
+
+// showDualWritePrompt asks for the primary key of an item to compare
+// between a table and its dual-write partner
+func showDualWritePrompt(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string) {
+	ctx := showLoadingModal(pages, "loadingdualwrite", "Describing table...")
+	var tableInfo aws.TableInfo
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		tableInfo, err = client.DescribeTable(ctx, tableName)
+		return err
+	}, func(err error) {
+		pages.RemovePage("loadingdualwrite")
+		if err != nil {
+			showMessageModal(pages, "dualwriteerror", fmt.Sprintf("Error describing %s: %v", tableName, err))
+			return
+		}
+
+		keyAttrs := []string{tableInfo.PartitionKey}
+		if tableInfo.SortKey != "" {
+			keyAttrs = append(keyAttrs, tableInfo.SortKey)
+		}
+		form := tview.NewForm()
+		for _, attr := range keyAttrs {
+			label := attr
+			if t, ok := keyTypeNames[tableInfo.AttributeTypes[attr]]; ok {
+				label = fmt.Sprintf("%s (%s)", attr, t)
+			}
+			form.AddInputField(label, "", 36, nil, nil)
+		}
+		form.AddButton("Compare", func() {
+			key := make(map[string]interface{}, len(keyAttrs))
+			for i, attr := range keyAttrs {
+				text := form.GetFormItem(i).(*tview.InputField).GetText()
+				if text == "" {
+					return
+				}
+				v, err := keyValueOf(text, tableInfo.AttributeTypes[attr])
+				if err != nil {
+					showMessageModal(pages, "dualwriteerror", fmt.Sprintf("Invalid %s: %v", attr, err))
+					return
+				}
+				key[attr] = v
+			}
+			pages.RemovePage("dualwritekey")
+			showDualWriteDiff(app, pages, client, tableName, key)
+		})
+		pair := dualWritePairs[tableName]
+		showFormPrompt(app, pages, "dualwritekey", fmt.Sprintf("Compare %s and %s", pair.oldTable, pair.newTable), form)
+	})
+}
+
+// dualWriteResult is an item as read from both tables of a pair
+type dualWriteResult struct {
+	dualWritePair
+	oldItem, newItem map[string]interface{} // Raw items, nil when missing
+}
+
+// fetchDualWrite reads an item from both tables of the pair with strongly
+// consistent reads. The tables must share their key attributes.
+func fetchDualWrite(ctx context.Context, client *aws.Client, pair dualWritePair, key map[string]interface{}) (dualWriteResult, error) {
+	r := dualWriteResult{dualWritePair: pair}
+	for _, name := range []string{r.oldTable, r.newTable} {
+		info, err := client.DescribeTable(ctx, name)
+		if err != nil {
+			return r, err
+		}
+		for attr := range key {
+			if attr != info.PartitionKey && attr != info.SortKey {
+				return r, fmt.Errorf("%s has no key attribute %s: the dual-write check needs tables with the same primary key", name, attr)
+			}
+		}
+	}
+	for _, name := range []string{r.oldTable, r.newTable} {
+		page, err := client.GetItem(ctx, name, key)
+		if err != nil {
+			return r, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(page.RawItems) == 0 {
+			continue
+		}
+		if name == r.oldTable {
+			r.oldItem = page.RawItems[0]
+		} else {
+			r.newItem = page.RawItems[0]
+		}
+	}
+	return r, nil
+}
+
+// dualWriteText lays out the verdict and the attributes that differ, then
+// the ones that match
+func dualWriteText(key map[string]interface{}, r dualWriteResult) string {
+	var b strings.Builder
+	b.WriteString("[#ff9500::b]Key[white::-]\n")
+	attrs := make([]string, 0, len(key))
+	for attr := range key {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	for _, attr := range attrs {
+		fmt.Fprintf(&b, "  %s = %s\n", tview.Escape(attr), tview.Escape(jsonString(key[attr])))
+	}
+	b.WriteString("\n")
+
+	switch {
+	case r.oldItem == nil && r.newItem == nil:
+		fmt.Fprintf(&b, "[#ffd60a]Neither %s nor %s has this item.[white]\n", r.oldTable, r.newTable)
+		return b.String()
+	case r.newItem == nil:
+		fmt.Fprintf(&b, "[#ff453a]Missing in %s[white]: only %s has this item.\n", r.newTable, r.oldTable)
+		return b.String()
+	case r.oldItem == nil:
+		fmt.Fprintf(&b, "[#ff453a]Missing in %s[white]: only %s has this item.\n", r.oldTable, r.newTable)
+		return b.String()
+	}
+
+	changed := changedAttributes(r.oldItem, r.newItem)
+	var same []string
+	for attr := range r.newItem {
+		if _, ok := r.oldItem[attr]; ok && !slices.Contains(changed, attr) {
+			same = append(same, attr)
+		}
+	}
+	sort.Strings(same)
+	if len(changed) == 0 {
+		fmt.Fprintf(&b, "[#30d158]Identical: all %d attributes match.[white]\n", len(same))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "[#ffd60a]%d of %d attributes differ.[white]\n", len(changed), len(changed)+len(same))
+
+	b.WriteString("\n[#ff9500::b]Differences[white::-]\n")
+	width := max(len(r.oldTable), len(r.newTable))
+	value := func(item map[string]interface{}, attr string) string {
+		v, ok := item[attr]
+		if !ok {
+			return "[#b8b8b8](absent)[white]"
+		}
+		return tview.Escape(jsonString(v))
+	}
+	for _, attr := range changed {
+		fmt.Fprintf(&b, "  [::b]%s[::-]\n", tview.Escape(attr))
+		fmt.Fprintf(&b, "    [#b8b8b8]%-*s[white]  %s\n", width, r.oldTable, value(r.oldItem, attr))
+		fmt.Fprintf(&b, "    [#b8b8b8]%-*s[white]  %s\n", width, r.newTable, value(r.newItem, attr))
+	}
+	if len(same) > 0 {
+		b.WriteString("\n[#ff9500::b]Matching[white::-]\n")
+		fmt.Fprintf(&b, "  %s\n", tview.Escape(strings.Join(same, ", ")))
+	}
+	return b.String()
+}
+
+// showDualWriteDiff reads an item from a table and its dual-write partner
+// and shows how they differ, attribute by attribute. r reads both again,
+// for writes that were still on their way.
+func showDualWriteDiff(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string, key map[string]interface{}) {
+	pair := dualWritePairs[tableName]
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Dual-write check: %s ↔ %s (%s: check again | ↑/↓: scroll | ESC: close)", tview.Escape(pair.oldTable), tview.Escape(pair.newTable), keyLabel("dualWrite.recheck")))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+
+	check := func() {
+		ctx := showLoadingModal(pages, "loadingdualwrite", fmt.Sprintf("Reading %s and %s...", pair.oldTable, pair.newTable))
+		var r dualWriteResult
+		runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+			var err error
+			r, err = fetchDualWrite(ctx, client, pair, key)
+			return err
+		}, func(err error) {
+			pages.RemovePage("loadingdualwrite")
+			if err != nil {
+				showMessageModal(pages, "dualwriteerror", fmt.Sprintf("Dual-write check error: %v", err))
+				return
+			}
+			if pages.GetPage("dualwrite") != flex {
+				pages.AddPage("dualwrite", flex, true, true)
+			}
+			text.SetText(dualWriteText(key, r)).ScrollToBeginning()
+			app.SetFocus(text)
+		})
+	}
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("dualwrite")
+			return nil
+		}
+		if keyPressed(event, "dualWrite.recheck") {
+			check()
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("dualwrite")
+	check()
+}
+
+// dualWriteKeyOf takes the primary key of a results row to compare
+func dualWriteKeyOf(tableInfo aws.TableInfo, rawItem map[string]interface{}) map[string]interface{} {
+	key := map[string]interface{}{tableInfo.PartitionKey: rawItem[tableInfo.PartitionKey]}
+	if tableInfo.SortKey != "" {
+		key[tableInfo.SortKey] = rawItem[tableInfo.SortKey]
+	}
+	return key
+}
+
+// noDualWriteText explains how to pair a table for the dual-write check
+func noDualWriteText(tableName string) string {
+	return fmt.Sprintf("%s isn't paired with another table. Pair the tables of a dual-write migration with --dual-write OLD=NEW or under dualWrites in config.yaml.", tableName)
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// editorMaxItems is the largest table the editor loads in full
+const editorMaxItems = 1000
+
+// editorRow is a row of the table editor
+type editorRow struct {
+	item    aws.EditableItem
+	loaded  map[string]interface{} // Values as loaded, nil for added rows
+	deleted bool
+}
+
+// status returns the pending change of the row: "+", "~", "-" or "" when unchanged
+func (r editorRow) status() string {
+	switch {
+	case r.deleted:
+		return "-"
+	case r.loaded == nil:
+		return "+"
+	case len(changedAttributes(r.loaded, r.item.Values)) > 0:
+		return "~"
+	}
+	return ""
+}
+
+// editorStatusNames spells out row statuses for screen readers
+var editorStatusNames = map[string]string{
+	"+": "added",
+	"~": "modified",
+	"-": "deleted",
+	"":  "unchanged",
+}
+
+// showTableEditor loads a small table in full and opens it in the editor
+func showTableEditor(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo) {
+	ctx := showLoadingModal(pages, "editorloading", fmt.Sprintf("Loading %s for editing...", tableInfo.Name))
+
+	var items []aws.EditableItem
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		items, err = client.ScanForEdit(ctx, tableInfo.Name, editorMaxItems)
+		return err
+	}, func(err error) {
+		pages.RemovePage("editorloading")
+		if err != nil {
+			showMessageModal(pages, "editorerror", fmt.Sprintf("Cannot edit table: %v", err))
+			return
+		}
+		rows := make([]*editorRow, len(items))
+		for i, item := range items {
+			rows[i] = &editorRow{item: item, loaded: item.Values}
+		}
+		createTableEditorPage(app, pages, client, tableInfo, rows)
+	})
+}
+
+// createTableEditorPage shows the rows in an editable grid. Changes are kept
+// locally until they are reviewed and applied with Ctrl+S.
+func createTableEditorPage(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, rows []*editorRow) {
+	keyAttrs := []string{tableInfo.PartitionKey}
+	if tableInfo.SortKey != "" {
+		keyAttrs = append(keyAttrs, tableInfo.SortKey)
+	}
+
+	grid := newDataTable().
+		SetFixed(1, 0)
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+
+	pendingChanges := func() int {
+		n := 0
+		for _, row := range rows {
+			if row.status() != "" {
+				n++
+			}
+		}
+		return n
+	}
+
+	render := func() {
+		selected, _ := grid.GetSelection()
+		grid.Clear()
+
+		// Key attributes first, then every other attribute present on any row
+		seen := make(map[string]bool)
+		var others []string
+		for _, row := range rows {
+			for attr := range row.item.Values {
+				if !seen[attr] {
+					seen[attr] = true
+					others = append(others, attr)
+				}
+			}
+		}
+		sort.Strings(others)
+		columns := append([]string(nil), keyAttrs...)
+		for _, attr := range others {
+			if attr != tableInfo.PartitionKey && attr != tableInfo.SortKey {
+				columns = append(columns, attr)
+			}
+		}
+
+		grid.SetCell(0, 0, tview.NewTableCell(" ").SetSelectable(false))
+		for col, name := range columns {
+			grid.SetCell(0, col+1, tview.NewTableCell(name).
+				SetTextColor(tview.Styles.SecondaryTextColor).
+				SetSelectable(false).
+				SetAlign(tview.AlignCenter))
+		}
+
+		if len(rows) == 0 {
+			grid.SetCell(1, 0, tview.NewTableCell("No items. Press 'a' to add one.").
+				SetTextColor(tview.Styles.PrimaryTextColor))
+		}
+		for i, row := range rows {
+			status := row.status()
+			color := tview.Styles.PrimaryTextColor
+			switch status {
+			case "+":
+				color = accentGreen
+			case "~":
+				color = accentYellow
+			case "-":
+				color = accentRed
+			}
+			if *screenReader {
+				status = rowPrefix("Item", i+1, len(rows)) + editorStatusNames[status]
+			}
+			grid.SetCell(i+1, 0, tview.NewTableCell(status).SetTextColor(color))
+			for col, name := range columns {
+				value := ""
+				if v, ok := row.item.Values[name]; ok {
+					value = labeled(tview.Escape(name), editorCellText(v))
+				}
+				grid.SetCell(i+1, col+1, tview.NewTableCell(value).SetTextColor(color))
+			}
+		}
+		if selected > 0 {
+			grid.Select(min(selected, max(len(rows), 1)), 0)
+		}
+
+		header.SetText(fmt.Sprintf("Editing %s - %d items, %d pending changes (%s: add | Enter/%s: edit | %s: delete | %s: review & apply | ESC: close)",
+			tableInfo.Name, len(rows), pendingChanges(), keyLabel("editor.add"), keyLabel("editor.edit"), keyLabel("editor.delete"), keyLabel("editor.review")))
+	}
+
+	editRow := func(row *editorRow) {
+		showEditorItemForm(app, pages, keyAttrs, row.item.Values, func(values map[string]interface{}) {
+			row.item.Values = values
+			render()
+			app.SetFocus(grid)
+		})
+	}
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(grid, 0, 1, true)
+
+	closeEditor := func() {
+		pages.RemovePage("tableeditor")
+	}
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		selected, _ := grid.GetSelection()
+		var current *editorRow
+		if selected > 0 && selected <= len(rows) {
+			current = rows[selected-1]
+		}
+
+		switch {
+		case event.Key() == tcell.KeyESC:
+			if pendingChanges() == 0 {
+				closeEditor()
+				return nil
+			}
+			modal := tview.NewModal().
+				SetText(fmt.Sprintf("Discard %d pending changes?", pendingChanges())).
+				AddButtons([]string{"Discard", "Keep Editing"}).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					pages.RemovePage("editordiscard")
+					if buttonLabel == "Discard" {
+						closeEditor()
+					}
+				})
+			pages.AddPage("editordiscard", modal, true, true)
+			return nil
+		case keyPressed(event, "editor.review"):
+			showEditorReview(app, pages, client, tableInfo, keyAttrs, rows, func() {
+				// Reload so the grid reflects what is now stored in the table
+				showTableEditor(app, pages, client, tableInfo)
+			})
+			return nil
+		case event.Key() == tcell.KeyEnter || keyPressed(event, "editor.edit"):
+			if current != nil && !current.deleted {
+				editRow(current)
+			}
+			return nil
+		case keyPressed(event, "editor.add"):
+			values := make(map[string]interface{}, len(keyAttrs))
+			for _, k := range keyAttrs {
+				values[k] = ""
+			}
+			showEditorItemForm(app, pages, keyAttrs, values, func(values map[string]interface{}) {
+				rows = append(rows, &editorRow{item: aws.NewEditableItem(values)})
+				render()
+				grid.Select(len(rows), 0)
+				app.SetFocus(grid)
+			})
+			return nil
+		case keyPressed(event, "editor.delete"):
+			if current == nil {
+				return nil
+			}
+			if current.loaded == nil {
+				// Added rows were never written, so just drop them
+				rows = append(rows[:selected-1], rows[selected:]...)
+			} else {
+				current.deleted = !current.deleted
+			}
+			render()
+			return nil
+		}
+		return event
+	})
+
+	render()
+	pages.RemovePage("tableeditor")
+	pages.AddPage("tableeditor", flex, true, true)
+	app.SetFocus(grid)
+}
+
+// editorCellText renders a value for a grid cell
+func editorCellText(v interface{}) string {
+	text, ok := v.(string)
+	if !ok || text == "" {
+		text = jsonString(v) // Quoted, so an empty string shows as ""
+	}
+	if len(text) > 40 {
+		text = text[:37] + "..."
+	}
+	return tview.Escape(text)
+}
+
+// showEditorItemForm edits an item as JSON. save receives the parsed item and
+// is only called once the JSON is valid and contains every key attribute.
+func showEditorItemForm(app *tview.Application, pages *tview.Pages, keyAttrs []string, values map[string]interface{}, save func(map[string]interface{})) {
+	jsonBytes, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		showMessageModal(pages, "editorerror", fmt.Sprintf("Error formatting item: %v", err))
+		return
+	}
+
+	textArea := tview.NewTextArea().
+		SetText(string(jsonBytes), false)
+	textArea.SetBorder(true)
+
+	formFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	formFlex.AddItem(tview.NewTextView().
+		SetText(fmt.Sprintf("Edit Item as JSON (%s: save | ESC: cancel)", keyLabel("itemEditor.save"))).
+		SetTextAlign(tview.AlignCenter), 1, 0, false)
+	formFlex.AddItem(textArea, 0, 1, true)
+
+	formFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("editoritem")
+			return nil
+		}
+		if keyPressed(event, "itemEditor.save") {
+			edited, err := parseEditedItem(textArea.GetText(), keyAttrs)
+			if err != nil {
+				showMessageModal(pages, "editorerror", err.Error())
+				return nil
+			}
+			pages.RemovePage("editoritem")
+			save(edited)
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("editoritem", formFlex, true, true)
+	app.SetFocus(textArea)
+}
+
+// parseEditedItem reads an item edited as JSON and checks it has every key
+// attribute
+func parseEditedItem(text string, keyAttrs []string) (map[string]interface{}, error) {
+	// Numbers are kept as written so large integers and decimals aren't rounded
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var edited map[string]interface{}
+	if err := decoder.Decode(&edited); err != nil {
+		return nil, fmt.Errorf("Invalid JSON: %v", err)
+	}
+	for _, k := range keyAttrs {
+		if v, ok := edited[k]; !ok || v == nil || v == "" {
+			return nil, fmt.Errorf("Key attribute %s is required", k)
+		}
+	}
+	return edited, nil
+}
+
+// showEditorReview lists the pending changes with the exact requests that
+// write them, and applies them on confirmation. applied runs once every
+// write succeeded and its results were closed.
+func showEditorReview(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, keyAttrs []string, rows []*editorRow, applied func()) {
+	var puts, deletes []aws.EditableItem
+	var lines []string
+	for _, row := range rows {
+		switch row.status() {
+		case "+":
+			puts = append(puts, row.item)
+			lines = append(lines, fmt.Sprintf("[#30d158]+ %s[white]", tview.Escape(itemKey(row.item.Values, keyAttrs))))
+		case "~":
+			puts = append(puts, row.item)
+			lines = append(lines, fmt.Sprintf("[#ffd60a]~ %s[white]", tview.Escape(itemKey(row.loaded, keyAttrs))))
+			for _, attr := range changedAttributes(row.loaded, row.item.Values) {
+				old, hadOld := row.loaded[attr]
+				cur, hasCur := row.item.Values[attr]
+				before, after := "(absent)", "(absent)"
+				if hadOld {
+					before = jsonString(old)
+				}
+				if hasCur {
+					after = jsonString(cur)
+				}
+				lines = append(lines, tview.Escape(fmt.Sprintf("    %s: %s -> %s", attr, before, after)))
+			}
+		case "-":
+			deletes = append(deletes, row.item)
+			lines = append(lines, fmt.Sprintf("[#ff453a]- %s[white]", tview.Escape(itemKey(row.loaded, keyAttrs))))
+		}
+	}
+
+	if len(puts)+len(deletes) == 0 {
+		showMessageModal(pages, "editorreview", "No pending changes.")
+		return
+	}
+
+	// The exact requests are shown under the summary so nothing written is a surprise
+	plan, err := client.PlanEdits(tableInfo.Name, keyAttrs, puts, deletes)
+	if err != nil {
+		showMessageModal(pages, "editorerror", fmt.Sprintf("Cannot apply changes: %v", err))
+		return
+	}
+	requests, err := plan.Requests()
+	if err != nil {
+		showMessageModal(pages, "editorerror", fmt.Sprintf("Cannot serialize requests: %v", err))
+		return
+	}
+	title := fmt.Sprintf("Review changes to %s: %d puts, %d deletes", tableInfo.Name, len(puts), len(deletes))
+	showRequestReview(app, pages, "editorreview", title, lines, requests, "Apply", func() {
+		loadingModal := tview.NewModal().
+			SetText("Applying changes...").
+			SetTextColor(tcell.NewHexColor(0x121212))
+		pages.AddPage("editorapplying", loadingModal, true, true)
+
+		// Not cancellable: stopping half way would leave the table partially updated
+		var results []aws.WriteResult
+		runWithReauth(context.Background(), app, pages, client, func(ctx context.Context) error {
+			var err error
+			results, err = client.ApplyWritePlan(ctx, plan, keyAttrs)
+			return err
+		}, func(err error) {
+			pages.RemovePage("editorapplying")
+			if err != nil && len(results) == 0 {
+				// Pending changes stay in the editor so they can be retried
+				showMessageModal(pages, "editorerror", fmt.Sprintf("Apply error: %v", err))
+				return
+			}
+			showWriteResults(app, pages, tableInfo, keyAttrs, results, err, func() {
+				if err != nil {
+					return // Keep the pending changes so the failed writes can be retried
+				}
+				pages.RemovePage("editorreview")
+				applied()
+			})
+		})
+	})
+}
+
+// showRequestReview shows a summary of a write above the exact requests it
+// sends, which can be copied, and runs apply when the action button is
+// pressed. The page stays open; apply removes it when it's done with it.
+func showRequestReview(app *tview.Application, pages *tview.Pages, pageName, title string, lines []string, requests []aws.SerializedRequest, action string, apply func()) {
+	lines = append(lines, "", fmt.Sprintf("[#b8b8b8]Requests sent to DynamoDB (%d calls):[white]", len(requests)))
+	var bodies []string
+	for i, r := range requests {
+		lines = append(lines, "", fmt.Sprintf("[#b8b8b8]%s %d of %d[white]", r.Operation, i+1, len(requests)), tview.Escape(r.Body))
+		bodies = append(bodies, r.Operation+" "+r.Body)
+	}
+
+	summary := tview.NewTextView().
+		SetText(strings.Join(lines, "\n")).
+		SetDynamicColors(true).
+		SetScrollable(true)
+	summary.SetBorder(true)
+
+	buttons := tview.NewForm().
+		SetButtonBackgroundColor(accentOrange).
+		SetButtonTextColor(tcell.NewHexColor(0x121212))
+
+	reviewFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	reviewFlex.AddItem(tview.NewTextView().
+		SetText(title+" (Tab: buttons | ESC: back)").
+		SetTextAlign(tview.AlignCenter), 1, 0, false)
+	reviewFlex.AddItem(summary, 0, 1, true)
+	reviewFlex.AddItem(buttons, 3, 0, false)
+
+	buttons.AddButton(action, apply)
+	buttons.AddButton("Copy Requests", func() {
+		if copyToClipboard(strings.Join(bodies, "\n\n")) {
+			status.notify("Requests copied to the clipboard", noticeDuration)
+		} else {
+			showMessageModal(pages, pageName+"copied", "The clipboard is not available yet.")
+		}
+	})
+	buttons.AddButton("Cancel", func() {
+		pages.RemovePage(pageName)
+	})
+	buttons.SetCancelFunc(func() {
+		pages.RemovePage(pageName)
+	})
+
+	reviewFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage(pageName)
+			return nil
+		case tcell.KeyTab:
+			if summary.HasFocus() {
+				app.SetFocus(buttons)
+				return nil
+			}
+		}
+		return event
+	})
+
+	pages.AddPage(pageName, reviewFlex, true, true)
+	app.SetFocus(summary)
+}
+
+// showWriteResults shows the item each write replaced next to what was
+// written, from the old images DynamoDB returned. A non-nil err is shown
+// above the writes that did complete. done runs when the page is closed.
+func showWriteResults(app *tview.Application, pages *tview.Pages, tableInfo aws.TableInfo, keyAttrs []string, results []aws.WriteResult, err error, done func()) {
+	var lines []string
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("[#ff453a]%s[white]", tview.Escape(fmt.Sprintf("Apply error: %v", err))), "")
+	}
+	for _, r := range results {
+		key := tview.Escape(itemKey(r.Key, keyAttrs))
+		switch {
+		case r.New == nil && r.Old == nil:
+			lines = append(lines, fmt.Sprintf("[#ff453a]- %s[white] (no item was stored)", key))
+		case r.New == nil:
+			lines = append(lines, fmt.Sprintf("[#ff453a]- %s[white]", key),
+				tview.Escape("    before: "+jsonString(r.Old)))
+		case r.Old == nil:
+			lines = append(lines, fmt.Sprintf("[#30d158]+ %s[white] (new item)", key),
+				tview.Escape("    after:  "+jsonString(r.New)))
+		default:
+			changed := changedAttributes(r.Old, r.New)
+			summary := "no attributes changed"
+			if len(changed) > 0 {
+				summary = "changed: " + strings.Join(changed, ", ")
+			}
+			lines = append(lines, fmt.Sprintf("[#ffd60a]~ %s[white]", key),
+				tview.Escape("    before: "+jsonString(r.Old)),
+				tview.Escape("    after:  "+jsonString(r.New)),
+				tview.Escape("    "+summary))
+		}
+	}
+
+	view := tview.NewTextView().
+		SetText(strings.Join(lines, "\n")).
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true)
+
+	resultsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	resultsFlex.AddItem(tview.NewTextView().
+		SetText(fmt.Sprintf("Wrote %d items to %s, old images returned by DynamoDB (ESC: close)", len(results), tableInfo.Name)).
+		SetTextAlign(tview.AlignCenter), 1, 0, false)
+	resultsFlex.AddItem(view, 0, 1, true)
+
+	resultsFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("editorwrites")
+			done()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("editorwrites", resultsFlex, true, true)
+	app.SetFocus(view)
+}
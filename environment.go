@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// environments are the environment names accepted in the config file
+var environments = []string{"dev", "staging", "prod"}
+
+// resolveEnvironment finds the environment of the session. An account ID
+// mapping wins over a profile mapping; without either, the profile name is
+// used when it names an environment.
+func resolveEnvironment(mapping map[string]string, profile, account string) (string, error) {
+	for _, key := range []string{account, profile} {
+		env, ok := mapping[key]
+		if key == "" || !ok {
+			continue
+		}
+		for _, known := range environments {
+			if env == known {
+				return env, nil
+			}
+		}
+		return "", fmt.Errorf("invalid tier %q for %s in tiers of config.json: must be dev, staging or prod", env, key)
+	}
+	for _, known := range environments {
+		if profile == known {
+			return profile, nil
+		}
+	}
+	return "", nil
+}
+
+// environmentColor is the accent that tells environments apart at a glance
+func environmentColor(env string) tcell.Color {
+	switch env {
+	case "dev":
+		return accentGreen
+	case "staging":
+		return accentOrange
+	case "prod":
+		return accentRed
+	}
+	return bgAccent
+}
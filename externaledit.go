@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// editorCommand is the editor an item is opened in: $VISUAL, then $EDITOR,
+// then vi. It may carry arguments, e.g. "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if strings.TrimSpace(editor) == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd
+}
+
+// editInExternalEditor reads an item again, opens it as JSON in the user's
+// editor with the screen suspended, and shows the changes saved there in the
+// editor review, which writes them on confirmation. applied receives the
+// item as written.
+func editInExternalEditor(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, rawItem map[string]interface{}, applied func(values map[string]interface{})) {
+	keyAttrs := []string{tableInfo.PartitionKey}
+	if tableInfo.SortKey != "" {
+		keyAttrs = append(keyAttrs, tableInfo.SortKey)
+	}
+	key := make(map[string]interface{}, len(keyAttrs))
+	for _, k := range keyAttrs {
+		v, ok := rawItem[k]
+		if !ok {
+			showMessageModal(pages, "externaleditorerror", fmt.Sprintf("The item has no %s, so it can't be read for editing.", k))
+			return
+		}
+		key[k] = v
+	}
+
+	// The item shown may be stale or projected by an index; edit what is stored
+	ctx := showLoadingModal(pages, "externaleditorloading", "Reading the item...")
+	var item aws.EditableItem
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		item, err = client.GetItemForEdit(ctx, tableInfo.Name, key)
+		return err
+	}, func(err error) {
+		pages.RemovePage("externaleditorloading")
+		if err != nil {
+			showMessageModal(pages, "externaleditorerror", fmt.Sprintf("Cannot edit item: %v", err))
+			return
+		}
+		jsonBytes, err := json.MarshalIndent(item.Values, "", "    ")
+		if err != nil {
+			showMessageModal(pages, "externaleditorerror", fmt.Sprintf("Error formatting item: %v", err))
+			return
+		}
+		file, err := os.CreateTemp("", "ddb-explorer-*.json")
+		if err == nil {
+			_, err = file.Write(append(jsonBytes, '\n'))
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			showMessageModal(pages, "externaleditorerror", fmt.Sprintf("Error writing the item for the editor: %v", err))
+			return
+		}
+		openItemFile(app, pages, client, tableInfo, keyAttrs, item, file.Name(), applied)
+	})
+}
+
+// openItemFile runs the editor on the item's file until what is saved there
+// is a valid item, or the edit is given up. The file is removed unless the
+// editor couldn't be run, so no edit is lost.
+func openItemFile(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, keyAttrs []string, item aws.EditableItem, path string, applied func(values map[string]interface{})) {
+	var runErr error
+	app.Suspend(func() {
+		runErr = editorCommand(path).Run()
+	})
+	if runErr != nil {
+		showMessageModal(pages, "externaleditorerror", fmt.Sprintf("Editor failed: %v\n\nThe item is in %s. Set $EDITOR to the editor to use.", runErr, path))
+		return
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		showMessageModal(pages, "externaleditorerror", fmt.Sprintf("Error reading the edited item: %v", err))
+		return
+	}
+	values, err := parseEditedItem(string(text), keyAttrs)
+	if err != nil {
+		// Back to the editor with the text as saved, rather than losing it
+		modal := tview.NewModal().
+			SetText(err.Error()).
+			AddButtons([]string{"Edit Again", "Discard"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				pages.RemovePage("externaleditorinvalid")
+				if buttonLabel == "Edit Again" {
+					openItemFile(app, pages, client, tableInfo, keyAttrs, item, path, applied)
+					return
+				}
+				os.Remove(path)
+			})
+		pages.AddPage("externaleditorinvalid", modal, true, true)
+		return
+	}
+	os.Remove(path)
+
+	if len(changedAttributes(item.Values, values)) == 0 {
+		status.notify("The item wasn't changed", noticeDuration)
+		return
+	}
+	edited := item
+	edited.Values = values
+	rows := []*editorRow{{item: edited, loaded: item.Values}}
+	showEditorReview(app, pages, client, tableInfo, keyAttrs, rows, func() {
+		applied(values)
+	})
+}
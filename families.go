@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Limits of a query fanned out across a table family
+const (
+	familyMaxTables = 100 // Tables a single query may span
+	familyMaxItems  = 100 // Items read from each table
+)
+
+// familyPattern matches tables sharded by a date suffix, such as
+// events_2024_05, events-2024-05-17 or events_20240517
+var familyPattern = regexp.MustCompile(`^(.+?)[_-](\d{4})[_-]?(\d{2})(?:[_-]?(\d{2}))?$`)
+
+// tableFamily is a group of tables that share a name prefix and differ only
+// by a monthly or daily date suffix
+type tableFamily struct {
+	prefix  string
+	daily   bool
+	members []familyMember // Oldest first
+}
+
+// familyMember is a table of a family and the first day it covers
+type familyMember struct {
+	name  string
+	start time.Time
+}
+
+// end returns the first day after the period a member covers
+func (f tableFamily) end(m familyMember) time.Time {
+	if f.daily {
+		return m.start.AddDate(0, 0, 1)
+	}
+	return m.start.AddDate(0, 1, 0)
+}
+
+// dateLayout is how dates of the family are written in the range inputs
+func (f tableFamily) dateLayout() string {
+	if f.daily {
+		return "2006-01-02"
+	}
+	return "2006-01"
+}
+
+// label names the family with a placeholder for the date suffix
+func (f tableFamily) label() string {
+	if f.daily {
+		return f.prefix + "_YYYY_MM_DD"
+	}
+	return f.prefix + "_YYYY_MM"
+}
+
+// findTableFamilies groups date-suffixed table names by prefix and
+// granularity. Prefixes with a single table aren't considered a family.
+func findTableFamilies(names []string) []tableFamily {
+	byKey := make(map[string]*tableFamily)
+	var keys []string
+	for _, name := range names {
+		m := familyPattern.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		daily := m[4] != ""
+		layout, value := "2006-01", m[2]+"-"+m[3]
+		if daily {
+			layout, value = "2006-01-02", value+"-"+m[4]
+		}
+		start, err := time.Parse(layout, value)
+		if err != nil {
+			continue // Not a real date, e.g. orders_1234_56
+		}
+
+		key := fmt.Sprintf("%s|%t", m[1], daily)
+		family, ok := byKey[key]
+		if !ok {
+			family = &tableFamily{prefix: m[1], daily: daily}
+			byKey[key] = family
+			keys = append(keys, key)
+		}
+		family.members = append(family.members, familyMember{name: name, start: start})
+	}
+
+	sort.Strings(keys)
+	var families []tableFamily
+	for _, key := range keys {
+		family := byKey[key]
+		if len(family.members) < 2 {
+			continue
+		}
+		sort.Slice(family.members, func(i, j int) bool {
+			return family.members[i].start.Before(family.members[j].start)
+		})
+		families = append(families, *family)
+	}
+	return families
+}
+
+// parseFamilyDate reads a range input as a month (2024-05) or a day
+// (2024-05-17) and returns the period it covers
+func parseFamilyDate(s string) (start, end time.Time, err error) {
+	if start, err = time.Parse("2006-01-02", s); err == nil {
+		return start, start.AddDate(0, 0, 1), nil
+	}
+	if start, err = time.Parse("2006-01", s); err == nil {
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM or YYYY-MM-DD", s)
+}
+
+// showTableFamilies lists the date-sharded table families of the account
+func showTableFamilies(app *tview.Application, pages *tview.Pages, client *aws.Client) {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+	families := findTableFamilies(names)
+	if len(families) == 0 {
+		showMessageModal(pages, "familyerror", "No date-sharded tables found. Tables are grouped when at least two share a prefix followed by a date, like events_2024_05.")
+		return
+	}
+
+	list := newDataTable().
+		SetFixed(1, 0)
+	for col, name := range []string{"Family", "Shards", "Tables", "From", "To"} {
+		list.SetCell(0, col, tview.NewTableCell(name).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for i, f := range families {
+		row := i + 1
+		shards := "monthly"
+		if f.daily {
+			shards = "daily"
+		}
+		first, last := f.members[0], f.members[len(f.members)-1]
+		list.SetCell(row, 0, tview.NewTableCell(rowPrefix("Family", row, len(families))+f.label()).SetTextColor(tview.Styles.PrimaryTextColor))
+		list.SetCell(row, 1, tview.NewTableCell(labeled("shards", shards)).SetTextColor(tview.Styles.PrimaryTextColor))
+		list.SetCell(row, 2, tview.NewTableCell(labeled("tables", formatNumber(int64(len(f.members))))).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
+		list.SetCell(row, 3, tview.NewTableCell(labeled("from", first.start.Format(f.dateLayout()))).SetTextColor(tview.Styles.PrimaryTextColor))
+		list.SetCell(row, 4, tview.NewTableCell(labeled("to", last.start.Format(f.dateLayout()))).SetTextColor(tview.Styles.PrimaryTextColor))
+	}
+	list.SetSelectable(true, false)
+	list.Select(1, 0)
+
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText("Date-sharded table families (Enter: query a date range | ESC: close)")
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(list, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("tablefamilies")
+			return nil
+		case tcell.KeyEnter:
+			if row, _ := list.GetSelection(); row > 0 && row <= len(families) {
+				openFamilyQuery(app, pages, client, families[row-1])
+			}
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("tablefamilies")
+	pages.AddPage("tablefamilies", flex, true, true)
+	app.SetFocus(list)
+}
+
+// openFamilyQuery describes the newest table of a family, whose key schema
+// is used for the whole family, and opens the family query form
+func openFamilyQuery(app *tview.Application, pages *tview.Pages, client *aws.Client, family tableFamily) {
+	newest := family.members[len(family.members)-1].name
+	ctx := showLoadingModal(pages, "familydescribing", fmt.Sprintf("Describing %s...", newest))
+
+	var info aws.TableInfo
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		info, err = client.DescribeTable(ctx, newest)
+		return err
+	}, func(err error) {
+		pages.RemovePage("familydescribing")
+		if err != nil {
+			showMessageModal(pages, "familyerror", fmt.Sprintf("Failed to describe %s: %v", newest, err))
+			return
+		}
+		createFamilyQueryPage(app, pages, client, family, info)
+	})
+}
+
+// createFamilyQueryPage shows a query form with a date range and runs the
+// query against every table of the family that falls in the range
+func createFamilyQueryPage(app *tview.Application, pages *tview.Pages, client *aws.Client, family tableFamily, keySchema aws.TableInfo) {
+	layout := family.dateLayout()
+	form := tview.NewForm()
+	form.AddInputField("From", family.members[0].start.Format(layout), 12, nil, nil)
+	fromField := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+	form.AddInputField("To", family.members[len(family.members)-1].start.Format(layout), 12, nil, nil)
+	toField := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+	form.AddInputField(fmt.Sprintf("Partition Key (%s)", keySchema.PartitionKey), "", 20, nil, nil)
+	pkField := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+
+	var skField *tview.InputField
+	var conditionDropDown *tview.DropDown
+	if keySchema.SortKey != "" {
+		form.AddInputField(fmt.Sprintf("Sort Key (%s)", keySchema.SortKey), "", 20, nil, nil)
+		skField = form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+		form.AddDropDown("Condition", []string{"=", "begins_with", "<", "<=", ">", ">="}, 0, nil)
+		conditionDropDown = form.GetFormItem(form.GetFormItemCount() - 1).(*tview.DropDown)
+	}
+
+	form.AddButton("Query", func() {
+		from, _, err := parseFamilyDate(fromField.GetText())
+		if err != nil {
+			showMessageModal(pages, "familyerror", err.Error())
+			return
+		}
+		_, to, err := parseFamilyDate(toField.GetText())
+		if err != nil {
+			showMessageModal(pages, "familyerror", err.Error())
+			return
+		}
+		msg := keyValueError(keySchema.PartitionKey, keySchema.AttributeTypes[keySchema.PartitionKey], pkField.GetText(), "")
+		if msg == "" && skField != nil && skField.GetText() != "" {
+			_, condition := conditionDropDown.GetCurrentOption()
+			msg = keyValueError(keySchema.SortKey, keySchema.AttributeTypes[keySchema.SortKey], skField.GetText(), condition)
+		}
+		if msg != "" {
+			showMessageModal(pages, "familyerror", msg)
+			return
+		}
+
+		// Tables whose period overlaps the range, oldest first
+		var queries []aws.QueryParams
+		var labels []string
+		for _, m := range family.members {
+			if m.start.Before(to) && family.end(m).After(from) {
+				params := aws.QueryParams{
+					TableName:        m.name,
+					PartitionKey:     keySchema.PartitionKey,
+					PartitionValue:   pkField.GetText(),
+					PartitionKeyType: keySchema.AttributeTypes[keySchema.PartitionKey],
+				}
+				if skField != nil && skField.GetText() != "" {
+					params.SortKey = keySchema.SortKey
+					params.SortValue = skField.GetText()
+					params.SortKeyType = keySchema.AttributeTypes[keySchema.SortKey]
+					_, params.Condition = conditionDropDown.GetCurrentOption()
+				}
+				queries = append(queries, params)
+				labels = append(labels, m.name)
+			}
+		}
+		switch {
+		case len(queries) == 0:
+			showMessageModal(pages, "familyerror", "No tables of the family fall in this date range.")
+			return
+		case len(queries) > familyMaxTables:
+			showMessageModal(pages, "familyerror", fmt.Sprintf("The range spans %d tables; narrow it to at most %d.", len(queries), familyMaxTables))
+			return
+		}
+
+		ctx := showLoadingModal(pages, "familyloading", fmt.Sprintf("Querying %d tables...", len(queries)))
+		var result aws.QueryResult
+		runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+			var err error
+			result, err = client.FanOutQuery(ctx, queries, labels, familyMaxItems)
+			return err
+		}, func(err error) {
+			pages.RemovePage("familyloading")
+			if err != nil {
+				showMessageModal(pages, "familyerror", fmt.Sprintf("Query error: %v", err))
+				return
+			}
+			opts := ui.ResultsOptions{
+				PageName:     "familyresult",
+				Title:        fmt.Sprintf("Results for %s (%d tables)", family.label(), len(queries)),
+				SourceColumn: "Table",
+			}
+			showResultsPage(app, pages, client, keySchema, opts, result, false)
+		})
+	})
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Query %s (ESC: back) ", family.label())).
+		SetTitleAlign(tview.AlignLeft)
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("familyquery")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("familyquery")
+	pages.AddPage("familyquery", form, true, true)
+	app.SetFocus(form)
+}
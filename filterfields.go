@@ -0,0 +1,75 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// attributeFilter is a filter on one attribute as entered in the Query and
+// Scan tabs. An empty attribute filters nothing.
+type attributeFilter struct {
+	attribute string
+	condition string // One of aws.FilterConditions
+	value     string
+}
+
+// problem says why the filter can't be sent, or returns "" when it can
+func (f attributeFilter) problem() string {
+	if f.attribute == "" {
+		return ""
+	}
+	if err := aws.CheckFilter(f.condition, f.value); err != nil {
+		return "Filter: " + err.Error()
+	}
+	return ""
+}
+
+// filterValueHint is the placeholder of the value field for a condition
+func filterValueHint(condition string) string {
+	switch {
+	case !aws.FilterTakesValue(condition):
+		return "(no value)"
+	case condition == "IN":
+		return "a, b, c"
+	case condition == "attribute_type":
+		return strings.Join(aws.TypeNames, " ")
+	case strings.HasPrefix(condition, "size "):
+		return "whole number"
+	}
+	return ""
+}
+
+// addFilterFields adds the Filter Attribute, Filter Condition and Filter
+// Value fields to a form, filled with filter. The attribute field completes
+// the attribute names of the table. changed receives the filter whenever one
+// of the fields changes.
+func addFilterFields(form *tview.Form, client *aws.Client, tableInfo aws.TableInfo, filter attributeFilter, changed func(attributeFilter)) {
+	form.AddInputField("Filter Attribute", filter.attribute, 20, nil, nil)
+	attrField := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+	completeTableAttributes(attrField, client, tableInfo)
+	condition := max(0, slices.Index(aws.FilterConditions, filter.condition))
+	form.AddDropDown("Filter Condition", aws.FilterConditions, condition, nil)
+	conditionDropDown := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.DropDown)
+	form.AddInputField("Filter Value", filter.value, 20, nil, nil)
+	valueField := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+	valueField.SetPlaceholder(filterValueHint(aws.FilterConditions[condition]))
+	valueField.SetPlaceholderTextColor(textSecondary)
+
+	current := func() attributeFilter {
+		_, condition := conditionDropDown.GetCurrentOption()
+		return attributeFilter{
+			attribute: strings.TrimSpace(attrField.GetText()),
+			condition: condition,
+			value:     valueField.GetText(),
+		}
+	}
+	attrField.SetChangedFunc(func(string) { changed(current()) })
+	valueField.SetChangedFunc(func(string) { changed(current()) })
+	conditionDropDown.SetSelectedFunc(func(option string, _ int) {
+		valueField.SetPlaceholder(filterValueHint(option))
+		changed(current())
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"ddb-explorer/aws"
+)
+
+// fuzzyScore matches query against name as a case-insensitive subsequence,
+// so "usrevt" finds user_events. Runs of consecutive characters and
+// characters that start a word (after _, -, . or at a camelCase hump) score
+// higher, and shorter names win ties. ok is false when name doesn't match.
+func fuzzyScore(query, name string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	n := []rune(name)
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	matched := 0
+	consecutive := false
+	for i, c := range n {
+		if unicode.ToLower(c) != q[matched] {
+			consecutive = false
+			continue
+		}
+		score++
+		if consecutive {
+			score += 2
+		}
+		if i == 0 || strings.ContainsRune("_-.", n[i-1]) || (unicode.IsUpper(c) && unicode.IsLower(n[i-1])) {
+			score += 3
+		}
+		consecutive = true
+		matched++
+		if matched == len(q) {
+			return score*100 - len(n), true
+		}
+	}
+	return 0, false
+}
+
+// fuzzyFilterTables returns the tables whose names match query, best match
+// first. Equal scores keep the list order.
+func fuzzyFilterTables(tables []aws.TableInfo, query string) []aws.TableInfo {
+	type match struct {
+		table aws.TableInfo
+		score int
+	}
+	var matches []match
+	for _, t := range tables {
+		if score, ok := fuzzyScore(query, t.Name); ok {
+			matches = append(matches, match{t, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	filtered := make([]aws.TableInfo, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.table
+	}
+	return filtered
+}
+
+// closeTableNames returns up to n names that look like name, most similar
+// first: names within a few edits of it, or that contain it or are contained
+// in it, as when a table is renamed to orders_v2
+func closeTableNames(name string, names []string, n int) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	lower := strings.ToLower(name)
+	maxDistance := max(3, len(name)/3)
+	var candidates []candidate
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		lowerOther := strings.ToLower(other)
+		d := editDistance(lower, lowerOther)
+		if d <= maxDistance || strings.Contains(lowerOther, lower) || strings.Contains(lower, lowerOther) {
+			candidates = append(candidates, candidate{other, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var similar []string
+	for _, c := range candidates[:min(n, len(candidates))] {
+		similar = append(similar, c.name)
+	}
+	return similar
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
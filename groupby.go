@@ -0,0 +1,84 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// missingGroup is the group of the items without the attribute grouped by
+var missingGroup = aggregateValue{}
+
+// groupLabel is how a group is named in the summary and the results header
+func groupLabel(a aggregate, value aggregateValue) string {
+	if value == missingGroup {
+		return "(not set)"
+	}
+	label := ui.TruncateCell(value.text, ui.MaxCellLength)
+	if len(a.types) > 1 {
+		label += fmt.Sprintf(" (%s)", value.kind)
+	}
+	return label
+}
+
+// showGroups shows how many of the loaded items hold each value of an
+// attribute, the largest group first. Enter on a group calls pick with its
+// value, missingGroup for the items without the attribute.
+func showGroups(app *tview.Application, pages *tview.Pages, title, attrTitle string, a aggregate, pick func(value aggregateValue)) {
+	groups := a.topValues(len(a.values))
+	if missing := a.items - a.present; missing > 0 {
+		groups = append(groups, missingGroup)
+	}
+	count := func(value aggregateValue) int {
+		if value == missingGroup {
+			return a.items - a.present
+		}
+		return a.values[value]
+	}
+
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("%s grouped by %s: %s groups (Enter: show the group | ESC: close)",
+			tview.Escape(title), tview.Escape(attrTitle), formatNumber(int64(len(groups)))))
+	table := newDataTable()
+	for col, text := range []string{tview.Escape(attrTitle), "Items", "Share"} {
+		table.SetCell(0, col, tview.NewTableCell(text).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for i, value := range groups {
+		n := count(value)
+		table.SetCell(i+1, 0, tview.NewTableCell(rowPrefix("Group", i+1, len(groups))+labeled(a.attr, tview.Escape(groupLabel(a, value)))))
+		table.SetCell(i+1, 1, tview.NewTableCell(labeled("items", formatNumber(int64(n)))).
+			SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 2, tview.NewTableCell(labeled("share", formatStat(float64(n)*100/float64(a.items))+"%")).
+			SetAlign(tview.AlignRight))
+	}
+	table.SetFixed(1, 0)
+	table.Select(1, 0)
+	table.SetSelectedFunc(func(row, column int) {
+		if row < 1 || row > len(groups) {
+			return
+		}
+		pages.RemovePage("groupby")
+		pick(groups[row-1])
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(table, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("groupby")
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("groupby", flex, true, true)
+	app.SetFocus(table)
+}
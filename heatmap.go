@@ -0,0 +1,69 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"math"
+
+	"github.com/rivo/tview"
+)
+
+// heatmapLevels are the glyphs for increasing activity. The shade as well as
+// the color carries the level, so the heatmap also reads in monochrome.
+var heatmapLevels = []rune(" ░▒▓█")
+
+// heatmap renders hourly values as a strip of shaded blocks. Levels use a log
+// scale against peak so quiet tables still show up next to busy ones.
+func heatmap(values []float64, peak float64) string {
+	strip := make([]rune, len(values))
+	top := len(heatmapLevels) - 1
+	for i, v := range values {
+		level := 0
+		if v > 0 && peak > 0 {
+			level = int(math.Ceil(math.Log1p(v) / math.Log1p(peak) * float64(top)))
+			level = min(max(level, 1), top)
+		}
+		strip[i] = heatmapLevels[level]
+	}
+	return string(strip)
+}
+
+// activityPeak returns the highest hourly value across all tables, used to
+// put every heatmap on the same scale
+func activityPeak(activity map[string]aws.TableActivity) float64 {
+	peak := 0.0
+	for _, a := range activity {
+		for _, v := range a.Reads {
+			peak = max(peak, v)
+		}
+		for _, v := range a.Writes {
+			peak = max(peak, v)
+		}
+	}
+	return peak
+}
+
+// sum adds up hourly values
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// setActivityCells fills the read and write heatmap columns of a table list row.
+// In screen reader mode the daily totals are shown instead of the blocks.
+func setActivityCells(table *tview.Table, row, col int, activity aws.TableActivity, ok bool, peak float64) {
+	reads, writes := "loading…", "loading…"
+	if ok {
+		if *screenReader {
+			reads = formatNumber(int64(math.Round(sum(activity.Reads))))
+			writes = formatNumber(int64(math.Round(sum(activity.Writes))))
+		} else {
+			reads = heatmap(activity.Reads, peak)
+			writes = heatmap(activity.Writes, peak)
+		}
+	}
+	table.SetCell(row, col, tview.NewTableCell(labeled("reads", reads)).SetTextColor(accentTeal))
+	table.SetCell(row, col+1, tview.NewTableCell(labeled("writes", writes)).SetTextColor(accentOrange))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// idleLock blanks the screen after a period without key presses so data
+// isn't left on display. Sessions that require re-authentication only
+// unlock once the user passed an SSO login or entered a new MFA code.
+type idleLock struct {
+	app     *tview.Application
+	pages   *tview.Pages
+	client  *aws.Client
+	timeout time.Duration
+	reauth  bool
+	noAuth  bool          // Re-authentication was asked for but the credentials can't challenge the user
+	done    chan struct{} // Closed by stop
+
+	// Only accessed from the UI goroutine
+	lastInput time.Time
+	locked    bool
+	unlocking bool
+	focus     tview.Primitive // Focused before locking
+	message   *tview.TextView
+	covered   map[string]bool // Pages present when locking, hidden until unlocked
+	restore   []string        // Covered pages to show again when unlocking
+}
+
+// canReauthenticate reports whether unlocking can prove who is at the
+// keyboard: SSO profiles log in again and roles assumed with --mfa-serial ask
+// for a new code. Static keys, or a role without MFA, would renew silently.
+func canReauthenticate(client *aws.Client) bool {
+	return client.UsesSSO() || client.MFASerial() != ""
+}
+
+// startIdleLock starts watching for idle time. reauth requires an SSO login
+// or MFA code to unlock, which is used for prod sessions; it is refused for
+// credentials that can't ask for either, which unlock with any key instead.
+func startIdleLock(app *tview.Application, pages *tview.Pages, client *aws.Client, timeout time.Duration, reauth bool) *idleLock {
+	l := &idleLock{app: app, pages: pages, client: client, timeout: timeout, lastInput: time.Now(), done: make(chan struct{})}
+	if reauth {
+		l.reauth = canReauthenticate(client)
+		l.noAuth = !l.reauth
+	}
+	go func() {
+		defer recoverPanic()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.done:
+				return
+			case <-ticker.C:
+			}
+			app.QueueUpdate(func() {
+				if !l.locked && time.Since(l.lastInput) >= l.timeout {
+					l.lock()
+					app.ForceDraw() // Draw would wait for this update to finish
+				}
+			})
+		}
+	}()
+	return l
+}
+
+// stop ends the watch once the application stopped, so a following session
+// starts its own
+func (l *idleLock) stop() {
+	close(l.done)
+}
+
+// handleKey records activity and, while locked, swallows keys and starts
+// unlocking. It is called first by the application's input capture.
+func (l *idleLock) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	l.lastInput = time.Now()
+	if !l.locked || l.unlocking {
+		return event
+	}
+	if !l.reauth {
+		l.unlock()
+		return nil
+	}
+	l.unlocking = true
+	l.message.SetText("Re-authenticating...")
+	l.renewCredentials()
+	return nil
+}
+
+// lock hides every page behind a blank screen. The pages are hidden rather
+// than covered because tview doesn't clear the cells around the message.
+func (l *idleLock) lock() {
+	l.locked = true
+	l.focus = l.app.GetFocus()
+	l.covered = make(map[string]bool)
+	l.restore = l.pages.GetPageNames(true)
+	for _, name := range l.pages.GetPageNames(false) {
+		l.covered[name] = true
+	}
+
+	prompt := "Press any key to resume."
+	switch {
+	case l.reauth:
+		prompt = "Press any key to re-authenticate and resume."
+	case l.noAuth:
+		prompt = "Press any key to resume.\n[gray]Re-authentication needs an SSO profile or --mfa-serial.[-]"
+	}
+	l.message = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	l.message.SetText(fmt.Sprintf("Locked after %s without input.\n\n%s", l.timeout, prompt))
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(l.message, 5, 0, true).
+		AddItem(nil, 0, 1, false)
+	l.pages.AddPage("idlelock", layout, true, true)
+
+	// Background work such as a finished table list load switches pages
+	// while locked, so coverPages hides them again whenever the pages change
+	l.coverPages()
+	l.app.SetFocus(layout)
+}
+
+// coverPages hides the pages that were present when locking and keeps the
+// lock screen shown. Pages added since, like the MFA prompt, stay visible.
+func (l *idleLock) coverPages() {
+	l.pages.SetChangedFunc(nil) // Hiding calls back
+	defer func() {
+		if l.locked {
+			l.pages.SetChangedFunc(l.coverPages)
+		}
+	}()
+	for _, name := range l.pages.GetPageNames(true) {
+		if l.covered[name] {
+			l.pages.HidePage(name)
+			if !slices.Contains(l.restore, name) {
+				l.restore = append(l.restore, name) // Shown while locked
+			}
+		}
+	}
+	if l.pages.HasPage("idlelock") {
+		l.pages.ShowPage("idlelock")
+	}
+}
+
+// unlock removes the lock screen, shows the hidden pages again and restores
+// the focus
+func (l *idleLock) unlock() {
+	l.locked = false
+	l.unlocking = false
+	l.lastInput = time.Now()
+	l.pages.SetChangedFunc(nil)
+	l.pages.RemovePage("idlelock")
+	for _, name := range l.restore {
+		l.pages.ShowPage(name)
+	}
+	l.covered, l.restore = nil, nil
+	if l.focus != nil {
+		l.app.SetFocus(l.focus)
+	}
+}
+
+// renewCredentials re-runs the SSO login for SSO profiles, then reloads the
+// credentials and checks them. With --mfa-serial the role is assumed again,
+// which asks for a new MFA code; canReauthenticate guarantees one of the two.
+func (l *idleLock) renewCredentials() {
+	fail := func(err error) {
+		l.unlocking = false
+		l.message.SetText(fmt.Sprintf("[#ff453a]%s[white]\n\nPress any key to try again.", tview.Escape(err.Error())))
+	}
+
+	if l.client.UsesSSO() {
+		var loginErr error
+		l.app.Suspend(func() {
+			cmd := l.client.SSOLoginCommand()
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			loginErr = cmd.Run()
+		})
+		if loginErr != nil {
+			fail(fmt.Errorf("SSO login failed: %w", loginErr))
+			return
+		}
+	}
+	if err := l.client.RefreshCredentials(); err != nil {
+		fail(err)
+		return
+	}
+	go func() {
+		defer recoverPanic()
+		err := l.client.TestConnection(context.Background())
+		l.app.QueueUpdateDraw(func() {
+			if err != nil {
+				fail(err)
+				return
+			}
+			l.unlock()
+		})
+	}()
+}
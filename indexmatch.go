@@ -0,0 +1,201 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// maxIndexChoices is the number of indexes offered as buttons when a
+// partition key value fits several
+const maxIndexChoices = 3
+
+// keyTypeNames spells out the attribute types of key attributes
+var keyTypeNames = map[string]string{"S": "string", "N": "number", "B": "binary"}
+
+// numberPattern is the text DynamoDB accepts as a number: no NaN, Inf or
+// hexadecimal, which strconv would parse
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// fitsKeyType reports whether a value entered as text can be a value of a
+// key attribute of the given type. Values of unknown types fit.
+func fitsKeyType(value, attrType string) bool {
+	switch attrType {
+	case "N":
+		return numberPattern.MatchString(strings.TrimSpace(value))
+	case "B":
+		_, err := base64.StdEncoding.DecodeString(value)
+		return err == nil
+	}
+	return true
+}
+
+// keyValueError explains why a key value typed in the query form can't be
+// sent: it doesn't fit the type of its attribute in AttributeDefinitions,
+// or the sort key condition can't compare that type. It is empty for values
+// that can be sent.
+func keyValueError(attr, attrType, value, condition string) string {
+	if !fitsKeyType(value, attrType) {
+		if attrType == "B" {
+			return fmt.Sprintf("%s is binary: enter its value as base64", attr)
+		}
+		return fmt.Sprintf("%q isn't a number, which %s is", value, attr)
+	}
+	if condition == "begins_with" && attrType == "N" {
+		return fmt.Sprintf("begins_with only compares strings and binary values, and %s is a number", attr)
+	}
+	return ""
+}
+
+// matchingIndexes returns the positions in tableInfo.Indexes of the GSIs a
+// partition key value entered for the base table was likely meant for: when
+// the value can't be the table's partition key, by the types of
+// AttributeDefinitions, the active GSIs whose partition key it can be.
+func matchingIndexes(tableInfo aws.TableInfo, value string) []int {
+	tableType := tableInfo.AttributeTypes[tableInfo.PartitionKey]
+	if value == "" || fitsKeyType(value, tableType) {
+		return nil
+	}
+	var matches []int
+	for i, idx := range tableInfo.Indexes {
+		if idx.Local || idx.Status != "ACTIVE" || idx.PartitionKey == tableInfo.PartitionKey {
+			continue
+		}
+		if fitsKeyType(value, tableInfo.AttributeTypes[idx.PartitionKey]) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// offerMatchingIndex explains that a partition key value can't be the
+// table's and offers to query one of the matching indexes instead. query is
+// called with the chosen position in tableInfo.Indexes, or -1 to query the
+// table anyway.
+func offerMatchingIndex(pages *tview.Pages, tableInfo aws.TableInfo, value string, withSortKey bool, matches []int, query func(index int)) {
+	tableType := tableInfo.AttributeTypes[tableInfo.PartitionKey]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q isn't a %s, which the partition key %s of %s is, so querying the table would fail.\n\n",
+		value, keyTypeNames[tableType], tableInfo.PartitionKey, tableInfo.Name)
+	if len(matches) == 1 {
+		idx := tableInfo.Indexes[matches[0]]
+		fmt.Fprintf(&b, "It fits the partition key %s of the index %s. Query the index instead?", idx.PartitionKey, idx.Name)
+	} else {
+		b.WriteString("It fits the partition keys of these indexes:\n")
+		for _, i := range matches {
+			idx := tableInfo.Indexes[i]
+			fmt.Fprintf(&b, "%s (%s)\n", idx.Name, idx.PartitionKey)
+		}
+		if len(matches) > maxIndexChoices {
+			fmt.Fprintf(&b, "The first %d are offered, pick others from the Index list.\n", maxIndexChoices)
+		}
+		b.WriteString("Query one of them instead?")
+	}
+	if withSortKey {
+		b.WriteString("\nThe sort key condition is left out, the index has its own sort key.")
+	}
+
+	var buttons []string
+	for _, i := range matches[:min(len(matches), maxIndexChoices)] {
+		buttons = append(buttons, tableInfo.Indexes[i].Name)
+	}
+	buttons = append(buttons, "Query Table", "Cancel")
+	modal := tview.NewModal().
+		SetText(b.String()).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("indexmatch")
+			switch {
+			case buttonLabel == "Query Table":
+				query(-1)
+			case buttonIndex >= 0 && buttonIndex < min(len(matches), maxIndexChoices):
+				query(matches[buttonIndex])
+			}
+		})
+	pages.AddPage("indexmatch", modal, true, true)
+}
+
+// partitionKeyTargets returns where attr is the partition key: -1 for the
+// table, then the positions in tableInfo.Indexes of the active GSIs. LSIs
+// share the table's partition key and are left to the Index list.
+func partitionKeyTargets(tableInfo aws.TableInfo, attr string) []int {
+	var targets []int
+	if tableInfo.PartitionKey == attr {
+		targets = append(targets, -1)
+	}
+	for i, idx := range tableInfo.Indexes {
+		if !idx.Local && idx.Status == "ACTIVE" && idx.PartitionKey == attr {
+			targets = append(targets, i)
+		}
+	}
+	return targets
+}
+
+// splitKeyAttribute reads a partition key value typed as attribute=value,
+// e.g. email=jane@example.com, which names the key the value is for. Text
+// whose part before the "=" isn't the partition key of the table or of an
+// index is a plain value.
+func splitKeyAttribute(tableInfo aws.TableInfo, text string) (attr, value string, ok bool) {
+	attr, value, found := strings.Cut(text, "=")
+	attr = strings.TrimSpace(attr)
+	if !found || len(partitionKeyTargets(tableInfo, attr)) == 0 {
+		return "", text, false
+	}
+	return attr, strings.TrimSpace(value), true
+}
+
+// offerKeyAttributeTargets queries where attr is the partition key instead
+// of the table or index the form is on: right away when that is one place,
+// otherwise after asking which. query is called with -1 for the table or a
+// position in tableInfo.Indexes.
+func offerKeyAttributeTargets(pages *tview.Pages, tableInfo aws.TableInfo, attr string, withSortKey bool, query func(index int)) {
+	targets := partitionKeyTargets(tableInfo, attr)
+	targetName := func(i int) string {
+		if i < 0 {
+			return "Table"
+		}
+		return tableInfo.Indexes[i].Name
+	}
+	if len(targets) == 1 {
+		notice := "Querying the table"
+		if targets[0] >= 0 {
+			notice = "Querying the index " + tview.Escape(targetName(targets[0]))
+		}
+		notice += fmt.Sprintf(", where %s is the partition key", tview.Escape(attr))
+		if withSortKey {
+			notice += "; the sort key condition is left out"
+		}
+		status.notify(notice, noticeDuration)
+		query(targets[0])
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is the partition key of several indexes. Query which one?", attr)
+	if withSortKey {
+		b.WriteString("\n\nThe sort key condition is left out, each index has its own sort key.")
+	}
+	shown := targets[:min(len(targets), maxIndexChoices)]
+	if len(targets) > maxIndexChoices {
+		fmt.Fprintf(&b, "\n\nThe first %d are offered, pick others from the Index list.", maxIndexChoices)
+	}
+	var buttons []string
+	for _, i := range shown {
+		buttons = append(buttons, targetName(i))
+	}
+	buttons = append(buttons, "Cancel")
+	modal := tview.NewModal().
+		SetText(b.String()).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("indexmatch")
+			if buttonIndex >= 0 && buttonIndex < len(shown) {
+				query(shown[buttonIndex])
+			}
+		})
+	pages.AddPage("indexmatch", modal, true, true)
+}
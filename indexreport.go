@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Bounds of --unused-days. CloudWatch keeps daily datapoints for 455 days.
+const (
+	defaultUnusedDays = 30
+	maxUnusedDays     = 455
+)
+
+// showIndexReport loads the consumed capacity of every GSI of a table and
+// opens the utilization report
+func showIndexReport(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo) {
+	// LSIs use the table's capacity, so the report covers the GSIs only
+	tableInfo.Indexes = tableInfo.GlobalIndexes()
+	if len(tableInfo.Indexes) == 0 {
+		showMessageModal(pages, "indexreporterror", fmt.Sprintf("%s has no global secondary indexes.", tableInfo.Name))
+		return
+	}
+
+	ctx := showLoadingModal(pages, "indexreportloading", fmt.Sprintf("Loading index metrics for %s...", tableInfo.Name))
+
+	var usage map[string]aws.IndexUsage
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		usage, err = client.IndexUtilization(ctx, tableInfo, *unusedDays)
+		return err
+	}, func(err error) {
+		pages.RemovePage("indexreportloading")
+		if err != nil {
+			showMessageModal(pages, "indexreporterror", fmt.Sprintf("Failed to load index metrics: %v", err))
+			return
+		}
+		createIndexReportPage(app, pages, tableInfo, usage)
+	})
+}
+
+// createIndexReportPage lists each GSI with its daily reads and writes, how
+// much of its provisioned capacity was used and whether it was read at all
+func createIndexReportPage(app *tview.Application, pages *tview.Pages, tableInfo aws.TableInfo, usage map[string]aws.IndexUsage) {
+	report := newDataTable().
+		SetFixed(1, 0)
+
+	columns := []string{"Index", "Capacity", "Reads", "Read Usage", "Writes", "Write Usage", "Last Read"}
+	for col, name := range columns {
+		report.SetCell(0, col, tview.NewTableCell(name).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+
+	// All strips share one scale so busy and idle indexes can be compared
+	peak := 0.0
+	for _, u := range usage {
+		for _, v := range u.Reads {
+			peak = max(peak, v)
+		}
+		for _, v := range u.Writes {
+			peak = max(peak, v)
+		}
+	}
+
+	unused := 0
+	for i, idx := range tableInfo.Indexes {
+		row := i + 1
+		u := usage[idx.Name]
+
+		name := idx.Name
+		color := tview.Styles.PrimaryTextColor
+		if sum(u.Reads) == 0 {
+			unused++
+			name += " (unused)"
+			color = accentRed
+		}
+		capacity := "on-demand"
+		if idx.ReadCapacity > 0 || idx.WriteCapacity > 0 {
+			capacity = fmt.Sprintf("%s RCU / %s WCU", formatNumber(idx.ReadCapacity), formatNumber(idx.WriteCapacity))
+		}
+		reads, writes := heatmap(u.Reads, peak), heatmap(u.Writes, peak)
+		if *screenReader {
+			reads = formatNumber(int64(math.Round(sum(u.Reads))))
+			writes = formatNumber(int64(math.Round(sum(u.Writes))))
+		}
+		lastRead := "never"
+		if !u.LastRead.IsZero() {
+			lastRead = formatDate(u.LastRead)
+		}
+
+		report.SetCell(row, 0, tview.NewTableCell(rowPrefix("Index", row, len(tableInfo.Indexes))+tview.Escape(name)).SetTextColor(color))
+		report.SetCell(row, 1, tview.NewTableCell(labeled("capacity", capacity)).SetTextColor(tview.Styles.PrimaryTextColor))
+		report.SetCell(row, 2, tview.NewTableCell(labeled("reads", reads)).SetTextColor(accentTeal))
+		report.SetCell(row, 3, tview.NewTableCell(labeled("read usage", capacityUsage(u.Reads, idx.ReadCapacity))).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
+		report.SetCell(row, 4, tview.NewTableCell(labeled("writes", writes)).SetTextColor(accentOrange))
+		report.SetCell(row, 5, tview.NewTableCell(labeled("write usage", capacityUsage(u.Writes, idx.WriteCapacity))).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
+		report.SetCell(row, 6, tview.NewTableCell(labeled("last read", lastRead)).SetTextColor(color).SetAlign(tview.AlignRight))
+	}
+
+	summary := fmt.Sprintf("%d of %d indexes had no reads", unused, len(tableInfo.Indexes))
+	if unused == 0 {
+		summary = "every index was read"
+	}
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Index utilization of %s over the last %d days: %s (ESC: close)", tableInfo.Name, *unusedDays, summary))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(report, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("indexreport")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("indexreport")
+	pages.AddPage("indexreport", flex, true, true)
+	app.SetFocus(report)
+}
+
+// capacityUsage describes consumed capacity units against the provisioned
+// capacity as the average and busiest day's share, or the total consumed
+// units for on-demand tables
+func capacityUsage(daily []float64, provisioned int64) string {
+	if provisioned == 0 {
+		return formatNumber(int64(math.Round(sum(daily)))) + " units"
+	}
+	perDay := float64(provisioned) * 24 * 60 * 60
+	busiest := 0.0
+	for _, v := range daily {
+		busiest = max(busiest, v)
+	}
+	percent := func(v float64) string {
+		return strings.Replace(fmt.Sprintf("%.1f%%", v/perDay*100), ".", displayLocale.decimal, 1)
+	}
+	return fmt.Sprintf("avg %s, peak %s", percent(sum(daily)/float64(len(daily))), percent(busiest))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ddb-explorer/aws"
+)
+
+// inventoryRecord is a table of the inventory report as written to JSON
+type inventoryRecord struct {
+	Name                string            `json:"name"`
+	Status              string            `json:"status"`
+	ItemCount           int64             `json:"itemCount"`
+	SizeBytes           int64             `json:"sizeBytes"`
+	BillingMode         string            `json:"billingMode"`
+	ReadCapacity        int64             `json:"readCapacity"`
+	WriteCapacity       int64             `json:"writeCapacity"`
+	PartitionKey        string            `json:"partitionKey"`
+	SortKey             string            `json:"sortKey,omitempty"`
+	GSIs                []string          `json:"gsis"`
+	Tags                map[string]string `json:"tags"`
+	PointInTimeRecovery bool              `json:"pointInTimeRecovery"`
+	Stream              string            `json:"stream,omitempty"`
+	CreatedAt           time.Time         `json:"createdAt"`
+}
+
+// inventoryColumns is the CSV header of the inventory report
+var inventoryColumns = []string{
+	"name", "status", "item_count", "size_bytes", "billing_mode", "read_capacity", "write_capacity",
+	"partition_key", "sort_key", "gsis", "tags", "pitr", "stream", "created_at",
+}
+
+// runInventory implements the export-inventory subcommand. It describes
+// every table of the account and writes one record per table as CSV or JSON.
+func runInventory(args []string) int {
+	fs := flag.NewFlagSet("export-inventory", flag.ContinueOnError)
+	profile := fs.String("profile", "dev", "AWS profile to use")
+	roleARN := fs.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+	mfaSerial := fs.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+	format := fs.String("format", "", "Output format: csv or json (default: from the --output extension, otherwise csv)")
+	output := fs.String("output", "", "Write the report to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ddb-explorer export-inventory [OPTIONS]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(*output), ".json") {
+			*format = "json"
+		}
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Invalid format: %s. Must be 'csv' or 'json'\n", *format)
+		return 1
+	}
+
+	client, err := newHeadlessClient(*profile, *roleARN, *mfaSerial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create AWS client: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintln(os.Stderr, "Describing tables...")
+	entries, err := client.Inventory(ctx, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\r%d/%d tables", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build inventory: %v\n", err)
+		return 1
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "json" {
+		err = writeInventoryJSON(out, entries)
+	} else {
+		err = writeInventoryCSV(out, entries)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write inventory: %v\n", err)
+		return 1
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d tables to %s\n", len(entries), *output)
+	}
+	return 0
+}
+
+// newInventoryRecord flattens an inventory entry for the report
+func newInventoryRecord(e aws.InventoryEntry) inventoryRecord {
+	global := e.GlobalIndexes()
+	gsis := make([]string, len(global))
+	for i, idx := range global {
+		gsis[i] = idx.Name
+	}
+	return inventoryRecord{
+		Name:                e.Name,
+		Status:              e.Status,
+		ItemCount:           e.ItemCount,
+		SizeBytes:           e.SizeBytes,
+		BillingMode:         e.BillingMode,
+		ReadCapacity:        e.ReadCapacity,
+		WriteCapacity:       e.WriteCapacity,
+		PartitionKey:        e.PartitionKey,
+		SortKey:             e.SortKey,
+		GSIs:                gsis,
+		Tags:                e.Tags,
+		PointInTimeRecovery: e.PointInTimeRecovery,
+		Stream:              e.StreamView,
+		CreatedAt:           e.CreatedAt.UTC(),
+	}
+}
+
+// writeInventoryJSON writes the inventory as an indented JSON array
+func writeInventoryJSON(w io.Writer, entries []aws.InventoryEntry) error {
+	records := make([]inventoryRecord, len(entries))
+	for i, e := range entries {
+		records[i] = newInventoryRecord(e)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// writeInventoryCSV writes the inventory with one row per table. Lists are
+// joined with semicolons and tags written as key=value pairs, sorted by key.
+// Numbers and dates are left unformatted so spreadsheets can parse them.
+func writeInventoryCSV(w io.Writer, entries []aws.InventoryEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(inventoryColumns); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		r := newInventoryRecord(e)
+		tagKeys := make([]string, 0, len(r.Tags))
+		for k := range r.Tags {
+			tagKeys = append(tagKeys, k)
+		}
+		sort.Strings(tagKeys)
+		tags := make([]string, len(tagKeys))
+		for i, k := range tagKeys {
+			tags[i] = k + "=" + r.Tags[k]
+		}
+
+		if err := cw.Write([]string{
+			r.Name,
+			r.Status,
+			strconv.FormatInt(r.ItemCount, 10),
+			strconv.FormatInt(r.SizeBytes, 10),
+			r.BillingMode,
+			strconv.FormatInt(r.ReadCapacity, 10),
+			strconv.FormatInt(r.WriteCapacity, 10),
+			r.PartitionKey,
+			r.SortKey,
+			strings.Join(r.GSIs, ";"),
+			strings.Join(tags, ";"),
+			strconv.FormatBool(r.PointInTimeRecovery),
+			r.Stream,
+			r.CreatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// keyBinding is a key of a page and what it does there. Bindings with an
+// action can be remapped under keys in config.yaml; navigation keys such as
+// the arrows, Enter, Tab and ESC can't.
+type keyBinding struct {
+	action string // e.g. "results.nextPage", empty for a fixed key
+	keys   string // Default keys, e.g. "Ctrl+N", several separated by spaces; written on screen as they are for fixed keys, e.g. "↑/↓"
+	help   string
+}
+
+// keyContext is a page, or a few pages that handle the same keys, with its
+// bindings. pages are the page names the '?' overlay recognizes it by.
+type keyContext struct {
+	title    string
+	pages    []string
+	bindings []keyBinding
+}
+
+// keymap lists the keys of every page, in the order of the Ctrl+H help. The
+// '?' overlay shows the section of the page in front, so a key added to a
+// page's input capture belongs here too.
+var keymap = []keyContext{
+	{title: "Table List", pages: []string{"tablelist"}, bindings: []keyBinding{
+		{"", "↑/↓", "Navigate tables"},
+		{"", "Enter", "Select table"},
+		{"tables.heatmap", "a", "Toggle activity heatmap"},
+		{"tables.autoRefresh", "Ctrl+R", "Toggle auto-refresh of item counts and sizes"},
+		{"tables.limits", "l", "Account limits"},
+		{"tables.families", "f", "Date-sharded table families"},
+		{"tables.details", "i", "Table details (keys, indexes, capacity, stream, TTL, tags)"},
+		{"tables.analyze", "n", "Analyze: attributes, types and fill rates of a sample"},
+		{"tables.exportS3", "x", "Export to S3 (not with --read-only)"},
+		{"tables.copy", "c", "Copy to another profile/region (not with --read-only)"},
+		{"tables.delete", "d", "Delete table (not with --read-only)"},
+		{"tables.dualWrite", "w", "Dual-write check by key"},
+		{"tables.switchProfile", "Ctrl+P", "Switch profile/environment"},
+		{"tables.filter", "/", "Fuzzy filter by name"},
+		{"tables.quit", "q", "Quit"},
+		{"", "ESC", "Quit"},
+	}},
+	{title: "Query/Scan View", pages: []string{"tableaction"}, bindings: []keyBinding{
+		{"", "Tab", "Navigate fields"},
+		{"query.queryTab", "Ctrl+Q", "Switch to Query tab"},
+		{"query.scanTab", "Ctrl+S", "Switch to Scan tab"},
+		{"query.unionTab", "Ctrl+U", "Switch to Union tab (tables with GSIs)"},
+		{"query.edit", "Ctrl+E", "Edit table (under 1,000 items, not with --read-only)"},
+		{"query.indexReport", "Ctrl+R", "Index utilization report"},
+		{"query.tailStream", "Ctrl+L", "Tail the table's stream"},
+		{"query.raw", "Ctrl+X", "Toggle raw expressions on the Query and Scan tabs"},
+		{"", "←/→", "Switch tabs"},
+		{"", "Enter", "Execute query/scan"},
+		{"", "ESC", "Cancel while loading"},
+		{"", "ESC", "Back to table list"},
+	}},
+	{title: "Table Editor", pages: []string{"tableeditor"}, bindings: []keyBinding{
+		{"editor.add", "a", "Add item"},
+		{"", "Enter", "Edit item as JSON"},
+		{"editor.edit", "e", "Edit item as JSON"},
+		{"editor.delete", "d", "Toggle delete"},
+		{"editor.review", "Ctrl+S", "Review and apply changes"},
+		{"", "ESC", "Close editor"},
+	}},
+	{title: "Item JSON Editor", pages: []string{"editoritem"}, bindings: []keyBinding{
+		{"itemEditor.save", "Ctrl+S", "Save the item"},
+		{"", "ESC", "Cancel"},
+	}},
+	{title: "Results View", pages: []string{"queryresult", "scanresult", "unionresult", "familyresult"}, bindings: []keyBinding{
+		{"", "↑/↓", "Navigate items"},
+		{"results.columnLeft", "Left h", "Column to the left, scrolling (key columns stay)"},
+		{"results.columnRight", "Right l", "Column to the right, scrolling"},
+		{"results.expandCell", "v", "Full value of the current column"},
+		{"results.sort", "o", "Sort loaded pages by the current column"},
+		{"results.groupBy", "G", "Group loaded pages by an attribute"},
+		{"results.widen", "+", "Widen the current column"},
+		{"results.narrow", "-", "Narrow the current column"},
+		{"results.autoFit", "=", "Auto-fit columns to the terminal"},
+		{"", "Enter", "View item (details, JSON or preview)"},
+		{"results.enterAction", "Ctrl+O", "Cycle Enter action"},
+		{"results.split", "s", "Split view: selected item next to the results"},
+		{"results.distribution", "Ctrl+K", "Numeric distribution"},
+		{"results.aggregate", "Ctrl+A", "Stats of an attribute over the loaded pages"},
+		{"results.export", "Ctrl+X", "Export rows in view (CSV/JSON)"},
+		{"results.pageJSON", "Ctrl+J", "Page as JSON"},
+		{"results.copyItem", "y", "Copy item as JSON"},
+		{"results.copyCommand", "a", "Copy the read as an aws cli command"},
+		{"results.copySnippet", "A", "Copy the read as Go or Python SDK code"},
+		{"results.editItem", "e", "Edit item in $EDITOR (not with --read-only)"},
+		{"results.timing", "Ctrl+D", "Request timing"},
+		{"results.pin", "Ctrl+P", "Pin/unpin row"},
+		{"results.dualWrite", "Ctrl+W", "Dual-write check of row"},
+		{"", "Tab", "Pinned rows / results"},
+		{"results.nextPage", "Ctrl+N", "Next page"},
+		{"results.previousPage", "Ctrl+B", "Previous page"},
+		{"results.hydrate", "Ctrl+G", "Hydrate page from base table (index)"},
+		{"results.cursor", "g", "Go to a cursor (ExclusiveStartKey)"},
+		{"results.columns", "Ctrl+C", "Choose or rename columns"},
+		{"results.rawValues", "Ctrl+T", "Toggle raw values"},
+		{"results.watch", "w", "Watch the first page for changes"},
+		{"", "ESC", "Leave a group, or back to query/scan"},
+	}},
+	{title: "Column Chooser", pages: []string{"columnchooser"}, bindings: []keyBinding{
+		{"", "Space", "Show/hide column"},
+		{"columns.rename", "n", "Rename column"},
+		{"columns.reset", "r", "Reset to the default columns"},
+		{"", "Enter", "Save"},
+		{"", "ESC", "Cancel"},
+	}},
+	{title: "Item Details", pages: []string{"fullitem"}, bindings: []keyBinding{
+		{"", "↑/↓", "Navigate fields"},
+		{"", "Enter", "View JSON (complex fields) or bytes (binary fields)"},
+		{"item.copyValue", "y", "Copy field value"},
+		{"item.copyItem", "Y", "Copy item as JSON"},
+		{"item.download", "Ctrl+D", "Download as JSON"},
+		{"item.rawValues", "Ctrl+T", "Toggle raw values"},
+		{"", "ESC", "Back to results"},
+	}},
+	{title: "JSON Viewer", pages: []string{"jsonview"}, bindings: []keyBinding{
+		{"", "↑/↓", "Select entry / scroll"},
+		{"", "Enter", "Open nested map or list"},
+		{"", "Backspace", "Up one level"},
+		{"", "Tab", "Switch between entries and JSON"},
+		{"", "Space", "Scroll JSON down one page"},
+		{"json.copy", "y", "Copy JSON"},
+		{"", "ESC", "Close viewer"},
+	}},
+	{title: "Table Details", pages: []string{"tabledetails", "indexlist", "indexdetail"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"details.indexes", "g", "List the indexes"},
+		{"details.filter", "/", "Filter the indexes"},
+		{"", "Enter", "Open index"},
+		{"", "ESC", "Back"},
+	}},
+	{title: "Schema Report", pages: []string{"schema"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Table Families", pages: []string{"tablefamilies", "familyquery"}, bindings: []keyBinding{
+		{"", "Enter", "Query a date range"},
+		{"", "ESC", "Back"},
+	}},
+	{title: "Stream Tail", pages: []string{"streamtail", "streamrecord"}, bindings: []keyBinding{
+		{"stream.pause", "Space", "Pause/resume"},
+		{"", "Enter", "Old and new images of a record"},
+		{"", "Tab", "Switch image"},
+		{"stream.clear", "c", "Clear records"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Dual-Write Check", pages: []string{"dualwrite"}, bindings: []keyBinding{
+		{"dualWrite.recheck", "r", "Check again"},
+		{"", "↑/↓", "Scroll"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Cell Value", pages: []string{"cellvalue"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"cell.copy", "y", "Copy the value"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Binary Viewer", pages: []string{"binaryview"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"binary.copy", "y", "Copy the value as base64"},
+		{"binary.save", "s", "Save the value to a file"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Group By", pages: []string{"groupby"}, bindings: []keyBinding{
+		{"", "↑/↓", "Navigate groups"},
+		{"", "Enter", "Show the items of the group"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Attribute Stats", pages: []string{"aggregate"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Request Timing", pages: []string{"readtiming"}, bindings: []keyBinding{
+		{"", "↑/↓", "Scroll"},
+		{"", "ESC", "Close"},
+	}},
+	{title: "Table Copy", pages: []string{"tablecopy"}, bindings: []keyBinding{
+		{"", "ESC", "Close and stop the copy"},
+	}},
+	{title: "S3 Export", pages: []string{"s3export"}, bindings: []keyBinding{
+		{"", "ESC", "Close, the export keeps running"},
+	}},
+	{title: "Index Utilization Report", pages: []string{"indexreport"}, bindings: []keyBinding{
+		{"", "ESC", "Close"},
+	}},
+	{title: "Account Limits", pages: []string{"quotas"}, bindings: []keyBinding{
+		{"", "ESC", "Close"},
+	}},
+	{title: "Connection Screen", pages: []string{"connection"}, bindings: []keyBinding{
+		{"", "Enter", "Connect, testing the profile first"},
+		{"connection.testAll", "t", "Test every profile again"},
+		{"", "Tab", "Region and endpoint"},
+		{"connection.quit", "q", "Quit"},
+		{"", "ESC", "Quit"},
+	}},
+}
+
+// globalKeys work on every page
+var globalKeys = keyContext{title: "Everywhere", bindings: []keyBinding{
+	{"app.pageKeys", "?", "Keys of this page"},
+	{"app.allKeys", "Ctrl+H", "Keys of every page"},
+	{"", "Ctrl+C", "Quit (columns on results pages)"},
+}}
+
+// keyContextOf finds the keys of the topmost visible page that has them.
+// Modals such as errors and prompts have none, so the page under them
+// counts.
+func keyContextOf(pages *tview.Pages) (keyContext, bool) {
+	for _, name := range pages.GetPageNames(true) { // Topmost first
+		for _, c := range keymap {
+			if slices.Contains(c.pages, name) {
+				return c, true
+			}
+		}
+	}
+	return keyContext{}, false
+}
+
+// keymapText lays out the bindings of the contexts, one section each. With
+// actions, remappable keys are followed by the action to remap them under.
+func keymapText(contexts []keyContext, actions bool) string {
+	var b strings.Builder
+	for i, c := range contexts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[#ff9500::b]%s:[white::-]\n", c.title)
+		for _, k := range c.bindings {
+			fmt.Fprintf(&b, "  [#ff9500]%-11s[white] %s", tview.Escape(k.label()), tview.Escape(k.help))
+			if actions && k.action != "" {
+				fmt.Fprintf(&b, " [gray](%s)[white]", k.action)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// handleKeyHelp opens the keys of the page in front (?) or of every page
+// (Ctrl+H), and closes them again with the same key. The keys are passed on
+// while a text field has focus or the screen is locked: a field types ? and
+// deletes with Ctrl+H, which tcell can't tell from Backspace. The JSON
+// viewer keeps Backspace for going up a level.
+func handleKeyHelp(app *tview.Application, pages *tview.Pages, event *tcell.EventKey) *tcell.EventKey {
+	pageKeys, allKeys := keyPressed(event, "app.pageKeys"), keyPressed(event, "app.allKeys")
+	if !pageKeys && !allKeys {
+		return event
+	}
+	switch app.GetFocus().(type) {
+	case *tview.InputField, *tview.TextArea:
+		return event
+	}
+	switch name, _ := pages.GetFrontPage(); {
+	case name == "idlelock":
+		return event
+	case name == "jsonview" && event.Key() == tcell.KeyBackspace:
+		return event
+	case name == "keyhelp" && pageKeys, name == "help" && allKeys:
+		pages.RemovePage(name)
+	case pageKeys:
+		showKeyHelp(pages)
+	default:
+		pages.AddPage("help", createHelpModal(pages), true, true)
+	}
+	return nil
+}
+
+// showKeyHelp shows the keys of the page in front and those that work
+// everywhere in a box over it
+func showKeyHelp(pages *tview.Pages) {
+	contexts := []keyContext{globalKeys}
+	title := " Keys "
+	if c, ok := keyContextOf(pages); ok {
+		contexts = []keyContext{c, globalKeys}
+		title = fmt.Sprintf(" Keys: %s ", c.title)
+	}
+	text := keymapText(contexts, false) + fmt.Sprintf("\n[gray]%s or ESC: close | %s: every page[white]", keyLabel("app.pageKeys"), keyLabel("app.allKeys"))
+
+	width, height := len(title)+2, 2
+	for _, line := range strings.Split(text, "\n") {
+		width = max(width, tview.TaggedStringWidth(line)+4)
+		height++
+	}
+	view := tview.NewTextView().
+		SetText(text).
+		SetDynamicColors(true).
+		SetScrollable(true)
+	view.SetBorder(true).
+		SetBorderColor(accentOrange).
+		SetTitle(title).
+		SetTitleColor(accentOrange)
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("keyhelp")
+			return nil
+		}
+		return event
+	})
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(view, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage("keyhelp", modal, true, true)
+}
+
+// keySpec is a key as it can be bound: a key such as Ctrl+N or Enter, or a
+// character, optionally with Alt
+type keySpec struct {
+	key  tcell.Key
+	ch   rune
+	alt  bool
+	name string // As written on screen
+}
+
+// namedKeys are the keys that are spelled out in config.yaml, by their
+// lowercase name
+var namedKeys = func() map[string]tcell.Key {
+	named := map[string]tcell.Key{"escape": tcell.KeyEscape, "return": tcell.KeyEnter}
+	for key, name := range tcell.KeyNames {
+		if !strings.HasPrefix(name, "Ctrl-") {
+			named[strings.ToLower(name)] = key
+		}
+	}
+	return named
+}()
+
+// parseKey reads a key as written in config.yaml: a character such as "n"
+// or "/", "Space", "Ctrl+N", "Alt+x" or the name of a key such as "F5",
+// "Enter" or "PgDn"
+func parseKey(s string) (keySpec, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	runes := []rune(s)
+	switch {
+	case len(runes) == 1:
+		return keySpec{key: tcell.KeyRune, ch: runes[0], name: s}, nil
+	case lower == "space":
+		return keySpec{key: tcell.KeyRune, ch: ' ', name: "Space"}, nil
+	case strings.HasPrefix(lower, "ctrl+") && len(s) == len("ctrl+")+1:
+		letter := lower[len(lower)-1]
+		if letter >= 'a' && letter <= 'z' {
+			return keySpec{key: tcell.KeyCtrlA + tcell.Key(letter-'a'), name: "Ctrl+" + strings.ToUpper(string(letter))}, nil
+		}
+	case strings.HasPrefix(lower, "alt+") && len([]rune(s)) == len("alt+")+1:
+		ch := []rune(s)[len("alt+")]
+		return keySpec{key: tcell.KeyRune, ch: ch, alt: true, name: "Alt+" + string(ch)}, nil
+	}
+	if key, ok := namedKeys[lower]; ok {
+		return keySpec{key: key, name: tcell.KeyNames[key]}, nil
+	}
+	return keySpec{}, fmt.Errorf("unknown key %q: expected a character, Space, Ctrl+<letter>, Alt+<character> or a key name such as F5 or Enter", s)
+}
+
+func (k keySpec) matches(event *tcell.EventKey) bool {
+	if k.key != tcell.KeyRune {
+		return event.Key() == k.key
+	}
+	return event.Key() == tcell.KeyRune && event.Rune() == k.ch && (event.Modifiers()&tcell.ModAlt != 0) == k.alt
+}
+
+// boundKeys are the keys of every action: their defaults from keymap, or
+// the keys config.yaml sets
+var boundKeys = func() map[string][]keySpec {
+	bound := make(map[string][]keySpec)
+	for _, c := range append(slices.Clone(keymap), globalKeys) {
+		for _, b := range c.bindings {
+			if b.action == "" {
+				continue
+			}
+			for _, key := range strings.Fields(b.keys) {
+				k, err := parseKey(key)
+				if err != nil {
+					panic(fmt.Sprintf("default key of %s: %v", b.action, err))
+				}
+				bound[b.action] = append(bound[b.action], k)
+			}
+		}
+	}
+	return bound
+}()
+
+// keyPressed reports whether the event is one of the keys of an action
+func keyPressed(event *tcell.EventKey, action string) bool {
+	for _, k := range boundKeys[action] {
+		if k.matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyLabel writes the keys of an action for hints on screen, e.g. "Ctrl+N"
+func keyLabel(action string) string {
+	names := make([]string, len(boundKeys[action]))
+	for i, k := range boundKeys[action] {
+		names[i] = k.name
+	}
+	return strings.Join(names, "/")
+}
+
+// label is how the help writes the keys of a binding
+func (b keyBinding) label() string {
+	if b.action == "" {
+		return b.keys
+	}
+	return keyLabel(b.action)
+}
+
+// keyList is the keys of an action in config.yaml: one key, or a list of
+// keys that all trigger it
+type keyList []string
+
+func (l *keyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = keyList{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+	*l = keys
+	return nil
+}
+
+// bindKeys remaps the actions named under keys in config.yaml. An action
+// can't share a key with another action of its page, or with one that works
+// on every page.
+func bindKeys(keys map[string]keyList) error {
+	contextOf := make(map[string]string)
+	for _, c := range append(slices.Clone(keymap), globalKeys) {
+		for _, b := range c.bindings {
+			if b.action != "" {
+				contextOf[b.action] = c.title
+			}
+		}
+	}
+	for _, action := range slices.Sorted(maps.Keys(keys)) {
+		if _, ok := contextOf[action]; !ok {
+			return fmt.Errorf("unknown action %q under keys in config.yaml", action)
+		}
+		if len(keys[action]) == 0 {
+			return fmt.Errorf("no keys for %s under keys in config.yaml", action)
+		}
+		var specs []keySpec
+		for _, key := range keys[action] {
+			k, err := parseKey(key)
+			if err != nil {
+				return fmt.Errorf("invalid key for %s in config.yaml: %w", action, err)
+			}
+			specs = append(specs, k)
+		}
+		boundKeys[action] = specs
+	}
+
+	// Two actions of a page sharing a key would leave one unreachable
+	for _, c := range keymap {
+		taken := make(map[keySpec]string)
+		for _, b := range append(slices.Clone(c.bindings), globalKeys.bindings...) {
+			for _, k := range boundKeys[b.action] {
+				name := k.name
+				k.name = "" // ctrl+n and Ctrl+N are the same key
+				if other, ok := taken[k]; ok && other != b.action {
+					return fmt.Errorf("%s and %s are both bound to %s in config.yaml", other, b.action, name)
+				}
+				taken[k] = b.action
+			}
+		}
+	}
+	return nil
+}
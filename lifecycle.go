@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+
+	"github.com/rivo/tview"
+)
+
+// taskGroup is the background work of one page. Its context is cancelled
+// once the page is removed or replaced, which aborts the requests still in
+// flight and drops their results instead of updating a view that is gone.
+type taskGroup struct {
+	page   tview.Primitive // The page as it was when the group was created
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Modal pages shown for the group's work, such as loading modals, by
+	// page name. They are removed along with the group.
+	overlays map[string]tview.Primitive
+}
+
+// pageTasks keeps the task group of every page that started background
+// work. It is only used from the UI goroutine.
+type pageTasks struct {
+	app    *tview.Application
+	pages  *tview.Pages
+	groups map[string]*taskGroup // By page name
+}
+
+// tasks is created in main once the pages exist
+var tasks *pageTasks
+
+func newPageTasks(app *tview.Application, pages *tview.Pages) *pageTasks {
+	return &pageTasks{app: app, pages: pages, groups: make(map[string]*taskGroup)}
+}
+
+// group returns the task group of the named page, which must have been
+// added. A page added again under the same name gets a new group, and the
+// group of the page it replaced is cancelled.
+func (t *pageTasks) group(name string) *taskGroup {
+	page := t.pages.GetPage(name)
+	if g, ok := t.groups[name]; ok {
+		if g.page == page {
+			return g
+		}
+		t.close(g)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &taskGroup{page: page, ctx: ctx, cancel: cancel, overlays: make(map[string]tview.Primitive)}
+	t.groups[name] = g
+	return g
+}
+
+// Go runs work in the background with the group's context. The update it
+// returns is run on the UI goroutine, unless the group was cancelled in the
+// meantime.
+func (t *pageTasks) Go(g *taskGroup, work func(ctx context.Context) (update func())) {
+	go func() {
+		defer recoverPanic()
+		update := work(g.ctx)
+		t.app.QueueUpdateDraw(func() {
+			if g.ctx.Err() == nil && update != nil {
+				update()
+			}
+		})
+	}()
+}
+
+// sweep cancels the groups of pages that were removed or replaced. It runs
+// before every draw, so work stops as soon as its page is gone.
+func (t *pageTasks) sweep() {
+	for name, g := range t.groups {
+		if t.pages.GetPage(name) != g.page {
+			delete(t.groups, name)
+			t.close(g)
+		}
+	}
+}
+
+// close cancels a group and removes its overlays. Pages can't be changed
+// while a draw is in progress, so the removal is queued.
+func (t *pageTasks) close(g *taskGroup) {
+	g.cancel()
+	if len(g.overlays) == 0 {
+		return
+	}
+	go t.app.QueueUpdateDraw(func() {
+		for name, overlay := range g.overlays {
+			if t.pages.GetPage(name) == overlay {
+				t.pages.RemovePage(name)
+			}
+		}
+	})
+}
+
+// stop cancels all background work when the application exits
+func (t *pageTasks) stop() {
+	for _, g := range t.groups {
+		g.cancel()
+	}
+}
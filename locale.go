@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// numberLocale describes how numbers and dates are written in a locale
+type numberLocale struct {
+	thousands  string // Digit group separator
+	decimal    string // Decimal separator
+	dateLayout string // time.Format layout for calendar dates
+}
+
+// locales maps BCP 47 style tags to their formatting conventions
+var locales = map[string]numberLocale{
+	"en-US": {thousands: ",", decimal: ".", dateLayout: "01/02/2006"},
+	"en-GB": {thousands: ",", decimal: ".", dateLayout: "02/01/2006"},
+	"en-AU": {thousands: ",", decimal: ".", dateLayout: "02/01/2006"},
+	"en-IN": {thousands: ",", decimal: ".", dateLayout: "02/01/2006"},
+	"de-DE": {thousands: ".", decimal: ",", dateLayout: "02.01.2006"},
+	"de-CH": {thousands: "’", decimal: ".", dateLayout: "02.01.2006"},
+	"fr-FR": {thousands: " ", decimal: ",", dateLayout: "02/01/2006"},
+	"es-ES": {thousands: ".", decimal: ",", dateLayout: "02/01/2006"},
+	"it-IT": {thousands: ".", decimal: ",", dateLayout: "02/01/2006"},
+	"nl-NL": {thousands: ".", decimal: ",", dateLayout: "02-01-2006"},
+	"pt-BR": {thousands: ".", decimal: ",", dateLayout: "02/01/2006"},
+	"pl-PL": {thousands: " ", decimal: ",", dateLayout: "02.01.2006"},
+	"sv-SE": {thousands: " ", decimal: ",", dateLayout: "2006-01-02"},
+	"he-IL": {thousands: ",", decimal: ".", dateLayout: "02.01.2006"},
+	"ja-JP": {thousands: ",", decimal: ".", dateLayout: "2006/01/02"},
+	"zh-CN": {thousands: ",", decimal: ".", dateLayout: "2006/01/02"},
+}
+
+// languageDefaults picks the region for language-only tags (e.g. "de") when
+// several regions of the language are known
+var languageDefaults = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+}
+
+// displayLocale is the locale used by formatNumber, formatBytes and formatDate
+var displayLocale = locales["en-US"]
+
+// setLocale selects the display locale. An empty tag is taken from the
+// LC_ALL, LC_NUMERIC or LANG environment variables, falling back to en-US.
+func setLocale(tag string) error {
+	if tag == "" {
+		for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+			if v := os.Getenv(env); v != "" {
+				tag = v
+				break
+			}
+		}
+		if l, ok := lookupLocale(tag); ok {
+			displayLocale = l
+		}
+		return nil
+	}
+
+	l, ok := lookupLocale(tag)
+	if !ok {
+		var known []string
+		for k := range locales {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		return fmt.Errorf("unsupported locale %q, use one of: %s", tag, strings.Join(known, ", "))
+	}
+	displayLocale = l
+	return nil
+}
+
+// lookupLocale accepts tags like "de-DE", "de_DE.UTF-8" or "de"
+func lookupLocale(tag string) (numberLocale, bool) {
+	tag, _, _ = strings.Cut(tag, ".") // Drop the encoding of POSIX locale names
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if l, ok := locales[tag]; ok {
+		return l, true
+	}
+
+	lang, _, _ := strings.Cut(tag, "-")
+	if def, ok := languageDefaults[lang]; ok {
+		return locales[def], true
+	}
+	var candidates []string
+	for k := range locales {
+		if strings.HasPrefix(k, lang+"-") {
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) != 1 {
+		return numberLocale{}, false
+	}
+	return locales[candidates[0]], true
+}
+
+// formatDate formats a calendar date in the display locale
+func formatDate(t time.Time) string {
+	return t.Local().Format(displayLocale.dateLayout)
+}
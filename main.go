@@ -1,23 +1,82 @@
 package main
 
 import (
+	"context"
 	"ddb-explorer/aws"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-var profile = flag.String("profile", "dev", "AWS profile to use (dev or prod)")
+var profile = flag.String("profile", "dev", "AWS profile to use")
+var envName = flag.String("env", "", "Named environment of config.yaml: profile, region, endpoint and role in one")
 var showHelp = flag.Bool("help", false, "Show help and usage information")
+var roleARN = flag.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+var mfaSerial = flag.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+var themeName = flag.String("theme", "default", "Color theme: default or high-contrast")
+var screenReader = flag.Bool("screen-reader", false, "Linear rendering without borders, with row positions and column names spoken inline")
+var localeTag = flag.String("locale", "", "Locale for numbers and dates, e.g. de-DE (default: from LANG)")
+var lazyMetadata = flag.Bool("lazy", false, "List table names immediately and load table metadata as rows become visible")
+var unusedDays = flag.Int("unused-days", defaultUnusedDays, "Days of metrics in the index utilization report; indexes without reads in that time are flagged")
+var idleLockAfter = flag.Duration("idle-lock", 0, "Blank the screen after this long without input, e.g. 10m; prod SSO and MFA sessions re-authenticate to resume (default: off)")
+var region = flag.String("region", "", "AWS region to connect to (default: us-east-1)")
+var endpoint = flag.String("endpoint", "", "Custom DynamoDB endpoint URL, e.g. http://localhost:8000 for DynamoDB Local")
+var pageSize = flag.Int("page-size", aws.DefaultPageSize, "Items read per query or scan page")
+var autoRefresh = flag.Duration("auto-refresh", 0, "Refresh the item counts and sizes of the table list this often, e.g. 1m (default: off, Ctrl+R turns it on)")
+var watchInterval = flag.Duration("watch-interval", 10*time.Second, "Time between reads of a results page watched with w")
+var readOnly = flag.Bool("read-only", false, "Hide actions that write: deleting, editing, copying and exporting tables")
+var debugLog = flag.Bool("debug", false, "Log every DynamoDB request with its duration and consumed capacity (to --log-file, default debug.log next to the config file)")
+var logFile = flag.String("log-file", "", "Append structured logs (JSON lines) of errors, throttling and sessions to this file")
+var demo = flag.Bool("demo", false, "Explore generated tables held in memory instead of connecting to AWS")
+var noMouse = flag.Bool("no-mouse", false, "Leave the mouse to the terminal, for selecting and copying text")
+var gzipAttrs = flag.String("gzip-attrs", "", "Comma-separated binary attributes holding gzip-compressed JSON (attr or table.attr)")
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+var decoderSpecs stringList
+var shardSpecs stringList
+var dualWriteSpecs stringList
+
+func init() {
+	flag.Var(&decoderSpecs, "decoder", "Decode a binary attribute: ATTR=proto:DESCRIPTOR_SET:MESSAGE or ATTR=avro:SCHEMA_FILE (repeatable)")
+	flag.Var(&shardSpecs, "shards", "Write-sharded partition keys of a table: TABLE=PATTERN or TABLE.INDEX=PATTERN, e.g. users=#shard{0..15} (repeatable)")
+	flag.Var(&dualWriteSpecs, "dual-write", "Tables written in parallel during a migration, compared with the dual-write check: OLD=NEW (repeatable)")
+}
+
+// shardPatterns holds the --shards settings by table or table.index
+var shardPatterns = make(map[string]aws.ShardPattern)
 
 var tables []aws.TableInfo
 
+// unionMaxItems caps how many items each index query of a union query reads
+const unionMaxItems = 100
+
+// shardMaxItems caps how many items each shard of a sharded query reads
+const shardMaxItems = 100
+
+// Default budget of a query with "Fetch all pages" checked
+const (
+	defaultFetchMaxItems = 1000
+	defaultFetchMaxMB    = 10
+)
+
 // Custom color scheme
 var (
 	// Background colors
@@ -58,42 +117,252 @@ func printHelp() {
 	fmt.Println(`DynamoDB TUI Explorer - Terminal interface for browsing DynamoDB tables
 
 USAGE:
-    ddb-explorer [--profile PROFILE]
+    ddb-explorer [--env NAME] [--profile PROFILE] [--region REGION] [--endpoint URL]
+                 [--role-arn ARN [--mfa-serial ARN]] [--gzip-attrs ATTRS]
+                 [--decoder SPEC]... [--shards SPEC]... [--theme THEME] [--screen-reader] [--lazy]
+                 [--idle-lock DURATION] [--read-only] [--page-size N] [--no-mouse]
+                 [--auto-refresh DURATION] [--watch-interval DURATION]
+                 [--debug] [--log-file FILE] [--demo]
+    ddb-explorer watch-table --pk VALUE [OPTIONS] TABLE
+    ddb-explorer export-inventory [--format csv|json] [--output FILE] [OPTIONS]
+    ddb-explorer export --table TABLE [--out FILE] [--filter EXPR] [--segments N] [OPTIONS]
+    ddb-explorer import --table TABLE [--in FILE] [--dry-run] [OPTIONS]
+    ddb-explorer describe --table TABLE [--output json|yaml] [OPTIONS]
 
 OPTIONS:
-    --profile    AWS profile to use (default: dev)
+    --profile    AWS profile to use (default: dev). When it can't connect, a
+                 connection screen tests every profile of ~/.aws/config and
+                 ~/.aws/credentials and lets you pick one, a region and an
+                 endpoint
+    --env        Named environment of config.yaml, setting the profile,
+                 region, endpoint, role and frame color in one go
+    --region     AWS region to connect to (default: us-east-1)
+    --endpoint   Custom DynamoDB endpoint, e.g. http://localhost:8000 for
+                 DynamoDB Local
+    --read-only  Hide every action that writes: tables can't be deleted (d),
+                 copied (c), exported to S3 (x) or edited (Ctrl+E), and
+                 results can't be edited in $EDITOR (e)
+    --page-size  Items read per query or scan page (default: 15)
+    --auto-refresh
+                 Describe the tables of the table list again this often,
+                 e.g. 2m, to follow their item counts and sizes (default:
+                 off; Ctrl+R turns it on, every minute)
+    --watch-interval
+                 Time between reads of a results page watched with w, e.g.
+                 30s (default: 10s)
+    --role-arn   IAM role to assume via STS for cross-account access
+    --mfa-serial MFA device serial required by the role (prompts for a code)
+    --theme      Color theme: default or high-contrast
+    --screen-reader
+                 Render tables without borders and prefix rows with their
+                 position and cells with their column name
+    --locale     Number and date format, e.g. en-GB or de-DE (default: from
+                 LC_ALL, LC_NUMERIC or LANG, otherwise en-US)
+    --lazy       Show table names immediately and load status, item count and
+                 size as rows become visible
+    --no-mouse   Leave the mouse to the terminal, for selecting and copying
+                 text. Otherwise clicks select rows, tabs and buttons and the
+                 wheel scrolls
+    --idle-lock  Blank the screen after this long without a key press, e.g.
+                 10m. Any key resumes; prod sessions re-authenticate first
+                 (SSO login, or a new MFA code with --mfa-serial). Static
+                 keys and roles without MFA can't, and resume with any key
+    --log-file   Append structured logs (JSON lines) to this file: errors,
+                 throttling retries and sessions
+    --debug      Also log every DynamoDB request with its table, duration,
+                 attempts and consumed capacity; logs to debug.log next to
+                 the config file unless --log-file is given
+    --demo       Explore generated tables held in memory, without an AWS
+                 account. Writes last until the app quits; metrics and
+                 streams aren't available
+    --unused-days
+                 Days covered by the index utilization report (Ctrl+R);
+                 indexes without reads in that time are flagged (default: 30)
+    --gzip-attrs Comma-separated binary attributes holding gzip-compressed
+                 JSON, as attr or table.attr (rendered as readable JSON)
+    --decoder    Decode a binary attribute with a schema (repeatable):
+                   ATTR=proto:DESCRIPTOR_SET:MESSAGE
+                   ATTR=avro:SCHEMA_FILE
+                 ATTR is an attribute name or table.attr
+    --shards     Write-sharded partition keys (repeatable): TABLE=PATTERN or
+                 TABLE.INDEX=PATTERN, where PATTERN is the key suffix with a
+                 shard number range, e.g. users=#shard{0..15}. Queries then
+                 fan out over every shard and merge the results
+    --dual-write Tables dual-written during a migration (repeatable):
+                 OLD=NEW. w on the table list and Ctrl+W on a results row
+                 compare an item between the two
     --help       Show this help message
 
+    Defaults for --profile, --region, --endpoint, --role-arn, --mfa-serial,
+    --page-size, --auto-refresh, --watch-interval, --theme, --read-only and
+    --no-mouse, result columns per table, named environments and dual-write
+    pairs can be set in ~/.config/ddb-explorer/config.yaml; flags given on
+    the command line win. Columns chosen with Ctrl+C and widths set with +/-
+    are kept in config.json and win over those of config.yaml.
+
+    Keys can be remapped under keys in config.yaml, by the action names that
+    Ctrl+H shows next to them.
+
+    When the explorer quits, the open table, its last query or scan and the
+    page of results in view are kept per profile in config.json, next to
+    config.yaml. The next start with the profile offers to resume there.
+
+SUBCOMMANDS:
+    watch-table  Headless: re-run a query every --interval and report items
+                 that were added, removed or modified. Exits 1 when changes
+                 were seen (immediately with --exit-on-change), 2 on errors.
+                 Run "ddb-explorer watch-table --help" for its options.
+    export-inventory
+                 Headless: describe every table and write name, size, items,
+                 billing mode, GSIs, tags, PITR and stream settings as CSV
+                 or JSON, for spreadsheet reviews.
+    export       Headless: scan a whole table, optionally filtered and in
+                 parallel segments, and write one JSON item per line
+                 (NDJSON), for CI jobs and backups. --typed keeps the
+                 DynamoDB types. Run "ddb-explorer export --help" for its
+                 options.
+    import       Headless: put the items of an NDJSON file (or stdin) into a
+                 table with batch writes, retrying items DynamoDB leaves
+                 unprocessed, and print a summary. --dry-run only checks the
+                 items; --typed reads what export --typed wrote. Run
+                 "ddb-explorer import --help" for its options.
+    describe     Headless: print the full metadata of a table (keys with
+                 their types, indexes, billing and capacity, TTL, streams,
+                 encryption, tags) as JSON or YAML, for scripts and
+                 generated docs.
+
 KEYBOARD SHORTCUTS:
 
+Everywhere:
+    ?           Show the keys of the current page (typed as usual in text
+                fields)
+    Ctrl+H      Show the keys of every page (not in text fields or the
+                JSON viewer, where it is Backspace on many terminals)
+    Ctrl+C      Quit (on results pages it chooses columns)
+
 Table List View:
     ↑/↓         Navigate table list
     Enter       Select table and open query view
+    a           Show/hide read and write activity over the last 24 hours
+    Ctrl+R      Turn auto-refresh of item counts and sizes on or off
+    l           Show account limits and how much of each is in use
+    f           Browse date-sharded table families (events_2024_05, ...)
+    i           Show the selected table's full configuration: key types,
+                indexes, capacity, stream, TTL and tags; g there lists
+                the indexes, / filters them and Enter opens one
+    n           Analyze the selected table: the attributes of a sample of its
+                items with their types, fill rates and example values
+    x           Export the selected table to S3 (needs point-in-time recovery;
+                hidden with --read-only)
+    c           Copy the selected table's items to a table of another profile
+                or region, with a dry-run item count and a write rate limit
+                (hidden with --read-only)
+    d           Delete the selected table after typing its name to confirm
+                (hidden with --read-only)
+    w           Compare an item, by primary key, between the selected table
+                and its --dual-write partner
+    Ctrl+P      Switch to another profile or named environment and reload
+                the tables
+    /           Filter tables by name (fuzzy: usrevt finds user_events)
     q/ESC       Quit application
 
 Query/Scan View:
     Tab         Navigate between input fields
-    Enter       Execute query
-    ←/→         Switch between Query and Scan tabs
+    Enter       Execute query; a partition key typed as attribute=value,
+                e.g. email=jane@example.com, queries the table or GSI whose
+                partition key the attribute is
+    ESC         Cancel a query or scan while it is running
+    ←/→         Switch between Query, Scan and Union tabs
+    ↑/↓ Enter   Pick a completed attribute name in Filter Attribute
+    Ctrl+U      Union query across the table and its indexes
+    Ctrl+E      Edit the whole table (tables under 1,000 items; hidden with
+                --read-only)
+    Ctrl+R      Index utilization report (tables with GSIs)
+    Ctrl+L      Tail the table's stream (tables with streams enabled)
+    Ctrl+X      Raw expressions instead of the Query and Scan fields
     ESC         Return to table list
 
+Table Editor:
+    a           Add an item
+    Enter/e     Edit the selected item as JSON (Ctrl+S saves)
+    d           Mark the selected item for deletion (press again to undo)
+    Ctrl+S      Review pending changes and apply them
+    ESC         Close the editor (asks before discarding changes)
+
 Query Results View:
     ↑/↓         Navigate results
-    Enter       View full item details
+    ←/→ or h/l  Move the current column, scrolling the columns sideways; the
+                key columns stay in view
+    v           Show the full value of the current column in the selected row,
+                or the bytes of a binary value
+    o           Sort the loaded pages by the current column: ascending,
+                descending, then as read (paging ends the sort)
+    G           Group the loaded pages by an attribute: items per value, and
+                Enter on a group shows its items (ESC shows all again)
+    +/-         Widen or narrow the current column (saved per table)
+    =           Auto-fit the columns to the terminal width (saved)
+    Enter       View full item details, or the item as JSON or in a preview
+                pane next to the results, depending on the Enter action
+    Ctrl+O      Cycle the Enter action: item view, JSON, preview (saved)
+    s           Split view: the selected item follows the selection in a pane
+                next to the results (saved)
+    Ctrl+K      Show the distribution of a numeric attribute over the loaded
+                pages: min, median, max, a histogram and outliers
+    Ctrl+A      Stats of an attribute over the loaded pages: count, distinct
+                values, min/max/sum/average and the most frequent values
+    Ctrl+X      Copy or save the rows and columns in view as CSV or JSON
+    Ctrl+J      Open every item of the page, as stored, in the JSON viewer
+    y           Copy the selected item to the clipboard as JSON
+    a           Copy the query or scan as an aws dynamodb CLI command
+    A           Copy the query or scan as Go (aws-sdk-go-v2) or Python
+                (boto3) code
+    e           Edit the selected item as JSON in $VISUAL or $EDITOR; the
+                changes are reviewed before they are written
+    Ctrl+D      Show how the page's requests spent their time: connection
+                setup, latency, retries, pages and bytes received
+    Ctrl+P      Pin the selected row above the results, or unpin it; pins
+                stay across pages and re-run queries of the table
+    Ctrl+W      Compare the selected item with its copy in the table's
+                --dual-write partner, attribute by attribute
+    Tab         Move between the pinned rows and the results
     Ctrl+N      Load next page
     Ctrl+B      Go to previous page
-    ESC         Return to query view
+    Ctrl+G      Fetch full items from the base table (KEYS_ONLY/INCLUDE indexes)
+    g           Go to a cursor: enter an ExclusiveStartKey as typed JSON or pick
+                a saved one; save or copy the cursor of the page in view
+    Ctrl+C      Choose the attribute columns shown for this table; n there
+                gives a column a display name
+    Ctrl+T      Toggle between formatted and raw (exact, untruncated) values
+    w           Watch the first page: read it again every --watch-interval
+                and highlight the rows that appeared or changed
+    ESC         Close the preview pane, leave a group, or return to query view
 
 Item Detail View:
     ↑/↓         Navigate item fields
-    Enter       View complex field as formatted JSON
+    Enter       View a map, list or set as formatted JSON, or the bytes of
+                a binary field as base64 and hex
+    Ctrl+T      Toggle between formatted and raw values
+    y           Copy the selected field's value to the clipboard
+    Y           Copy the whole item to the clipboard as JSON
+    Ctrl+D      Save the item as a JSON file
     ESC         Return to results view
 
 JSON Viewer:
-    ↑/↓         Scroll line by line
-    Space       Scroll down one page
+    ↑/↓         Select an entry, or scroll the JSON line by line
+    Enter       Open the selected map or list as the new root
+    Backspace   Go back up one level of the path
+    Tab         Switch between the entry list and the JSON
+    Space       Scroll the JSON down one page
+    y           Copy the JSON of the current root to the clipboard
     ESC         Close JSON viewer
 
+Binary Viewer:
+    ↑/↓         Scroll
+    y           Copy the value as base64 to the clipboard
+    s           Save the bytes to <key>_<attribute>.bin in the working
+                directory
+    ESC         Close the viewer
+
 EXAMPLES:
     # Run with default (dev) profile
     ./ddb-explorer
@@ -101,17 +370,52 @@ EXAMPLES:
     # Run with production profile
     ./ddb-explorer --profile prod
 
+    # Lock a prod session shown on a shared screen after 5 idle minutes
+    ./ddb-explorer --profile prod --idle-lock 5m
+
+    # Browse another account by assuming a role with MFA
+    ./ddb-explorer --role-arn arn:aws:iam::123456789012:role/ReadOnly \
+        --mfa-serial arn:aws:iam::111111111111:mfa/me
+
+    # Decode gzip-compressed JSON stored in the "payload" attribute
+    ./ddb-explorer --gzip-attrs payload,events.body
+
+    # Decode protobuf and Avro encoded attributes
+    ./ddb-explorer --decoder orders.details=proto:orders.pb:shop.v1.OrderDetails \
+        --decoder audit=avro:audit.avsc
+
+    # Fail a cron job if the feature flags for tenant "acme" changed in the last hour
+    ./ddb-explorer watch-table --profile prod --pk acme --interval 1h --count 1 \
+        --exit-on-change feature-flags
+
+    # Export the table inventory of the prod account for a spreadsheet review
+    ./ddb-explorer export-inventory --profile prod --output tables.csv
+
 QUERY CONDITIONS:
     =              Exact match
     begins_with    String starts with value
     <, <=, >, >=   Comparison operators
-    between        Between two values
+    between        Between the Sort Key value and the And value, inclusive
+
+    The Sort Order drop-down returns items in descending sort key order
+    (newest first for timestamp sort keys).
+
+SAVED QUERIES:
+    Save Query in the Query tab stores the index, key values and condition
+    under a name; the Saved Query drop-down lists them when the table is
+    opened. Key values can hold placeholders such as USER#{org}, which are
+    asked for each time the query runs.
 
 For more information, see README.md`)
 }
 
-// formatWithCommas formats a number with commas
-func formatWithCommas(n int64) string {
+// formatNumber formats a number with the digit group separator of the display locale
+func formatNumber(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
 	s := strconv.FormatInt(n, 10)
 	var parts []string
 	for len(s) > 3 {
@@ -121,7 +425,7 @@ func formatWithCommas(n int64) string {
 	if len(s) > 0 {
 		parts = append([]string{s}, parts...)
 	}
-	return strings.Join(parts, ",")
+	return sign + strings.Join(parts, displayLocale.thousands)
 }
 
 // formatBytes formats bytes into human-readable size (GB, MB, KB)
@@ -132,54 +436,67 @@ func formatBytes(bytes int64) string {
 		GB = 1024 * MB
 	)
 	
+	var size string
 	switch {
 	case bytes >= GB:
-		return fmt.Sprintf("%.2f GB", float64(bytes)/float64(GB))
+		size = fmt.Sprintf("%.2f GB", float64(bytes)/float64(GB))
 	case bytes >= MB:
-		return fmt.Sprintf("%.2f MB", float64(bytes)/float64(MB))
+		size = fmt.Sprintf("%.2f MB", float64(bytes)/float64(MB))
 	case bytes >= KB:
-		return fmt.Sprintf("%.2f KB", float64(bytes)/float64(KB))
+		size = fmt.Sprintf("%.2f KB", float64(bytes)/float64(KB))
 	default:
 		return fmt.Sprintf("%d B", bytes)
 	}
+	return strings.Replace(size, ".", displayLocale.decimal, 1)
+}
+
+// formatCapacity formats capacity units with one decimal in the display locale
+func formatCapacity(units float64) string {
+	whole, frac, _ := strings.Cut(strconv.FormatFloat(units, 'f', 1, 64), ".")
+	n, _ := strconv.ParseInt(whole, 10, 64)
+	return formatNumber(n) + displayLocale.decimal + frac
+}
+
+// parseFetchBudget reads the fetch-all limits of the query form. An empty or
+// zero field leaves that limit off, but at least one limit must be set.
+func parseFetchBudget(maxItems, maxMB string) (aws.FetchBudget, error) {
+	var budget aws.FetchBudget
+	if maxItems != "" {
+		n, err := strconv.Atoi(maxItems)
+		if err != nil || n < 0 {
+			return budget, fmt.Errorf("Max items must be a positive number, got %q", maxItems)
+		}
+		budget.MaxItems = n
+	}
+	if maxMB != "" {
+		n, err := strconv.ParseInt(maxMB, 10, 64)
+		if err != nil || n < 0 {
+			return budget, fmt.Errorf("Max size must be a positive number of MB, got %q", maxMB)
+		}
+		budget.MaxBytes = n * 1024 * 1024
+	}
+	if budget.MaxItems == 0 && budget.MaxBytes == 0 {
+		return budget, fmt.Errorf("Fetch all pages needs a max items or max size limit")
+	}
+	return budget, nil
+}
+
+// setTableRow renders one of total table list rows, with placeholders while metadata is loading
+func setTableRow(table *tview.Table, row, total int, t aws.TableInfo) {
+	status, count, size := t.Status, formatNumber(t.ItemCount), formatBytes(t.SizeBytes)
+	if !t.Described && t.Status == "" {
+		status, count, size = "loading…", "loading…", "loading…"
+	}
+	table.SetCell(row, 0, tview.NewTableCell(rowPrefix("Table", row, total)+t.Name).SetTextColor(tview.Styles.PrimaryTextColor))
+	table.SetCell(row, 1, tview.NewTableCell(labeled("status", status)).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignCenter))
+	table.SetCell(row, 2, tview.NewTableCell(labeled("items", count)).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
+	table.SetCell(row, 3, tview.NewTableCell(labeled("size", size)).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
 }
 
 func createHelpModal(pages *tview.Pages) *tview.TextView {
-	helpText := `[::b]DDB-Explorer - Keyboard Shortcuts[::-]
-
-[#ff9500::b]Table List:[white::-]
-  [#ff9500]↑/↓[white]         Navigate tables
-  [#ff9500]Enter[white]       Select table
-  [#ff9500]q/ESC[white]       Quit
-  [#ff9500]Ctrl+H[white]      Show help
-
-[#ff9500::b]Query/Scan View:[white::-]
-  [#ff9500]Tab[white]         Navigate fields
-  [#ff9500]Ctrl+Q[white]      Switch to Query tab
-  [#ff9500]Ctrl+S[white]      Switch to Scan tab
-  [#ff9500]←/→[white]         Switch tabs
-  [#ff9500]Enter[white]       Execute query/scan
-  [#ff9500]ESC[white]         Back to table list
-
-[#ff9500::b]Results View:[white::-]
-  [#ff9500]↑/↓[white]         Navigate items
-  [#ff9500]Enter[white]       View item details
-  [#ff9500]Ctrl+N[white]      Next page
-  [#ff9500]Ctrl+B[white]      Previous page
-  [#ff9500]ESC[white]         Back to query/scan
-
-[#ff9500::b]Item Details:[white::-]
-  [#ff9500]↑/↓[white]         Navigate fields
-  [#ff9500]Enter[white]       View JSON (complex fields)
-  [#ff9500]Ctrl+D[white]      Download as JSON
-  [#ff9500]ESC[white]         Back to results
-
-[#ff9500::b]JSON Viewer:[white::-]
-  [#ff9500]↑/↓[white]         Scroll line by line
-  [#ff9500]Space[white]       Scroll down one page
-  [#ff9500]ESC[white]         Close viewer
-
-[gray]Press ESC or Ctrl+H to close[white]`
+	helpText := "[::b]DDB-Explorer - Keyboard Shortcuts[::-]\n\n" +
+		keymapText(append(slices.Clone(keymap), globalKeys), true) +
+		"\n[gray]Press ESC or " + keyLabel("app.allKeys") + " to close[white]"
 
 	helpView := tview.NewTextView().
 		SetText(helpText).
@@ -194,7 +511,7 @@ func createHelpModal(pages *tview.Pages) *tview.TextView {
 		SetTitleAlign(tview.AlignCenter)
 	
 	helpView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyESC || event.Key() == tcell.KeyCtrlH {
+		if event.Key() == tcell.KeyESC {
 			pages.RemovePage("help")
 			return nil
 		}
@@ -204,7 +521,255 @@ func createHelpModal(pages *tview.Pages) *tview.TextView {
 	return helpView
 }
 
+// mfaPrompt asks the user for an MFA token code when assuming a role. It reads
+// from stdin until the TUI starts, after which a modal prompt is used instead.
+var mfaPrompt = func() (string, error) {
+	fmt.Printf("Enter MFA code for %s: ", *mfaSerial)
+	var code string
+	if _, err := fmt.Scanln(&code); err != nil {
+		return "", fmt.Errorf("failed to read MFA code: %w", err)
+	}
+	return strings.TrimSpace(code), nil
+}
+
+// newHeadlessClient creates the client of a subcommand. Prompts and
+// throttling notices go to stderr so they don't mix with the command's output.
+func newHeadlessClient(profile, roleARN, mfaSerial string) (*aws.Client, error) {
+	clientOpts := []aws.Option{aws.WithThrottleHandler(func(e aws.ThrottleEvent) {
+		fmt.Fprintf(os.Stderr, "%s: throttled, retrying in %s (retry %d)\n", time.Now().Format(time.RFC3339), e.Delay.Round(time.Millisecond), e.Attempt)
+	})}
+	if roleARN != "" {
+		clientOpts = append(clientOpts, aws.WithRoleARN(roleARN))
+		if mfaSerial != "" {
+			clientOpts = append(clientOpts, aws.WithMFA(mfaSerial, func() (string, error) {
+				fmt.Fprintf(os.Stderr, "Enter MFA code for %s: ", mfaSerial)
+				var code string
+				if _, err := fmt.Scanln(&code); err != nil {
+					return "", fmt.Errorf("failed to read MFA code: %w", err)
+				}
+				return strings.TrimSpace(code), nil
+			}))
+		}
+	}
+	return aws.NewClient(profile, clientOpts...)
+}
+
+// sessionClientOptions are the client options of the interactive explorer:
+// throttling shows in the status line and MFA codes are asked for in the TUI
+func sessionClientOptions(roleARN, mfaSerial string) []aws.Option {
+	clientOpts := []aws.Option{aws.WithThrottleHandler(func(e aws.ThrottleEvent) {
+		throttleHandler(e)
+	}), aws.WithPageSize(*pageSize)}
+	if logging {
+		clientOpts = append(clientOpts, aws.WithLogger(appLog))
+	}
+	if roleARN != "" {
+		clientOpts = append(clientOpts, aws.WithRoleARN(roleARN))
+		if mfaSerial != "" {
+			clientOpts = append(clientOpts, aws.WithMFA(mfaSerial, func() (string, error) {
+				return mfaPrompt()
+			}))
+		}
+	}
+	return clientOpts
+}
+
+// configureClient applies the compressed attributes and decoders given on
+// the command line
+func configureClient(client *aws.Client) error {
+	if *gzipAttrs != "" {
+		client.SetCompressedAttributes(strings.Split(*gzipAttrs, ","))
+	}
+	for _, spec := range decoderSpecs {
+		attr, decoder, err := aws.DecoderSpec(spec)
+		if err != nil {
+			return fmt.Errorf("failed to load decoder: %w", err)
+		}
+		client.RegisterDecoder(attr, decoder)
+	}
+	return nil
+}
+
+// promptMFATokenModal shows an MFA code input in the TUI and blocks until the
+// user submits or cancels it. It must not be called from the UI goroutine.
+func promptMFATokenModal(app *tview.Application, pages *tview.Pages) (string, error) {
+	codes := make(chan string, 1)
+	app.QueueUpdateDraw(func() {
+		form := tview.NewForm()
+		form.AddInputField("MFA code", "", 10, tview.InputFieldInteger, nil)
+		form.AddButton("OK", func() {
+			pages.RemovePage("mfaprompt")
+			codes <- form.GetFormItem(0).(*tview.InputField).GetText()
+		})
+		form.SetCancelFunc(func() {
+			pages.RemovePage("mfaprompt")
+			codes <- ""
+		})
+		form.SetLabelColor(textSecondary).
+			SetFieldBackgroundColor(accentOrange).
+			SetFieldTextColor(tcell.NewHexColor(0x121212)).
+			SetButtonBackgroundColor(accentOrange).
+			SetButtonTextColor(tcell.NewHexColor(0x121212))
+		form.SetBorder(true).
+			SetBorderColor(accentOrange).
+			SetTitle(fmt.Sprintf(" MFA for %s ", *mfaSerial)).
+			SetTitleColor(accentOrange)
+
+		// Center the form over the current page
+		modal := tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(nil, 0, 1, false).
+				AddItem(form, 7, 0, true).
+				AddItem(nil, 0, 1, false), 50, 0, true).
+			AddItem(nil, 0, 1, false)
+		pages.AddPage("mfaprompt", modal, true, true)
+		app.SetFocus(form)
+	})
+
+	select {
+	case code := <-codes:
+		if code == "" {
+			return "", fmt.Errorf("MFA prompt cancelled")
+		}
+		return code, nil
+	case <-time.After(2 * time.Minute):
+		app.QueueUpdateDraw(func() {
+			pages.RemovePage("mfaprompt")
+		})
+		return "", fmt.Errorf("timed out waiting for MFA code")
+	}
+}
+
+// throttleHandler is told about throttled requests that are being retried.
+// Before the TUI starts they are not reported; afterwards a status line is shown.
+var throttleHandler = func(e aws.ThrottleEvent) {}
+
+// tableMissingHandler is told when a request failed because its table or
+// index no longer exists, so the table list can be refreshed
+var tableMissingHandler = func(name string) {}
+
+// runWithReauth runs call in the background and passes its error to done on the
+// UI goroutine. If the call failed because the SSO session or assumed role
+// credentials expired, the user is offered to re-authenticate and the call is retried.
+// A table that no longer exists is reported to tableMissingHandler after done.
+// Once ctx is cancelled the result is dropped and done is not called.
+func runWithReauth(ctx context.Context, app *tview.Application, pages *tview.Pages, client *aws.Client, call func(ctx context.Context) error, done func(err error)) {
+	go func() {
+		defer recoverPanic()
+		err := call(ctx)
+		app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			if !aws.IsExpiredTokenError(err) {
+				done(err)
+				if name, ok := aws.MissingTable(err); ok {
+					tableMissingHandler(name)
+				}
+				return
+			}
+			showReauthModal(app, pages, client, err, func() {
+				runWithReauth(ctx, app, pages, client, call, done)
+			}, func() {
+				done(err)
+			})
+		})
+	}()
+}
+
+// showLoadingModal shows a progress modal and returns a context for the
+// request behind it. ESC or the Cancel button closes the modal and cancels
+// the context, abandoning the in-flight request. The request belongs to the
+// page the modal covers: removing that page cancels it and closes the modal.
+func showLoadingModal(pages *tview.Pages, pageName, text string) context.Context {
+	owner, _ := pages.GetFrontPage()
+	group := tasks.group(owner)
+	ctx, cancel := context.WithCancel(group.ctx)
+	modal := tview.NewModal().
+		SetText(text).
+		SetTextColor(tcell.NewHexColor(0x121212)).
+		AddButtons([]string{"Cancel (ESC)"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			cancel()
+			pages.RemovePage(pageName)
+		})
+	pages.AddPage(pageName, modal, true, true)
+	group.overlays[pageName] = modal
+	return ctx
+}
+
+// showReauthModal offers to re-run the SSO login or reload credentials after they expired
+func showReauthModal(app *tview.Application, pages *tview.Pages, client *aws.Client, cause error, retry func(), cancel func()) {
+	buttons := []string{"Refresh Credentials", "Cancel"}
+	if client.UsesSSO() {
+		buttons = append([]string{"SSO Login"}, buttons...)
+	}
+
+	showAuthError := func(err error) {
+		errorModal := tview.NewModal().
+			SetText(fmt.Sprintf("Re-authentication failed: %v", err)).
+			AddButtons([]string{"OK"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				pages.RemovePage("autherror")
+				cancel()
+			})
+		pages.AddPage("autherror", errorModal, true, true)
+	}
+
+	reauthModal := tview.NewModal().
+		SetText(fmt.Sprintf("AWS credentials for profile %s have expired.\n\n%v", client.Profile(), cause)).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("reauth")
+			switch buttonLabel {
+			case "SSO Login":
+				var loginErr error
+				app.Suspend(func() {
+					cmd := client.SSOLoginCommand()
+					cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+					loginErr = cmd.Run()
+				})
+				if loginErr != nil {
+					showAuthError(loginErr)
+					return
+				}
+				if err := client.RefreshCredentials(); err != nil {
+					showAuthError(err)
+					return
+				}
+				retry()
+			case "Refresh Credentials":
+				if err := client.RefreshCredentials(); err != nil {
+					showAuthError(err)
+					return
+				}
+				retry()
+			default:
+				cancel()
+			}
+		})
+	pages.AddPage("reauth", reauthModal, true, true)
+}
+
 func main() {
+	aws.RecoverPanic = recoverPanic
+	if len(os.Args) > 1 && os.Args[1] == "watch-table" {
+		os.Exit(runWatchTable(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export-inventory" {
+		os.Exit(runInventory(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "describe" {
+		os.Exit(runDescribe(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	// Show help if requested
@@ -213,60 +778,216 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Apply custom theme before creating any widgets
-	applyCustomTheme()
+	// Defaults from config.yaml fill in the flags that weren't given
+	var err error
+	if configDefaults, err = loadDefaults(); err == nil {
+		err = applyDefaults(configDefaults)
+	}
+	if err == nil {
+		err = bindKeys(configDefaults.Keys)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	// Validate profile
-	if *profile != "dev" && *profile != "prod" {
-		fmt.Printf("Invalid profile: %s. Must be 'dev' or 'prod'\n", *profile)
+	if *pageSize < 1 {
+		fmt.Printf("Invalid --page-size: %d. Must be at least 1\n", *pageSize)
 		os.Exit(1)
 	}
 
-	// Create AWS client
-	client, err := aws.NewClient(*profile)
+	if *autoRefresh < 0 || *autoRefresh > 0 && *autoRefresh < minAutoRefresh {
+		fmt.Printf("Invalid --auto-refresh: %s. Must be at least %s\n", *autoRefresh, minAutoRefresh)
+		os.Exit(1)
+	}
+
+	if *watchInterval < time.Second {
+		fmt.Printf("Invalid --watch-interval: %s. Must be at least 1s\n", *watchInterval)
+		os.Exit(1)
+	}
+
+	if *idleLockAfter < 0 {
+		fmt.Printf("Invalid --idle-lock: %s. Must not be negative\n", *idleLockAfter)
+		os.Exit(1)
+	}
+
+	if *unusedDays < 1 || *unusedDays > maxUnusedDays {
+		fmt.Printf("Invalid --unused-days: %d. Must be between 1 and %d\n", *unusedDays, maxUnusedDays)
+		os.Exit(1)
+	}
+
+	if err := setLocale(*localeTag); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Apply the theme before creating any widgets
+	if err := applyTheme(*themeName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	logPath, err := openLog()
 	if err != nil {
-		fmt.Printf("Failed to create AWS client: %v\n", err)
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if logPath != "" {
+		fmt.Printf("Logging to %s\n", logPath)
+	}
+
+	// Create AWS client
+	var client *aws.Client
+	if *demo {
+		if client, err = newDemoClient(*region); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		*profile, *envName = client.Profile(), "" // Neither names the demo's account
+		fmt.Printf("Running the demo on generated in-memory tables (region %s)\n", client.Region())
+	} else {
+		clientOpts := sessionClientOptions(*roleARN, *mfaSerial)
+		connectOpts := slices.Clone(clientOpts)
+		if *region != "" {
+			connectOpts = append(connectOpts, aws.WithRegion(*region))
+		}
+		if *endpoint != "" {
+			connectOpts = append(connectOpts, aws.WithEndpoint(*endpoint))
+		}
+
+		// Test the connection, offering the other profiles and regions if it fails
+		client, err = aws.NewClient(*profile, connectOpts...)
+		if err == nil {
+			err = client.TestConnection(context.Background())
+		}
+		if err != nil {
+			appLog.Warn("connection failed", "profile", *profile, "error", err.Error())
+			fmt.Printf("Failed to connect to AWS: %v\n", err)
+			if client != nil && aws.IsExpiredTokenError(err) && client.UsesSSO() {
+				fmt.Printf("Your SSO session has expired. Run: aws sso login --profile %s\n", *profile)
+			}
+			if client = chooseConnection(*profile, *region, *endpoint, clientOpts, err); client == nil {
+				os.Exit(1)
+			}
+			*profile = client.Profile()
+			*envName = "" // The picked connection is no longer the named environment
+		}
+
+		fmt.Printf("Connected to AWS successfully (profile %s, region %s)\n", client.Profile(), client.Region())
+	}
+
+	if err := configureClient(client); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, spec := range shardSpecs {
+		target, pattern, err := aws.ShardSpec(spec)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		shardPatterns[target] = pattern
+	}
+	if err := loadDualWrites(); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// Test connection
-	if err := client.TestConnection(); err != nil {
-		fmt.Printf("Failed to connect to AWS: %v\n", err)
+	if userSettings, err = loadSettings(); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Connected to AWS successfully")
+	// A session ends when the app quits, or with the client to continue with
+	// when Ctrl+P switched to another profile or environment
+	for client != nil {
+		client = runSession(client)
+	}
+}
+
+// runSession runs the explorer with a connected client until it quits. It
+// returns the client picked with Ctrl+P on the table list, or nil.
+func runSession(client *aws.Client) *aws.Client {
+	tables = nil
+	pinnedRows = nil // Keys of another account or region would match other items
+	session = nil
+	var next *aws.Client
+	appLog.Info("session started", "profile", client.Profile(), "region", client.Region(), "env", *envName)
+
+	// Tint borders by environment so prod is never mistaken for dev
+	var accountID string
+	if len(userSettings.Tiers) > 0 {
+		accountID, _ = client.AccountID(context.Background()) // Profile mappings still apply without it
+	}
+	environment, err := resolveEnvironment(userSettings.Tiers, *profile, accountID)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if tier := configDefaults.Environments[*envName].Tier; tier != "" {
+		environment = tier
+	}
+	envColor := environmentColor(environment)
+	tview.Styles.BorderColor = envColor
 
 	// Create Tview app
 	app := tview.NewApplication()
 
 	// Create pages
 	pages := tview.NewPages()
+	tasks = newPageTasks(app, pages)
+	status = newStatusBar(app, pages, client)
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		appScreen = screen
+		tasks.sweep()
+		status.render()
+		return false
+	})
 
-	// Create table
-	table := tview.NewTable().
-		SetBorders(true).
-		SetSelectable(true, false)
-	
-	// Create filter input
-	filterInput := tview.NewInputField().
-		SetLabel("Filter: ").
-		SetFieldWidth(0).
-		SetLabelColor(textSecondary).
-		SetFieldBackgroundColor(accentOrange).
-		SetFieldTextColor(tcell.NewHexColor(0x121212))
-	
-	var filteredTables []aws.TableInfo
-	
-	// Wrap table in flex to add margins and center it
-	tableFlex := tview.NewFlex().
-		AddItem(nil, 0, 1, false).                    // Left margin
-		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
-			AddItem(nil, 1, 0, false).                 // Top margin
-			AddItem(filterInput, 1, 0, false).         // Filter input
-			AddItem(table, 0, 1, true).                // Table
-			AddItem(nil, 1, 0, false), 0, 3, true).   // Bottom margin
-		AddItem(nil, 0, 1, false)                     // Right margin
+	// From now on MFA codes for role re-assumption are requested inside the TUI
+	mfaPrompt = func() (string, error) {
+		return promptMFATokenModal(app, pages)
+	}
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow)
+	envTitle := ""
+	if environment != "" || *envName != "" {
+		label := strings.ToUpper(environment)
+		switch {
+		case environment == "":
+			label = *envName
+		case *envName != "" && *envName != environment:
+			label = fmt.Sprintf("%s (%s)", *envName, strings.ToUpper(environment))
+		}
+		envTitle = fmt.Sprintf(" %s - profile %s ", label, *profile)
+		if accountID != "" {
+			envTitle = fmt.Sprintf(" %s - profile %s - account %s ", label, *profile, accountID)
+		}
+	}
+	if envTitle != "" && *screenReader {
+		// No frame to color, so the environment is announced on the first line
+		root.AddItem(tview.NewTextView().SetText("Environment:"+envTitle), 1, 0, false)
+	}
+	root.AddItem(pages, 0, 1, true).
+		AddItem(status.view, 1, 0, false)
+	if envTitle != "" && !*screenReader {
+		root.SetBorder(true).
+			SetBorderColor(envColor).
+			SetTitle(envTitle).
+			SetTitleColor(envColor)
+	}
+	throttleHandler = func(e aws.ThrottleEvent) {
+		app.QueueUpdateDraw(func() {
+			// Shown until no further throttling was reported for a while
+			status.notify(fmt.Sprintf("[#ffd60a]Throttled by DynamoDB, retrying in %s (retry %d)...[-]",
+				e.Delay.Round(100*time.Millisecond), e.Attempt), e.Delay+2*time.Second)
+		})
+	}
+
+	roleLine := ""
+	if *roleARN != "" {
+		roleLine = fmt.Sprintf("[gray]Role: %s[white::-]\n", *roleARN)
+	}
 
 	// Create MOTD-style loading screen
 	loadingText := fmt.Sprintf(`
@@ -282,7 +1003,12 @@ func main() {
 
 
 [gray]Profile: %s[white::-]
-`, *profile)
+%s`, *profile, roleLine)
+
+	if *screenReader {
+		// Skip the ASCII art banner, which is read out character by character
+		loadingText = fmt.Sprintf("DDB Explorer. Loading tables for profile %s.\n%s", *profile, roleLine)
+	}
 
 	loadingView := tview.NewTextView().
 		SetText(loadingText).
@@ -297,1093 +1023,61 @@ func main() {
 
 	// Add loading screen as initial page
 	pages.AddPage("loading", loadingView, true, true)
-	pages.AddPage("tablelist", tableFlex, true, false)
-
-	// Function to populate table
-	populateTable := func(tablesToShow []aws.TableInfo) {
-		table.Clear()
-		
-		// Set headers
-		headers := []string{"Table Name", "Status", "Item Count", "Size"}
-		for col, header := range headers {
-			table.SetCell(0, col, tview.NewTableCell(header).
-				SetTextColor(tview.Styles.SecondaryTextColor).
-				SetSelectable(false).
-				SetAlign(tview.AlignCenter))
-		}
-		
-		if len(tablesToShow) == 0 {
-			table.SetCell(1, 0, tview.NewTableCell("No tables found.").
-				SetTextColor(tview.Styles.PrimaryTextColor))
-		} else {
-			for i, t := range tablesToShow {
-				table.SetCell(i+1, 0, tview.NewTableCell(t.Name).SetTextColor(tview.Styles.PrimaryTextColor))
-				table.SetCell(i+1, 1, tview.NewTableCell(t.Status).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignCenter))
-				table.SetCell(i+1, 2, tview.NewTableCell(formatWithCommas(t.ItemCount)).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
-				table.SetCell(i+1, 3, tview.NewTableCell(formatBytes(t.SizeBytes)).SetTextColor(tview.Styles.PrimaryTextColor).SetAlign(tview.AlignRight))
-			}
-			table.ScrollToBeginning()
-		}
+
+	// The list of tables, shown once they are listed
+	list := newTableListPage(app, pages, client, environment, func(c *aws.Client) {
+		next = c
+		app.Stop()
+	})
+	list.load()
+	if *autoRefresh > 0 {
+		list.startAutoRefresh()
 	}
-	
-	// Filter input change handler
-	filterInput.SetChangedFunc(func(text string) {
-		if text == "" {
-			filteredTables = tables
-		} else {
-			filteredTables = []aws.TableInfo{}
-			lowerText := strings.ToLower(text)
-			for _, t := range tables {
-				if strings.Contains(strings.ToLower(t.Name), lowerText) {
-					filteredTables = append(filteredTables, t)
-				}
+
+	// Tables deleted or renamed since they were listed are dropped from the
+	// list, and similarly named tables offered instead
+	tableMissingHandler = list.handleMissingTable
+
+	var lock *idleLock
+	if *idleLockAfter > 0 {
+		lock = startIdleLock(app, pages, client, *idleLockAfter, environment == "prod")
+	}
+
+	// Ctrl+C quits, except on results pages while it opens the column chooser
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if lock != nil {
+			if event = lock.handleKey(event); event == nil {
+				return nil
 			}
 		}
-		populateTable(filteredTables)
-	})
-	
-	// Set input capture for filter
-	filterInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyESC {
-			if filterInput.GetText() != "" {
-				filterInput.SetText("")
-			}
-			app.SetFocus(table)
-			return nil
-		} else if event.Key() == tcell.KeyDown || event.Key() == tcell.KeyEnter {
-			app.SetFocus(table)
+		if event = handleKeyHelp(app, pages, event); event == nil {
 			return nil
 		}
-		return event
-	})
-	
-	// Set input capture
-	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyESC || event.Rune() == 'q' {
-			app.Stop()
-		} else if event.Key() == tcell.KeyCtrlH {
-			pages.AddPage("help", createHelpModal(pages), true, true)
-			return nil
-		} else if event.Key() == tcell.KeyEnter {
-			row, _ := table.GetSelection()
-			currentTables := filteredTables
-			if len(currentTables) == 0 {
-				currentTables = tables
-			}
-			if row > 0 && row <= len(currentTables) {
-				selectedTable := currentTables[row-1]
-				createTableActionPage(pages, app, selectedTable, client)
-				pages.SwitchToPage("tableaction")
+		if event.Key() == tcell.KeyCtrlC && keyPressed(event, "results.columns") {
+			if name, _ := pages.GetFrontPage(); strings.HasSuffix(name, "result") {
+				return tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone) // Forwarded without quitting
 			}
-		} else if event.Rune() != 0 && event.Key() != tcell.KeyEnter {
-			// Start typing - switch to filter
-			filterInput.SetText(string(event.Rune()))
-			app.SetFocus(filterInput)
-			return nil
 		}
 		return event
 	})
 
-	// Load tables asynchronously
-	go func() {
-		tableInfos, err := client.ListTables()
-		app.QueueUpdateDraw(func() {
-			// Switch from loading screen to table list
-			pages.SwitchToPage("tablelist")
-
-			if err != nil {
-				table.Clear()
-				table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("Error: %v", err)).
-					SetTextColor(tview.Styles.PrimaryTextColor))
-			} else {
-				tables = tableInfos
-				filteredTables = tableInfos
-				populateTable(filteredTables)
-			}
-		})
-	}()
+	enableMouse(app, pages)
 
 	// Set root to pages
-	app.SetRoot(pages, true).SetFocus(table)
+	app.SetRoot(root, true).SetFocus(list.table)
 
 	// Run app
-	if err := app.Run(); err != nil {
+	err = runApp(app)
+	tasks.stop()
+	if lock != nil {
+		lock.stop()
+	}
+	if err != nil {
 		fmt.Printf("Error running app: %v\n", err)
 		os.Exit(1)
 	}
-}
-
-func createTableActionPage(pages *tview.Pages, app *tview.Application, tableInfo aws.TableInfo, client *aws.Client) {
-	// Create flex layout
-	flex := tview.NewFlex().SetDirection(tview.FlexRow)
-
-	// Header
-	header := tview.NewTextView().
-		SetText(fmt.Sprintf("Table: %s (Ctrl+Q: Query | Ctrl+S: Scan)", tableInfo.Name)).
-		SetTextAlign(tview.AlignCenter).
-		SetDynamicColors(true)
-	flex.AddItem(header, 1, 0, false)
-
-	// Form for inputs
-	form := tview.NewForm()
-	form.SetCancelFunc(func() {
-		pages.SwitchToPage("tablelist")
-	})
-	
-	// Apply form styling
-	form.SetLabelColor(textSecondary).
-		SetFieldBackgroundColor(accentOrange).
-		SetFieldTextColor(tcell.NewHexColor(0x121212)).
-		SetButtonBackgroundColor(accentOrange).
-		SetButtonTextColor(tcell.NewHexColor(0x121212))
-
-	// Tabs flex
-	tabsFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
-
-	// Query tab
-	queryTab := tview.NewTextView().
-		SetText("[ Query ]").
-		SetTextAlign(tview.AlignCenter).
-		SetDynamicColors(true).
-		SetTextColor(tcell.NewHexColor(0x121212))
-	queryTab.SetBackgroundColor(accentOrange)
-	tabsFlex.AddItem(queryTab, 0, 1, true)
-
-	// Scan tab
-	scanTab := tview.NewTextView().
-		SetText("  Scan  ").
-		SetTextAlign(tview.AlignCenter).
-		SetDynamicColors(true).
-		SetTextColor(textSecondary)
-	scanTab.SetBackgroundColor(bgSecondary)
-	tabsFlex.AddItem(scanTab, 0, 1, false)
-
-	flex.AddItem(tabsFlex, 1, 0, false)
-	flex.AddItem(form, 0, 1, true)
-
-	// Function to update form based on tab
-	updateForm := func(tab int) {
-		form.Clear(true)
-		if tab == 0 { // Query
-			if tableInfo.PartitionKey != "" {
-				form.AddInputField(fmt.Sprintf("Partition Key (%s)", tableInfo.PartitionKey), "", 20, nil, nil)
-			}
-			if tableInfo.SortKey != "" {
-				form.AddInputField(fmt.Sprintf("Sort Key (%s)", tableInfo.SortKey), "", 20, nil, nil)
-				form.AddDropDown("Condition", []string{"=", "begins_with", "<", "<=", ">", ">=", "between"}, 0, nil)
-			}
-			form.AddButton("Query", func() {
-				// Get form values
-				var pkValue, skValue, condition string
-				if tableInfo.PartitionKey != "" {
-					pkValue = form.GetFormItem(0).(*tview.InputField).GetText()
-				}
-				if tableInfo.SortKey != "" {
-					skValue = form.GetFormItem(1).(*tview.InputField).GetText()
-					_, condition = form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
-				}
-
-				// Show loading
-				loadingModal := tview.NewModal().
-					SetText("Querying...").
-					SetTextColor(tcell.NewHexColor(0x121212)).
-					SetDoneFunc(func(buttonIndex int, buttonLabel string) {})
-				pages.AddPage("loading", loadingModal, false, true)
-
-				// Perform query async
-				go func() {
-					var sortKey, sortValue, cond string
-					if skValue != "" {
-						sortKey = tableInfo.SortKey
-						sortValue = skValue
-						cond = condition
-					}
-					result, err := client.Query(tableInfo.Name, tableInfo.PartitionKey, pkValue, sortKey, sortValue, cond, nil)
-
-					app.QueueUpdateDraw(func() {
-						pages.RemovePage("loading")
-						if err != nil {
-							errorModal := tview.NewModal().
-								SetText(fmt.Sprintf("Query error: %v", err)).
-								AddButtons([]string{"OK"}).
-								SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-									pages.RemovePage("queryerror")
-								})
-							pages.AddPage("queryerror", errorModal, true, true)
-						} else {
-							// Create results table
-							resultsTable := tview.NewTable().
-					SetBorders(true).
-							 SetSelectable(true, false)
-
-							// Detect additional fields to display (title, name, etc.)
-							var additionalFields []string
-							if len(result.Items) > 0 {
-								firstItem := result.Items[0]
-								// Common field names to look for
-								candidateFields := []string{"title", "Title", "name", "Name", "displayName", "description", "Description", "email", "Email"}
-								for _, field := range candidateFields {
-									if _, exists := firstItem[field]; exists {
-										// Skip if it's already a key field
-										if field != tableInfo.PartitionKey && field != tableInfo.SortKey {
-											additionalFields = append(additionalFields, field)
-											if len(additionalFields) >= 2 {
-												break
-											}
-										}
-									}
-								}
-							}
-
-							// Headers
-							headers := []string{tableInfo.PartitionKey}
-							if tableInfo.SortKey != "" {
-							 headers = append(headers, tableInfo.SortKey)
-				}
-							headers = append(headers, additionalFields...)
-							
-							for col, header := range headers {
-							 resultsTable.SetCell(0, col, tview.NewTableCell(header).
-							 SetTextColor(tview.Styles.SecondaryTextColor).
-							 SetSelectable(false).
-						SetAlign(tview.AlignCenter))
-							}
-
-							// Data
-							if len(result.Items) == 0 {
-							 resultsTable.SetCell(1, 0, tview.NewTableCell("No items found.").
-							 SetTextColor(tview.Styles.PrimaryTextColor))
-							} else {
-							for i, item := range result.Items {
-							col := 0
-							resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.PartitionKey])).
-							SetTextColor(tview.Styles.PrimaryTextColor))
-							col++
-							if tableInfo.SortKey != "" {
-							resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.SortKey])).
-							SetTextColor(tview.Styles.PrimaryTextColor))
-							col++
-							}
-							// Add additional fields
-							for _, field := range additionalFields {
-								value := ""
-								if v, ok := item[field]; ok {
-									value = fmt.Sprintf("%v", v)
-									// Truncate if too long
-									if len(value) > 50 {
-										value = value[:47] + "..."
-									}
-								}
-								resultsTable.SetCell(i+1, col, tview.NewTableCell(value).
-									SetTextColor(tview.Styles.PrimaryTextColor))
-								col++
-							}
-							}
-							 resultsTable.ScrollToBeginning()
-				}
-
-				// Add page
-				resultsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-				pageHeader := tview.NewTextView().
-					SetText(fmt.Sprintf("Query Results for %s - Page 1", tableInfo.Name)).
-					SetTextAlign(tview.AlignCenter)
-				resultsFlex.AddItem(pageHeader, 1, 0, false)
-				resultsFlex.AddItem(resultsTable, 0, 1, true)
-
-				currentPage := 1
-				
-				// Track pagination history
-				type pageState struct {
-					items            []map[string]interface{}
-					lastEvaluatedKey map[string]interface{}
-				}
-				pageHistory := []pageState{{items: result.Items, lastEvaluatedKey: result.LastEvaluatedKey}}
-
-				// Function to update results table with new items
-				updateResultsTable := func(newResult aws.QueryResult, page int, fields []string) {
-					resultsTable.Clear()
-					
-					// Re-add headers
-					headers := []string{tableInfo.PartitionKey}
-					if tableInfo.SortKey != "" {
-						headers = append(headers, tableInfo.SortKey)
-					}
-					headers = append(headers, fields...)
-					
-					for col, header := range headers {
-						resultsTable.SetCell(0, col, tview.NewTableCell(header).
-							SetTextColor(tview.Styles.SecondaryTextColor).
-							SetSelectable(false).
-							SetAlign(tview.AlignCenter))
-					}
-
-					// Add new data
-					if len(newResult.Items) == 0 {
-						resultsTable.SetCell(1, 0, tview.NewTableCell("No items found.").
-							SetTextColor(tview.Styles.PrimaryTextColor))
-					} else {
-						for i, item := range newResult.Items {
-							col := 0
-							resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.PartitionKey])).
-								SetTextColor(tview.Styles.PrimaryTextColor))
-							col++
-							if tableInfo.SortKey != "" {
-								resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.SortKey])).
-									SetTextColor(tview.Styles.PrimaryTextColor))
-								col++
-							}
-							// Add additional fields
-							for _, field := range fields {
-								value := ""
-								if v, ok := item[field]; ok {
-									value = fmt.Sprintf("%v", v)
-									// Truncate if too long
-									if len(value) > 50 {
-										value = value[:47] + "..."
-									}
-								}
-								resultsTable.SetCell(i+1, col, tview.NewTableCell(value).
-									SetTextColor(tview.Styles.PrimaryTextColor))
-								col++
-							}
-						}
-						resultsTable.ScrollToBeginning()
-					}
-					
-					// Update result reference
-					result = newResult
-					
-					// Update page header
-					pageHeader.SetText(fmt.Sprintf("Query Results for %s - Page %d", tableInfo.Name, page))
-				}
-
-				// Add navigation buttons
-				navFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
-				
-				btnStyle := tcell.StyleDefault.Background(accentOrange).Foreground(tcell.NewHexColor(0x121212))
-				
-				loadPrevBtn := tview.NewButton("< Previous (Ctrl+B)").SetSelectedFunc(func() {
-					if currentPage > 1 {
-						currentPage--
-						prevState := pageHistory[currentPage-1]
-						updateResultsTable(aws.QueryResult{Items: prevState.items, LastEvaluatedKey: prevState.lastEvaluatedKey}, currentPage, additionalFields)
-					}
-				})
-				loadPrevBtn.SetStyle(btnStyle)
-				navFlex.AddItem(loadPrevBtn, 0, 1, false)
-				
-				var loadNextBtn *tview.Button
-				if result.LastEvaluatedKey != nil {
-					loadNextBtn = tview.NewButton("Next > (Ctrl+N)").SetSelectedFunc(func() {
-						// Load next page
-						nextResult, err := client.Query(tableInfo.Name, tableInfo.PartitionKey, pkValue, sortKey, sortValue, cond, result.LastEvaluatedKey)
-						if err != nil {
-							return
-						}
-						currentPage++
-						
-						// Add to history if it's a new page
-						if currentPage > len(pageHistory) {
-							pageHistory = append(pageHistory, pageState{items: nextResult.Items, lastEvaluatedKey: nextResult.LastEvaluatedKey})
-						}
-						
-						updateResultsTable(nextResult, currentPage, additionalFields)
-						
-						// If no more pages, remove next button
-						if nextResult.LastEvaluatedKey == nil && loadNextBtn != nil {
-							navFlex.RemoveItem(loadNextBtn)
-						}
-					})
-					loadNextBtn.SetStyle(btnStyle)
-					navFlex.AddItem(loadNextBtn, 0, 1, false)
-				}
-				
-				resultsFlex.AddItem(navFlex, 1, 0, false)
-							resultsFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-							if event.Key() == tcell.KeyESC {
-							pages.RemovePage("queryresult")
-							} else if event.Key() == tcell.KeyCtrlH {
-								pages.AddPage("help", createHelpModal(pages), true, true)
-								return nil
-							} else if event.Key() == tcell.KeyCtrlB {
-						// Go back to previous page
-						if currentPage > 1 {
-							currentPage--
-							prevState := pageHistory[currentPage-1]
-							updateResultsTable(aws.QueryResult{Items: prevState.items, LastEvaluatedKey: prevState.lastEvaluatedKey}, currentPage, additionalFields)
-						}
-					} else if event.Key() == tcell.KeyCtrlN && result.LastEvaluatedKey != nil {
-						// Load next page with Ctrl+N
-						nextResult, err := client.Query(tableInfo.Name, tableInfo.PartitionKey, pkValue, sortKey, sortValue, cond, result.LastEvaluatedKey)
-						if err != nil {
-							return event
-						}
-						currentPage++
-						
-						// Add to history if it's a new page
-						if currentPage > len(pageHistory) {
-							pageHistory = append(pageHistory, pageState{items: nextResult.Items, lastEvaluatedKey: nextResult.LastEvaluatedKey})
-						}
-						
-						updateResultsTable(nextResult, currentPage, additionalFields)
-						
-						if nextResult.LastEvaluatedKey == nil && loadNextBtn != nil {
-							navFlex.RemoveItem(loadNextBtn)
-						}
-					} else if event.Key() == tcell.KeyEnter {
-									row, _ := resultsTable.GetSelection()
-									if row > 0 && row <= len(result.Items) {
-										item := result.Items[row-1]
-										rawItem := result.RawItems[row-1]
-										
-										// Create item table (QUERY RESULTS)
-										itemTable := tview.NewTable().
-											SetBorders(true).
-											SetSelectable(true, false)
-
-										// Headers
-										itemTable.SetCell(0, 0, tview.NewTableCell("Field").
-											SetTextColor(tview.Styles.SecondaryTextColor).
-											SetSelectable(false).
-											SetAlign(tview.AlignCenter))
-										itemTable.SetCell(0, 1, tview.NewTableCell("Value").
-											SetTextColor(tview.Styles.SecondaryTextColor).
-											SetSelectable(false).
-											SetAlign(tview.AlignCenter))
-										
-										// Function to save item as JSON
-										saveItemAsJSON := func() {
-											// Generate filename from keys
-											pkValue := fmt.Sprintf("%v", rawItem[tableInfo.PartitionKey])
-											filename := pkValue
-											if tableInfo.SortKey != "" {
-												skValue := fmt.Sprintf("%v", rawItem[tableInfo.SortKey])
-												filename = fmt.Sprintf("%s_%s", pkValue, skValue)
-											}
-											// Clean filename (remove special characters)
-											filename = strings.ReplaceAll(filename, "/", "_")
-											filename = strings.ReplaceAll(filename, " ", "_")
-											filename = strings.ReplaceAll(filename, ":", "_")
-											filename += ".json"
-											
-											// Marshal to JSON
-											jsonBytes, err := json.MarshalIndent(rawItem, "", "    ")
-											if err != nil {
-												// Show error
-												errorModal := tview.NewModal().
-													SetText(fmt.Sprintf("Error saving JSON: %v", err)).
-													AddButtons([]string{"OK"}).
-													SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-														pages.RemovePage("saveerror")
-													})
-												pages.AddPage("saveerror", errorModal, true, true)
-												return
-											}
-											
-											// Write to file
-											err = os.WriteFile(filename, jsonBytes, 0644)
-											if err != nil {
-												// Show error
-												errorModal := tview.NewModal().
-													SetText(fmt.Sprintf("Error writing file: %v", err)).
-													AddButtons([]string{"OK"}).
-													SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-														pages.RemovePage("saveerror")
-													})
-												pages.AddPage("saveerror", errorModal, true, true)
-												return
-											}
-											
-											// Show success
-											successModal := tview.NewModal().
-												SetText(fmt.Sprintf("Saved to: %s", filename)).
-												AddButtons([]string{"OK"}).
-												SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-													pages.RemovePage("savesuccess")
-												})
-											pages.AddPage("savesuccess", successModal, true, true)
-										}
-
-										// Data: schema fields first
-										i := 1
-										// Schema fields
-										for _, sf := range tableInfo.SchemaFields {
-											if v, ok := item[sf]; ok {
-												displayValue := fmt.Sprintf("%v", v)
-												itemTable.SetCell(i, 0, tview.NewTableCell(sf).
-													SetTextColor(accentTeal).
-													SetSelectable(true))
-												itemTable.SetCell(i, 1, tview.NewTableCell(displayValue).
-													SetTextColor(accentTeal).
-													SetSelectable(true))
-												delete(item, sf)
-												i++
-											}
-										}
-										// Other fields
-										for k, v := range item {
-											displayValue := fmt.Sprintf("%v", v)
-											itemTable.SetCell(i, 0, tview.NewTableCell(k).
-												SetTextColor(tview.Styles.PrimaryTextColor).
-												SetSelectable(true))
-											itemTable.SetCell(i, 1, tview.NewTableCell(displayValue).
-												SetTextColor(tview.Styles.PrimaryTextColor).
-												SetSelectable(true))
-											i++
-										}
-										itemTable.ScrollToBeginning()
-
-										// Create flex for the table
-										itemFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-										itemFlex.AddItem(tview.NewTextView().SetText("Full Item (Ctrl+D: download | Ctrl+H: help)").SetTextAlign(tview.AlignCenter), 1, 0, false)
-										itemFlex.AddItem(itemTable, 0, 1, true)
-										itemFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-											if event.Key() == tcell.KeyESC {
-												pages.RemovePage("fullitem")
-											} else if event.Key() == tcell.KeyCtrlH {
-												pages.AddPage("help", createHelpModal(pages), true, true)
-												return nil
-											} else if event.Key() == tcell.KeyCtrlD {
-												saveItemAsJSON()
-												return nil
-											} else if event.Key() == tcell.KeyEnter {
-												row, _ := itemTable.GetSelection()
-												if row > 0 {
-													fieldCell := itemTable.GetCell(row, 0)
-													fieldName := fieldCell.Text
-													if v, ok := rawItem[fieldName]; ok {
-														// Check if it's a complex type (map or slice)
-														switch v.(type) {
-														case map[string]interface{}, []interface{}:
-															// Format as JSON
-															jsonBytes, err := json.MarshalIndent(v, "", "    ")
-															if err != nil {
-																jsonBytes = []byte(fmt.Sprintf("Error formatting JSON: %v", err))
-															}
-															jsonView := tview.NewTextView().
-																SetText(string(jsonBytes)).
-																SetTextAlign(tview.AlignLeft).
-																SetDynamicColors(true).
-																SetScrollable(true).
-																SetWrap(true)
-															
-															jsonFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-															jsonFlex.AddItem(tview.NewTextView().SetText(fmt.Sprintf("JSON View - %s (Space: page down, ESC: close)", fieldName)).SetTextAlign(tview.AlignCenter), 1, 0, false)
-															jsonFlex.AddItem(jsonView, 0, 1, true)
-															
-															jsonView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-																if event.Key() == tcell.KeyESC {
-																	pages.RemovePage("jsonview")
-																	return nil
-																} else if event.Rune() == ' ' {
-																	// Scroll down by page
-																	row, col := jsonView.GetScrollOffset()
-																	_, _, _, height := jsonView.GetInnerRect()
-																	jsonView.ScrollTo(row+height-1, col)
-																	return nil
-																}
-																return event
-															})
-															
-															pages.AddPage("jsonview", jsonFlex, true, true)
-															app.SetFocus(jsonView)
-														}
-													}
-												}
-											}
-											return event
-										})
-
-										pages.AddPage("fullitem", itemFlex, true, true)
-									}
-								}
-								return event
-							})
-							pages.AddPage("queryresult", resultsFlex, true, true)
-						}
-					})
-				}()
-			})
-			
-			// Set focus to form itself to enable Tab navigation
-			app.SetFocus(form)
-		} else { // Scan
-			form.AddButton(fmt.Sprintf("Scan %s", tableInfo.Name), func() {
-				// Show loading modal
-				loadingModal := tview.NewModal().
-					SetText("Scanning...").
-					SetTextColor(tcell.NewHexColor(0x121212))
-				pages.AddPage("loadingscan", loadingModal, true, true)
-
-				// Perform scan async
-				go func() {
-					result, err := client.Scan(tableInfo.Name, nil)
-
-					app.QueueUpdateDraw(func() {
-						pages.RemovePage("loadingscan")
-						pages.RemovePage("scanresult") // Remove any existing scan results
-						if err != nil {
-							errorModal := tview.NewModal().
-								SetText(fmt.Sprintf("Scan error: %v", err)).
-								AddButtons([]string{"OK"}).
-								SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-									pages.RemovePage("scanerror")
-								})
-							pages.AddPage("scanerror", errorModal, true, true)
-						} else {
-							// Create results table
-							resultsTable := tview.NewTable().
-								SetBorders(true).
-								SetSelectable(true, false)
-
-							// Detect additional fields to display (title, name, etc.)
-							var additionalFields []string
-							if len(result.Items) > 0 {
-								firstItem := result.Items[0]
-								// Common field names to look for
-								candidateFields := []string{"title", "Title", "name", "Name", "displayName", "description", "Description", "email", "Email"}
-								for _, field := range candidateFields {
-									if _, exists := firstItem[field]; exists {
-										// Skip if it's already a key field
-										if field != tableInfo.PartitionKey && field != tableInfo.SortKey {
-											additionalFields = append(additionalFields, field)
-											if len(additionalFields) >= 2 {
-												break
-											}
-										}
-									}
-								}
-							}
-
-							// Headers
-							headers := []string{tableInfo.PartitionKey}
-							if tableInfo.SortKey != "" {
-								headers = append(headers, tableInfo.SortKey)
-							}
-							headers = append(headers, additionalFields...)
-							
-							for col, header := range headers {
-								resultsTable.SetCell(0, col, tview.NewTableCell(header).
-									SetTextColor(tview.Styles.SecondaryTextColor).
-									SetSelectable(false).
-									SetAlign(tview.AlignCenter))
-							}
-
-							// Data
-							if len(result.Items) == 0 {
-								resultsTable.SetCell(1, 0, tview.NewTableCell("No items found.").
-									SetTextColor(tview.Styles.PrimaryTextColor))
-							} else {
-								for i, item := range result.Items {
-									col := 0
-									resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.PartitionKey])).
-										SetTextColor(tview.Styles.PrimaryTextColor))
-									col++
-									if tableInfo.SortKey != "" {
-										resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.SortKey])).
-											SetTextColor(tview.Styles.PrimaryTextColor))
-										col++
-									}
-									// Add additional fields
-									for _, field := range additionalFields {
-										value := ""
-										if v, ok := item[field]; ok {
-											value = fmt.Sprintf("%v", v)
-											// Truncate if too long
-											if len(value) > 50 {
-												value = value[:47] + "..."
-											}
-										}
-										resultsTable.SetCell(i+1, col, tview.NewTableCell(value).
-											SetTextColor(tview.Styles.PrimaryTextColor))
-										col++
-									}
-								}
-								resultsTable.ScrollToBeginning()
-							}
-
-							// Add page
-							resultsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-							pageHeader := tview.NewTextView().
-								SetText(fmt.Sprintf("Scan Results for %s - Page 1", tableInfo.Name)).
-								SetTextAlign(tview.AlignCenter)
-							resultsFlex.AddItem(pageHeader, 1, 0, false)
-							resultsFlex.AddItem(resultsTable, 0, 1, true)
-
-							currentPage := 1
-							
-							// Track pagination history
-							type pageState struct {
-								items            []map[string]interface{}
-								rawItems         []map[string]interface{}
-								lastEvaluatedKey map[string]interface{}
-							}
-							pageHistory := []pageState{{items: result.Items, rawItems: result.RawItems, lastEvaluatedKey: result.LastEvaluatedKey}}
-
-							// Function to update results table with new items
-							updateResultsTable := func(newResult aws.QueryResult, page int, fields []string) {
-								resultsTable.Clear()
-								
-								// Re-add headers
-								headers := []string{tableInfo.PartitionKey}
-								if tableInfo.SortKey != "" {
-									headers = append(headers, tableInfo.SortKey)
-								}
-								headers = append(headers, fields...)
-								
-								for col, header := range headers {
-									resultsTable.SetCell(0, col, tview.NewTableCell(header).
-										SetTextColor(tview.Styles.SecondaryTextColor).
-										SetSelectable(false).
-										SetAlign(tview.AlignCenter))
-								}
-
-								// Add new data
-								if len(newResult.Items) == 0 {
-									resultsTable.SetCell(1, 0, tview.NewTableCell("No items found.").
-										SetTextColor(tview.Styles.PrimaryTextColor))
-								} else {
-									for i, item := range newResult.Items {
-										col := 0
-										resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.PartitionKey])).
-											SetTextColor(tview.Styles.PrimaryTextColor))
-										col++
-										if tableInfo.SortKey != "" {
-											resultsTable.SetCell(i+1, col, tview.NewTableCell(fmt.Sprintf("%v", item[tableInfo.SortKey])).
-												SetTextColor(tview.Styles.PrimaryTextColor))
-											col++
-										}
-										// Add additional fields
-										for _, field := range fields {
-											value := ""
-											if v, ok := item[field]; ok {
-												value = fmt.Sprintf("%v", v)
-												// Truncate if too long
-												if len(value) > 50 {
-													value = value[:47] + "..."
-												}
-											}
-											resultsTable.SetCell(i+1, col, tview.NewTableCell(value).
-												SetTextColor(tview.Styles.PrimaryTextColor))
-											col++
-										}
-									}
-									resultsTable.ScrollToBeginning()
-								}
-								
-								// Update result reference
-								result = newResult
-								
-								// Update page header
-								pageHeader.SetText(fmt.Sprintf("Scan Results for %s - Page %d", tableInfo.Name, page))
-							}
-
-							// Add navigation buttons
-							navFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
-							
-							btnStyle := tcell.StyleDefault.Background(accentOrange).Foreground(tcell.NewHexColor(0x121212))
-							
-							loadPrevBtn := tview.NewButton("< Previous (Ctrl+B)").SetSelectedFunc(func() {
-								if currentPage > 1 {
-									currentPage--
-									prevState := pageHistory[currentPage-1]
-									updateResultsTable(aws.QueryResult{Items: prevState.items, RawItems: prevState.rawItems, LastEvaluatedKey: prevState.lastEvaluatedKey}, currentPage, additionalFields)
-								}
-							})
-							loadPrevBtn.SetStyle(btnStyle)
-							navFlex.AddItem(loadPrevBtn, 0, 1, false)
-							
-							var loadNextBtn *tview.Button
-							if result.LastEvaluatedKey != nil {
-								loadNextBtn = tview.NewButton("Next > (Ctrl+N)").SetSelectedFunc(func() {
-									// Load next page
-									nextResult, err := client.Scan(tableInfo.Name, result.LastEvaluatedKey)
-									if err != nil {
-										return
-									}
-									currentPage++
-									
-									// Add to history if it's a new page
-									if currentPage > len(pageHistory) {
-										pageHistory = append(pageHistory, pageState{items: nextResult.Items, rawItems: nextResult.RawItems, lastEvaluatedKey: nextResult.LastEvaluatedKey})
-									}
-									
-									updateResultsTable(nextResult, currentPage, additionalFields)
-									
-									// If no more pages, remove next button
-									if nextResult.LastEvaluatedKey == nil && loadNextBtn != nil {
-										navFlex.RemoveItem(loadNextBtn)
-									}
-								})
-								loadNextBtn.SetStyle(btnStyle)
-								navFlex.AddItem(loadNextBtn, 0, 1, false)
-							}
-							
-							resultsFlex.AddItem(navFlex, 1, 0, false)
-
-							resultsFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-								if event.Key() == tcell.KeyESC {
-									pages.RemovePage("scanresult")
-								} else if event.Key() == tcell.KeyCtrlH {
-									pages.AddPage("help", createHelpModal(pages), true, true)
-									return nil
-								} else if event.Key() == tcell.KeyCtrlB {
-									// Go back to previous page
-									if currentPage > 1 {
-										currentPage--
-										prevState := pageHistory[currentPage-1]
-										updateResultsTable(aws.QueryResult{Items: prevState.items, RawItems: prevState.rawItems, LastEvaluatedKey: prevState.lastEvaluatedKey}, currentPage, additionalFields)
-									}
-								} else if event.Key() == tcell.KeyCtrlN && result.LastEvaluatedKey != nil {
-									// Load next page with Ctrl+N
-									nextResult, err := client.Scan(tableInfo.Name, result.LastEvaluatedKey)
-									if err != nil {
-										return event
-									}
-									currentPage++
-									
-									// Add to history if it's a new page
-									if currentPage > len(pageHistory) {
-										pageHistory = append(pageHistory, pageState{items: nextResult.Items, rawItems: nextResult.RawItems, lastEvaluatedKey: nextResult.LastEvaluatedKey})
-									}
-									
-									updateResultsTable(nextResult, currentPage, additionalFields)
-									
-									if nextResult.LastEvaluatedKey == nil && loadNextBtn != nil {
-										navFlex.RemoveItem(loadNextBtn)
-									}
-								} else if event.Key() == tcell.KeyEnter {
-									row, _ := resultsTable.GetSelection()
-									if row > 0 && row <= len(result.Items) {
-										item := result.Items[row-1]
-										rawItem := result.RawItems[row-1]
-										
-										// Create item table (SCAN RESULTS)
-										itemTable := tview.NewTable().
-											SetBorders(true).
-											SetSelectable(true, false)
-
-										// Headers
-										itemTable.SetCell(0, 0, tview.NewTableCell("Field").
-											SetTextColor(tview.Styles.SecondaryTextColor).
-											SetSelectable(false).
-											SetAlign(tview.AlignCenter))
-										itemTable.SetCell(0, 1, tview.NewTableCell("Value").
-											SetTextColor(tview.Styles.SecondaryTextColor).
-											SetSelectable(false).
-											SetAlign(tview.AlignCenter))
-										
-										// Function to save item as JSON
-										saveItemAsJSON := func() {
-											// Generate filename from keys
-											pkValue := fmt.Sprintf("%v", rawItem[tableInfo.PartitionKey])
-											filename := pkValue
-											if tableInfo.SortKey != "" {
-												skValue := fmt.Sprintf("%v", rawItem[tableInfo.SortKey])
-												filename = fmt.Sprintf("%s_%s", pkValue, skValue)
-											}
-											// Clean filename (remove special characters)
-											filename = strings.ReplaceAll(filename, "/", "_")
-											filename = strings.ReplaceAll(filename, " ", "_")
-											filename = strings.ReplaceAll(filename, ":", "_")
-											filename += ".json"
-											
-											// Marshal to JSON
-											jsonBytes, err := json.MarshalIndent(rawItem, "", "    ")
-											if err != nil {
-												// Show error
-												errorModal := tview.NewModal().
-													SetText(fmt.Sprintf("Error saving JSON: %v", err)).
-													AddButtons([]string{"OK"}).
-													SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-														pages.RemovePage("saveerror")
-													})
-												pages.AddPage("saveerror", errorModal, true, true)
-												return
-											}
-											
-											// Write to file
-											err = os.WriteFile(filename, jsonBytes, 0644)
-											if err != nil {
-												// Show error
-												errorModal := tview.NewModal().
-													SetText(fmt.Sprintf("Error writing file: %v", err)).
-													AddButtons([]string{"OK"}).
-													SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-														pages.RemovePage("saveerror")
-													})
-												pages.AddPage("saveerror", errorModal, true, true)
-												return
-											}
-											
-											// Show success
-											successModal := tview.NewModal().
-												SetText(fmt.Sprintf("Saved to: %s", filename)).
-												AddButtons([]string{"OK"}).
-												SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-													pages.RemovePage("savesuccess")
-												})
-											pages.AddPage("savesuccess", successModal, true, true)
-										}
-
-										// Data: schema fields first
-										i := 1
-										// Schema fields
-										for _, sf := range tableInfo.SchemaFields {
-											if v, ok := item[sf]; ok {
-												displayValue := fmt.Sprintf("%v", v)
-												itemTable.SetCell(i, 0, tview.NewTableCell(sf).
-													SetTextColor(accentTeal).
-													SetSelectable(true))
-												itemTable.SetCell(i, 1, tview.NewTableCell(displayValue).
-													SetTextColor(accentTeal).
-													SetSelectable(true))
-												delete(item, sf)
-												i++
-											}
-										}
-										// Other fields
-										for k, v := range item {
-											displayValue := fmt.Sprintf("%v", v)
-											itemTable.SetCell(i, 0, tview.NewTableCell(k).
-												SetTextColor(tview.Styles.PrimaryTextColor).
-												SetSelectable(true))
-											itemTable.SetCell(i, 1, tview.NewTableCell(displayValue).
-												SetTextColor(tview.Styles.PrimaryTextColor).
-												SetSelectable(true))
-											i++
-										}
-										itemTable.ScrollToBeginning()
-
-										// Create flex for the table
-										itemFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-										itemFlex.AddItem(tview.NewTextView().SetText("Full Item (Ctrl+D: download | Ctrl+H: help)").SetTextAlign(tview.AlignCenter), 1, 0, false)
-										itemFlex.AddItem(itemTable, 0, 1, true)
-										itemFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-											if event.Key() == tcell.KeyESC {
-												pages.RemovePage("fullitem")
-											} else if event.Key() == tcell.KeyCtrlH {
-												pages.AddPage("help", createHelpModal(pages), true, true)
-												return nil
-											} else if event.Key() == tcell.KeyCtrlD {
-												saveItemAsJSON()
-												return nil
-											} else if event.Key() == tcell.KeyEnter {
-												row, _ := itemTable.GetSelection()
-												if row > 0 {
-													fieldCell := itemTable.GetCell(row, 0)
-													fieldName := fieldCell.Text
-													if v, ok := rawItem[fieldName]; ok {
-														// Check if it's a complex type (map or slice)
-														switch v.(type) {
-														case map[string]interface{}, []interface{}:
-															// Format as JSON
-															jsonBytes, err := json.MarshalIndent(v, "", "    ")
-															if err != nil {
-																jsonBytes = []byte(fmt.Sprintf("Error formatting JSON: %v", err))
-															}
-															jsonView := tview.NewTextView().
-																SetText(string(jsonBytes)).
-																SetTextAlign(tview.AlignLeft).
-																SetDynamicColors(true).
-																SetScrollable(true).
-																SetWrap(true)
-															
-															jsonFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-															jsonFlex.AddItem(tview.NewTextView().SetText(fmt.Sprintf("JSON View - %s (Space: page down, ESC: close)", fieldName)).SetTextAlign(tview.AlignCenter), 1, 0, false)
-															jsonFlex.AddItem(jsonView, 0, 1, true)
-															
-															jsonView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-																if event.Key() == tcell.KeyESC {
-																	pages.RemovePage("jsonview")
-																	return nil
-																} else if event.Rune() == ' ' {
-																	// Scroll down by page
-																	row, col := jsonView.GetScrollOffset()
-																	_, _, _, height := jsonView.GetInnerRect()
-																	jsonView.ScrollTo(row+height-1, col)
-																	return nil
-																}
-																return event
-															})
-															
-															pages.AddPage("jsonview", jsonFlex, true, true)
-															app.SetFocus(jsonView)
-														}
-													}
-												}
-											}
-											return event
-										})
-
-										pages.AddPage("fullitem", itemFlex, true, true)
-									}
-								}
-								return event
-							})
-							pages.AddPage("scanresult", resultsFlex, true, true)
-							app.SetFocus(resultsTable)
-						}
-					})
-				}()
-			})
-			
-			// Set focus to form itself
-			app.SetFocus(form)
-		}
+	if err := saveSession(client.Profile()); err != nil {
+		fmt.Printf("Failed to save the session: %v\n", err)
 	}
-
-	// Initial form
-	updateForm(0)
-
-	// Set input capture for tab switching
-	currentTab := 0 // 0: Query, 1: Scan
-	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyESC {
-			pages.SwitchToPage("tablelist")
-		} else if event.Key() == tcell.KeyCtrlH {
-			pages.AddPage("help", createHelpModal(pages), true, true)
-			return nil
-		} else if event.Key() == tcell.KeyCtrlQ {
-			// Switch to Query tab
-			if currentTab != 0 {
-				currentTab = 0
-				updateForm(currentTab)
-				queryTab.SetTextColor(tcell.NewHexColor(0x121212))
-				queryTab.SetBackgroundColor(accentOrange)
-				scanTab.SetTextColor(textSecondary)
-				scanTab.SetBackgroundColor(bgSecondary)
-			}
-			return nil
-		} else if event.Key() == tcell.KeyCtrlS {
-			// Switch to Scan tab
-			if currentTab != 1 {
-				currentTab = 1
-				updateForm(currentTab)
-				queryTab.SetTextColor(textSecondary)
-				queryTab.SetBackgroundColor(bgSecondary)
-				scanTab.SetTextColor(tcell.NewHexColor(0x121212))
-				scanTab.SetBackgroundColor(accentOrange)
-			}
-			return nil
-		} else if event.Key() == tcell.KeyRight || event.Key() == tcell.KeyLeft {
-			currentTab = 1 - currentTab
-			updateForm(currentTab)
-			if currentTab == 0 {
-				queryTab.SetTextColor(tcell.NewHexColor(0x121212))
-				queryTab.SetBackgroundColor(accentOrange)
-				scanTab.SetTextColor(textSecondary)
-				scanTab.SetBackgroundColor(bgSecondary)
-			} else {
-				queryTab.SetTextColor(textSecondary)
-				queryTab.SetBackgroundColor(bgSecondary)
-				scanTab.SetTextColor(tcell.NewHexColor(0x121212))
-				scanTab.SetBackgroundColor(accentOrange)
-			}
-		}
-		return event
-	})
-
-	// Add page
-	pages.AddPage("tableaction", flex, true, false)
+	return next
 }
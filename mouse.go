@@ -0,0 +1,53 @@
+package main
+
+import (
+	"ddb-explorer/ui"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// enableMouse lets the mouse select rows, press buttons, switch tabs and
+// scroll, unless --no-mouse keeps the terminal's own text selection.
+func enableMouse(app *tview.Application, pages *tview.Pages) {
+	if *noMouse {
+		return
+	}
+	app.EnableMouse(true)
+
+	// Only the page in front gets the mouse. Prompts and messages don't
+	// cover the screen, and a click beside one would otherwise select a row
+	// of the page under it and take the focus away from the prompt.
+	pages.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		_, front := pages.GetFrontPage()
+		if front == nil {
+			return action, event
+		}
+		consumed, _ := front.MouseHandler()(action, event, func(p tview.Primitive) {
+			app.SetFocus(p)
+		})
+		// A double click on a row opens it like Enter
+		if table, ok := app.GetFocus().(*tview.Table); ok && action == tview.MouseLeftDoubleClick {
+			row, _ := table.CellAt(event.Position())
+			if selected, _ := table.GetSelection(); row == selected {
+				app.QueueEvent(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+			}
+		}
+		if consumed {
+			return tview.MouseConsumed, nil // Redraws the screen
+		}
+		return action, nil
+	})
+}
+
+// clickable calls selected when a text view, such as a tab, is clicked
+func clickable(view *tview.TextView, selected func()) {
+	withoutFocus := ui.ClickWithoutFocus(view.Box)
+	view.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftClick && view.InRect(event.Position()) {
+			selected()
+			return tview.MouseConsumed, nil
+		}
+		return withoutFocus(action, event)
+	})
+}
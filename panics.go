@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rivo/tview"
+)
+
+// activeApp is the application on screen, whose terminal a panic restores
+var activeApp atomic.Pointer[tview.Application]
+
+// crashing is set once a panic is being reported, so the program exits with
+// it rather than when app.Run returns after the screen was stopped
+var crashing atomic.Bool
+
+var crashOnce sync.Once
+
+// recoverPanic is deferred at the top of app.Run and of every goroutine.
+// Without it a panic outside the UI goroutine kills the program with the
+// terminal still in raw mode, and the stack trace scrambled on the screen.
+func recoverPanic() {
+	if p := recover(); p != nil {
+		crash(p, debug.Stack())
+	}
+}
+
+// crash restores the terminal, then prints and logs the panic and exits the
+// way Go does. A second panic racing the first waits for it to exit.
+func crash(p any, stack []byte) {
+	crashing.Store(true)
+	crashOnce.Do(func() {
+		if app := activeApp.Load(); app != nil {
+			app.Stop()
+		}
+		appLog.Error("panic", "value", fmt.Sprint(p), "stack", string(stack))
+		fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", p, stack)
+		os.Exit(2)
+	})
+	select {}
+}
+
+// runApp runs an application with panics handled by recoverPanic. tview
+// restores the terminal itself for panics on the UI goroutine and passes
+// them on, so they arrive here with their stack intact.
+func runApp(app *tview.Application) error {
+	activeApp.Store(app)
+	defer activeApp.CompareAndSwap(app, nil)
+	defer recoverPanic()
+	err := app.Run()
+	if crashing.Load() {
+		select {} // A goroutine stopped the screen and is reporting its panic
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"reflect"
+
+	"github.com/rivo/tview"
+)
+
+// maxPinnedShown is the number of pinned rows shown above the results;
+// more are scrolled
+const maxPinnedShown = 5
+
+// pinnedRow is a results row pinned with Ctrl+P, as it was when pinned
+type pinnedRow struct {
+	key     string
+	item    map[string]interface{}
+	rawItem map[string]interface{}
+}
+
+// pinnedRows holds the rows pinned per table name. They stay for the
+// session, across re-run queries and pages, until unpinned or the
+// connection is switched.
+var pinnedRows map[string][]pinnedRow
+
+// primaryKeyOf identifies an item of a table by its key values
+func primaryKeyOf(tableInfo aws.TableInfo, rawItem map[string]interface{}) string {
+	key := fmt.Sprintf("%v", rawItem[tableInfo.PartitionKey])
+	if tableInfo.SortKey != "" {
+		key += "|" + fmt.Sprintf("%v", rawItem[tableInfo.SortKey])
+	}
+	return key
+}
+
+// pinIndex returns the position of an item among the pins of its table, or -1
+func pinIndex(tableInfo aws.TableInfo, rawItem map[string]interface{}) int {
+	key := primaryKeyOf(tableInfo, rawItem)
+	for i, pin := range pinnedRows[tableInfo.Name] {
+		if pin.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// togglePin pins an item or unpins it, reporting whether it is pinned now
+func togglePin(tableInfo aws.TableInfo, item, rawItem map[string]interface{}) bool {
+	pins := pinnedRows[tableInfo.Name]
+	if i := pinIndex(tableInfo, rawItem); i >= 0 {
+		pinnedRows[tableInfo.Name] = append(pins[:i:i], pins[i+1:]...)
+		return false
+	}
+	if pinnedRows == nil {
+		pinnedRows = make(map[string][]pinnedRow)
+	}
+	pinnedRows[tableInfo.Name] = append(pins, pinnedRow{key: primaryKeyOf(tableInfo, rawItem), item: item, rawItem: rawItem})
+	return true
+}
+
+// pinStatus compares a pinned row with the item of the same key on the page
+// shown: unchanged, changed, or empty when the page doesn't have it
+func pinStatus(tableInfo aws.TableInfo, pin pinnedRow, page aws.QueryResult) string {
+	for _, rawItem := range page.RawItems {
+		if primaryKeyOf(tableInfo, rawItem) != pin.key {
+			continue
+		}
+		if reflect.DeepEqual(rawItem, pin.rawItem) {
+			return "[#30d158]on page, same[white]"
+		}
+		return "[#ffd60a]on page, changed[white]"
+	}
+	return ""
+}
+
+// fillPinnedTable lists the pinned rows of a table with the given columns,
+// each with how it compares to the page shown
+func fillPinnedTable(table *tview.Table, tableInfo aws.TableInfo, fields []string, page aws.QueryResult) {
+	table.Clear()
+	pins := pinnedRows[tableInfo.Name]
+	table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("Pinned (%d)", len(pins))).
+		SetTextColor(accentYellow).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter))
+	for col, field := range fields {
+		table.SetCell(0, col+1, tview.NewTableCell(tview.Escape(columnTitle(tableInfo.Name, field))).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for i, pin := range pins {
+		table.SetCell(i+1, 0, tview.NewTableCell(rowPrefix("Pinned item", i+1, len(pins))+labeled("compared", pinStatus(tableInfo, pin, page))).
+			SetTextColor(tview.Styles.PrimaryTextColor))
+		for col, field := range fields {
+			value := ui.CellValue(pin.item, pin.rawItem, field, userSettings.RawValues)
+			if !userSettings.RawValues && !ui.IsMarker(value) {
+				value = ui.TruncateCell(value, cellWidth(tableInfo.Name, field))
+			}
+			table.SetCell(i+1, col+1, tview.NewTableCell(labeled(field, value)).
+				SetTextColor(accentYellow))
+		}
+	}
+}
+
+// pinnedTableHeight is the height of the pinned section for n pins, 0 when
+// there are none
+func pinnedTableHeight(n int) int {
+	if n == 0 {
+		return 0
+	}
+	rows := min(n, maxPinnedShown) + 1
+	if *screenReader {
+		return rows
+	}
+	return rows*linesPerRow() + 1
+}
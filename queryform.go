@@ -0,0 +1,498 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// addQueryForm fills the form of the Query tab: the saved query and index
+// to read, the key values, the filter and how many pages to fetch
+func (p *tableActionPage) addQueryForm() {
+	// Key attributes of the selected target (base table or GSI)
+	partitionKey, sortKey := p.tableInfo.PartitionKey, p.tableInfo.SortKey
+	var index aws.IndexInfo
+	if p.selectedIndex > 0 {
+		index = p.tableInfo.Indexes[p.selectedIndex-1]
+		partitionKey, sortKey = index.PartitionKey, index.SortKey
+	}
+
+	p.addQueryTargetFields()
+
+	var pkField, skField, skEndField *tview.InputField
+	var conditionDropDown *tview.DropDown
+	var pkText, skText, skEndText string
+	condition := 0
+	conditions := []string{"=", "begins_with", "<", "<=", ">", ">=", "between"}
+	if p.loadedQuery != nil {
+		pkText, skText, skEndText = p.loadedQuery.PartitionValue, p.loadedQuery.SortValue, p.loadedQuery.SortValueEnd
+		condition = max(0, slices.Index(conditions, p.loadedQuery.SortCondition))
+	}
+	if p.carriedPartitionValue != "" {
+		pkText, p.carriedPartitionValue = p.carriedPartitionValue, ""
+	}
+	if partitionKey != "" {
+		p.form.AddInputField(fmt.Sprintf("Partition Key (%s)", partitionKey), pkText, 20, nil, nil)
+		pkField = p.form.GetFormItem(p.form.GetFormItemCount() - 1).(*tview.InputField)
+	}
+	if sortKey != "" {
+		p.form.AddInputField(fmt.Sprintf("Sort Key (%s)", sortKey), skText, 20, nil, nil)
+		skField = p.form.GetFormItem(p.form.GetFormItemCount() - 1).(*tview.InputField)
+		p.form.AddDropDown("Condition", conditions, condition, nil)
+		conditionDropDown = p.form.GetFormItem(p.form.GetFormItemCount() - 1).(*tview.DropDown)
+		// The upper bound, only used by between
+		p.form.AddInputField("And (between)", skEndText, 20, nil, nil)
+		skEndField = p.form.GetFormItem(p.form.GetFormItemCount() - 1).(*tview.InputField)
+		skEndField.SetDisabled(conditions[condition] != "between")
+		sortOrder := 0
+		if p.descending {
+			sortOrder = 1
+		}
+		p.form.AddDropDown("Sort Order", []string{"Ascending", "Descending"}, sortOrder, func(option string, optionIndex int) {
+			p.descending = optionIndex == 1
+		})
+	}
+	// Checks the form as it is edited, once the fields below exist
+	var checkForm func()
+	addFilterFields(p.form, p.client, p.tableInfo, p.queryFilter, func(f attributeFilter) {
+		p.queryFilter = f
+		checkForm()
+	})
+	// Write-sharded partition keys configured with --shards
+	shards, sharded := p.shardPattern(index)
+	if sharded {
+		p.form.AddCheckbox(fmt.Sprintf("Fan out over %d shards (%s)", shards.Count(), tview.Escape(shards.String())), p.fanOutShards, func(checked bool) {
+			p.fanOutShards = checked
+		})
+	}
+	p.form.AddCheckbox("Fetch all pages", p.fetchAll, func(checked bool) {
+		p.fetchAll = checked
+	})
+	p.form.AddInputField("Max items", p.fetchMaxItems, 10, tview.InputFieldInteger, func(text string) {
+		p.fetchMaxItems = text
+	})
+	p.form.AddInputField("Max size (MB)", p.fetchMaxMB, 10, tview.InputFieldInteger, func(text string) {
+		p.fetchMaxMB = text
+	})
+	// formValues reads the key values and sort key condition of the
+	// form; skEnd is the upper bound of between
+	formValues := func() (pkValue, skValue, condition, skEnd string) {
+		if pkField != nil {
+			pkValue = pkField.GetText()
+		}
+		if skField != nil {
+			skValue = skField.GetText()
+			_, condition = conditionDropDown.GetCurrentOption()
+			if condition == "between" {
+				skEnd = skEndField.GetText()
+			}
+		}
+		return pkValue, skValue, condition, skEnd
+	}
+
+	// readValues is what the form reads with the key values, as kept
+	// with a saved query or session
+	readValues := func(pkValue, skValue, condition, skEnd string) savedQuery {
+		q := savedQuery{
+			Index:          index.Name,
+			PartitionValue: pkValue,
+			SortValue:      skValue,
+			Descending:     p.descending && sortKey != "",
+		}
+		if skValue != "" {
+			q.SortCondition = condition
+			q.SortValueEnd = skEnd
+		}
+		if p.queryFilter.attribute != "" {
+			q.FilterAttribute = p.queryFilter.attribute
+			q.FilterCondition = p.queryFilter.condition
+			q.FilterValue = p.queryFilter.value
+		}
+		return q
+	}
+
+	// keysError checks the key values against the key schema types,
+	// so a mistyped value is explained before a request fails
+	keysError := func(pkValue, skValue, condition, skEnd string) string {
+		if pkValue != "" {
+			if msg := keyValueError(partitionKey, p.tableInfo.AttributeTypes[partitionKey], pkValue, ""); msg != "" {
+				return msg
+			}
+		}
+		if skValue != "" {
+			if msg := keyValueError(sortKey, p.tableInfo.AttributeTypes[sortKey], skValue, condition); msg != "" {
+				return msg
+			}
+			if condition == "between" {
+				if skEnd == "" {
+					return fmt.Sprintf("Enter the upper bound of between for %s in And", sortKey)
+				}
+				return keyValueError(sortKey, p.tableInfo.AttributeTypes[sortKey], skEnd, condition)
+			}
+		}
+		return ""
+	}
+	// Checked while typing, except for placeholders filled in later
+	checkForm = func() {
+		pkValue, skValue, condition, skEnd := formValues()
+		if _, value, ok := splitKeyAttribute(p.tableInfo, pkValue); ok {
+			pkValue = value // Checked where the query goes
+		}
+		msg := ""
+		if len(placeholders(pkValue, skValue, skEnd)) == 0 {
+			msg = keysError(pkValue, skValue, condition, skEnd)
+		}
+		if msg == "" {
+			msg = p.queryFilter.problem()
+		}
+		p.formError.SetText(msg)
+	}
+	if pkField != nil {
+		pkField.SetChangedFunc(func(string) { checkForm() })
+	}
+	if skField != nil {
+		skField.SetChangedFunc(func(string) { checkForm() })
+		skEndField.SetChangedFunc(func(string) { checkForm() })
+		conditionDropDown.SetSelectedFunc(func(option string, _ int) {
+			skEndField.SetDisabled(option != "between")
+			checkForm()
+		})
+	}
+	checkForm()
+
+	// runQuery reads the items matching the key values, with any placeholders filled in
+	runQuery := func(pkValue, skValue, condition, skEnd string) {
+		if pkValue == "" {
+			p.formError.SetText(fmt.Sprintf("Enter a value for the partition key %s", partitionKey))
+			return
+		}
+		if msg := keysError(pkValue, skValue, condition, skEnd); msg != "" {
+			p.formError.SetText(msg)
+			return
+		}
+		if msg := p.queryFilter.problem(); msg != "" {
+			p.formError.SetText(msg)
+			return
+		}
+		p.formError.SetText("")
+		var budget aws.FetchBudget
+		if p.fetchAll {
+			var err error
+			if budget, err = parseFetchBudget(p.fetchMaxItems, p.fetchMaxMB); err != nil {
+				showMessageModal(p.pages, "queryerror", err.Error())
+				return
+			}
+		}
+
+		params := aws.QueryParams{
+			TableName:        p.tableInfo.Name,
+			IndexName:        index.Name,
+			PartitionKey:     partitionKey,
+			PartitionValue:   pkValue,
+			PartitionKeyType: p.tableInfo.AttributeTypes[partitionKey],
+			Descending:       p.descending && sortKey != "",
+		}
+		if skValue != "" {
+			params.SortKey = sortKey
+			params.SortValue = skValue
+			params.SortValueEnd = skEnd
+			params.SortKeyType = p.tableInfo.AttributeTypes[sortKey]
+			params.Condition = condition
+		}
+		if p.queryFilter.attribute != "" {
+			params.FilterAttribute = p.queryFilter.attribute
+			params.FilterCondition = p.queryFilter.condition
+			params.FilterValue = p.queryFilter.value
+		}
+		p.readQuery(params, index, sortKey, budget, readValues(pkValue, skValue, condition, skEnd))
+	}
+
+	// queryOrOffer runs the query, unless the partition key value can't
+	// be the table's but fits an index, which it offers to query instead
+	queryOrOffer := func(pkValue, skValue, condition, skEnd string) {
+		// attribute=value names the key the value is for
+		if attr, value, ok := splitKeyAttribute(p.tableInfo, pkValue); ok {
+			if attr != partitionKey {
+				offerKeyAttributeTargets(p.pages, p.tableInfo, attr, skValue != "", func(i int) {
+					p.queryTarget(i, value)
+				})
+				return
+			}
+			pkValue = value
+		}
+		var matches []int
+		if p.selectedIndex == 0 {
+			matches = matchingIndexes(p.tableInfo, pkValue)
+		}
+		if len(matches) == 0 {
+			runQuery(pkValue, skValue, condition, skEnd)
+			return
+		}
+		offerMatchingIndex(p.pages, p.tableInfo, pkValue, skValue != "", matches, func(i int) {
+			if i < 0 {
+				runQuery(pkValue, skValue, condition, skEnd)
+				return
+			}
+			p.queryTarget(i, pkValue)
+		})
+	}
+
+	p.submitQuery = func() {
+		pkValue, skValue, condition, skEnd := formValues()
+		names := placeholders(pkValue, skValue, skEnd)
+		if len(names) == 0 {
+			queryOrOffer(pkValue, skValue, condition, skEnd)
+			return
+		}
+		queryName := ""
+		if p.loadedQuery != nil {
+			queryName = p.loadedQuery.Name
+		}
+		promptPlaceholders(p.app, p.pages, queryName, names, func(values map[string]string) {
+			queryOrOffer(fillPlaceholders(pkValue, values), fillPlaceholders(skValue, values), condition, fillPlaceholders(skEnd, values))
+		})
+	}
+	if p.resuming {
+		p.resumeRead = func() { runQuery(formValues()) }
+	}
+	p.form.AddButton("Query", p.submitQuery)
+	p.addSavedQueryButtons(func() savedQuery { return readValues(formValues()) })
+
+	// Set focus to form itself to enable Tab navigation
+	p.app.SetFocus(p.form)
+}
+
+// addQueryTargetFields adds the saved queries of the table and the index to
+// query, each switching the form to its choice
+func (p *tableActionPage) addQueryTargetFields() {
+	// Queries saved for this table, listed first so they are one step away
+	if saved := userSettings.SavedQueries[p.tableInfo.Name]; len(saved) > 0 {
+		options := []string{"(new query)"}
+		current := 0
+		for i, q := range saved {
+			options = append(options, q.Name)
+			if p.loadedQuery != nil && q.Name == p.loadedQuery.Name {
+				current = i + 1
+			}
+		}
+		p.form.AddDropDown("Saved Query", options, current, func(option string, optionIndex int) {
+			if optionIndex < 0 || optionIndex == current {
+				return
+			}
+			if optionIndex == 0 {
+				p.loadedQuery = nil
+				p.updateForm(0)
+				return
+			}
+			q := saved[optionIndex-1]
+			target := 0
+			if q.Index != "" {
+				i := slices.IndexFunc(p.tableInfo.Indexes, func(idx aws.IndexInfo) bool { return idx.Name == q.Index })
+				if i < 0 {
+					showMessageModal(p.pages, "queryerror", fmt.Sprintf("The index %s of the saved query %q no longer exists.", q.Index, q.Name))
+					return
+				}
+				target = i + 1
+			}
+			p.loadedQuery = &q
+			p.selectedIndex = target
+			p.descending = q.Descending
+			p.queryFilter = attributeFilter{attribute: q.FilterAttribute, condition: q.FilterCondition, value: q.FilterValue}
+			p.updateForm(0)
+		})
+	}
+
+	if len(p.tableInfo.Indexes) > 0 {
+		targets := []string{"Table"}
+		for _, idx := range p.tableInfo.Indexes {
+			if idx.Local {
+				targets = append(targets, fmt.Sprintf("%s (LSI, %s)", idx.Name, idx.ProjectionType))
+			} else {
+				targets = append(targets, fmt.Sprintf("%s (%s)", idx.Name, idx.ProjectionType))
+			}
+		}
+		p.form.AddDropDown("Index", targets, p.selectedIndex, func(option string, optionIndex int) {
+			if optionIndex >= 0 && optionIndex != p.selectedIndex {
+				p.selectedIndex = optionIndex
+				p.loadedQuery = nil
+				p.updateForm(0)
+			}
+		})
+	}
+}
+
+// shardPattern is the write-sharding configured with --shards for the table
+// or an index of it
+func (p *tableActionPage) shardPattern(index aws.IndexInfo) (aws.ShardPattern, bool) {
+	target := p.tableInfo.Name
+	if index.Name != "" {
+		target += "." + index.Name
+	}
+	pattern, ok := shardPatterns[target]
+	return pattern, ok
+}
+
+// readQuery reads the items of a query and shows them: a page at a time, as
+// many pages as the budget allows when fetching all, or every shard of a write-sharded
+// partition. read is the query as kept with the session.
+func (p *tableActionPage) readQuery(params aws.QueryParams, index aws.IndexInfo, sortKey string, budget aws.FetchBudget, read savedQuery) {
+	// Show loading. Closing the results of a fetch-all query shown
+	// while it still reads stops the read as well.
+	ctx, cancel := context.WithCancel(showLoadingModal(p.pages, "loading", "Querying..."))
+
+	// Reads one page, or as many pages as the budget allows when fetching all
+	fetch := func(ctx context.Context, page *aws.PageToken, partial func(aws.QueryResult)) (aws.QueryResult, error) {
+		if p.fetchAll {
+			return aws.QueryPages(ctx, p.client, params, page, budget, partial)
+		}
+		return p.client.Query(ctx, params, page)
+	}
+	shards, sharded := p.shardPattern(index)
+	fanOut := sharded && p.fanOutShards
+	start := p.resumeStart
+	p.resumeStart = nil
+
+	opts := ui.ResultsOptions{
+		PageName:  "queryresult",
+		Title:     fmt.Sprintf("Query Results for %s", p.tableInfo.Name),
+		FetchNext: fetch,
+		Request: func() (aws.ReadRequest, error) {
+			return p.client.QueryRequest(params)
+		},
+		Start:  start,
+		OnPage: trackRead(p.tableInfo.Name, false, read),
+	}
+	if params.FilterAttribute != "" {
+		opts.Notice = fmt.Sprintf("[#b8b8b8]Filtered pages can hold fewer items than the %d read, %s continues[white]", *pageSize, keyLabel("results.nextPage"))
+	}
+	if fanOut {
+		// Every shard was read up to shardMaxItems, so there is no next page
+		opts.Title = fmt.Sprintf("Query Results for %s (%d shards)", p.tableInfo.Name, shards.Count())
+		opts.SourceColumn = "Shard"
+		opts.FetchNext = nil
+		opts.Request = nil
+		opts.Start = nil
+	}
+
+	// Warn when the index doesn't project every attribute of the base items
+	if index.Name != "" && !index.ProjectsAll() {
+		available := []string{"keys"}
+		available = append(available, index.NonKeyAttributes...)
+		opts.Notice = fmt.Sprintf("[#ffd60a]%s projects %s: only %s available (%s: hydrate from base table)[white]",
+			index.Name, index.ProjectionType, strings.Join(available, ", "), keyLabel("results.hydrate"))
+		opts.Hydrate = true
+	}
+
+	// Say why a fetch-all result still has more pages
+	if p.fetchAll {
+		var limits []string
+		if budget.MaxItems > 0 {
+			limits = append(limits, formatNumber(int64(budget.MaxItems))+" items")
+		}
+		if budget.MaxBytes > 0 {
+			limits = append(limits, formatBytes(budget.MaxBytes))
+		}
+		opts.MorePagesNotice = fmt.Sprintf("[#ffd60a]Each page reads up to %s, %s fetches the next batch[white]", strings.Join(limits, " or "), keyLabel("results.nextPage"))
+	}
+
+	// Pages of a fetch-all query are shown as they arrive
+	var update ui.ResultsUpdater
+	var shown aws.QueryResult
+	showPartial := func(partial aws.QueryResult) {
+		p.app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			shown = partial
+			if update != nil {
+				update(partial, true)
+				return
+			}
+			p.pages.RemovePage("loading")
+			update = showResultsPage(p.app, p.pages, p.client, p.tableInfo, opts, partial, true)
+			context.AfterFunc(tasks.group(opts.PageName).ctx, cancel)
+		})
+	}
+
+	var result aws.QueryResult
+	runWithReauth(ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+		var err error
+		if fanOut {
+			shardParams := params
+			shardParams.SortKey = sortKey // Merge order, even without a sort key condition
+			result, err = p.client.ShardedQuery(ctx, shardParams, shards, shardMaxItems)
+		} else {
+			result, err = fetch(ctx, start, showPartial)
+		}
+		return err
+	}, func(err error) {
+		defer cancel()
+		p.pages.RemovePage("loading")
+		if err != nil {
+			if update != nil {
+				// Keep the items read before the error
+				update(shown, false)
+			}
+			errorModal := tview.NewModal().
+				SetText(fmt.Sprintf("Query error: %v", err)).
+				AddButtons([]string{"OK"}).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					p.pages.RemovePage("queryerror")
+				})
+			p.pages.AddPage("queryerror", errorModal, true, true)
+			return
+		}
+		if update != nil {
+			update(result, false)
+			return
+		}
+		showResultsPage(p.app, p.pages, p.client, p.tableInfo, opts, result, false)
+	})
+}
+
+// queryTarget queries the table (-1) or an index with a partition key value,
+// switching the form to it
+func (p *tableActionPage) queryTarget(i int, pkValue string) {
+	p.selectedIndex = i + 1
+	p.loadedQuery = nil
+	p.carriedPartitionValue = pkValue
+	p.updateForm(0)
+	p.submitQuery()
+}
+
+// addSavedQueryButtons adds the buttons saving the query of the form, whose
+// values reads, and deleting the loaded saved query
+func (p *tableActionPage) addSavedQueryButtons(values func() savedQuery) {
+	p.form.AddButton("Save Query", func() {
+		initial := ""
+		if p.loadedQuery != nil {
+			initial = p.loadedQuery.Name
+		}
+		promptQueryName(p.app, p.pages, initial, func(name string) {
+			q := values()
+			q.Name = name
+			p.loadedQuery = &q
+			err := saveQuery(p.tableInfo.Name, q)
+			p.updateForm(0)
+			if err != nil {
+				showMessageModal(p.pages, "configerror", fmt.Sprintf("Query saved for this session, but writing the config file failed: %v", err))
+			}
+		})
+	})
+	if p.loadedQuery != nil && p.loadedQuery.Name != "" {
+		p.form.AddButton("Delete Saved", func() {
+			name := p.loadedQuery.Name
+			p.loadedQuery = nil
+			err := deleteSavedQuery(p.tableInfo.Name, name)
+			p.updateForm(0)
+			if err != nil {
+				showMessageModal(p.pages, "configerror", fmt.Sprintf("Query deleted for this session, but writing the config file failed: %v", err))
+			}
+		})
+	}
+}
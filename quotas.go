@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// quotaWarnPercent is the share of a quota at which the panel warns
+const quotaWarnPercent = 80
+
+// showQuotaPanel loads the account limits and describes every table to
+// measure how much of each quota is in use
+func showQuotaPanel(app *tview.Application, pages *tview.Pages, client *aws.Client) {
+	ctx := showLoadingModal(pages, "quotaloading", "Loading account limits...")
+
+	var limits aws.AccountLimits
+	var described []aws.TableInfo
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		if limits, err = client.AccountLimits(ctx); err != nil {
+			return err
+		}
+		// Described again (or within the last minute), so operations started since startup are counted
+		described, err = client.ListTables(ctx, nil)
+		return err
+	}, func(err error) {
+		pages.RemovePage("quotaloading")
+		if err != nil {
+			showMessageModal(pages, "quotaerror", fmt.Sprintf("Failed to load account limits: %v", err))
+			return
+		}
+		createQuotaPage(app, pages, limits, aws.UsageOf(described))
+	})
+}
+
+// createQuotaPage shows each quota with its current usage. Quotas at or
+// above quotaWarnPercent are highlighted and counted in the header.
+func createQuotaPage(app *tview.Application, pages *tview.Pages, limits aws.AccountLimits, usage aws.AccountUsage) {
+	quotas := []struct {
+		name        string
+		used, limit int64
+	}{
+		{"Tables", int64(usage.Tables), aws.DefaultMaxTables},
+		{"Table and index operations in progress", int64(usage.Operations), aws.DefaultMaxConcurrentOperations},
+		{"Account read capacity (RCU)", usage.ReadCapacity, limits.AccountMaxReadCapacity},
+		{"Account write capacity (WCU)", usage.WriteCapacity, limits.AccountMaxWriteCapacity},
+		{"Largest table or index read capacity (RCU)", usage.TableMaxRead, limits.TableMaxReadCapacity},
+		{"Largest table or index write capacity (WCU)", usage.TableMaxWrite, limits.TableMaxWriteCapacity},
+	}
+
+	list := newDataTable().
+		SetFixed(1, 0)
+	for col, name := range []string{"Quota", "Used", "Limit", "Usage"} {
+		list.SetCell(0, col, tview.NewTableCell(name).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+
+	warnings := 0
+	for i, q := range quotas {
+		row := i + 1
+		percent := int64(0)
+		if q.limit > 0 {
+			percent = q.used * 100 / q.limit
+		}
+		color := tview.Styles.PrimaryTextColor
+		name := q.name
+		switch {
+		case percent >= 100:
+			color = accentRed
+		case percent >= quotaWarnPercent:
+			color = accentYellow
+		}
+		if percent >= quotaWarnPercent {
+			warnings++
+			name += " (near limit)"
+		}
+		list.SetCell(row, 0, tview.NewTableCell(rowPrefix("Quota", row, len(quotas))+name).SetTextColor(color))
+		list.SetCell(row, 1, tview.NewTableCell(labeled("used", formatNumber(q.used))).SetTextColor(color).SetAlign(tview.AlignRight))
+		list.SetCell(row, 2, tview.NewTableCell(labeled("limit", formatNumber(q.limit))).SetTextColor(color).SetAlign(tview.AlignRight))
+		list.SetCell(row, 3, tview.NewTableCell(labeled("usage", fmt.Sprintf("%d%%", percent))).SetTextColor(color).SetAlign(tview.AlignRight))
+	}
+
+	summary := fmt.Sprintf("all quotas below %d%%", quotaWarnPercent)
+	if warnings > 0 {
+		summary = fmt.Sprintf("[#ffd60a]%d quotas at or above %d%%[white]", warnings, quotaWarnPercent)
+	}
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Account limits for profile %s: %s (ESC: close)", *profile, summary))
+	footer := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText("[#b8b8b8]Table and operation limits are the AWS defaults; raised quotas are not visible to DescribeLimits[white]")
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(list, 0, 1, true)
+	flex.AddItem(footer, 1, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("quotas")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("quotas")
+	pages.AddPage("quotas", flex, true, true)
+	app.SetFocus(list)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// rawProblem says why the expression attributes of a raw read can't be
+// sent, or returns "" when they can
+func rawProblem(params aws.RawParams) string {
+	if _, _, err := params.Attributes(); err != nil {
+		return "Expression " + err.Error()
+	}
+	return ""
+}
+
+// addRawFields adds the fields of a query, or of a scan without key
+// condition, written as DynamoDB expressions: the index, the expressions and
+// the expression attribute names and values as JSON. Expression fields
+// complete attribute names. changed receives the params whenever a field
+// changes.
+func addRawFields(form *tview.Form, client *aws.Client, tableInfo aws.TableInfo, params aws.RawParams, query bool, changed func(aws.RawParams)) {
+	targets := []string{"Table"}
+	selected := 0
+	for i, idx := range tableInfo.Indexes {
+		targets = append(targets, idx.Name)
+		if idx.Name == params.IndexName {
+			selected = i + 1
+		}
+	}
+	if len(targets) > 1 {
+		form.AddDropDown("Index", targets, selected, func(option string, optionIndex int) {
+			params.IndexName = ""
+			if optionIndex > 0 {
+				params.IndexName = option
+			}
+			changed(params)
+		})
+	}
+
+	// expressionField adds a field for an expression or JSON, as wide as the form
+	expressionField := func(label, text, placeholder string, completes bool, set func(string)) {
+		form.AddInputField(label, text, 0, nil, func(text string) {
+			set(text)
+			changed(params)
+		})
+		field := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.InputField)
+		field.SetPlaceholder(placeholder)
+		field.SetPlaceholderTextColor(textSecondary)
+		if completes {
+			completeTableAttributes(field, client, tableInfo)
+		}
+	}
+	if query {
+		example := "#pk = :pk"
+		if tableInfo.SortKey != "" {
+			example += " AND begins_with(#sk, :sk)"
+		}
+		expressionField("Key Condition", params.KeyCondition, example, true, func(text string) { params.KeyCondition = text })
+	}
+	expressionField("Filter", params.Filter, "attribute_exists(#a) AND (#b = :b OR size(#c) > :n)", true, func(text string) { params.Filter = text })
+	expressionField("Projection", params.Projection, "all attributes", true, func(text string) { params.Projection = text })
+	pkType := tableInfo.AttributeTypes[tableInfo.PartitionKey]
+	if pkType == "" {
+		pkType = "S"
+	}
+	expressionField("Names (JSON)", params.Names, fmt.Sprintf(`{"#pk": %q}`, tableInfo.PartitionKey), false, func(text string) { params.Names = text })
+	expressionField("Values (JSON)", params.Values, fmt.Sprintf(`{":pk": {%q: "..."}}`, pkType), false, func(text string) { params.Values = text })
+
+	if query {
+		sortOrder := 0
+		if params.Descending {
+			sortOrder = 1
+		}
+		form.AddDropDown("Sort Order", []string{"Ascending", "Descending"}, sortOrder, func(option string, optionIndex int) {
+			params.Descending = optionIndex == 1
+			changed(params)
+		})
+	}
+	form.AddCheckbox("Consistent read", params.ConsistentRead, func(checked bool) {
+		params.ConsistentRead = checked
+		changed(params)
+	})
+}
+
+// runRawRead runs a raw query or scan and shows its results
+func runRawRead(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, params aws.RawParams) {
+	kind, pageName, loading := "Scan", "scanresult", "Scanning..."
+	if params.IsQuery() {
+		kind, pageName, loading = "Query", "queryresult", "Querying..."
+	}
+	ctx := showLoadingModal(pages, "loading", loading)
+
+	var result aws.QueryResult
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		result, err = client.RawRead(ctx, params, nil)
+		return err
+	}, func(err error) {
+		pages.RemovePage("loading")
+		if err != nil {
+			showMessageModal(pages, "queryerror", fmt.Sprintf("%s error: %v", kind, err))
+			return
+		}
+		target := tableInfo.Name
+		if params.IndexName != "" {
+			target += " (" + params.IndexName + ")"
+		}
+		opts := ui.ResultsOptions{
+			PageName: pageName,
+			Title:    fmt.Sprintf("%s Results for %s, raw expressions", kind, target),
+			FetchNext: func(ctx context.Context, page *aws.PageToken, _ func(aws.QueryResult)) (aws.QueryResult, error) {
+				return client.RawRead(ctx, params, page)
+			},
+			Request: func() (aws.ReadRequest, error) {
+				return client.RawRequest(params)
+			},
+		}
+		if params.Filter != "" {
+			opts.Notice = fmt.Sprintf("[#b8b8b8]Filtered pages can hold fewer items than the %d read, %s continues[white]", *pageSize, keyLabel("results.nextPage"))
+		}
+		if index, ok := tableInfo.Index(params.IndexName); ok && !index.ProjectsAll() && params.Projection == "" {
+			opts.Notice = fmt.Sprintf("[#ffd60a]%s projects %s: %s hydrates from the base table[white]", index.Name, index.ProjectionType, keyLabel("results.hydrate"))
+			opts.Hydrate = true
+		}
+		showResultsPage(app, pages, client, tableInfo, opts, result, false)
+	})
+}
+
+// addRawForm fills the form of the Query tab, or of the Scan tab when query
+// is false, in raw expression mode
+func (p *tableActionPage) addRawForm(query bool) {
+	addRawFields(p.form, p.client, p.tableInfo, p.rawParams, query, func(params aws.RawParams) {
+		p.rawParams = params
+		p.formError.SetText(rawProblem(params))
+	})
+	label := "Scan"
+	if query {
+		label = "Query"
+	}
+	p.form.AddButton(label, func() {
+		params := p.rawParams
+		if !query {
+			params.KeyCondition, params.Descending = "", false
+		}
+		if query && !params.IsQuery() {
+			p.formError.SetText("Enter a key condition expression, e.g. #pk = :pk")
+			return
+		}
+		if msg := rawProblem(params); msg != "" {
+			p.formError.SetText(msg)
+			return
+		}
+		p.formError.SetText("")
+		runRawRead(p.app, p.pages, p.client, p.tableInfo, params)
+	})
+	p.formError.SetText(rawProblem(p.rawParams))
+	p.app.SetFocus(p.form)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// formatMillis formats a duration in whole milliseconds, or in tenths below
+// ten milliseconds, where local endpoints tend to answer
+func formatMillis(d time.Duration) string {
+	ms := float64(d) / float64(time.Millisecond)
+	if ms < 10 {
+		return strings.Replace(fmt.Sprintf("%.1f ms", ms), ".", displayLocale.decimal, 1)
+	}
+	return formatNumber(int64(ms+0.5)) + " ms"
+}
+
+// readTiming sums up the requests of a read
+type readTiming struct {
+	requests   int
+	retries    int
+	total      time.Duration // Summed over the requests, which merged views send concurrently
+	setup      time.Duration // DNS, connect and TLS of the attempts that opened a connection
+	dns        time.Duration
+	connect    time.Duration
+	tls        time.Duration
+	newConns   int
+	latency    time.Duration // Summed over the attempts
+	maxLatency time.Duration
+	attempts   int
+	bytes      int64
+}
+
+func newReadTiming(requests []aws.RequestDiagnostics) readTiming {
+	t := readTiming{requests: len(requests)}
+	for _, r := range requests {
+		t.retries += r.Retries()
+		t.total += r.Duration
+		for _, a := range r.Attempts {
+			t.attempts++
+			t.latency += a.Latency
+			t.maxLatency = max(t.maxLatency, a.Latency)
+			t.bytes += a.Bytes
+			if !a.Reused {
+				t.newConns++
+				t.dns += a.DNS
+				t.connect += a.Connect
+				t.tls += a.TLS
+			}
+		}
+	}
+	t.setup = t.dns + t.connect + t.tls
+	return t
+}
+
+// statusText is the short form for the status bar
+func (t readTiming) statusText() string {
+	if t.requests == 0 {
+		return ""
+	}
+	requests := "1 request"
+	if t.requests > 1 {
+		requests = formatNumber(int64(t.requests)) + " requests"
+	}
+	return fmt.Sprintf("%s in %s (%s)", requests, formatMillis(t.total), keyLabel("results.timing"))
+}
+
+// verdict says where the time of a read went: connection setup points at
+// the network, retries at throttling, and the rest is the table's latency
+func (t readTiming) verdict() string {
+	switch {
+	case t.total == 0:
+		return ""
+	case t.setup*2 >= t.total:
+		return "Most of the time went into opening connections (DNS, connect, TLS): a slow network or resolver rather than a slow table. Later reads reuse the connection."
+	case t.retries > 0:
+		return "Requests were retried, usually because the table or index is throttled; the backoff between attempts counts towards their time."
+	default:
+		return "The time is request latency: the round trip to DynamoDB and the time it took to read the page. Filtered scans and large pages take longer."
+	}
+}
+
+// readTimingText lays out the summary and every request of a read
+func readTimingText(requests []aws.RequestDiagnostics) string {
+	t := newReadTiming(requests)
+	var b strings.Builder
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "  %-22s %s\n", label, value)
+	}
+	b.WriteString("[#ff9500::b]Summary[white::-]\n")
+	line("Requests (pages)", formatNumber(int64(t.requests)))
+	line("Retries", formatNumber(int64(t.retries)))
+	line("Time in requests", formatMillis(t.total))
+	if t.newConns > 0 {
+		connections := "1 new connection"
+		if t.newConns > 1 {
+			connections = formatNumber(int64(t.newConns)) + " new connections"
+		}
+		line("Connection setup", fmt.Sprintf("%s over %s (DNS %s, connect %s, TLS %s)",
+			formatMillis(t.setup), connections, formatMillis(t.dns), formatMillis(t.connect), formatMillis(t.tls)))
+	} else {
+		line("Connection setup", "none, every request reused an open connection")
+	}
+	if t.attempts > 0 {
+		line("Request latency", fmt.Sprintf("average %s, slowest %s",
+			formatMillis(t.latency/time.Duration(t.attempts)), formatMillis(t.maxLatency)))
+	}
+	line("Bytes received", formatBytes(t.bytes))
+	if v := t.verdict(); v != "" {
+		fmt.Fprintf(&b, "\n  [#ffd60a]%s[white]\n", v)
+	}
+
+	b.WriteString("\n[#ff9500::b]Requests[white::-]\n")
+	fmt.Fprintf(&b, "  [#b8b8b8]%-4s %-13s %10s %8s  %-28s %10s %10s  %s[white]\n",
+		"#", "Operation", "Time", "Attempts", "Connection", "Latency", "Bytes", "Status")
+	for i, r := range requests {
+		for j, a := range r.Attempts {
+			number, operation, duration, attempts := "", "", "", ""
+			if j == 0 {
+				number = fmt.Sprintf("%d", i+1)
+				operation = r.Operation
+				duration = formatMillis(r.Duration)
+				attempts = fmt.Sprintf("%d", len(r.Attempts))
+			}
+			connection := "reused"
+			if !a.Reused {
+				connection = fmt.Sprintf("new, %s", formatMillis(a.DNS+a.Connect+a.TLS))
+			}
+			status := "no response"
+			if a.Status != 0 {
+				status = fmt.Sprintf("%d", a.Status)
+			}
+			fmt.Fprintf(&b, "  %-4s %-13s %10s %8s  %-28s %10s %10s  %s\n",
+				number, operation, duration, attempts, connection, formatMillis(a.Latency), formatBytes(a.Bytes), status)
+		}
+	}
+	return b.String()
+}
+
+// showReadTiming shows how the requests of a results page spent their time
+func showReadTiming(app *tview.Application, pages *tview.Pages, title string, requests []aws.RequestDiagnostics) {
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Read diagnostics: %s (↑/↓: scroll | ESC: close)", tview.Escape(title)))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(readTimingText(requests))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("readtiming")
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("readtiming", flex, true, true)
+	app.SetFocus(text)
+}
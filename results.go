@@ -0,0 +1,724 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showColumnChooser lists every non-key attribute of the items, plus the
+// current columns, for picking the result columns of a table. The choice is
+// saved to the config file and passed to apply; nil means the columns were
+// reset to the automatically detected ones. Any column, keys included, can
+// be given a display name, after which renamed is called.
+func showColumnChooser(app *tview.Application, pages *tview.Pages, tableInfo aws.TableInfo, items []map[string]interface{}, current []string, apply func(columns []string), renamed func()) {
+	selected := make(map[string]bool, len(current))
+	for _, c := range current {
+		selected[c] = true
+	}
+	seen := make(map[string]bool)
+	var attrs []string
+	addAttr := func(attr string) {
+		if !seen[attr] && attr != tableInfo.PartitionKey && attr != tableInfo.SortKey {
+			seen[attr] = true
+			attrs = append(attrs, attr)
+		}
+	}
+	for _, item := range items {
+		for attr := range item {
+			addAttr(attr)
+		}
+	}
+	for _, c := range current {
+		addAttr(c) // Keep saved columns that this page doesn't have
+	}
+	sort.Strings(attrs)
+
+	// The keys are always shown, so they are listed first only for renaming
+	keys := []string{tableInfo.PartitionKey}
+	if tableInfo.SortKey != "" {
+		keys = append(keys, tableInfo.SortKey)
+	}
+	attrAt := func(row int) (attr string, key bool) {
+		if row < len(keys) {
+			return keys[row], true
+		}
+		return attrs[row-len(keys)], false
+	}
+
+	list := tview.NewTable().SetSelectable(true, false)
+	ui.SelectRowsOnClick(list)
+	render := func() {
+		for i, attr := range keys {
+			list.SetCell(i, 0, tview.NewTableCell(tview.Escape("[key] "+columnTitle(tableInfo.Name, attr))).SetTextColor(accentTeal))
+		}
+		for i, attr := range attrs {
+			mark := "[ ]"
+			if selected[attr] {
+				mark = "[x]"
+			}
+			list.SetCell(len(keys)+i, 0, tview.NewTableCell(tview.Escape(mark+" "+columnTitle(tableInfo.Name, attr))).SetTextColor(tview.Styles.PrimaryTextColor))
+		}
+	}
+	render()
+	if len(attrs) == 0 {
+		list.SetCell(len(keys), 0, tview.NewTableCell("No attributes besides the keys on this page.").
+			SetTextColor(tview.Styles.PrimaryTextColor).
+			SetSelectable(false))
+	}
+
+	// The full name of the selected attribute, when it is shown otherwise
+	fullName := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	list.SetSelectionChangedFunc(func(row, column int) {
+		if row >= 0 && row < len(keys)+len(attrs) {
+			attr, _ := attrAt(row)
+			fullName.SetText(fullNameNote(tableInfo.Name, attr))
+		}
+	})
+	fullName.SetText(fullNameNote(tableInfo.Name, keys[0])) // Selected first
+	hint := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("Space: toggle | Enter: save | %s: rename | %s: reset | ESC: cancel", keyLabel("columns.rename"), keyLabel("columns.reset")))
+	chooser := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(fullName, 1, 0, false).
+		AddItem(hint, 1, 0, false)
+	chooser.SetBorder(true).
+		SetBorderColor(accentOrange).
+		SetTitle(fmt.Sprintf(" Columns for %s ", tableInfo.Name)).
+		SetTitleColor(accentOrange)
+
+	save := func(columns []string) {
+		pages.RemovePage("columnchooser")
+		if columns == nil {
+			delete(userSettings.Columns, tableInfo.Name)
+		} else {
+			if userSettings.Columns == nil {
+				userSettings.Columns = make(map[string][]string)
+			}
+			userSettings.Columns[tableInfo.Name] = columns
+		}
+		apply(columns)
+		if err := saveSettings(userSettings); err != nil {
+			showMessageModal(pages, "configerror", fmt.Sprintf("Columns changed for this session, but saving them failed: %v", err))
+		}
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := list.GetSelection()
+		switch {
+		case event.Key() == tcell.KeyESC:
+			pages.RemovePage("columnchooser")
+			return nil
+		case event.Rune() == ' ':
+			if row >= len(keys) && row < len(keys)+len(attrs) {
+				attr, _ := attrAt(row)
+				selected[attr] = !selected[attr]
+				render()
+			}
+			return nil
+		case keyPressed(event, "columns.rename"):
+			if row >= 0 && row < len(keys)+len(attrs) {
+				attr, _ := attrAt(row)
+				showColumnRename(app, pages, tableInfo.Name, attr, func() {
+					render()
+					fullName.SetText(fullNameNote(tableInfo.Name, attr))
+					renamed()
+					app.SetFocus(list)
+				})
+			}
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			columns := []string{}
+			for _, attr := range attrs {
+				if selected[attr] {
+					columns = append(columns, attr)
+				}
+			}
+			save(columns)
+			return nil
+		case keyPressed(event, "columns.reset"):
+			save(nil)
+			return nil
+		}
+		return event
+	})
+
+	// Center the chooser over the results
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(chooser, min(len(keys)+max(len(attrs), 1), 15)+4, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage("columnchooser", modal, true, true)
+	app.SetFocus(list)
+}
+
+// showMessageModal shows a modal with an OK button under the given page name
+func showMessageModal(pages *tview.Pages, pageName, text string) {
+	logShownError(pageName, text)
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage(pageName)
+		})
+	pages.AddPage(pageName, modal, true, true)
+}
+
+// newViewHost is the ui.Host the item views of a session are shown with
+func newViewHost(app *tview.Application, pages *tview.Pages, client *aws.Client) *ui.Host {
+	return &ui.Host{
+		App:          app,
+		Pages:        pages,
+		Client:       client,
+		Theme:        ui.Theme{Orange: accentOrange, Teal: accentTeal, TextSecondary: textSecondary, BgSecondary: bgSecondary},
+		Settings:     &userSettings.Settings,
+		ScreenReader: *screenReader,
+		KeyPressed:   keyPressed,
+		KeyLabel:     keyLabel,
+		Read: func(ctx context.Context, read func(ctx context.Context) error, done func(err error)) {
+			runWithReauth(ctx, app, pages, client, read, done)
+		},
+		Loading: func(pageName, text string) context.Context {
+			return showLoadingModal(pages, pageName, text)
+		},
+		Message: func(pageName, text string) {
+			showMessageModal(pages, pageName, text)
+		},
+		Notify: func(text string) {
+			status.notify(text, noticeDuration)
+		},
+		Copy: copyToClipboard,
+		SaveSettings: func() error {
+			return saveSettings(userSettings)
+		},
+		ColumnTitle:  columnTitle,
+		CellWidth:    cellWidth,
+		FormatNumber: formatNumber,
+		TTLText: func(v interface{}) (string, bool) {
+			return ttlText(v, time.Now())
+		},
+		ShowBinary: func(tableInfo aws.TableInfo, rawItem map[string]interface{}, attr, title string) bool {
+			blobs, ok := binaryBlobs(rawItem[attr])
+			if ok {
+				showBinaryView(app, pages, title, binaryFileName(tableInfo, rawItem, attr), blobs)
+			}
+			return ok
+		},
+	}
+}
+
+// resultColumns are the columns shown after the keys in the results of a
+// table: those chosen with Ctrl+C, those preset in config.yaml, or up to two
+// descriptive attributes of the first item
+func resultColumns(tableInfo aws.TableInfo, items []map[string]interface{}) []string {
+	if columns, ok := userSettings.Columns[tableInfo.Name]; ok {
+		return columns
+	}
+	if preset, ok := configDefaults.Columns[tableInfo.Name]; ok {
+		return preset
+	}
+	return ui.DetectColumns(tableInfo, items)
+}
+
+// resultsPage is a ui.ResultsView with the features of the application
+// around it: pinned rows, watch mode, distributions, stats and groups of
+// the loaded pages, exports, edits and the other keys that reach past the
+// items shown
+type resultsPage struct {
+	app       *tview.Application
+	pages     *tview.Pages
+	client    *aws.Client
+	host      *ui.Host
+	tableInfo aws.TableInfo
+	opts      ui.ResultsOptions
+	view      *ui.ResultsView
+
+	// Rows pinned with Ctrl+P, kept above the results across queries and pages
+	pinned *tview.Table
+
+	// Distribution of a numeric attribute over every loaded page, chosen with Ctrl+K
+	distribution     *tview.TextView
+	distributionAttr string
+
+	// Watch mode, toggled with w: the first page is read again every
+	// --watch-interval, and the rows that appeared or changed since the
+	// previous read are highlighted
+	watching     bool
+	stopWatching context.CancelFunc
+	watchChanges map[string]string // By itemKey
+	watchState   string            // Shown in the header while watching
+	primaryKey   []string
+}
+
+// showResultsPage renders a page of items with pagination and item drill-down.
+// A first page still being read is shown with loading set and kept current
+// through the returned updater.
+func showResultsPage(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, opts ui.ResultsOptions, result aws.QueryResult, loading bool) ui.ResultsUpdater {
+	p := &resultsPage{
+		app:        app,
+		pages:      pages,
+		client:     client,
+		host:       newViewHost(app, pages, client),
+		tableInfo:  tableInfo,
+		opts:       opts,
+		primaryKey: tablePrimaryKey(tableInfo),
+	}
+	p.view = ui.NewResultsView(p.host, tableInfo, opts, result, loading)
+	p.view.SetColumns(resultColumns(tableInfo, result.Items))
+
+	p.distribution = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	p.view.AddPane(p.distribution) // Shown once an attribute is picked
+
+	// The pinned rows, which start with their status, scroll along with the
+	// results
+	p.pinned = newDataTable()
+	p.pinned.SetFixed(1, p.view.KeyColumns()+1)
+	p.view.AddPane(p.pinned) // Sized by updatePinned
+	p.view.ScrollAlong(p.pinned)
+
+	p.view.SetRowColorFunc(p.rowColor)
+	p.view.SetHeaderNoteFunc(p.watchNote)
+	p.view.SetChangedFunc(p.changed)
+	p.view.SetInputCapture(p.handleKey)
+	p.view.Show()
+	return p.view.Update
+}
+
+// changed follows the items shown: it notes their attributes for
+// completion, describes the page in the status bar and updates the
+// distribution and the pinned rows
+func (p *resultsPage) changed() {
+	result := p.view.Result()
+	noteAttributes(p.tableInfo.Name, result.RawItems)
+	read := fmt.Sprintf("%s RCU", formatCapacity(result.ConsumedCapacity))
+	if timing := newReadTiming(result.Requests).statusText(); timing != "" && !p.view.Loading() {
+		read = timing + ", " + read
+	}
+	cursor := ""
+	if start := p.view.PageStart(); start != nil {
+		cursor = "after " + tview.Escape(start.Summary())
+	}
+	status.set(p.opts.PageName, pageStatus{
+		table: p.tableInfo.Name,
+		page:  p.view.Page(),
+		items: formatNumber(int64(len(result.Items))) + " items",
+		read:  read,
+		note:  cursor,
+	})
+	p.updateDistribution()
+	p.updatePinned()
+}
+
+// rowColor highlights pinned rows and, on the first page, the rows watch
+// mode found new or changed
+func (p *resultsPage) rowColor(page int, rawItem map[string]interface{}) (tcell.Color, bool) {
+	if page == 1 {
+		switch p.watchChanges[itemKey(rawItem, p.primaryKey)] {
+		case watchAdded:
+			return accentGreen, true
+		case watchChanged:
+			return accentYellow, true
+		}
+	}
+	return accentYellow, pinIndex(p.tableInfo, rawItem) >= 0
+}
+
+// watchNote is the state of watch mode, for the header
+func (p *resultsPage) watchNote() string {
+	switch {
+	case p.watching && p.view.Viewing():
+		return "watch paused while sorted or grouped"
+	case p.watching && p.view.Page() > 1:
+		return "watch paused off page 1"
+	}
+	return p.watchState
+}
+
+// loadedItems are the items of every page read so far
+func (p *resultsPage) loadedItems() []map[string]interface{} {
+	var rawItems []map[string]interface{}
+	for _, page := range p.view.LoadedPages() {
+		rawItems = append(rawItems, page.RawItems...)
+	}
+	return rawItems
+}
+
+func (p *resultsPage) updateDistribution() {
+	if p.distributionAttr != "" {
+		p.distribution.SetText(newDistribution(p.loadedItems(), p.distributionAttr).text())
+	}
+}
+
+func (p *resultsPage) updatePinned() {
+	fillPinnedTable(p.pinned, p.tableInfo, p.view.ShownFields(), p.view.Result())
+	p.view.ResizePane(p.pinned, pinnedTableHeight(len(pinnedRows[p.tableInfo.Name])))
+}
+
+// selectedItem is the item of the selected row, among the pinned rows when
+// they have the focus
+func (p *resultsPage) selectedItem() (item, rawItem map[string]interface{}, ok bool) {
+	if p.pinned.HasFocus() {
+		row, _ := p.pinned.GetSelection()
+		pins := pinnedRows[p.tableInfo.Name]
+		if row < 1 || row > len(pins) {
+			return nil, nil, false
+		}
+		return pins[row-1].item, pins[row-1].rawItem, true
+	}
+	i, ok := p.view.Selected()
+	if !ok {
+		return nil, nil, false
+	}
+	result := p.view.Result()
+	return result.Items[i], result.RawItems[i], true
+}
+
+// showWatched shows a new read of the first page in place of the pages
+// loaded so far, highlighting what changed since the previous read
+func (p *resultsPage) showWatched(newResult aws.QueryResult) {
+	changes, gone := pageChanges(p.view.LoadedPages()[0].RawItems, newResult.RawItems, p.primaryKey)
+	added := 0
+	for _, change := range changes {
+		if change == watchAdded {
+			added++
+		}
+	}
+	p.watchChanges = changes
+	p.watchState = fmt.Sprintf("watching every %s, read at %s: %d new, %d changed, %d gone",
+		*watchInterval, time.Now().Format("15:04:05"), added, len(changes)-added, gone)
+	p.view.Reread(newResult)
+}
+
+// endWatch stops watching, leaving why in the header when there is a reason
+func (p *resultsPage) endWatch(reason string) {
+	p.stopWatching()
+	p.watching = false
+	p.watchChanges = nil
+	p.watchState = reason
+	p.view.Redraw()
+}
+
+// watchNext waits an interval, then reads the first page again if it is
+// the page shown, and schedules the next read once that one is done
+func (p *resultsPage) watchNext(ctx context.Context) {
+	go func() {
+		defer recoverPanic()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*watchInterval):
+		}
+		p.app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			if p.view.Page() != 1 || p.view.Loading() || p.view.Viewing() {
+				p.watchNext(ctx)
+				return
+			}
+			tasks.Go(tasks.group(p.opts.PageName), func(context.Context) func() {
+				refreshed, err := p.opts.FetchNext(ctx, nil, func(aws.QueryResult) {})
+				return func() {
+					if ctx.Err() != nil {
+						return
+					}
+					if err != nil {
+						p.endWatch(fmt.Sprintf("watch stopped: %v", err))
+						return
+					}
+					if p.view.Page() == 1 && !p.view.Loading() {
+						p.showWatched(refreshed)
+					}
+					p.watchNext(ctx)
+				}
+			})
+		})
+	}()
+}
+
+// handleKey handles the keys of the features around the view, before the
+// view's own
+func (p *resultsPage) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	app, pages, client, tableInfo, v := p.app, p.pages, p.client, p.tableInfo, p.view
+	result := v.Result()
+	if keyPressed(event, "results.columns") {
+		showColumnChooser(app, pages, tableInfo, result.Items, v.Columns(), func(columns []string) {
+			if columns == nil {
+				columns = resultColumns(tableInfo, v.Result().Items)
+			}
+			v.SetColumns(columns)
+			v.Focus()
+		}, v.Redraw)
+		return nil
+	} else if keyPressed(event, "results.distribution") {
+		attrs := numericAttributes(p.loadedItems())
+		if len(attrs) == 0 {
+			showMessageModal(pages, "distributionerror", "The loaded items have no numeric attributes.")
+			return nil
+		}
+		showAttributePicker(app, pages, "Distribution of", attrs, p.distributionAttr, "(hide distribution)", func(attr string) {
+			p.distributionAttr = attr
+			if attr == "" {
+				v.ResizePane(p.distribution, 0)
+			} else {
+				v.ResizePane(p.distribution, 1)
+				p.updateDistribution()
+			}
+			v.Focus()
+		})
+		return nil
+	} else if keyPressed(event, "results.aggregate") {
+		// Stats of an attribute over every loaded page, starting on the current column
+		rawItems := p.loadedItems()
+		if len(rawItems) == 0 {
+			showMessageModal(pages, "aggregateerror", "No items are loaded.")
+			return nil
+		}
+		showAttributePicker(app, pages, "Stats of", itemAttributes(rawItems), v.ShownFields()[v.CurrentColumn()], "", func(attr string) {
+			showAggregate(app, pages, p.opts.Title, newAggregate(rawItems, attr))
+		})
+		return nil
+	} else if keyPressed(event, "results.export") && !v.Loading() {
+		if len(result.Items) == 0 {
+			showMessageModal(pages, "exporterror", "There are no rows to export.")
+			return nil
+		}
+		name := fmt.Sprintf("%s_page%d", tableInfo.Name, v.Page())
+		showVisibleExport(pages, name, newVisibleRows(p.opts, result, v.ShownFields()))
+		return nil
+	} else if keyPressed(event, "results.pin") {
+		// Pin the selected row, or unpin the selected pinned row
+		item, rawItem, ok := p.selectedItem()
+		if !ok {
+			return nil
+		}
+		togglePin(tableInfo, item, rawItem)
+		if p.pinned.HasFocus() && len(pinnedRows[tableInfo.Name]) == 0 {
+			v.Focus()
+		}
+		v.Redraw()
+		return nil
+	} else if keyPressed(event, "results.dualWrite") {
+		// Compare the selected item with the other table of its dual-write pair
+		if _, ok := dualWritePairs[tableInfo.Name]; !ok {
+			showMessageModal(pages, "dualwriteerror", noDualWriteText(tableInfo.Name))
+			return nil
+		}
+		if i, ok := v.Selected(); ok {
+			showDualWriteDiff(app, pages, client, tableInfo.Name, dualWriteKeyOf(tableInfo, result.RawItems[i]))
+		}
+		return nil
+	} else if event.Key() == tcell.KeyTab && len(pinnedRows[tableInfo.Name]) > 0 {
+		// Move between the pinned rows and the results
+		if p.pinned.HasFocus() {
+			v.Focus()
+		} else {
+			app.SetFocus(p.pinned)
+		}
+		return nil
+	} else if keyPressed(event, "results.timing") && !v.Loading() {
+		if len(result.Requests) == 0 {
+			showMessageModal(pages, "timingerror", "No requests were recorded for this page.")
+			return nil
+		}
+		showReadTiming(app, pages, fmt.Sprintf("%s - Page %d", p.opts.Title, v.Page()), result.Requests)
+		return nil
+	} else if keyPressed(event, "results.copyItem") && p.pinned.HasFocus() {
+		// Pinned rows are copied as they were when pinned
+		if _, rawItem, ok := p.selectedItem(); ok {
+			ui.CopyItemAsJSON(p.host, rawItem, ui.ItemSource{})
+		}
+		return nil
+	} else if keyPressed(event, "results.watch") {
+		// Read the first page again and again, highlighting changes
+		if p.watching {
+			p.endWatch("")
+			status.notify("Stopped watching", noticeDuration)
+			return nil
+		}
+		if p.opts.FetchNext == nil {
+			showMessageModal(pages, "watcherror", "These results merge several reads that can't be repeated page by page, so they can't be watched.")
+			return nil
+		}
+		ctx, cancel := context.WithCancel(tasks.group(p.opts.PageName).ctx)
+		p.watching, p.stopWatching = true, cancel
+		p.watchState = fmt.Sprintf("watching every %s", *watchInterval)
+		v.Redraw()
+		p.watchNext(ctx)
+		return nil
+	} else if keyPressed(event, "results.cursor") && !v.Loading() {
+		// Read a page from a cursor entered, saved or copied earlier
+		if p.opts.FetchNext == nil {
+			showMessageModal(pages, "cursorerror", "These results merge several reads, so they have no single cursor.")
+			return nil
+		}
+		label := fmt.Sprintf("%s, page %d", p.opts.Title, v.Page())
+		showCursorPrompt(app, pages, tableInfo.Name, label, v.PageStart(), func(start *aws.PageToken) {
+			ctx := showLoadingModal(pages, "loadingpage", "Loading page...")
+			var jumped aws.QueryResult
+			runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+				var err error
+				jumped, err = p.opts.FetchNext(ctx, start, func(aws.QueryResult) {})
+				return err
+			}, func(err error) {
+				pages.RemovePage("loadingpage")
+				if err != nil {
+					showMessageModal(pages, "pageerror", fmt.Sprintf("Error loading the page: %v", err))
+					return
+				}
+				if p.watching {
+					p.endWatch("")
+				}
+				v.JumpTo(start, jumped)
+			})
+		})
+		return nil
+	} else if keyPressed(event, "results.copyCommand") {
+		// The read as an aws dynamodb command, to share or run elsewhere
+		if request, ok := readRequest(pages, p.opts); ok {
+			copyReadText(pages, request.CLICommand(), "the aws dynamodb command")
+		}
+		return nil
+	} else if keyPressed(event, "results.copySnippet") {
+		// The read as SDK code, to drop into a service
+		request, ok := readRequest(pages, p.opts)
+		if !ok {
+			return nil
+		}
+		modal := tview.NewModal().
+			SetText("Copy the read as code for which SDK?").
+			AddButtons([]string{"Go (aws-sdk-go-v2)", "Python (boto3)", "Cancel"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				pages.RemovePage("copysnippet")
+				switch buttonIndex {
+				case 0:
+					copyReadText(pages, request.GoSnippet(), "the Go snippet")
+				case 1:
+					copyReadText(pages, request.PythonSnippet(), "the Python snippet")
+				}
+			})
+		pages.AddPage("copysnippet", modal, true, true)
+		return nil
+	} else if keyPressed(event, "results.editItem") && !v.Loading() && !p.pinned.HasFocus() && !*readOnly {
+		// Edit the selected item in $EDITOR, then show the row as written
+		i, ok := v.Selected()
+		if !ok {
+			return nil
+		}
+		itemTable := tableInfo
+		if p.opts.SourceColumn == "Table" {
+			itemTable.Name = p.opts.SourceOf(result, i).Value // A member of a table family
+		}
+		editInExternalEditor(app, pages, client, itemTable, result.RawItems[i], func(values map[string]interface{}) {
+			key := map[string]interface{}{tableInfo.PartitionKey: values[tableInfo.PartitionKey]}
+			if tableInfo.SortKey != "" {
+				key[tableInfo.SortKey] = values[tableInfo.SortKey]
+			}
+			v.RefreshItem(itemTable.Name, key, i)
+		})
+		return nil
+	} else if keyPressed(event, "results.expandCell") {
+		// The full value of the current column in the selected row
+		item, rawItem, ok := p.selectedItem()
+		if !ok {
+			return nil
+		}
+		field := v.ShownFields()[v.CurrentColumn()]
+		title := fmt.Sprintf("%s of %v", columnTitle(tableInfo.Name, field), rawItem[tableInfo.PartitionKey])
+		if !p.host.ShowBinary(tableInfo, rawItem, field, title) {
+			showCellValue(app, pages, title, plainCellValue(item, rawItem, field))
+		}
+		return nil
+	} else if keyPressed(event, "results.groupBy") && !v.Loading() {
+		// Items per value of an attribute over every loaded page, and
+		// the items of a group picked there
+		rawItems := p.loadedItems()
+		if len(rawItems) == 0 {
+			showMessageModal(pages, "groupbyerror", "No items are loaded.")
+			return nil
+		}
+		current := v.Group()
+		if current == "" {
+			current = v.ShownFields()[v.CurrentColumn()]
+		}
+		showAttributePicker(app, pages, "Group by", itemAttributes(rawItems), current, "", func(attr string) {
+			a := newAggregate(rawItems, attr)
+			showGroups(app, pages, p.opts.Title, columnTitle(tableInfo.Name, attr), a, func(value aggregateValue) {
+				v.SetGroup(attr, groupLabel(a, value), func(rawItem map[string]interface{}) bool {
+					stored, ok := rawItem[attr]
+					if !ok {
+						return value == missingGroup
+					}
+					return newAggregateValue(stored) == value
+				})
+			})
+		})
+		return nil
+	} else if keyPressed(event, "results.widen") || keyPressed(event, "results.narrow") {
+		// Widen or narrow the current column and remember it
+		field := v.ShownFields()[v.CurrentColumn()]
+		title := columnTitle(tableInfo.Name, field)
+		if v.CurrentColumn() < v.KeyColumns() {
+			status.notify(title+" is a key column, always shown in full", noticeDuration)
+			return nil
+		}
+		width := cellWidth(tableInfo.Name, field) + cellWidthStep
+		if keyPressed(event, "results.narrow") {
+			width = max(ui.MinCellWidth, cellWidth(tableInfo.Name, field)-cellWidthStep)
+		}
+		err := setCellWidth(tableInfo.Name, field, width)
+		v.Redraw()
+		if err != nil {
+			showMessageModal(pages, "configerror", fmt.Sprintf("Column width changed for this session, but saving it failed: %v", err))
+			return nil
+		}
+		status.notify(fmt.Sprintf("%s: up to %d characters", title, width), noticeDuration)
+		return nil
+	} else if event.Key() == tcell.KeyEnter && p.pinned.HasFocus() {
+		// A pinned row opens as it was when pinned
+		if item, rawItem, ok := p.selectedItem(); ok {
+			ui.ShowItemDetail(p.host, tableInfo, item, rawItem, ui.ItemSource{})
+		}
+		return nil
+	}
+	return event
+}
+
+// readRequest builds the request of the results' read, showing why when
+// there is none to copy
+func readRequest(pages *tview.Pages, opts ui.ResultsOptions) (aws.ReadRequest, bool) {
+	if opts.Request == nil {
+		showMessageModal(pages, "copyerror", "These results merge several reads, so no single request reproduces them.")
+		return aws.ReadRequest{}, false
+	}
+	request, err := opts.Request()
+	if err != nil {
+		showMessageModal(pages, "copyerror", fmt.Sprintf("Error building the request: %v", err))
+		return aws.ReadRequest{}, false
+	}
+	return request, true
+}
+
+// copyReadText copies a command or snippet of a read, naming it in the
+// confirmation
+func copyReadText(pages *tview.Pages, text, what string) {
+	if !copyToClipboard(text) {
+		showMessageModal(pages, "copyerror", "The clipboard isn't available yet, try again.")
+		return
+	}
+	status.notify("Copied "+what, noticeDuration)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// s3ExportPollInterval is how often a running export is described. Exports
+// take minutes at least, so there is no point asking more often.
+const s3ExportPollInterval = 15 * time.Second
+
+// splitS3Location accepts a bucket name or an s3://bucket/prefix URL
+func splitS3Location(bucket, prefix string) (string, string) {
+	bucket = strings.TrimSpace(bucket)
+	prefix = strings.TrimSpace(prefix)
+	if rest, ok := strings.CutPrefix(bucket, "s3://"); ok {
+		bucket, rest, _ = strings.Cut(rest, "/")
+		if prefix == "" {
+			prefix = rest
+		}
+	}
+	return bucket, prefix
+}
+
+// showS3ExportForm asks where to export a table to and starts the export
+func showS3ExportForm(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName string) {
+	form := tview.NewForm()
+	form.AddInputField("Bucket", "", 36, nil, nil)
+	form.AddInputField("Prefix", tableName+"/", 36, nil, nil)
+	form.AddDropDown("Format", aws.ExportFormats, 0, nil)
+	form.AddButton("Export", func() {
+		bucket, prefix := splitS3Location(
+			form.GetFormItem(0).(*tview.InputField).GetText(),
+			form.GetFormItem(1).(*tview.InputField).GetText())
+		if bucket == "" {
+			return
+		}
+		_, format := form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
+		pages.RemovePage("s3exportform")
+		startS3Export(app, pages, client, tableName, bucket, prefix, format)
+	})
+	showFormPrompt(app, pages, "s3exportform", "Export "+tableName+" to S3", form)
+}
+
+// startS3Export requests the export and opens its status page
+func startS3Export(app *tview.Application, pages *tview.Pages, client *aws.Client, tableName, bucket, prefix, format string) {
+	ctx := showLoadingModal(pages, "s3exportloading", fmt.Sprintf("Starting the export of %s...", tableName))
+
+	var export aws.S3Export
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		export, err = client.ExportTableToS3(ctx, tableName, bucket, prefix, format)
+		return err
+	}, func(err error) {
+		pages.RemovePage("s3exportloading")
+		if err != nil {
+			showMessageModal(pages, "s3exporterror", fmt.Sprintf("Export error: %v", err))
+			return
+		}
+		createS3ExportPage(app, pages, client, export)
+	})
+}
+
+// s3ExportText describes the state of an export
+func s3ExportText(e aws.S3Export, now time.Time) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "  %-16s %s\n", label, tview.Escape(value))
+	}
+	when := func(t time.Time) string {
+		return formatDate(t) + " " + t.Local().Format("15:04:05")
+	}
+
+	status := e.Status
+	switch e.Status {
+	case "COMPLETED":
+		status = "[#30d158]" + status + "[white]"
+	case "FAILED":
+		status = "[#ff453a]" + status + "[white]"
+	default:
+		status = "[#ffd60a]" + status + "[white]"
+	}
+	fmt.Fprintf(&b, "\n  %-16s %s\n", "Status", status)
+	line("Table", e.Table)
+	line("Destination", fmt.Sprintf("s3://%s/%s", e.Bucket, e.Prefix))
+	line("Format", e.Format)
+	if !e.ExportTime.IsZero() {
+		line("Consistent to", when(e.ExportTime))
+	}
+	if !e.StartTime.IsZero() {
+		line("Started", when(e.StartTime))
+		end := now
+		if !e.EndTime.IsZero() {
+			end = e.EndTime
+			line("Finished", when(e.EndTime))
+		}
+		line("Elapsed", roughDuration(end.Sub(e.StartTime)))
+	}
+	if e.Status == "COMPLETED" {
+		line("Items", formatNumber(e.ItemCount))
+		line("Billed size", formatBytes(e.BilledBytes))
+		line("Manifest", fmt.Sprintf("s3://%s/%s", e.Bucket, e.Manifest))
+	}
+	if e.FailureMessage != "" {
+		line("Failure", e.FailureMessage)
+	}
+	line("Export ARN", e.ARN)
+	return b.String()
+}
+
+// createS3ExportPage follows an export until it finishes. Closing the page
+// stops following it; the export itself keeps running.
+func createS3ExportPage(app *tview.Application, pages *tview.Pages, client *aws.Client, export aws.S3Export) {
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	status := ""
+
+	render := func() {
+		state := fmt.Sprintf("checking every %v", s3ExportPollInterval)
+		if export.Done() {
+			state = "finished"
+		}
+		if status != "" {
+			state = status
+		}
+		header.SetText(fmt.Sprintf("S3 export of %s: %s (ESC: close, the export keeps running | %s: keys)",
+			tview.Escape(export.Table), state, keyLabel("app.pageKeys")))
+		text.SetText(s3ExportText(export, time.Now()))
+	}
+	render()
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("s3export")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("s3export")
+	pages.AddPage("s3export", flex, true, true)
+	app.SetFocus(text)
+
+	// Poll until the export finishes or the page is closed
+	ctx := tasks.group("s3export").ctx
+	go func(current aws.S3Export) {
+		defer recoverPanic()
+		for !current.Done() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s3ExportPollInterval):
+			}
+			latest, err := client.DescribeS3Export(ctx, current)
+			if err == nil {
+				current = latest
+			}
+			app.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					status = fmt.Sprintf("[#ff453a]stopped checking: %s[white]", tview.Escape(err.Error()))
+				} else {
+					export = latest
+				}
+				render()
+			})
+			if err != nil {
+				return
+			}
+		}
+	}(export)
+}
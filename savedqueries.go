@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// savedQuery is a Query tab search saved under a name, such as "active users
+// by org". Key values can hold placeholders like {org} that are asked for
+// every time the query runs.
+type savedQuery struct {
+	Name           string `json:"name,omitempty"`  // Empty for the read of a session
+	Index          string `json:"index,omitempty"` // Empty for the base table
+	PartitionValue string `json:"partitionValue,omitempty"`
+	SortCondition  string `json:"sortCondition,omitempty"`
+	SortValue      string `json:"sortValue,omitempty"`
+	SortValueEnd   string `json:"sortValueEnd,omitempty"` // Upper bound of between
+	Descending     bool   `json:"descending,omitempty"`
+
+	// Filter on one attribute, empty for none
+	FilterAttribute string `json:"filterAttribute,omitempty"`
+	FilterCondition string `json:"filterCondition,omitempty"`
+	FilterValue     string `json:"filterValue,omitempty"`
+}
+
+// placeholderPattern matches a {name} placeholder in a saved key value
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// placeholders returns the distinct placeholder names used in values, in the
+// order they first appear
+func placeholders(values ...string) []string {
+	var names []string
+	for _, v := range values {
+		for _, m := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+			if !slices.Contains(names, m[1]) {
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names
+}
+
+// fillPlaceholders replaces every placeholder in s with its value
+func fillPlaceholders(s string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(p string) string {
+		return values[p[1:len(p)-1]]
+	})
+}
+
+// saveQuery stores q with the table's saved queries, replacing the one with
+// the same name, and writes the config file
+func saveQuery(table string, q savedQuery) error {
+	if userSettings.SavedQueries == nil {
+		userSettings.SavedQueries = make(map[string][]savedQuery)
+	}
+	queries := userSettings.SavedQueries[table]
+	if i := slices.IndexFunc(queries, func(s savedQuery) bool { return s.Name == q.Name }); i >= 0 {
+		queries[i] = q
+	} else {
+		queries = append(queries, q)
+	}
+	userSettings.SavedQueries[table] = queries
+	return saveSettings(userSettings)
+}
+
+// deleteSavedQuery removes a saved query of the table and writes the config file
+func deleteSavedQuery(table, name string) error {
+	userSettings.SavedQueries[table] = slices.DeleteFunc(userSettings.SavedQueries[table], func(s savedQuery) bool {
+		return s.Name == name
+	})
+	if len(userSettings.SavedQueries[table]) == 0 {
+		delete(userSettings.SavedQueries, table)
+	}
+	return saveSettings(userSettings)
+}
+
+// showFormPrompt shows a small bordered form centered over the current page
+func showFormPrompt(app *tview.Application, pages *tview.Pages, pageName, title string, form *tview.Form) {
+	form.SetCancelFunc(func() {
+		pages.RemovePage(pageName)
+	})
+	form.SetLabelColor(textSecondary).
+		SetFieldBackgroundColor(accentOrange).
+		SetFieldTextColor(tcell.NewHexColor(0x121212)).
+		SetButtonBackgroundColor(accentOrange).
+		SetButtonTextColor(tcell.NewHexColor(0x121212))
+	form.SetBorder(true).
+		SetBorderColor(accentOrange).
+		SetTitle(fmt.Sprintf(" %s ", title)).
+		SetTitleColor(accentOrange)
+
+	// Each field's rows and the gap after it, plus the buttons and borders
+	height := 5
+	for i := 0; i < form.GetFormItemCount(); i++ {
+		height += form.GetFormItem(i).GetFieldHeight() + 1
+	}
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, height, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage(pageName, modal, true, true)
+	app.SetFocus(form)
+}
+
+// promptQueryName asks for the name to save a query under
+func promptQueryName(app *tview.Application, pages *tview.Pages, initial string, save func(name string)) {
+	form := tview.NewForm()
+	form.AddInputField("Name", initial, 36, nil, nil)
+	form.AddButton("Save", func() {
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if name == "" {
+			return
+		}
+		pages.RemovePage("savequery")
+		save(name)
+	})
+	showFormPrompt(app, pages, "savequery", "Save Query", form)
+}
+
+// promptPlaceholders asks for a value for each placeholder of a saved query,
+// then runs it with them
+func promptPlaceholders(app *tview.Application, pages *tview.Pages, queryName string, names []string, run func(values map[string]string)) {
+	form := tview.NewForm()
+	for _, name := range names {
+		form.AddInputField(name, "", 30, nil, nil)
+	}
+	form.AddButton("Run", func() {
+		values := make(map[string]string, len(names))
+		for i, name := range names {
+			values[name] = form.GetFormItem(i).(*tview.InputField).GetText()
+		}
+		pages.RemovePage("placeholders")
+		run(values)
+	})
+	title := "Query Parameters"
+	if queryName != "" {
+		title = queryName
+	}
+	showFormPrompt(app, pages, "placeholders", title, form)
+}
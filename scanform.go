@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// addScanForm fills the form of the Scan tab: a filter, and the button
+// scanning the table or the sparse index that holds the filtered items
+func (p *tableActionPage) addScanForm() {
+	filter := p.scanFilter
+	addFilterFields(p.form, p.client, p.tableInfo, filter, func(f attributeFilter) {
+		filter = f
+		p.formError.SetText(f.problem())
+	})
+
+	// runScan reads the table or an index page by page
+	runScan := func(params aws.ScanParams) {
+		ctx := showLoadingModal(p.pages, "loadingscan", "Scanning...")
+		start := p.resumeStart
+		p.resumeStart = nil
+
+		var result aws.QueryResult
+		runWithReauth(ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+			var err error
+			result, err = p.client.Scan(ctx, params, start)
+			return err
+		}, func(err error) {
+			p.pages.RemovePage("loadingscan")
+			p.pages.RemovePage("scanresult") // Remove any existing scan results
+			if err != nil {
+				errorModal := tview.NewModal().
+					SetText(fmt.Sprintf("Scan error: %v", err)).
+					AddButtons([]string{"OK"}).
+					SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+						p.pages.RemovePage("scanerror")
+					})
+				p.pages.AddPage("scanerror", errorModal, true, true)
+				return
+			}
+
+			opts := ui.ResultsOptions{
+				PageName: "scanresult",
+				Title:    fmt.Sprintf("Scan Results for %s", p.tableInfo.Name),
+				FetchNext: func(ctx context.Context, page *aws.PageToken, _ func(aws.QueryResult)) (aws.QueryResult, error) {
+					return p.client.Scan(ctx, params, page)
+				},
+				Request: func() (aws.ReadRequest, error) {
+					return p.client.ScanRequest(params)
+				},
+				Start: start,
+				OnPage: trackRead(p.tableInfo.Name, true, savedQuery{
+					Index:           params.IndexName,
+					FilterAttribute: params.FilterAttribute,
+					FilterCondition: params.FilterCondition,
+					FilterValue:     params.FilterValue,
+				}),
+			}
+			if params.FilterAttribute != "" {
+				opts.Notice = fmt.Sprintf("[#b8b8b8]Filtered pages can hold fewer items than the %d read, %s continues[white]", *pageSize, keyLabel("results.nextPage"))
+			}
+			if index, ok := p.tableInfo.Index(params.IndexName); ok {
+				opts.Title = fmt.Sprintf("Scan Results for %s (%s)", p.tableInfo.Name, index.Name)
+				if !index.ProjectsAll() {
+					opts.Notice = fmt.Sprintf("[#ffd60a]%s projects %s: %s hydrates from the base table[white]", index.Name, index.ProjectionType, keyLabel("results.hydrate"))
+					opts.Hydrate = true
+				}
+			}
+			showResultsPage(p.app, p.pages, p.client, p.tableInfo, opts, result, false)
+		})
+	}
+
+	// runIndexQuery reads the items matching an equality filter from a sparse index
+	runIndexQuery := func(params aws.QueryParams, index aws.IndexInfo) {
+		ctx := showLoadingModal(p.pages, "loading", "Querying...")
+
+		var result aws.QueryResult
+		runWithReauth(ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+			var err error
+			result, err = p.client.Query(ctx, params, nil)
+			return err
+		}, func(err error) {
+			p.pages.RemovePage("loading")
+			if err != nil {
+				showMessageModal(p.pages, "queryerror", fmt.Sprintf("Query error: %v", err))
+				return
+			}
+			opts := ui.ResultsOptions{
+				PageName: "queryresult",
+				Title:    fmt.Sprintf("Query Results for %s (%s)", p.tableInfo.Name, index.Name),
+				FetchNext: func(ctx context.Context, page *aws.PageToken, _ func(aws.QueryResult)) (aws.QueryResult, error) {
+					return p.client.Query(ctx, params, page)
+				},
+				Request: func() (aws.ReadRequest, error) {
+					return p.client.QueryRequest(params)
+				},
+				OnPage: trackRead(p.tableInfo.Name, false, savedQuery{Index: index.Name, PartitionValue: params.PartitionValue}),
+			}
+			if !index.ProjectsAll() {
+				opts.Notice = fmt.Sprintf("[#ffd60a]%s projects %s: %s hydrates from the base table[white]", index.Name, index.ProjectionType, keyLabel("results.hydrate"))
+				opts.Hydrate = true
+			}
+			showResultsPage(p.app, p.pages, p.client, p.tableInfo, opts, result, false)
+		})
+	}
+
+	if p.resuming {
+		p.resumeRead = func() {
+			runScan(aws.ScanParams{
+				TableName:       p.tableInfo.Name,
+				IndexName:       p.resume.Read.Index,
+				FilterAttribute: filter.attribute,
+				FilterCondition: filter.condition,
+				FilterValue:     filter.value,
+			})
+		}
+	}
+	p.form.AddButton(fmt.Sprintf("Scan %s", p.tableInfo.Name), func() {
+		if msg := filter.problem(); msg != "" {
+			p.formError.SetText(msg)
+			return
+		}
+		params := aws.ScanParams{TableName: p.tableInfo.Name}
+		if filter.attribute != "" {
+			params.FilterAttribute = filter.attribute
+			params.FilterCondition = filter.condition
+			params.FilterValue = filter.value
+		}
+
+		hint, ok := aws.SparseIndexFor(p.tableInfo, params)
+		if !ok {
+			runScan(params)
+			return
+		}
+		showSparseIndexHint(p.pages, p.tableInfo, params, hint, func() {
+			if hint.Query {
+				runIndexQuery(hint.QueryParams(params), hint.Index)
+			} else {
+				runScan(hint.ScanParams(params))
+			}
+		}, func() {
+			runScan(params)
+		})
+	})
+
+	// Set focus to form itself
+	p.app.SetFocus(p.form)
+}
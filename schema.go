@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Bounds of the sample an analysis reads
+const (
+	defaultSampleItems = 1000
+	maxSampleItems     = 100000
+)
+
+// schemaExamples is how many distinct example values an attribute of the
+// schema report shows, each cut to schemaExampleWidth characters
+const (
+	schemaExamples     = 3
+	schemaExampleWidth = 30
+)
+
+// attributeSchema is what a sample tells of an attribute
+type attributeSchema struct {
+	name     string
+	items    int            // Sampled items holding it
+	types    map[string]int // Sampled items by the type of their value
+	examples []string       // Distinct values, the first ones found
+}
+
+// inferSchema collects the attributes of the sampled items, those held by
+// the most items first and equally filled ones by name
+func inferSchema(rawItems []map[string]interface{}) []attributeSchema {
+	byName := make(map[string]*attributeSchema)
+	for _, item := range rawItems {
+		for name, v := range item {
+			attr, ok := byName[name]
+			if !ok {
+				attr = &attributeSchema{name: name, types: make(map[string]int)}
+				byName[name] = attr
+			}
+			attr.items++
+			attr.types[valueType(v)]++
+			example := "NULL"
+			if v != nil {
+				example = ui.TruncateCell(ui.RawValueString(v), schemaExampleWidth)
+			}
+			if len(attr.examples) < schemaExamples && !slices.Contains(attr.examples, example) {
+				attr.examples = append(attr.examples, example)
+			}
+		}
+	}
+	schema := make([]attributeSchema, 0, len(byName))
+	for _, attr := range byName {
+		schema = append(schema, *attr)
+	}
+	slices.SortFunc(schema, func(a, b attributeSchema) int {
+		if a.items != b.items {
+			return b.items - a.items
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return schema
+}
+
+// typesText lists the types of an attribute, the most common first, with
+// their share when there are several
+func (a attributeSchema) typesText() string {
+	types := slices.Sorted(maps.Keys(a.types))
+	slices.SortStableFunc(types, func(x, y string) int {
+		return a.types[y] - a.types[x]
+	})
+	if len(types) == 1 {
+		return types[0]
+	}
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%s %s%%", t, formatStat(float64(a.types[t])*100/float64(a.items)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showAnalyzeForm asks how many items of a table to sample for the schema
+// report
+func showAnalyzeForm(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo) {
+	form := tview.NewForm()
+	form.AddInputField("Items to sample", strconv.Itoa(defaultSampleItems), 10, tview.InputFieldInteger, nil)
+	form.AddButton("Analyze", func() {
+		text := form.GetFormItem(0).(*tview.InputField).GetText()
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 1 || n > maxSampleItems {
+			showMessageModal(pages, "analyzeerror", fmt.Sprintf("Sample between 1 and %s items.", formatNumber(maxSampleItems)))
+			return
+		}
+		pages.RemovePage("analyzeform")
+		analyzeTable(app, pages, client, tableInfo, n)
+	})
+	showFormPrompt(app, pages, "analyzeform", "Analyze "+tableInfo.Name, form)
+}
+
+// analyzeTable scans up to n items of a table from its start and opens the
+// schema report of what it read
+func analyzeTable(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo, n int) {
+	ctx := showLoadingModal(pages, "analyzing", fmt.Sprintf("Sampling %s items of %s...", formatNumber(int64(n)), tableInfo.Name))
+
+	var sample aws.QueryResult
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		sample, err = aws.ScanSample(ctx, client, tableInfo.Name, n)
+		return err
+	}, func(err error) {
+		pages.RemovePage("analyzing")
+		if err != nil {
+			showMessageModal(pages, "analyzeerror", fmt.Sprintf("Failed to sample %s: %v", tableInfo.Name, err))
+			return
+		}
+		if len(sample.RawItems) == 0 {
+			showMessageModal(pages, "analyzeerror", fmt.Sprintf("%s has no items to analyze.", tableInfo.Name))
+			return
+		}
+		createSchemaPage(app, pages, tableInfo, sample)
+	})
+}
+
+// createSchemaPage lists the attributes of a sample with their types, how
+// many of the items hold them and example values
+func createSchemaPage(app *tview.Application, pages *tview.Pages, tableInfo aws.TableInfo, sample aws.QueryResult) {
+	schema := inferSchema(sample.RawItems)
+	report := newDataTable().
+		SetFixed(1, 1)
+
+	columns := []string{"Attribute", "Types", "Fill Rate", "Examples"}
+	for col, name := range columns {
+		report.SetCell(0, col, tview.NewTableCell(name).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for i, attr := range schema {
+		row := i + 1
+		name := attr.name
+		switch name {
+		case tableInfo.PartitionKey:
+			name += " (partition key)"
+		case tableInfo.SortKey:
+			name += " (sort key)"
+		}
+		color := tview.Styles.PrimaryTextColor
+		if attr.items < len(sample.RawItems) {
+			color = accentYellow // Not on every item
+		}
+		fill := fmt.Sprintf("%s%% (%s)", formatStat(float64(attr.items)*100/float64(len(sample.RawItems))), formatNumber(int64(attr.items)))
+
+		report.SetCell(row, 0, tview.NewTableCell(rowPrefix("Attribute", row, len(schema))+tview.Escape(name)).SetTextColor(color))
+		report.SetCell(row, 1, tview.NewTableCell(labeled("types", attr.typesText())).SetTextColor(accentTeal))
+		report.SetCell(row, 2, tview.NewTableCell(labeled("fill rate", fill)).SetTextColor(color).SetAlign(tview.AlignRight))
+		report.SetCell(row, 3, tview.NewTableCell(labeled("examples", tview.Escape(strings.Join(attr.examples, " | ")))).SetTextColor(tview.Styles.PrimaryTextColor))
+	}
+
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Schema of %s from %s sampled items: %d attributes, %s RCU (ESC: close)",
+			tview.Escape(tableInfo.Name), formatNumber(int64(len(sample.RawItems))), len(schema), formatCapacity(sample.ConsumedCapacity)))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(report, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("schema")
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("schema")
+	pages.AddPage("schema", flex, true, true)
+	app.SetFocus(report)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// sessionState is where exploring left off with a profile: the table open,
+// the last query or scan of it and the page of its results in view. It is
+// kept in the config file when a session ends and offered when the next one
+// starts.
+type sessionState struct {
+	Table string `json:"table"`
+
+	// Read holds the values of the last read of the table, nil when none was
+	// run. Scan tells a read of the Scan tab, which uses only the index and
+	// the filter.
+	Read *savedQuery `json:"read,omitempty"`
+	Scan bool        `json:"scan,omitempty"`
+
+	// Cursor is the ExclusiveStartKey of the page in view as typed JSON,
+	// empty for the first page
+	Cursor string `json:"cursor,omitempty"`
+
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// session is where the running session is, nil until a table is opened
+var session *sessionState
+
+// trackTable records that a table was opened. Its last read is kept when
+// the same table is opened again.
+func trackTable(name string) {
+	if session == nil || session.Table != name {
+		session = &sessionState{Table: name}
+	}
+}
+
+// trackRead returns the ui.ResultsOptions.OnPage of a read of the table, which
+// records the read and the page of it in view
+func trackRead(table string, scan bool, read savedQuery) func(start *aws.PageToken) {
+	return func(start *aws.PageToken) {
+		session = &sessionState{Table: table, Read: &read, Scan: scan}
+		if start != nil {
+			session.Cursor = start.JSON()
+		}
+	}
+}
+
+// saveSession keeps where the session left off under the profile and writes
+// the config file. Nothing is written when no table was opened.
+func saveSession(profile string) error {
+	if session == nil {
+		return nil
+	}
+	if userSettings.Sessions == nil {
+		userSettings.Sessions = make(map[string]sessionState)
+	}
+	s := *session
+	s.SavedAt = time.Now()
+	userSettings.Sessions[profile] = s
+	return saveSettings(userSettings)
+}
+
+// summary describes the session for the resume prompt
+func (s sessionState) summary() string {
+	lines := []string{"Table: " + s.Table}
+	if q := s.Read; q != nil {
+		var parts []string
+		if q.Index != "" {
+			parts = append(parts, "index "+q.Index)
+		}
+		if q.PartitionValue != "" {
+			parts = append(parts, fmt.Sprintf("partition key %q", q.PartitionValue))
+		}
+		if q.SortValue != "" {
+			sortKey := fmt.Sprintf("sort key %s %q", q.SortCondition, q.SortValue)
+			if q.SortCondition == "between" {
+				sortKey += fmt.Sprintf(" and %q", q.SortValueEnd)
+			}
+			parts = append(parts, sortKey)
+		}
+		if q.Descending {
+			parts = append(parts, "descending")
+		}
+		if q.FilterAttribute != "" {
+			filter := fmt.Sprintf("filter %s %s", q.FilterAttribute, q.FilterCondition)
+			if aws.FilterTakesValue(q.FilterCondition) {
+				filter += fmt.Sprintf(" %q", q.FilterValue)
+			}
+			parts = append(parts, filter)
+		}
+		read := "Query"
+		if s.Scan {
+			read = "Scan"
+		}
+		if len(parts) > 0 {
+			read += ": " + strings.Join(parts, ", ")
+		}
+		lines = append(lines, read)
+		if cursor, err := aws.ParseCursor(s.Cursor); err == nil && cursor != nil {
+			lines = append(lines, "Page after "+cursor.Summary())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// offerResume asks whether to pick up where the last session with the
+// profile left off, calling resume if so
+func offerResume(pages *tview.Pages, profile string, saved sessionState, resume func()) {
+	text := fmt.Sprintf("Resume where you left off with %s on %s %s?\n\n%s",
+		profile, formatDate(saved.SavedAt), saved.SavedAt.Local().Format("15:04"), saved.summary())
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Resume", "Start Fresh"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("resume")
+			if buttonLabel == "Resume" {
+				resume()
+			}
+		})
+	pages.AddPage("resume", modal, true, true)
+}
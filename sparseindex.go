@@ -0,0 +1,60 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// scanCost estimates the read capacity units of reading size bytes with
+// eventually consistent reads: half a unit per 4 KB
+func scanCost(size int64) float64 {
+	return float64((size+4095)/4096) / 2
+}
+
+// showSparseIndexHint explains why reading a sparse index returns the same
+// items as the filtered scan for less, and lets the user pick either one
+func showSparseIndexHint(pages *tview.Pages, tableInfo aws.TableInfo, scan aws.ScanParams, hint aws.SparseIndexHint, useIndex, scanTable func()) {
+	idx := hint.Index
+	role := "partition key"
+	if scan.FilterAttribute == idx.SortKey {
+		role = "sort key"
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "Filter: %s\n\n", aws.FilterText(scan.FilterAttribute, scan.FilterCondition, scan.FilterValue))
+	fmt.Fprintf(&text, "%s is the %s of the index %s. An index only holds the items that have its key attributes, so every item this filter can match is in it.\n\n",
+		scan.FilterAttribute, role, idx.Name)
+	fmt.Fprintf(&text, "Scanning the table reads all ~%s items (%s, about %s RCU) and is charged for each of them before the filter drops the ones that don't match. ",
+		formatNumber(tableInfo.ItemCount), formatBytes(tableInfo.SizeBytes), formatCapacity(scanCost(tableInfo.SizeBytes)))
+	if hint.Query {
+		fmt.Fprintf(&text, "Querying %s for %s reads only the matching items; the whole index holds ~%s items (%s).",
+			idx.Name, scan.FilterValue, formatNumber(idx.ItemCount), formatBytes(idx.SizeBytes))
+	} else {
+		fmt.Fprintf(&text, "Scanning %s with the same filter reads only its ~%s items (%s, about %s RCU).",
+			idx.Name, formatNumber(idx.ItemCount), formatBytes(idx.SizeBytes), formatCapacity(scanCost(idx.SizeBytes)))
+	}
+	if !idx.ProjectsAll() {
+		fmt.Fprintf(&text, "\n\n%s projects %s, so results carry fewer attributes; Ctrl+G fetches the full items.", idx.Name, idx.ProjectionType)
+	}
+	text.WriteString("\n\nItem counts and sizes are approximate, DynamoDB updates them every six hours.")
+
+	useLabel := "Scan " + idx.Name
+	if hint.Query {
+		useLabel = "Query " + idx.Name
+	}
+	modal := tview.NewModal().
+		SetText(text.String()).
+		AddButtons([]string{useLabel, "Scan Table", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("sparseindex")
+			switch buttonLabel {
+			case useLabel:
+				useIndex()
+			case "Scan Table":
+				scanTable()
+			}
+		})
+	pages.AddPage("sparseindex", modal, true, true)
+}
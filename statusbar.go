@@ -0,0 +1,110 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// statusBar is the line under every page: the connection, where the page in
+// front is and the read that filled it, and notices that go away by
+// themselves, such as throttling retries. It is only used from the UI
+// goroutine.
+type statusBar struct {
+	app      *tview.Application
+	pages    *tview.Pages
+	client   *aws.Client
+	view     *tview.TextView
+	contexts map[string]pageStatus // By page name
+	notice   string
+	noticeID int // Tells a notice from the ones that replaced it
+}
+
+// pageStatus is what the status bar shows while a page is in front. Pages
+// without one, such as the item views, show the one of the page under them.
+type pageStatus struct {
+	table string
+	page  int    // Results page number, 0 when the page has none
+	items string // e.g. "15 items" or "42 tables"
+	read  string // Requests, time and capacity of the read shown
+	note  string // Anything else about the page, e.g. still loading
+}
+
+// noticeDuration is how long a notice stays on the status bar
+const noticeDuration = 5 * time.Second
+
+// status is created in main once the pages exist
+var status *statusBar
+
+func newStatusBar(app *tview.Application, pages *tview.Pages, client *aws.Client) *statusBar {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	view.SetBackgroundColor(bgSecondary)
+	return &statusBar{app: app, pages: pages, client: client, view: view, contexts: make(map[string]pageStatus)}
+}
+
+// set is the status of a page, shown whenever it is the topmost page that
+// has one
+func (s *statusBar) set(pageName string, ps pageStatus) {
+	s.contexts[pageName] = ps
+}
+
+// notify shows a notice on the line for a while, replacing the one before
+// it
+func (s *statusBar) notify(text string, d time.Duration) {
+	s.noticeID++
+	id := s.noticeID
+	s.notice = text
+	time.AfterFunc(d, func() {
+		s.app.QueueUpdateDraw(func() {
+			if id == s.noticeID {
+				s.notice = ""
+			}
+		})
+	})
+}
+
+// render fills the line for the pages as they are about to be drawn
+func (s *statusBar) render() {
+	// The locked screen shows nothing of the session
+	if name, _ := s.pages.GetFrontPage(); name == "idlelock" {
+		s.view.SetText("")
+		return
+	}
+	fields := []string{labeled("profile", tview.Escape(s.client.Profile())) + " " + labeled("region", s.client.Region())}
+	for name := range s.contexts {
+		if !s.pages.HasPage(name) {
+			delete(s.contexts, name)
+		}
+	}
+	for _, name := range s.pages.GetPageNames(true) { // Topmost first
+		ps, ok := s.contexts[name]
+		if !ok {
+			continue
+		}
+		if ps.table != "" {
+			fields = append(fields, labeled("table", "[::b]"+tview.Escape(ps.table)+"[::-]"))
+		}
+		if ps.page > 0 {
+			fields = append(fields, fmt.Sprintf("page %d", ps.page))
+		}
+		for _, f := range []string{ps.items, ps.read, ps.note} {
+			if f != "" {
+				fields = append(fields, f)
+			}
+		}
+		break
+	}
+	if units := s.client.SessionCapacity(); units > 0 {
+		fields = append(fields, fmt.Sprintf("session %s RCU", formatCapacity(units)))
+	}
+	// Notices come first, where a narrow terminal doesn't cut them off
+	if s.notice != "" {
+		fields = append([]string{s.notice}, fields...)
+	}
+	s.view.SetText(" " + strings.Join(fields, " [gray]|[-] "))
+}
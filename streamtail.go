@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	maxStreamRecords   = 500         // Records kept on the stream page, newest first
+	streamPollInterval = time.Second // How often the open shards are read
+)
+
+// showStreamTail connects to the stream of a table and opens the page that
+// follows it
+func showStreamTail(app *tview.Application, pages *tview.Pages, client *aws.Client, tableInfo aws.TableInfo) {
+	ctx := showLoadingModal(pages, "streamloading", fmt.Sprintf("Connecting to the stream of %s...", tableInfo.Name))
+
+	var tail *aws.StreamTail
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		tail, err = client.TailStream(ctx, tableInfo)
+		return err
+	}, func(err error) {
+		pages.RemovePage("streamloading")
+		if err != nil {
+			showMessageModal(pages, "streamerror", fmt.Sprintf("Stream error: %v", err))
+			return
+		}
+		createStreamPage(app, pages, tableInfo, tail)
+	})
+}
+
+// streamRecordSummary describes what a record changed, as far as the
+// stream's view type shows
+func streamRecordSummary(r aws.StreamRecord) string {
+	switch {
+	case r.OldImage != nil && r.NewImage != nil:
+		changed := changedAttributes(r.OldImage, r.NewImage)
+		if len(changed) == 0 {
+			return "no attributes changed"
+		}
+		return "changed " + strings.Join(changed, ", ")
+	case r.NewImage != nil:
+		return fmt.Sprintf("%d attributes", len(r.NewImage))
+	case r.OldImage != nil:
+		return fmt.Sprintf("%d attributes", len(r.OldImage))
+	}
+	return ""
+}
+
+// formatStreamKeys renders the key of a record as name=value pairs
+func formatStreamKeys(keys map[string]interface{}) string {
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, k := range names {
+		parts[i] = fmt.Sprintf("%s=%v", k, keys[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// createStreamPage lists the stream records of a table as they arrive and
+// keeps polling until the page is closed
+func createStreamPage(app *tview.Application, pages *tview.Pages, tableInfo aws.TableInfo, tail *aws.StreamTail) {
+	list := newDataTable().
+		SetFixed(1, 0)
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+
+	var records []aws.StreamRecord // Newest first
+	paused := false
+	pending := 0 // Records received while paused
+	status := ""
+
+	setHeader := func() {
+		state := fmt.Sprintf("tailing %d shards", tail.Shards())
+		if paused {
+			state = fmt.Sprintf("[#ffd60a]paused, %d new[white]", pending)
+		}
+		if status != "" {
+			state = status
+		}
+		header.SetText(fmt.Sprintf("Stream of %s (%s): %s, %d records (%s: pause | Enter: images | %s: clear | ESC: close)",
+			tview.Escape(tableInfo.Name), tableInfo.StreamView, state, len(records), keyLabel("stream.pause"), keyLabel("stream.clear")))
+	}
+	render := func() {
+		list.Clear()
+		for col, name := range []string{"Time", "Event", "Key", "Change"} {
+			list.SetCell(0, col, tview.NewTableCell(name).
+				SetTextColor(tview.Styles.SecondaryTextColor).
+				SetSelectable(false).
+				SetAlign(tview.AlignCenter))
+		}
+		for i, r := range records {
+			row := i + 1
+			color := tview.Styles.PrimaryTextColor
+			switch r.EventName {
+			case "INSERT":
+				color = accentGreen
+			case "MODIFY":
+				color = accentYellow
+			case "REMOVE":
+				color = accentRed
+			}
+			list.SetCell(row, 0, tview.NewTableCell(rowPrefix("Record", row, len(records))+r.Time.Local().Format("15:04:05")).SetTextColor(color))
+			list.SetCell(row, 1, tview.NewTableCell(labeled("event", r.EventName)).SetTextColor(color))
+			list.SetCell(row, 2, tview.NewTableCell(labeled("key", tview.Escape(formatStreamKeys(r.Keys)))).SetTextColor(color))
+			list.SetCell(row, 3, tview.NewTableCell(labeled("change", tview.Escape(streamRecordSummary(r)))).SetTextColor(color))
+		}
+		setHeader()
+	}
+	render()
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(list, 0, 1, true)
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyESC:
+			pages.RemovePage("streamtail")
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			if row, _ := list.GetSelection(); row > 0 && row <= len(records) {
+				showStreamRecord(app, pages, records[row-1])
+			}
+			return nil
+		case keyPressed(event, "stream.pause"):
+			paused = !paused
+			if !paused {
+				pending = 0
+				render()
+			}
+			setHeader()
+			return nil
+		case keyPressed(event, "stream.clear"):
+			records = nil
+			pending = 0
+			render()
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("streamtail")
+	pages.AddPage("streamtail", flex, true, true)
+	app.SetFocus(list)
+
+	// Poll until the page is closed, which cancels its task group
+	ctx := tasks.group("streamtail").ctx
+	go func() {
+		defer recoverPanic()
+		for {
+			received, err := tail.Poll(ctx)
+			app.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					status = fmt.Sprintf("[#ff453a]stopped: %s[white]", tview.Escape(err.Error()))
+					setHeader()
+					return
+				}
+				if len(received) == 0 {
+					setHeader()
+					return
+				}
+				for _, r := range received {
+					records = append([]aws.StreamRecord{r}, records...)
+				}
+				if len(records) > maxStreamRecords {
+					records = records[:maxStreamRecords]
+				}
+				if paused {
+					pending += len(received)
+					setHeader()
+					return
+				}
+				// Keep the selection on the same record as new ones arrive on top
+				selected, _ := list.GetSelection()
+				render()
+				if selected > 0 {
+					list.Select(min(selected+len(received), len(records)), 0)
+				}
+			})
+			if err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamPollInterval):
+			}
+		}
+	}()
+}
+
+// showStreamRecord shows the old and new images of a stream record side by side
+func showStreamRecord(app *tview.Application, pages *tview.Pages, r aws.StreamRecord) {
+	imageView := func(title string, image map[string]interface{}) *tview.TextView {
+		text := "not in the stream (view type doesn't include it)"
+		if image != nil {
+			b, err := json.MarshalIndent(image, "", "  ")
+			if err != nil {
+				text = err.Error()
+			} else {
+				text = string(b)
+			}
+		}
+		view := tview.NewTextView().
+			SetText(tview.Escape(text)).
+			SetScrollable(true).
+			SetWrap(true)
+		view.SetBorder(true).
+			SetTitle(" " + title + " ")
+		return view
+	}
+	oldView := imageView("Old Image", r.OldImage)
+	newView := imageView("New Image", r.NewImage)
+
+	images := tview.NewFlex().
+		AddItem(oldView, 0, 1, false).
+		AddItem(newView, 0, 1, true)
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("%s %s at %s, %s (Tab: switch image | ESC: close)",
+			r.EventName, formatStreamKeys(r.Keys), r.Time.Local().Format("15:04:05"), streamRecordSummary(r)))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(images, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("streamrecord")
+			return nil
+		case tcell.KeyTab:
+			if oldView.HasFocus() {
+				app.SetFocus(newView)
+			} else {
+				app.SetFocus(oldView)
+			}
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("streamrecord", flex, true, true)
+	app.SetFocus(newView)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"flag"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// connectionChoice is a connection offered by the switcher: a named
+// environment of config.yaml or a plain profile
+type connectionChoice struct {
+	env       string // Named environment, empty for a plain profile
+	profile   string
+	region    string
+	endpoint  string
+	roleARN   string
+	mfaSerial string
+}
+
+// label describes the choice in the switcher
+func (c connectionChoice) label() string {
+	where := c.region
+	if where == "" {
+		where = aws.Regions[0]
+	}
+	if c.endpoint != "" {
+		where = c.endpoint
+	}
+	if c.env != "" {
+		return fmt.Sprintf("%s [#b8b8b8](profile %s, %s)[white]", tview.Escape(c.env), tview.Escape(c.profile), tview.Escape(where))
+	}
+	return fmt.Sprintf("profile %s [#b8b8b8](%s)[white]", tview.Escape(c.profile), tview.Escape(where))
+}
+
+// connectionChoices lists the named environments, resolved like --env
+// without other flags, followed by the profiles, which keep the current
+// region and endpoint
+func connectionChoices(current *aws.Client) []connectionChoice {
+	var choices []connectionChoice
+	for _, name := range configDefaults.environmentNames() {
+		env := configDefaults.Environments[name]
+		c := connectionChoice{
+			env:       name,
+			profile:   configDefaults.Profile,
+			region:    configDefaults.Region,
+			endpoint:  configDefaults.Endpoint,
+			roleARN:   env.RoleARN,
+			mfaSerial: env.MFASerial,
+		}
+		if c.profile == "" {
+			c.profile = flag.Lookup("profile").DefValue
+		}
+		if env.Profile != "" {
+			c.profile = env.Profile
+		}
+		if env.Region != "" {
+			c.region = env.Region
+		}
+		if env.Endpoint != "" {
+			c.endpoint = env.Endpoint
+		}
+		choices = append(choices, c)
+	}
+	for _, p := range aws.Profiles() {
+		choices = append(choices, connectionChoice{profile: p, region: current.Region(), endpoint: *endpoint})
+	}
+	return choices
+}
+
+// showConnectionSwitcher lets the user pick another profile or named
+// environment. Once its connection test passed, the flags describe the new
+// connection and switchTo is called with its client.
+func showConnectionSwitcher(app *tview.Application, pages *tview.Pages, current *aws.Client, switchTo func(client *aws.Client)) {
+	choices := connectionChoices(current)
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+	for _, c := range choices {
+		list.AddItem(c.label(), "", 0, func() {
+			pages.RemovePage("switcher")
+			switchConnection(app, pages, c, switchTo)
+		})
+		if c.env == *envName && c.profile == current.Profile() && (c.env != "" || c.region == current.Region()) {
+			list.SetCurrentItem(list.GetItemCount() - 1)
+		}
+	}
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			pages.RemovePage("switcher")
+			return nil
+		}
+		return event
+	})
+	list.SetBorder(true).
+		SetBorderColor(accentOrange).
+		SetTitle(" Switch Connection ").
+		SetTitleColor(accentOrange)
+
+	// Center the picker over the table list
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, min(len(choices), 15)+2, 0, true).
+			AddItem(nil, 0, 1, false), 70, 0, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage("switcher", modal, true, true)
+	app.SetFocus(list)
+}
+
+// switchConnection tests the chosen connection in the background; on
+// failure the current session carries on
+func switchConnection(app *tview.Application, pages *tview.Pages, c connectionChoice, switchTo func(client *aws.Client)) {
+	name := "profile " + c.profile
+	if c.env != "" {
+		name = c.env
+	}
+	ctx := showLoadingModal(pages, "switching", fmt.Sprintf("Connecting to %s...", name))
+	opts := sessionClientOptions(c.roleARN, c.mfaSerial)
+	if c.region != "" {
+		opts = append(opts, aws.WithRegion(c.region))
+	}
+	if c.endpoint != "" {
+		opts = append(opts, aws.WithEndpoint(c.endpoint))
+	}
+	go func() {
+		defer recoverPanic()
+		client, _, err := aws.CheckConnection(ctx, c.profile, opts...)
+		if err == nil {
+			err = configureClient(client)
+		}
+		app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			pages.RemovePage("switching")
+			if err != nil {
+				showMessageModal(pages, "switcherror", fmt.Sprintf("Could not connect to %s: %v", name, err))
+				return
+			}
+			*profile, *envName = client.Profile(), c.env
+			*region, *endpoint = c.region, c.endpoint
+			*roleARN, *mfaSerial = c.roleARN, c.mfaSerial
+			switchTo(client)
+		})
+	}()
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"ddb-explorer/aws"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableActionPage is the query page of a table: the form of the selected
+// tab and what it keeps while the form is rebuilt
+type tableActionPage struct {
+	app       *tview.Application
+	pages     *tview.Pages
+	client    *aws.Client
+	tableInfo aws.TableInfo
+	resume    *sessionState // The session the page resumes, if any
+
+	form      *tview.Form
+	formError *tview.TextView // Why the values entered can't be sent
+
+	// Selected query target: 0 is the base table, i > 0 is tableInfo.Indexes[i-1]
+	selectedIndex int
+
+	// Sort order, fetch-all and shard settings and filter of the Query tab
+	descending    bool
+	fetchAll      bool
+	fanOutShards  bool
+	fetchMaxItems string
+	fetchMaxMB    string
+	queryFilter   attributeFilter
+
+	// Raw expression mode replaces the fields of the Query and Scan tabs
+	// with expressions, shared by both tabs
+	rawMode   bool
+	rawParams aws.RawParams
+
+	// Saved query whose values fill the Query tab, nil for a new query. The
+	// read of a resumed session fills it too, without a name.
+	loadedQuery *savedQuery
+
+	// Filter the Scan tab opens with, set by a resumed scan
+	scanFilter attributeFilter
+
+	// The read of a resumed session, run once its tab is filled in, and the
+	// cursor the next read starts after
+	resuming    bool
+	resumeRead  func()
+	resumeStart *aws.PageToken
+
+	// Partition key value kept when the form switches to an index matching
+	// it, and the Query button of the current form to run it there
+	carriedPartitionValue string
+	submitQuery           func()
+
+	// Tabs of the page, Union only when there are indexes to combine
+	tabNames   []string
+	tabViews   []*tview.TextView
+	currentTab int // Index into tabNames
+}
+
+// createTableActionPage builds and shows the query page of a table. resume,
+// when set, is a session that ended on the table: its last read is filled in
+// and run again from the page it had reached.
+func createTableActionPage(pages *tview.Pages, app *tview.Application, tableInfo aws.TableInfo, client *aws.Client, resume *sessionState) {
+	trackTable(tableInfo.Name)
+
+	// Create flex layout
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	// Header
+	shortcuts := keyLabel("query.queryTab") + ": Query | " + keyLabel("query.scanTab") + ": Scan"
+	if len(tableInfo.GlobalIndexes()) > 0 {
+		shortcuts += " | " + keyLabel("query.unionTab") + ": Union | " + keyLabel("query.indexReport") + ": Index Report"
+	}
+	if tableInfo.ItemCount < editorMaxItems && !*readOnly {
+		shortcuts += " | " + keyLabel("query.edit") + ": Edit"
+	}
+	if tableInfo.StreamARN != "" {
+		shortcuts += " | " + keyLabel("query.tailStream") + ": Stream"
+	}
+	shortcuts += " | " + keyLabel("query.raw") + ": Raw"
+	created := ""
+	if !tableInfo.CreatedAt.IsZero() {
+		created = ", created " + formatDate(tableInfo.CreatedAt)
+	}
+	header := tview.NewTextView().
+		SetText(fmt.Sprintf("Table: %s%s (%s)", tableInfo.Name, created, shortcuts)).
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	flex.AddItem(header, 1, 0, false)
+
+	// Form for inputs
+	form := tview.NewForm()
+	form.SetCancelFunc(func() {
+		pages.SwitchToPage("tablelist")
+	})
+
+	// Apply form styling
+	form.SetLabelColor(textSecondary).
+		SetFieldBackgroundColor(accentOrange).
+		SetFieldTextColor(tcell.NewHexColor(0x121212)).
+		SetButtonBackgroundColor(accentOrange).
+		SetButtonTextColor(tcell.NewHexColor(0x121212))
+
+	// Tabs flex
+	tabsFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
+
+	// Query tab
+	queryTab := tview.NewTextView().
+		SetText("[ Query ]").
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetTextColor(tcell.NewHexColor(0x121212))
+	queryTab.SetBackgroundColor(accentOrange)
+	tabsFlex.AddItem(queryTab, 0, 1, true)
+
+	// Scan tab
+	scanTab := tview.NewTextView().
+		SetText("  Scan  ").
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetTextColor(textSecondary)
+	scanTab.SetBackgroundColor(bgSecondary)
+	tabsFlex.AddItem(scanTab, 0, 1, false)
+
+	tabNames := []string{"Query", "Scan"}
+	tabViews := []*tview.TextView{queryTab, scanTab}
+
+	// Union tab, only useful when there are indexes to combine. LSIs share
+	// the table's partition key, so they add nothing to a union.
+	if len(tableInfo.GlobalIndexes()) > 0 {
+		unionTab := tview.NewTextView().
+			SetText("  Union  ").
+			SetTextAlign(tview.AlignCenter).
+			SetDynamicColors(true).
+			SetTextColor(textSecondary)
+		unionTab.SetBackgroundColor(bgSecondary)
+		tabsFlex.AddItem(unionTab, 0, 1, false)
+		tabNames = append(tabNames, "Union")
+		tabViews = append(tabViews, unionTab)
+	}
+
+	flex.AddItem(tabsFlex, 1, 0, false)
+	flex.AddItem(form, 0, 1, true)
+
+	// Why the values entered in the form can't be sent, under it
+	formError := tview.NewTextView().SetTextColor(accentRed)
+	formError.SetBorderPadding(0, 0, 1, 1)
+	flex.AddItem(formError, 1, 0, false)
+
+	p := &tableActionPage{
+		app:           app,
+		pages:         pages,
+		client:        client,
+		tableInfo:     tableInfo,
+		resume:        resume,
+		form:          form,
+		formError:     formError,
+		fanOutShards:  true,
+		fetchMaxItems: strconv.Itoa(defaultFetchMaxItems),
+		fetchMaxMB:    strconv.Itoa(defaultFetchMaxMB),
+		rawParams:     aws.RawParams{TableName: tableInfo.Name},
+		resuming:      resume != nil && resume.Read != nil,
+		tabNames:      tabNames,
+		tabViews:      tabViews,
+	}
+
+	// A resumed session fills in the tab of its last read
+	if p.resuming {
+		q := *resume.Read
+		p.resumeStart, _ = aws.ParseCursor(resume.Cursor) // Written by PageToken.JSON
+		filter := attributeFilter{attribute: q.FilterAttribute, condition: q.FilterCondition, value: q.FilterValue}
+		if resume.Scan {
+			p.scanFilter = filter
+		} else if i := slices.IndexFunc(tableInfo.Indexes, func(idx aws.IndexInfo) bool { return idx.Name == q.Index }); i >= 0 || q.Index == "" {
+			p.loadedQuery = &q
+			p.selectedIndex = i + 1
+			p.descending = q.Descending
+			p.queryFilter = filter
+		} else {
+			p.resuming = false
+			p.resumeStart = nil
+			defer showMessageModal(pages, "resumeerror", fmt.Sprintf("The index %s of the last query no longer exists.", q.Index))
+		}
+	}
+
+	// Initial form
+	p.updateForm(0)
+	for i, tv := range p.tabViews {
+		clickable(tv, func() { p.selectTab(i) })
+	}
+	flex.SetInputCapture(p.handleKey)
+
+	// Add page
+	pages.AddPage("tableaction", flex, true, false)
+	status.set("tableaction", pageStatus{table: tableInfo.Name, items: formatNumber(tableInfo.ItemCount) + " items"})
+
+	pages.SwitchToPage("tableaction")
+
+	// The resumed read opens its results over the page
+	if p.resuming {
+		if resume.Scan {
+			p.selectTab(1)
+		}
+		p.resumeRead()
+	}
+}
+
+// renderTabs highlights the current tab and marks the tabs in raw mode
+func (p *tableActionPage) renderTabs() {
+	for i, tv := range p.tabViews {
+		name := p.tabNames[i]
+		if p.rawMode && i < 2 {
+			name += " (raw)"
+		}
+		if i == p.currentTab {
+			tv.SetText(fmt.Sprintf("[ %s ]", name))
+			tv.SetTextColor(tcell.NewHexColor(0x121212))
+			tv.SetBackgroundColor(accentOrange)
+		} else {
+			tv.SetText(fmt.Sprintf("  %s  ", name))
+			tv.SetTextColor(textSecondary)
+			tv.SetBackgroundColor(bgSecondary)
+		}
+	}
+}
+
+// selectTab switches the form to a tab
+func (p *tableActionPage) selectTab(tab int) {
+	if tab == p.currentTab || tab < 0 || tab >= len(p.tabViews) {
+		return
+	}
+	p.currentTab = tab
+	p.updateForm(p.currentTab)
+	p.renderTabs()
+}
+
+// handleKey switches tabs and opens the table's other views
+func (p *tableActionPage) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyESC {
+		p.pages.SwitchToPage("tablelist")
+	} else if keyPressed(event, "query.queryTab") {
+		// Switch to Query tab
+		p.selectTab(0)
+		return nil
+	} else if keyPressed(event, "query.scanTab") {
+		// Switch to Scan tab
+		p.selectTab(1)
+		return nil
+	} else if keyPressed(event, "query.unionTab") {
+		// Switch to Union tab
+		p.selectTab(2)
+		return nil
+	} else if keyPressed(event, "query.raw") {
+		// Expressions instead of the guided fields, or back
+		p.rawMode = !p.rawMode
+		if p.currentTab < 2 {
+			p.updateForm(p.currentTab)
+		}
+		p.renderTabs()
+		return nil
+	} else if keyPressed(event, "query.edit") {
+		// Edit small reference/config tables in full
+		if *readOnly {
+			return nil // Swallowed rather than opening the focused drop-down
+		}
+		if p.tableInfo.ItemCount >= editorMaxItems {
+			showMessageModal(p.pages, "editorerror", fmt.Sprintf("Edit mode is limited to tables with fewer than %d items.", editorMaxItems))
+			return nil
+		}
+		showTableEditor(p.app, p.pages, p.client, p.tableInfo)
+		return nil
+	} else if keyPressed(event, "query.indexReport") {
+		// GSI utilization report
+		showIndexReport(p.app, p.pages, p.client, p.tableInfo)
+		return nil
+	} else if keyPressed(event, "query.tailStream") {
+		// Live changes from the table's stream
+		if p.tableInfo.StreamARN == "" {
+			showMessageModal(p.pages, "streamerror", fmt.Sprintf("%s has no stream enabled.", p.tableInfo.Name))
+			return nil
+		}
+		showStreamTail(p.app, p.pages, p.client, p.tableInfo)
+		return nil
+	} else if event.Key() == tcell.KeyRight {
+		p.selectTab((p.currentTab + 1) % len(p.tabViews))
+	} else if event.Key() == tcell.KeyLeft {
+		p.selectTab((p.currentTab + len(p.tabViews) - 1) % len(p.tabViews))
+	}
+	return event
+}
+
+// updateForm rebuilds the form for a tab
+func (p *tableActionPage) updateForm(tab int) {
+	p.form.Clear(true)
+	p.formError.SetText("")
+	if p.rawMode && tab < 2 {
+		p.addRawForm(tab == 0)
+		return
+	}
+	switch tab {
+	case 0:
+		p.addQueryForm()
+	case 1:
+		p.addScanForm()
+	default:
+		p.addUnionForm()
+	}
+}
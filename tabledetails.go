@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showTableDetails describes a table with its TTL setting and tags and shows
+// the result on a read-only page
+func showTableDetails(app *tview.Application, pages *tview.Pages, client *aws.Client, name string) {
+	ctx := showLoadingModal(pages, "detailsloading", fmt.Sprintf("Describing %s...", name))
+
+	var details aws.TableDetails
+	runWithReauth(ctx, app, pages, client, func(ctx context.Context) error {
+		var err error
+		details, err = client.DescribeTableDetails(ctx, name)
+		return err
+	}, func(err error) {
+		pages.RemovePage("detailsloading")
+		if err != nil {
+			showMessageModal(pages, "detailserror", fmt.Sprintf("Describe error: %v", err))
+			return
+		}
+		createTableDetailsPage(app, pages, details)
+	})
+}
+
+// manyIndexes is the number of indexes above which the details page lists
+// them one per line, leaving their full description to the index list
+const manyIndexes = 5
+
+// detailsWriter lays out a details page in sections of labeled lines
+type detailsWriter struct {
+	strings.Builder
+	d aws.TableDetails
+}
+
+func (w *detailsWriter) section(title string) {
+	fmt.Fprintf(w, "\n[#ff9500::b]%s[white::-]\n", title)
+}
+
+func (w *detailsWriter) line(label, value string) {
+	fmt.Fprintf(w, "  %-22s %s\n", label, tview.Escape(value))
+}
+
+// keyAttr names a key attribute with its type
+func (w *detailsWriter) keyAttr(attr string) string {
+	if t, ok := w.d.AttributeTypes[attr]; ok {
+		return fmt.Sprintf("%s (%s)", attr, t)
+	}
+	return attr
+}
+
+func throughputText(read, write int64) string {
+	return fmt.Sprintf("%s RCU, %s WCU", formatNumber(read), formatNumber(write))
+}
+
+func projectionText(idx aws.IndexInfo) string {
+	projection := idx.ProjectionType
+	if len(idx.NonKeyAttributes) > 0 {
+		projection += ": " + strings.Join(idx.NonKeyAttributes, ", ")
+	}
+	return projection
+}
+
+// index describes one index of the table
+func (w *detailsWriter) index(idx aws.IndexInfo, global bool) {
+	w.line("Partition key", w.keyAttr(idx.PartitionKey))
+	if idx.SortKey != "" {
+		w.line("Sort key", w.keyAttr(idx.SortKey))
+	}
+	w.line("Projection", projectionText(idx))
+	if global && w.d.BillingMode == "PROVISIONED" {
+		w.line("Provisioned", throughputText(idx.ReadCapacity, idx.WriteCapacity))
+	}
+	w.line("Items", fmt.Sprintf("~%s (%s)", formatNumber(idx.ItemCount), formatBytes(idx.SizeBytes)))
+}
+
+// tableDetailsText lays out the table configuration in sections
+func tableDetailsText(d aws.TableDetails) string {
+	b := &detailsWriter{d: d}
+	section, line, keyAttr := b.section, b.line, b.keyAttr
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+	many := len(d.Indexes) > manyIndexes
+	indexes := func(list []aws.IndexInfo, global bool) {
+		if len(list) == 0 {
+			b.WriteString("  none\n")
+			return
+		}
+		for i, idx := range list {
+			if many {
+				keys := idx.PartitionKey
+				if idx.SortKey != "" {
+					keys += " / " + idx.SortKey
+				}
+				fmt.Fprintf(b, "  %-28s %-10s %s\n", tview.Escape(idx.Name), idx.Status, tview.Escape(keys))
+				continue
+			}
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "  [::b]%s[::-] %s\n", tview.Escape(idx.Name), idx.Status)
+			b.index(idx, global)
+		}
+	}
+
+	section("Table")
+	line("Name", d.Name)
+	line("ARN", d.ARN)
+	line("Status", d.Status)
+	if !d.CreatedAt.IsZero() {
+		line("Created", formatDate(d.CreatedAt))
+	}
+	line("Items", fmt.Sprintf("~%s (%s)", formatNumber(d.ItemCount), formatBytes(d.SizeBytes)))
+	line("Table class", d.TableClass)
+	line("Deletion protection", onOff(d.DeletionProtection))
+	if d.Encryption != "" {
+		line("Encryption", d.Encryption)
+	} else {
+		line("Encryption", "AWS owned key")
+	}
+	if len(d.Replicas) > 0 {
+		line("Replicas", strings.Join(d.Replicas, ", "))
+	}
+
+	section("Key Schema")
+	line("Partition key", keyAttr(d.PartitionKey))
+	if d.SortKey != "" {
+		line("Sort key", keyAttr(d.SortKey))
+	}
+
+	section("Capacity")
+	line("Billing mode", d.BillingMode)
+	if d.BillingMode == "PROVISIONED" {
+		line("Provisioned", throughputText(d.ReadCapacity, d.WriteCapacity))
+	}
+	if d.MaxReadUnits > 0 || d.MaxWriteUnits > 0 {
+		line("On-demand maximum", fmt.Sprintf("%s read, %s write request units", formatNumber(d.MaxReadUnits), formatNumber(d.MaxWriteUnits)))
+	}
+
+	if many {
+		b.WriteString("\n  [#b8b8b8]Press g to search the indexes and open each one[white]\n")
+	}
+	global, local := d.GlobalIndexes(), d.LocalIndexes()
+	section(fmt.Sprintf("Global Secondary Indexes (%d)", len(global)))
+	indexes(global, true)
+	section(fmt.Sprintf("Local Secondary Indexes (%d)", len(local)))
+	indexes(local, false)
+
+	section("Stream")
+	if d.StreamView == "" {
+		line("Enabled", "off")
+	} else {
+		line("Enabled", "on")
+		line("View type", d.StreamView)
+		line("ARN", d.StreamARN)
+		line("Label", d.StreamLabel)
+	}
+
+	section("Time to Live")
+	if d.TTLAttribute == "" {
+		line("Status", "off")
+	} else {
+		line("Status", d.TTLStatus)
+		line("Attribute", d.TTLAttribute)
+	}
+
+	section(fmt.Sprintf("Tags (%d)", len(d.Tags)))
+	if len(d.Tags) == 0 {
+		b.WriteString("  none\n")
+	}
+	keys := make([]string, 0, len(d.Tags))
+	for k := range d.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		line(k, d.Tags[k])
+	}
+	return b.String()
+}
+
+// createTableDetailsPage shows the configuration of a table
+func createTableDetailsPage(app *tview.Application, pages *tview.Pages, details aws.TableDetails) {
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Table details: %s (↑/↓: scroll | %s: indexes | ESC: close | %s: keys)", tview.Escape(details.Name), keyLabel("details.indexes"), keyLabel("app.pageKeys")))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(tableDetailsText(details))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("tabledetails")
+			return nil
+		}
+		if keyPressed(event, "details.indexes") {
+			createIndexListPage(app, pages, details)
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("tabledetails")
+	pages.AddPage("tabledetails", flex, true, true)
+	app.SetFocus(text)
+}
+
+// detailIndex is a secondary index of a table, global or local
+type detailIndex struct {
+	aws.IndexInfo
+	global bool
+}
+
+func (i detailIndex) kind() string {
+	if i.global {
+		return "GSI"
+	}
+	return "LSI"
+}
+
+// createIndexListPage lists every secondary index of a table, one per row,
+// with a filter on the index name for tables with many indexes
+func createIndexListPage(app *tview.Application, pages *tview.Pages, details aws.TableDetails) {
+	var all []detailIndex
+	for _, idx := range details.GlobalIndexes() {
+		all = append(all, detailIndex{idx, true})
+	}
+	for _, idx := range details.LocalIndexes() {
+		all = append(all, detailIndex{idx, false})
+	}
+	shown := all
+
+	list := newDataTable().
+		SetFixed(1, 0)
+	filterInput := tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder("press / or start typing").
+		SetPlaceholderTextColor(tcell.NewHexColor(0x404040)).
+		SetFieldWidth(0).
+		SetLabelColor(textSecondary).
+		SetFieldBackgroundColor(accentOrange).
+		SetFieldTextColor(tcell.NewHexColor(0x121212))
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+
+	render := func() {
+		header.SetText(fmt.Sprintf("Indexes of %s: %d of %d (Enter: open | /: filter | ESC: close)",
+			tview.Escape(details.Name), len(shown), len(all)))
+		list.Clear()
+		for col, name := range []string{"Index", "Type", "Partition Key", "Sort Key", "Projection", "Status", "Items"} {
+			list.SetCell(0, col, tview.NewTableCell(name).
+				SetTextColor(tview.Styles.SecondaryTextColor).
+				SetSelectable(false).
+				SetAlign(tview.AlignCenter))
+		}
+		for i, idx := range shown {
+			row := i + 1
+			color := tview.Styles.PrimaryTextColor
+			if idx.Status != "ACTIVE" {
+				color = accentYellow
+			}
+			cells := []string{
+				rowPrefix("Index", row, len(shown)) + tview.Escape(idx.Name),
+				labeled("type", idx.kind()),
+				labeled("partition key", tview.Escape(idx.PartitionKey)),
+				labeled("sort key", tview.Escape(idx.SortKey)),
+				labeled("projection", idx.ProjectionType),
+				labeled("status", idx.Status),
+				labeled("items", "~"+formatNumber(idx.ItemCount)),
+			}
+			for col, text := range cells {
+				list.SetCell(row, col, tview.NewTableCell(text).SetTextColor(color))
+			}
+		}
+		if len(shown) == 0 {
+			list.SetCell(1, 0, tview.NewTableCell("No indexes match.").
+				SetTextColor(tview.Styles.PrimaryTextColor))
+		}
+	}
+	applyFilter := func(query string) {
+		shown = nil
+		for _, idx := range all {
+			if _, ok := fuzzyScore(query, idx.Name); ok {
+				shown = append(shown, idx)
+			}
+		}
+		render()
+		list.Select(1, 0)
+	}
+	render()
+
+	filterInput.SetChangedFunc(applyFilter)
+	filterInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			filterInput.SetText("")
+			app.SetFocus(list)
+			return nil
+		case tcell.KeyDown, tcell.KeyEnter:
+			app.SetFocus(list)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(filterInput, 1, 0, false)
+	flex.AddItem(list, 0, 1, true)
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("indexlist")
+			return nil
+		case tcell.KeyEnter:
+			if row, _ := list.GetSelection(); row > 0 && row <= len(shown) {
+				createIndexDetailPage(app, pages, details, shown[row-1])
+			}
+			return nil
+		}
+		if keyPressed(event, "details.filter") {
+			app.SetFocus(filterInput)
+			return nil
+		}
+		return event
+	})
+
+	pages.RemovePage("indexlist")
+	pages.AddPage("indexlist", flex, true, true)
+	app.SetFocus(list)
+}
+
+// createIndexDetailPage describes one secondary index of a table
+func createIndexDetailPage(app *tview.Application, pages *tview.Pages, details aws.TableDetails, idx detailIndex) {
+	w := &detailsWriter{d: details}
+	w.section("Index")
+	w.line("Name", idx.Name)
+	w.line("Type", idx.kind())
+	w.line("Status", idx.Status)
+	w.section("Key Schema")
+	w.line("Partition key", w.keyAttr(idx.PartitionKey))
+	if idx.SortKey != "" {
+		w.line("Sort key", w.keyAttr(idx.SortKey))
+	}
+	w.section("Projection")
+	w.line("Type", idx.ProjectionType)
+	for _, attr := range idx.NonKeyAttributes {
+		w.line("", attr)
+	}
+	w.section("Capacity")
+	switch {
+	case !idx.global:
+		w.line("Shared", "uses the table's capacity")
+	case details.BillingMode == "PROVISIONED":
+		w.line("Provisioned", throughputText(idx.ReadCapacity, idx.WriteCapacity))
+	default:
+		w.line("Billing mode", details.BillingMode)
+	}
+	w.section("Size")
+	w.line("Items", "~"+formatNumber(idx.ItemCount))
+	w.line("Size", formatBytes(idx.SizeBytes))
+
+	header := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetText(fmt.Sprintf("%s %s of %s (ESC: back)", idx.kind(), idx.Name, details.Name))
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(w.String())
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(header, 1, 0, false)
+	flex.AddItem(text, 0, 1, true)
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyESC:
+			pages.RemovePage("indexdetail")
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("indexdetail", flex, true, true)
+	app.SetFocus(text)
+}
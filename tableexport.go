@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"ddb-explorer/aws"
+)
+
+// runExport implements the export subcommand. It scans a whole table,
+// optionally in parallel segments, and writes one JSON item per line.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	profile := fs.String("profile", "dev", "AWS profile to use")
+	roleARN := fs.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+	mfaSerial := fs.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+	tableName := fs.String("table", "", "Table to export (required)")
+	index := fs.String("index", "", "Secondary index to scan instead of the table")
+	output := fs.String("out", "", "Write the items to this file instead of stdout")
+	filter := fs.String("filter", "", `Only export matching items, e.g. "status = active", "status IN (open, held)", "size(tags) > 3" or "begins_with(sk, ORDER#)"`)
+	segments := fs.Int("segments", 1, "Parallel scan segments, for large tables")
+	typed := fs.Bool("typed", false, `Write typed DynamoDB JSON, e.g. {"N": "42"}, which keeps sets and binary values exact`)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ddb-explorer export --table TABLE [--out FILE] [OPTIONS]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 || *tableName == "" {
+		fs.Usage()
+		return 1
+	}
+	if *segments < 1 || *segments > aws.MaxExportSegments {
+		fmt.Fprintf(os.Stderr, "Invalid segments: %d. Must be between 1 and %d\n", *segments, aws.MaxExportSegments)
+		return 1
+	}
+	params := aws.ExportParams{
+		Scan:     aws.ScanParams{TableName: *tableName, IndexName: *index},
+		Segments: *segments,
+		Typed:    *typed,
+	}
+	if *filter != "" {
+		var err error
+		params.Scan.FilterAttribute, params.Scan.FilterCondition, params.Scan.FilterValue, err = parseFilterExpression(*filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid filter: %v\n", err)
+			return 1
+		}
+	}
+
+	client, err := newHeadlessClient(*profile, *roleARN, *mfaSerial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create AWS client: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	p, err := client.ExportTable(ctx, params, func(item map[string]interface{}) error {
+		return enc.Encode(item)
+	}, func(p aws.ExportProgress) {
+		fmt.Fprintf(os.Stderr, "\r%s items exported, %s scanned", formatNumber(p.Exported), formatNumber(p.Scanned))
+	})
+	fmt.Fprintln(os.Stderr)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed after %s items: %v\n", formatNumber(p.Exported), err)
+		if *output != "" {
+			fmt.Fprintf(os.Stderr, "%s is incomplete\n", *output)
+		}
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s items (%s RCU)", formatNumber(p.Exported), formatCapacity(p.ReadUnits))
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, " to %s", *output)
+	}
+	fmt.Fprintln(os.Stderr)
+	return 0
+}
+
+// parseFilterExpression parses a scan filter of the export subcommand into
+// the attribute, condition and value of aws.ScanParams. It accepts
+// "attr OP value" with a comparison of aws.FilterConditions, "attr IN (a, b)",
+// "size(attr) OP n", and the function forms "begins_with(attr, value)",
+// "contains(attr, value)", "not_contains(attr, value)" (or
+// "NOT contains(attr, value)"), "attribute_type(attr, type)",
+// "attribute_exists(attr)" and "attribute_not_exists(attr)". Values are
+// compared as strings and may be quoted.
+func parseFilterExpression(expr string) (attr, condition, value string, err error) {
+	attr, condition, value, err = splitFilterExpression(expr)
+	if err == nil {
+		err = aws.CheckFilter(condition, value)
+	}
+	return attr, condition, value, err
+}
+
+// splitFilterExpression does the parsing of parseFilterExpression, leaving
+// the values to aws.CheckFilter
+func splitFilterExpression(expr string) (attr, condition, value string, err error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := cutPrefixFold(expr, "NOT "); ok {
+		rest = strings.TrimSpace(rest)
+		if rest, ok = cutPrefixFold(rest, "contains("); !ok {
+			return "", "", "", fmt.Errorf("only contains can be negated with NOT in %q", expr)
+		}
+		expr = "not_contains(" + rest
+	}
+	if name, rest, ok := strings.Cut(expr, "("); ok && strings.TrimSpace(name) == "size" {
+		sized, comparison, ok := strings.Cut(rest, ")")
+		if !ok {
+			return "", "", "", fmt.Errorf("missing ) in %q", expr)
+		}
+		attr, condition, value, err = splitComparison(strings.TrimSpace(sized) + " " + comparison)
+		return attr, "size " + condition, value, err
+	}
+	if name, rest, ok := strings.Cut(expr, "("); ok && slices.Contains(aws.FilterConditions, strings.TrimSpace(name)) {
+		condition = strings.TrimSpace(name)
+		args, ok := strings.CutSuffix(strings.TrimSpace(rest), ")")
+		if !ok {
+			return "", "", "", fmt.Errorf("missing ) in %q", expr)
+		}
+		attr, value, hasValue := strings.Cut(args, ",")
+		attr = strings.TrimSpace(attr)
+		wantsValue := aws.FilterTakesValue(condition)
+		switch {
+		case attr == "":
+			return "", "", "", fmt.Errorf("%s needs an attribute", condition)
+		case wantsValue && !hasValue:
+			return "", "", "", fmt.Errorf("%s needs an attribute and a value", condition)
+		case !wantsValue && hasValue:
+			return "", "", "", fmt.Errorf("%s takes only an attribute", condition)
+		}
+		return attr, condition, unquote(strings.TrimSpace(value)), nil
+	}
+	if i := strings.Index(strings.ToUpper(expr), " IN "); i > 0 {
+		list := strings.TrimSpace(expr[i+len(" IN "):])
+		list, ok := strings.CutPrefix(list, "(")
+		if ok {
+			list, ok = strings.CutSuffix(list, ")")
+		}
+		if !ok {
+			return "", "", "", fmt.Errorf("IN takes a list in parentheses, e.g. status IN (open, held), not %q", expr)
+		}
+		values := strings.Split(list, ",")
+		for j, v := range values {
+			values[j] = unquote(strings.TrimSpace(v))
+		}
+		return strings.TrimSpace(expr[:i]), "IN", strings.Join(values, ","), nil
+	}
+	return splitComparison(expr)
+}
+
+// splitComparison parses "attr OP value". The earliest comparison operator
+// splits the expression, preferring the two-character operators that start
+// with the same character.
+func splitComparison(expr string) (attr, condition, value string, err error) {
+	start := strings.IndexAny(expr, "=<>")
+	if start < 0 {
+		return "", "", "", fmt.Errorf("no comparison in %q; use e.g. attr = value or begins_with(attr, value)", expr)
+	}
+	condition = expr[start : start+1]
+	for _, op := range []string{"<>", "<=", ">="} {
+		if strings.HasPrefix(expr[start:], op) {
+			condition = op
+		}
+	}
+	attr = strings.TrimSpace(expr[:start])
+	if attr == "" {
+		return "", "", "", fmt.Errorf("no attribute before %s in %q", condition, expr)
+	}
+	return attr, condition, unquote(strings.TrimSpace(expr[start+len(condition):])), nil
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// unquote strips matching single or double quotes around a filter value
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"ddb-explorer/aws"
+)
+
+// runImport implements the import subcommand. It puts the items of a JSON
+// lines file, such as one written by the export subcommand, into a table.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	profile := fs.String("profile", "dev", "AWS profile to use")
+	roleARN := fs.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+	mfaSerial := fs.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+	tableName := fs.String("table", "", "Table to import into (required)")
+	input := fs.String("in", "", "Read the items from this file instead of stdin")
+	typed := fs.Bool("typed", false, `Read typed DynamoDB JSON, e.g. {"N": "42"}, as written by export --typed`)
+	dryRun := fs.Bool("dry-run", false, "Check every item and report what would be written, without writing")
+	maxWCU := fs.Float64("max-wcu", 0, "Write capacity to use per second; 0 doesn't limit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ddb-explorer import --table TABLE [--in FILE] [--dry-run] [OPTIONS]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 || *tableName == "" {
+		fs.Usage()
+		return 1
+	}
+	if *maxWCU < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid max-wcu: %g. Must be 0 or more\n", *maxWCU)
+		return 1
+	}
+
+	in := io.Reader(os.Stdin)
+	source := "stdin"
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", *input, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+		source = *input
+	}
+
+	client, err := newHeadlessClient(*profile, *roleARN, *mfaSerial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create AWS client: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	params := aws.ImportParams{
+		TableName:     *tableName,
+		Typed:         *typed,
+		DryRun:        *dryRun,
+		MaxWriteUnits: *maxWCU,
+	}
+	start := time.Now()
+	p, err := client.ImportItems(ctx, params, in, func(p aws.ImportProgress) {
+		if *dryRun {
+			fmt.Fprintf(os.Stderr, "\r%s items checked", formatNumber(p.Items))
+		} else {
+			fmt.Fprintf(os.Stderr, "\r%s items written, %s read", formatNumber(p.Written), formatNumber(p.Items))
+		}
+	})
+	fmt.Fprintln(os.Stderr)
+	printImportSummary(p, *tableName, source, *dryRun, time.Since(start))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		if !*dryRun && p.Written > 0 {
+			fmt.Fprintf(os.Stderr, "The %s items written before the failure remain in %s\n", formatNumber(p.Written), *tableName)
+		}
+		return 1
+	}
+	return 0
+}
+
+// printImportSummary reports on stderr what an import wrote, or for a dry
+// run what it would write
+func printImportSummary(p aws.ImportProgress, tableName, source string, dryRun bool, elapsed time.Duration) {
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run, nothing written to %s\n", tableName)
+	} else {
+		fmt.Fprintf(os.Stderr, "Import into %s from %s\n", tableName, source)
+	}
+	line := func(label, value string) {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", label, value)
+	}
+	line("Items read", formatNumber(p.Items))
+	if dryRun {
+		line("Write capacity", "~"+formatCapacity(p.PlannedUnits)+" WCU")
+		return
+	}
+	line("Items written", formatNumber(p.Written))
+	line("Write capacity", formatCapacity(p.WriteUnits)+" WCU")
+	if p.UnprocessedRetries > 0 {
+		line("Throttled", formatNumber(int64(p.UnprocessedRetries))+" batches retried")
+	}
+	line("Elapsed", elapsed.Round(time.Second).String())
+}
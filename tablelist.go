@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableListPage is the list of tables a session opens on, filtered by name
+type tableListPage struct {
+	app         *tview.Application
+	pages       *tview.Pages
+	client      *aws.Client
+	environment string            // Tier of the session, prod asks more before deleting
+	switchTo    func(*aws.Client) // Ends the session to start one with another client
+
+	table          *tview.Table
+	filterInput    *tview.InputField
+	filteredTables []aws.TableInfo
+
+	// Background work of the table list, which stays for the whole session
+	tasks *taskGroup
+
+	// Auto-refresh of item counts and sizes, toggled with Ctrl+R
+	autoRefreshing  bool
+	stopAutoRefresh context.CancelFunc
+	loadedAt        time.Time // When the list was last loaded or refreshed
+	tableNote       string    // The note of the last showTableCount
+
+	// Activity heatmap columns, toggled with 'a' and loaded from CloudWatch on first use
+	showActivity      bool
+	activity          map[string]aws.TableActivity
+	activityPeakValue float64
+
+	// Lazy metadata loading: tables listed by name are described when their
+	// row becomes visible or selected, a few at a time
+	describing    map[string]bool
+	describeSlots chan struct{}
+}
+
+// newTableListPage adds the table list page, empty until load lists the
+// tables
+func newTableListPage(app *tview.Application, pages *tview.Pages, client *aws.Client, environment string, switchTo func(*aws.Client)) *tableListPage {
+	p := &tableListPage{
+		app:           app,
+		pages:         pages,
+		client:        client,
+		environment:   environment,
+		switchTo:      switchTo,
+		table:         newDataTable(),
+		describing:    make(map[string]bool),
+		describeSlots: make(chan struct{}, 4),
+	}
+
+	// Create filter input
+	p.filterInput = tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder("press / or start typing; letters match in order, e.g. usrevt finds user_events").
+		SetPlaceholderTextColor(tcell.NewHexColor(0x404040)).
+		SetFieldWidth(0).
+		SetLabelColor(textSecondary).
+		SetFieldBackgroundColor(accentOrange).
+		SetFieldTextColor(tcell.NewHexColor(0x121212))
+
+	// Wrap table in flex to add margins and center it
+	column := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 1, 0, false).           // Top margin
+		AddItem(p.filterInput, 1, 0, false). // Filter input
+		AddItem(p.table, 0, 1, true)         // Table
+	tableFlex := tview.NewFlex().
+		AddItem(nil, 0, 1, false). // Left margin
+		AddItem(column, 0, 3, true).
+		AddItem(nil, 0, 1, false) // Right margin
+	pages.AddPage("tablelist", tableFlex, true, false)
+	p.tasks = tasks.group("tablelist") // Once the page is added
+
+	p.table.SetSelectionChangedFunc(func(row, column int) {
+		p.loadVisibleMetadata()
+	})
+	p.filterInput.SetChangedFunc(func(text string) {
+		p.applyFilter(text)
+		p.loadVisibleMetadata()
+	})
+	p.filterInput.SetInputCapture(p.handleFilterKey)
+	p.table.SetInputCapture(p.handleKey)
+	return p
+}
+
+// showTableCount shows the number of tables in the status bar, with what
+// else there is to know about the list, e.g. that it is still loading
+func (p *tableListPage) showTableCount(note string) {
+	p.tableNote = note
+	if p.autoRefreshing {
+		if note != "" {
+			note += ", "
+		}
+		note += autoRefreshNote(p.loadedAt)
+	}
+	status.set("tablelist", pageStatus{items: formatNumber(int64(len(tables))) + " tables", note: note})
+}
+
+// setRow fills a row of the list, with its activity when the heatmap is shown
+func (p *tableListPage) setRow(row, total int, t aws.TableInfo) {
+	setTableRow(p.table, row, total, t)
+	if p.showActivity {
+		a, ok := p.activity[t.Name]
+		setActivityCells(p.table, row, 4, a, ok || p.activity != nil, p.activityPeakValue)
+	}
+}
+
+// populateTable lists tables under the headers
+func (p *tableListPage) populateTable(tablesToShow []aws.TableInfo) {
+	p.table.Clear()
+
+	// Set headers
+	headers := []string{"Table Name", "Status", "Item Count", "Size"}
+	if p.showActivity {
+		headers = append(headers, "Reads (24h)", "Writes (24h)")
+	}
+	for col, header := range headers {
+		p.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+
+	if len(tablesToShow) == 0 {
+		p.table.SetCell(1, 0, tview.NewTableCell("No tables found.").
+			SetTextColor(tview.Styles.PrimaryTextColor))
+	} else {
+		for i, t := range tablesToShow {
+			p.setRow(i+1, len(tablesToShow), t)
+		}
+		p.table.ScrollToBeginning()
+	}
+}
+
+// updateTableInfo replaces the metadata of a listed table
+func (p *tableListPage) updateTableInfo(info aws.TableInfo) {
+	for i := range tables {
+		if tables[i].Name == info.Name {
+			tables[i] = info
+		}
+	}
+	for i := range p.filteredTables {
+		if p.filteredTables[i].Name == info.Name {
+			p.filteredTables[i] = info
+			p.setRow(i+1, len(p.filteredTables), info)
+		}
+	}
+}
+
+// describeInBackground loads the metadata of a table listed by name
+func (p *tableListPage) describeInBackground(name string) {
+	p.describing[name] = true
+	tasks.Go(p.tasks, func(ctx context.Context) func() {
+		p.describeSlots <- struct{}{}
+		info, err := p.client.DescribeTable(ctx, name)
+		<-p.describeSlots
+		return func() {
+			if err != nil {
+				// Leave the table marked as in progress so it isn't retried on every scroll
+				info = aws.TableInfo{Name: name, Status: "ERROR"}
+			} else {
+				delete(p.describing, name)
+			}
+			p.updateTableInfo(info)
+		}
+	})
+}
+
+// loadVisibleMetadata describes the tables on screen and the selected one
+// when the list was loaded with --lazy
+func (p *tableListPage) loadVisibleMetadata() {
+	if !*lazyMetadata {
+		return
+	}
+	rowOffset, _ := p.table.GetOffset()
+	_, _, _, height := p.table.GetInnerRect()
+	selected, _ := p.table.GetSelection()
+	rows := []int{selected}
+	for row := rowOffset + 1; row <= rowOffset+height/linesPerRow()+1; row++ {
+		rows = append(rows, row)
+	}
+	for _, row := range rows {
+		if row < 1 || row > len(p.filteredTables) {
+			continue
+		}
+		t := p.filteredTables[row-1]
+		if !t.Described && !p.describing[t.Name] {
+			p.describeInBackground(t.Name)
+		}
+	}
+}
+
+// applyFilter filters tables by name, best fuzzy match first
+func (p *tableListPage) applyFilter(text string) {
+	if text == "" {
+		p.filteredTables = tables
+	} else {
+		p.filteredTables = fuzzyFilterTables(tables, text)
+	}
+	p.populateTable(p.filteredTables)
+}
+
+// handleFilterKey goes back to the list from the filter
+func (p *tableListPage) handleFilterKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyESC {
+		if p.filterInput.GetText() != "" {
+			p.filterInput.SetText("")
+		}
+		p.app.SetFocus(p.table)
+		return nil
+	} else if event.Key() == tcell.KeyDown || event.Key() == tcell.KeyEnter {
+		p.app.SetFocus(p.table)
+		return nil
+	}
+	return event
+}
+
+// toggleActivity shows or hides the read/write heatmap columns
+func (p *tableListPage) toggleActivity() {
+	p.showActivity = !p.showActivity
+	selected, _ := p.table.GetSelection()
+	p.populateTable(p.filteredTables)
+	p.table.Select(selected, 0)
+	if !p.showActivity || p.activity != nil {
+		return
+	}
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+	p.showTableCount("loading activity from CloudWatch...")
+	var loaded map[string]aws.TableActivity
+	runWithReauth(p.tasks.ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+		var err error
+		loaded, err = p.client.TableActivity(ctx, names)
+		return err
+	}, func(err error) {
+		if err != nil {
+			p.showActivity = false
+			p.showTableCount("")
+			showMessageModal(p.pages, "activityerror", fmt.Sprintf("Failed to load activity (needs cloudwatch:GetMetricData): %v", err))
+		} else {
+			p.activity = loaded
+			p.activityPeakValue = activityPeak(p.activity)
+			p.showTableCount("activity in hourly consumed capacity over the last 24h")
+		}
+		selected, _ := p.table.GetSelection()
+		p.populateTable(p.filteredTables)
+		p.table.Select(selected, 0)
+	})
+}
+
+// startAutoRefresh describes the tables in the filter again whenever the
+// list is older than the refresh interval, checking every second so the
+// age on the status bar keeps up. Tables listed lazily are left out until
+// their row loads them.
+func (p *tableListPage) startAutoRefresh() {
+	ctx, cancel := context.WithCancel(p.tasks.ctx)
+	p.autoRefreshing, p.stopAutoRefresh = true, cancel
+	p.showTableCount(p.tableNote)
+	refreshing := false
+	go func() {
+		defer recoverPanic()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			p.app.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if !refreshing && !p.loadedAt.IsZero() && time.Since(p.loadedAt) >= autoRefreshInterval() {
+					refreshing = true
+					var names []string
+					for _, t := range p.filteredTables {
+						if t.Described {
+							names = append(names, t.Name)
+						}
+					}
+					tasks.Go(p.tasks, func(context.Context) func() {
+						var infos []aws.TableInfo
+						for _, name := range names {
+							// A table that fails keeps its last values
+							if info, err := p.client.RefreshTable(ctx, name); err == nil {
+								infos = append(infos, info)
+							}
+						}
+						return func() {
+							refreshing = false
+							if ctx.Err() != nil {
+								return
+							}
+							p.loadedAt = time.Now()
+							for _, info := range infos {
+								p.updateTableInfo(info)
+							}
+							p.showTableCount(p.tableNote)
+						}
+					})
+				}
+				p.showTableCount(p.tableNote)
+			})
+		}
+	}()
+}
+
+// openTable shows the query page of a table, resuming an earlier session
+// on it when resume is set
+func (p *tableListPage) openTable(selectedTable aws.TableInfo, resume *sessionState) {
+	if selectedTable.Described {
+		createTableActionPage(p.pages, p.app, selectedTable, p.client, resume)
+		return
+	}
+
+	// Lazily listed table: its key schema is needed before querying
+	ctx := showLoadingModal(p.pages, "describing", fmt.Sprintf("Describing %s...", selectedTable.Name))
+	var info aws.TableInfo
+	runWithReauth(ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+		var err error
+		info, err = p.client.DescribeTable(ctx, selectedTable.Name)
+		return err
+	}, func(err error) {
+		p.pages.RemovePage("describing")
+		if err != nil {
+			showMessageModal(p.pages, "describeerror", fmt.Sprintf("Describe error: %v", err))
+			return
+		}
+		delete(p.describing, info.Name)
+		p.updateTableInfo(info)
+		createTableActionPage(p.pages, p.app, info, p.client, resume)
+	})
+}
+
+// offerLastSession offers to go back to where the last session with the
+// profile left off, once the tables are listed, unless a table was
+// opened in the meantime or the table is gone
+func (p *tableListPage) offerLastSession() {
+	saved, ok := userSettings.Sessions[p.client.Profile()]
+	if !ok || session != nil {
+		return
+	}
+	i := slices.IndexFunc(tables, func(t aws.TableInfo) bool { return t.Name == saved.Table })
+	if i < 0 {
+		return
+	}
+	offerResume(p.pages, p.client.Profile(), saved, func() {
+		p.openTable(tables[i], &saved)
+	})
+}
+
+// handleKey opens the selected table or one of its views, or starts
+// filtering when a letter is typed
+func (p *tableListPage) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyESC || keyPressed(event, "tables.quit") {
+		p.app.Stop()
+	} else if keyPressed(event, "tables.switchProfile") {
+		showConnectionSwitcher(p.app, p.pages, p.client, p.switchTo)
+		return nil
+	} else if keyPressed(event, "tables.heatmap") {
+		p.toggleActivity()
+		return nil
+	} else if keyPressed(event, "tables.autoRefresh") {
+		if p.autoRefreshing {
+			p.stopAutoRefresh()
+			p.autoRefreshing = false
+			p.showTableCount(p.tableNote)
+			status.notify("Auto-refresh off", noticeDuration)
+		} else {
+			p.startAutoRefresh()
+			status.notify(fmt.Sprintf("Refreshing item counts and sizes every %s", autoRefreshInterval()), noticeDuration)
+		}
+		return nil
+	} else if keyPressed(event, "tables.limits") {
+		showQuotaPanel(p.app, p.pages, p.client)
+		return nil
+	} else if keyPressed(event, "tables.families") {
+		showTableFamilies(p.app, p.pages, p.client)
+		return nil
+	} else if keyPressed(event, "tables.details") {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			showTableDetails(p.app, p.pages, p.client, p.filteredTables[row-1].Name)
+		}
+		return nil
+	} else if keyPressed(event, "tables.analyze") {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			showAnalyzeForm(p.app, p.pages, p.client, p.filteredTables[row-1])
+		}
+		return nil
+	} else if keyPressed(event, "tables.exportS3") && !*readOnly {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			showS3ExportForm(p.app, p.pages, p.client, p.filteredTables[row-1].Name)
+		}
+		return nil
+	} else if keyPressed(event, "tables.copy") && !*readOnly {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			showCopyTableForm(p.app, p.pages, p.client, p.filteredTables[row-1].Name)
+		}
+		return nil
+	} else if keyPressed(event, "tables.delete") && !*readOnly {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			name := p.filteredTables[row-1].Name
+			showDeleteTablePrompt(p.app, p.pages, p.client, name, p.environment == "prod", func() {
+				var remaining []aws.TableInfo
+				for _, t := range tables {
+					if t.Name != name {
+						remaining = append(remaining, t)
+					}
+				}
+				tables = remaining
+				p.applyFilter(p.filterInput.GetText())
+				p.showTableCount("")
+				status.notify(fmt.Sprintf("%s is being deleted", tview.Escape(name)), noticeDuration)
+			})
+		}
+		return nil
+	} else if keyPressed(event, "tables.dualWrite") {
+		row, _ := p.table.GetSelection()
+		if row > 0 && row <= len(p.filteredTables) {
+			name := p.filteredTables[row-1].Name
+			if _, ok := dualWritePairs[name]; !ok {
+				showMessageModal(p.pages, "dualwriteerror", noDualWriteText(name))
+				return nil
+			}
+			showDualWritePrompt(p.app, p.pages, p.client, name)
+		}
+		return nil
+	} else if keyPressed(event, "tables.filter") {
+		// Refine the current filter rather than starting over
+		p.app.SetFocus(p.filterInput)
+		return nil
+	} else if event.Key() == tcell.KeyEnter {
+		row, _ := p.table.GetSelection()
+		currentTables := p.filteredTables
+		if len(currentTables) == 0 {
+			currentTables = tables
+		}
+		if row > 0 && row <= len(currentTables) {
+			p.openTable(currentTables[row-1], nil)
+			return nil
+		}
+	} else if event.Rune() != 0 && event.Key() != tcell.KeyEnter {
+		// Start typing - switch to filter
+		p.filterInput.SetText(string(event.Rune()))
+		p.app.SetFocus(p.filterInput)
+		return nil
+	}
+	return event
+}
+
+// load lists the tables asynchronously, described as pages of them arrive
+// or only by name with --lazy, and switches from the loading
+// screen to the list
+func (p *tableListPage) load() {
+	if *lazyMetadata {
+		var names []string
+		runWithReauth(p.tasks.ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+			var err error
+			names, err = p.client.ListTableNames(ctx)
+			return err
+		}, func(err error) {
+			p.pages.SwitchToPage("tablelist")
+			if err != nil {
+				p.showLoadError(err)
+				return
+			}
+			tables = make([]aws.TableInfo, len(names))
+			for i, name := range names {
+				tables[i] = aws.TableInfo{Name: name}
+			}
+			p.loadedAt = time.Now()
+			p.applyFilter(p.filterInput.GetText())
+			p.showTableCount("metadata loads as you scroll")
+			p.loadVisibleMetadata()
+			p.offerLastSession()
+		})
+		return
+	}
+
+	var tableInfos []aws.TableInfo
+	runWithReauth(p.tasks.ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+		var err error
+		tableInfos, err = p.client.ListTables(ctx, func(loaded []aws.TableInfo) {
+			// Show tables as pages arrive instead of waiting for the full list
+			p.app.QueueUpdateDraw(func() {
+				p.pages.SwitchToPage("tablelist")
+				tables = loaded
+				p.applyFilter(p.filterInput.GetText())
+				p.showTableCount("loading...")
+			})
+		})
+		return err
+	}, func(err error) {
+		// Switch from loading screen to table list
+		p.pages.SwitchToPage("tablelist")
+
+		if err != nil {
+			p.showLoadError(err)
+			status.set("tablelist", pageStatus{})
+		} else {
+			tables = tableInfos
+			p.loadedAt = time.Now()
+			p.applyFilter(p.filterInput.GetText())
+			p.showTableCount("")
+			p.offerLastSession()
+		}
+	})
+}
+
+// showLoadError shows why the tables couldn't be listed in place of the list
+func (p *tableListPage) showLoadError(err error) {
+	p.table.Clear()
+	p.table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("Error: %v", err)).
+		SetTextColor(tview.Styles.PrimaryTextColor))
+}
+
+// handleMissingTable drops a table deleted or renamed since it was listed
+// from the list, and offers similarly named tables instead
+func (p *tableListPage) handleMissingTable(name string) {
+	tasks.Go(p.tasks, func(ctx context.Context) func() {
+		names, err := p.client.ListTableNames(ctx)
+		return func() {
+			if err != nil {
+				return
+			}
+			current := make(map[string]bool, len(names))
+			for _, n := range names {
+				current[n] = true
+			}
+			if current[name] {
+				// The table is still there, so one of its indexes was removed
+				p.describeInBackground(name)
+				showMessageModal(p.pages, "tablemissing", fmt.Sprintf("An index of %s no longer exists. Go back to the table list and open %s again to load its current indexes.", name, name))
+				return
+			}
+
+			known := make(map[string]bool, len(tables))
+			var refreshed []aws.TableInfo
+			for _, t := range tables {
+				known[t.Name] = true
+				if current[t.Name] {
+					refreshed = append(refreshed, t)
+				}
+			}
+			for _, n := range names {
+				if !known[n] {
+					refreshed = append(refreshed, aws.TableInfo{Name: n})
+					p.describeInBackground(n)
+				}
+			}
+			tables = refreshed
+			p.applyFilter(p.filterInput.GetText())
+			p.showTableCount(fmt.Sprintf("refreshed, %s no longer exists", tview.Escape(name)))
+			p.offerSimilarTables(name, names)
+		}
+	})
+}
+
+// offerSimilarTables says a table no longer exists, with buttons going to
+// the listed tables named like it
+func (p *tableListPage) offerSimilarTables(name string, names []string) {
+	similar := closeTableNames(name, names, 3)
+	text := fmt.Sprintf("Table %s no longer exists; it was deleted or renamed. The table list has been refreshed.", name)
+	if len(similar) > 0 {
+		text += "\n\nSimilar tables: " + strings.Join(similar, ", ")
+	}
+	var buttons []string
+	for _, n := range similar {
+		buttons = append(buttons, "Go to "+n)
+	}
+	buttons = append(buttons, "Table List")
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			p.pages.RemovePage("tablemissing")
+			p.pages.SwitchToPage("tablelist")
+			p.filterInput.SetText("")
+			if target, ok := strings.CutPrefix(buttonLabel, "Go to "); ok {
+				for i, t := range p.filteredTables {
+					if t.Name == target {
+						p.table.Select(i+1, 0)
+					}
+				}
+			}
+			p.app.SetFocus(p.table)
+		})
+	p.pages.AddPage("tablemissing", modal, true, true)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultAutoRefresh is how often Ctrl+R refreshes the table list when
+// --auto-refresh doesn't say
+const defaultAutoRefresh = time.Minute
+
+// minAutoRefresh keeps the refresh from describing the tables nonstop
+const minAutoRefresh = 5 * time.Second
+
+// autoRefreshInterval is how often the table list is refreshed once
+// auto-refresh is on
+func autoRefreshInterval() time.Duration {
+	if *autoRefresh > 0 {
+		return *autoRefresh
+	}
+	return defaultAutoRefresh
+}
+
+// autoRefreshNote tells how often the table list is refreshed and how long
+// ago it was last loaded, e.g. "refresh every 1m0s, updated 12s ago". A zero
+// time means the list is still loading.
+func autoRefreshNote(loadedAt time.Time) string {
+	note := fmt.Sprintf("refresh every %s", autoRefreshInterval())
+	if !loadedAt.IsZero() {
+		note += fmt.Sprintf(", updated %s ago", time.Since(loadedAt).Truncate(time.Second))
+	}
+	return note
+}
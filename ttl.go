@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ttlText renders the value of a table's TTL attribute, seconds since the
+// epoch, as a local date and time with how long until the item expires.
+// DynamoDB deletes expired items within a few days, so queries can still
+// return them for a while. ok is false for values that aren't a number.
+func ttlText(v interface{}, now time.Time) (text string, ok bool) {
+	var secs int64
+	switch n := v.(type) {
+	case int64:
+		secs = n
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return "", false
+		}
+		secs = int64(f)
+	default:
+		return "", false
+	}
+
+	// A millisecond timestamp read as seconds lies thousands of years ahead
+	if secs > 1e11 {
+		return fmt.Sprintf("%d (looks like milliseconds; TTL needs seconds, so the item never expires)", secs), true
+	}
+	at := time.Unix(secs, 0).Local()
+	date := formatDate(at) + " " + at.Format("15:04")
+	if d := at.Sub(now); d > 0 {
+		return fmt.Sprintf("%s (expires in %s)", date, roughDuration(d)), true
+	}
+	// TTL skips items that expired more than five years ago
+	if now.Sub(at) > 5*365*24*time.Hour {
+		return fmt.Sprintf("%s (expired %s ago, too long ago for TTL to delete it)", date, roughDuration(now.Sub(at))), true
+	}
+	return fmt.Sprintf("%s (expired %s ago, pending deletion)", date, roughDuration(now.Sub(at))), true
+}
+
+// roughDuration renders d in its largest whole unit, e.g. "3 days"
+func roughDuration(d time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if n := int64(d / u.size); n >= 1 {
+			if n == 1 {
+				return "1 " + u.name
+			}
+			return fmt.Sprintf("%s %ss", formatNumber(n), u.name)
+		}
+	}
+	return "less than a minute"
+}
@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// saveItemAsJSON writes the raw item to a JSON file named after its keys.
+// Items of merged views keep their source in provenanceAttribute.
+func saveItemAsJSON(h *Host, tableInfo aws.TableInfo, rawItem map[string]interface{}, source ItemSource) {
+	// Generate filename from keys
+	pkValue := fmt.Sprintf("%v", rawItem[tableInfo.PartitionKey])
+	filename := pkValue
+	if tableInfo.SortKey != "" {
+		skValue := fmt.Sprintf("%v", rawItem[tableInfo.SortKey])
+		filename = fmt.Sprintf("%s_%s", pkValue, skValue)
+	}
+	// Clean filename (remove special characters)
+	filename = strings.ReplaceAll(filename, "/", "_")
+	filename = strings.ReplaceAll(filename, " ", "_")
+	filename = strings.ReplaceAll(filename, ":", "_")
+	filename += ".json"
+
+	// Marshal to JSON
+	jsonBytes, err := json.MarshalIndent(withProvenance(rawItem, source), "", "    ")
+	if err != nil {
+		h.Message("saveerror", fmt.Sprintf("Error saving JSON: %v", err))
+		return
+	}
+
+	// Write to file
+	if err := os.WriteFile(filename, jsonBytes, 0644); err != nil {
+		h.Message("saveerror", fmt.Sprintf("Error writing file: %v", err))
+		return
+	}
+
+	h.Notify(fmt.Sprintf("Saved to %s", tview.Escape(filename)))
+}
+
+// CopyItemAsJSON puts an item on the clipboard as the item view's download
+// writes it
+func CopyItemAsJSON(h *Host, rawItem map[string]interface{}, source ItemSource) {
+	jsonBytes, err := json.MarshalIndent(withProvenance(rawItem, source), "", "    ")
+	if err != nil {
+		h.Message("copyerror", fmt.Sprintf("Error formatting JSON: %v", err))
+		return
+	}
+	h.copy("copyerror", string(jsonBytes), "Copied the item as JSON")
+}
+
+// fillItemTable lists every attribute of an item in a field/value table,
+// formatted or raw depending on Settings.RawValues. Schema fields come
+// first; index keys the item doesn't have are listed as missing, which is
+// why it isn't in that index.
+func fillItemTable(h *Host, itemTable *tview.Table, tableInfo aws.TableInfo, item, rawItem map[string]interface{}) {
+	itemTable.Clear()
+
+	// Headers
+	itemTable.SetCell(0, 0, tview.NewTableCell("Field").
+		SetTextColor(tview.Styles.SecondaryTextColor).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter))
+	itemTable.SetCell(0, 1, tview.NewTableCell("Value").
+		SetTextColor(tview.Styles.SecondaryTextColor).
+		SetSelectable(false).
+		SetAlign(tview.AlignCenter))
+
+	raw := h.Settings.RawValues
+	rows := len(item)
+	for _, sf := range tableInfo.SchemaFields {
+		if _, ok := item[sf]; !ok {
+			rows++
+		}
+	}
+	i := 1
+	shown := make(map[string]bool)
+	for _, sf := range tableInfo.SchemaFields {
+		if shown[sf] {
+			continue
+		}
+		value := MissingMarker
+		if _, ok := item[sf]; ok {
+			value = CellValue(item, rawItem, sf, raw)
+		}
+		name := h.rowPrefix("Field", i, rows) + tview.Escape(h.ColumnTitle(tableInfo.Name, sf))
+		if h.ScreenReader {
+			name += " (key)" // Key fields are otherwise only told apart by color
+		}
+		itemTable.SetCell(i, 0, tview.NewTableCell(name).
+			SetReference(sf).
+			SetTextColor(h.Theme.Teal).
+			SetSelectable(true))
+		itemTable.SetCell(i, 1, tview.NewTableCell(value).
+			SetTextColor(h.Theme.Teal).
+			SetSelectable(true))
+		shown[sf] = true
+		i++
+	}
+	// Other fields
+	var others []string
+	for k := range item {
+		if !shown[k] {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	for _, k := range others {
+		name, value := tview.Escape(h.ColumnTitle(tableInfo.Name, k)), CellValue(item, rawItem, k, raw)
+		// The TTL attribute is shown as the date the item expires
+		if k == tableInfo.TTLAttribute {
+			name += " (TTL)"
+			if text, ok := h.TTLText(rawItem[k]); ok && !raw {
+				value = text
+			}
+		}
+		itemTable.SetCell(i, 0, tview.NewTableCell(h.rowPrefix("Field", i, rows)+name).
+			SetReference(k).
+			SetTextColor(tview.Styles.PrimaryTextColor).
+			SetSelectable(true))
+		itemTable.SetCell(i, 1, tview.NewTableCell(value).
+			SetTextColor(tview.Styles.PrimaryTextColor).
+			SetSelectable(true))
+		i++
+	}
+}
+
+// itemDetail shows every attribute of an item, schema fields first, and for
+// items of merged views where the item came from
+type itemDetail struct {
+	h             *Host
+	tableInfo     aws.TableInfo
+	item, rawItem map[string]interface{}
+	source        ItemSource
+
+	flex   *tview.Flex
+	header *tview.TextView
+	table  *tview.Table
+}
+
+// ShowItemDetail opens the item view of an item
+func ShowItemDetail(h *Host, tableInfo aws.TableInfo, item, rawItem map[string]interface{}, source ItemSource) {
+	d := newItemDetail(h, tableInfo, item, rawItem, source)
+	h.Pages.AddPage("fullitem", d.flex, true, true)
+}
+
+// newItemDetail lays out the item view
+func newItemDetail(h *Host, tableInfo aws.TableInfo, item, rawItem map[string]interface{}, source ItemSource) *itemDetail {
+	d := &itemDetail{h: h, tableInfo: tableInfo, item: item, rawItem: rawItem, source: source}
+	d.table = h.dataTable()
+	d.header = tview.NewTextView().SetTextAlign(tview.AlignCenter)
+	d.fill()
+	d.table.ScrollToBeginning()
+
+	// Full name of the selected field, when it is shown shortened or renamed
+	fieldName := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	d.table.SetSelectionChangedFunc(func(row, column int) {
+		fieldName.SetText(h.fullNameNote(tableInfo.Name, d.field(row)))
+	})
+
+	d.flex = tview.NewFlex().SetDirection(tview.FlexRow)
+	d.flex.AddItem(d.header, 1, 0, false)
+	d.flex.AddItem(d.table, 0, 1, true)
+	d.flex.AddItem(fieldName, 1, 0, false)
+	d.flex.SetInputCapture(d.handleKey)
+	return d
+}
+
+// fill lists the values and describes them in the header, again when the
+// value display is toggled
+func (d *itemDetail) fill() {
+	fillItemTable(d.h, d.table, d.tableInfo, d.item, d.rawItem)
+	mode := "formatted"
+	if d.h.Settings.RawValues {
+		mode = "raw"
+	}
+	from := ""
+	if d.source.Column != "" {
+		from = fmt.Sprintf(" - %s: %s", d.source.Column, d.source.Value)
+	}
+	d.header.SetText(fmt.Sprintf("Full Item%s - %s values (%s: toggle raw | %s: copy value | %s: download | %s: keys)", from, mode, d.h.KeyLabel("item.rawValues"), d.h.KeyLabel("item.copyValue"), d.h.KeyLabel("item.download"), d.h.KeyLabel("app.pageKeys")))
+}
+
+// field is the attribute listed on a row, empty for the header
+func (d *itemDetail) field(row int) string {
+	field, _ := d.table.GetCell(row, 0).GetReference().(string)
+	return field
+}
+
+func (d *itemDetail) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	h := d.h
+	if event.Key() == tcell.KeyESC {
+		h.Pages.RemovePage("fullitem")
+	} else if h.KeyPressed(event, "item.download") {
+		saveItemAsJSON(h, d.tableInfo, d.rawItem, d.source)
+		return nil
+	} else if h.KeyPressed(event, "item.copyItem") {
+		CopyItemAsJSON(h, d.rawItem, d.source)
+		return nil
+	} else if h.KeyPressed(event, "item.copyValue") {
+		// Copy the selected field's value as text, or as JSON when it's
+		// a map, a list or a set
+		row, _ := d.table.GetSelection()
+		field := d.field(row)
+		if v, ok := d.rawItem[field]; ok {
+			h.copy("copyerror", RawValueString(v), fmt.Sprintf("Copied the value of %s", tview.Escape(field)))
+		}
+		return nil
+	} else if h.KeyPressed(event, "item.rawValues") {
+		h.Settings.RawValues = !h.Settings.RawValues
+		d.fill()
+		return nil
+	} else if event.Key() == tcell.KeyEnter {
+		row, _ := d.table.GetSelection()
+		if row > 0 {
+			field := d.field(row)
+			if v, ok := d.rawItem[field]; ok {
+				// Check if it's a complex type (map or slice)
+				switch v.(type) {
+				case map[string]interface{}, []interface{}, aws.StringSet, aws.NumberSet:
+					ShowJSONView(h, field, v)
+				default:
+					h.ShowBinary(d.tableInfo, d.rawItem, field, field)
+				}
+			}
+		}
+	}
+	return event
+}
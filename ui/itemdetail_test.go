@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"context"
+	"testing"
+)
+
+func TestItemDetailListsKeysFirst(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	tableInfo, err := client.DescribeTable(context.Background(), "devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := map[string]interface{}{"device": "a", "ts": "2", "reading": "8", "battery": ""}
+	rawItem := map[string]interface{}{"device": "a", "ts": int64(2), "reading": int64(8), "battery": ""}
+	d := newItemDetail(&h.Host, tableInfo, item, rawItem, ItemSource{})
+
+	// The keys of the table and its index, then the other attributes by name
+	want := []struct{ field, value string }{
+		{"device", "a"},
+		{"ts", "2"},
+		{"name", MissingMarker},
+		{"battery", EmptyStringMarker},
+		{"reading", "8"},
+	}
+	if rows := d.table.GetRowCount() - 1; rows != len(want) {
+		t.Fatalf("listed %d fields, want %d", rows, len(want))
+	}
+	for i, w := range want {
+		if field, value := d.field(i+1), d.table.GetCell(i+1, 1).Text; field != w.field || value != w.value {
+			t.Errorf("row %d is %s = %q, want %s = %q", i+1, field, value, w.field, w.value)
+		}
+	}
+
+	press(d.handleKey, "item.rawValues")
+	if !h.Settings.RawValues || d.table.GetCell(2, 1).Text != "2" {
+		t.Errorf("raw ts is %q", d.table.GetCell(2, 1).Text)
+	}
+}
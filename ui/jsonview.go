@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// jsonChild is an entry of a map or list shown in the JSON view
+type jsonChild struct {
+	label string // Key of a map entry, [index] of a list element
+	value interface{}
+}
+
+// jsonChildren lists the entries of a map, by key, or the elements of a list
+// or set; other values have none
+func jsonChildren(v interface{}) []jsonChild {
+	var children []jsonChild
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			children = append(children, jsonChild{label: k, value: val[k]})
+		}
+	case []interface{}:
+		for i, e := range val {
+			children = append(children, jsonChild{label: fmt.Sprintf("[%d]", i), value: e})
+		}
+	default:
+		for i, e := range setMembers(v) {
+			children = append(children, jsonChild{label: fmt.Sprintf("[%d]", i), value: e})
+		}
+	}
+	return children
+}
+
+// setMembers lists the members of a string, number or binary set, nil for
+// other values
+func setMembers(v interface{}) []interface{} {
+	var members []interface{}
+	switch set := v.(type) {
+	case aws.StringSet:
+		for _, m := range set {
+			members = append(members, m)
+		}
+	case aws.NumberSet:
+		for _, m := range set {
+			members = append(members, m)
+		}
+	case aws.BinarySet:
+		for _, m := range set {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// jsonSummary describes a value in one line: the size of a map, list or set,
+// or the value itself
+func jsonSummary(v interface{}, formatNumber func(int64) string) string {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("{ } %s keys", formatNumber(int64(len(val))))
+	case []interface{}:
+		return fmt.Sprintf("[ ] %s items", formatNumber(int64(len(val))))
+	case aws.StringSet, aws.NumberSet, aws.BinarySet:
+		return fmt.Sprintf("( ) %s of %s", setType(v), formatNumber(int64(len(setMembers(v)))))
+	case nil:
+		return NullMarker
+	case string:
+		if val == "" {
+			return EmptyStringMarker
+		}
+	}
+	text := RawValueText(v)
+	if len(text) > MaxCellLength {
+		text = text[:MaxCellLength-3] + "..."
+	}
+	return text
+}
+
+// jsonLevel is a step of the path drilled into in the JSON view
+type jsonLevel struct {
+	label string
+	value interface{}
+	row   int // Selected entry, restored when coming back up
+}
+
+// jsonView shows a value as pretty-printed, scrollable JSON next to the
+// list of its entries. Enter on a map or list entry makes it the new root,
+// so deeply nested values can be read one level at a time; Backspace goes
+// back up. The path from the attribute to the root is shown above.
+type jsonView struct {
+	h    *Host
+	path []jsonLevel
+
+	flex       *tview.Flex
+	breadcrumb *tview.TextView
+	entries    *tview.Table
+	text       *tview.TextView
+}
+
+// ShowJSONView opens a value, named name, in the JSON viewer
+func ShowJSONView(h *Host, name string, v interface{}) {
+	j := newJSONView(h, name, v)
+	h.Pages.AddPage("jsonview", j.flex, true, true)
+	h.App.SetFocus(j.entries)
+}
+
+// newJSONView lays out the viewer with the value as its root
+func newJSONView(h *Host, name string, v interface{}) *jsonView {
+	j := &jsonView{h: h, path: []jsonLevel{{label: name, value: v}}}
+	j.breadcrumb = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+	j.entries = h.dataTable()
+	j.text = tview.NewTextView().
+		SetTextAlign(tview.AlignLeft).
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+
+	panes := tview.NewFlex().
+		AddItem(j.entries, 0, 1, true).
+		AddItem(j.text, 0, 2, false)
+
+	j.flex = tview.NewFlex().SetDirection(tview.FlexRow)
+	j.flex.AddItem(tview.NewTextView().SetText(fmt.Sprintf("JSON View - %s (Enter: open entry | Backspace: up | Tab: switch pane | Space: page down | %s: copy | ESC: close)", name, h.KeyLabel("json.copy"))).SetTextAlign(tview.AlignCenter), 1, 0, false)
+	j.flex.AddItem(j.breadcrumb, 2, 0, false)
+	j.flex.AddItem(panes, 0, 1, true)
+	j.flex.SetInputCapture(j.handleKey)
+
+	j.render()
+	return j
+}
+
+// render shows the last level of the path
+func (j *jsonView) render() {
+	level := j.path[len(j.path)-1]
+	labels := make([]string, len(j.path))
+	for i, l := range j.path {
+		labels[i] = tview.Escape(l.label)
+	}
+	j.breadcrumb.SetText(fmt.Sprintf("[#b8b8b8]Path (depth %d):[white] %s", len(j.path)-1,
+		strings.Join(labels, " [#ff9500]>[white] ")))
+
+	j.entries.Clear()
+	for col, header := range []string{"Entry", "Value"} {
+		j.entries.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	children := jsonChildren(level.value)
+	for i, child := range children {
+		color := tview.Styles.PrimaryTextColor
+		if len(jsonChildren(child.value)) > 0 {
+			color = j.h.Theme.Teal // Can be entered
+		}
+		j.entries.SetCell(i+1, 0, tview.NewTableCell(j.h.rowPrefix("Entry", i+1, len(children))+tview.Escape(child.label)).
+			SetTextColor(color))
+		j.entries.SetCell(i+1, 1, tview.NewTableCell(j.h.labeled("value", jsonSummary(child.value, j.h.FormatNumber))).
+			SetTextColor(tview.Styles.PrimaryTextColor))
+	}
+	if len(children) == 0 {
+		j.entries.SetCell(1, 0, tview.NewTableCell("(empty)").
+			SetTextColor(j.h.Theme.TextSecondary))
+	}
+	j.entries.Select(max(level.row, 1), 0)
+
+	jsonBytes, err := json.MarshalIndent(level.value, "", "    ")
+	if err != nil {
+		jsonBytes = []byte(fmt.Sprintf("Error formatting JSON: %v", err))
+	}
+	j.text.SetText(tview.Escape(string(jsonBytes))).
+		ScrollToBeginning()
+}
+
+// enter makes the selected entry the root, if it is a map, list or set
+func (j *jsonView) enter() {
+	row, _ := j.entries.GetSelection()
+	children := jsonChildren(j.path[len(j.path)-1].value)
+	if row < 1 || row > len(children) || len(jsonChildren(children[row-1].value)) == 0 {
+		return
+	}
+	j.path[len(j.path)-1].row = row
+	j.path = append(j.path, jsonLevel{label: children[row-1].label, value: children[row-1].value})
+	j.render()
+}
+
+// up goes back to the level above, selecting the entry that was entered
+func (j *jsonView) up() {
+	if len(j.path) > 1 {
+		j.path = j.path[:len(j.path)-1]
+		j.render()
+	}
+}
+
+func (j *jsonView) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyESC:
+		j.h.Pages.RemovePage("jsonview")
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		j.up()
+		return nil
+	case tcell.KeyTab:
+		if j.entries.HasFocus() {
+			j.h.App.SetFocus(j.text)
+		} else {
+			j.h.App.SetFocus(j.entries)
+		}
+		return nil
+	case tcell.KeyEnter:
+		if j.entries.HasFocus() {
+			j.enter()
+			return nil
+		}
+	}
+	if j.h.KeyPressed(event, "json.copy") {
+		// Copy the JSON of the current root
+		level := j.path[len(j.path)-1]
+		jsonBytes, err := json.MarshalIndent(level.value, "", "    ")
+		if err != nil {
+			j.h.Message("jsonerror", fmt.Sprintf("Error formatting JSON: %v", err))
+			return nil
+		}
+		j.h.copy("jsonerror", string(jsonBytes), fmt.Sprintf("Copied the JSON of %s", tview.Escape(level.label)))
+		return nil
+	}
+	if event.Rune() == ' ' && j.text.HasFocus() {
+		// Scroll down by page
+		row, col := j.text.GetScrollOffset()
+		_, _, _, height := j.text.GetInnerRect()
+		j.text.ScrollTo(row+height-1, col)
+		return nil
+	}
+	return event
+}
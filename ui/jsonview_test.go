@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestJSONViewDrillsDownAndBackUp(t *testing.T) {
+	h := newTestHost(nil)
+	value := map[string]interface{}{
+		"battery": int64(81),
+		"history": []interface{}{
+			map[string]interface{}{"at": "noon", "level": int64(90)},
+			map[string]interface{}{"at": "dusk", "level": int64(85)},
+		},
+	}
+	j := newJSONView(&h.Host, "payload", value)
+	h.App.SetFocus(j.entries)
+
+	// Entries that aren't maps, lists or sets can't be entered
+	j.entries.Select(1, 0)
+	j.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if len(j.path) != 1 {
+		t.Fatalf("entered %s, want to stay on the root", j.path[len(j.path)-1].label)
+	}
+
+	j.entries.Select(2, 0)
+	j.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	j.entries.Select(2, 0)
+	j.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if len(j.path) != 3 || !strings.Contains(j.breadcrumb.GetText(true), "payload > history > [1]") {
+		t.Fatalf("path is %q, want the second entry of history", j.breadcrumb.GetText(true))
+	}
+	if text := j.text.GetText(true); !strings.Contains(text, `"dusk"`) || strings.Contains(text, "noon") {
+		t.Errorf("shows %s, want only the second entry", text)
+	}
+
+	// Backspace goes up, back to the entry that was entered; so does Ctrl+H
+	j.handleKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if row, _ := j.entries.GetSelection(); len(j.path) != 2 || row != 2 {
+		t.Errorf("Backspace went to depth %d with row %d selected, want history with row 2", len(j.path)-1, row)
+	}
+	j.handleKey(tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModCtrl))
+	j.handleKey(tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModCtrl))
+	if len(j.path) != 1 {
+		t.Errorf("going up past the root left depth %d", len(j.path)-1)
+	}
+}
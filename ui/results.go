@@ -0,0 +1,1120 @@
+package ui
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ResultsOptions describes a result set shown by a ResultsView
+type ResultsOptions struct {
+	PageName string // Page name used to add and remove the results page
+	Title    string // Header prefix, e.g. "Query Results for users"
+
+	// FetchNext loads the page the token points to; nil disables pagination
+	FetchNext PageFetcher
+
+	// Notice is an optional highlighted line shown under the header
+	Notice string
+
+	// MorePagesNotice replaces an empty notice on pages that have a next page
+	MorePagesNotice string
+
+	// Hydrate binds Ctrl+G to replacing the current page, read from an index
+	// that doesn't project every attribute, with the full items of the base
+	// table
+	Hydrate bool
+
+	// SourceColumn adds a column showing where each item came from (QueryResult.Sources)
+	SourceColumn string
+
+	// Request builds the request of the read, for copying it as an AWS CLI
+	// command or SDK code; nil for reads made of several requests, such as
+	// unions
+	Request func() (aws.ReadRequest, error)
+
+	// Start is the cursor the first page was read after, for reads resumed
+	// from an earlier session; nil for the start of the read
+	Start *aws.PageToken
+
+	// OnPage, when set, is told the cursor of every page shown, nil for the
+	// first page of the read
+	OnPage func(start *aws.PageToken)
+}
+
+// PageFetcher reads the page a token points to, nil for the first. Reads
+// spanning several requests pass the items read so far to partial, from the
+// reading goroutine; fetchers reading a single request ignore it.
+type PageFetcher func(ctx context.Context, page *aws.PageToken, partial func(aws.QueryResult)) (aws.QueryResult, error)
+
+// ResultsUpdater replaces the last page of a results page with a later state
+// of its read, with loading cleared once the read is complete
+type ResultsUpdater func(result aws.QueryResult, loading bool)
+
+// SourceOf returns where item i of a result came from
+func (opts ResultsOptions) SourceOf(result aws.QueryResult, i int) ItemSource {
+	if opts.SourceColumn == "" {
+		return ItemSource{}
+	}
+	source := ItemSource{Column: opts.SourceColumn}
+	if i < len(result.Sources) {
+		source.Value = result.Sources[i]
+	}
+	return source
+}
+
+// maxNameLines is the most full column names listed under the results
+const maxNameLines = 3
+
+// A read whose filter dropped at least filterWasteShare of the items it
+// paid for, out of at least filterWasteMinScanned, gets a warning badge
+const (
+	filterWasteShare      = 0.8
+	filterWasteMinScanned = 10
+)
+
+// readCounts shows how many items a query or scan returned out of those it
+// read, with a yellow badge when its filter dropped most of them
+func readCounts(result aws.QueryResult, formatNumber func(int64) string) string {
+	if result.ScannedCount == 0 {
+		return ""
+	}
+	text := fmt.Sprintf(" - Count %s / ScannedCount %s", formatNumber(int64(result.Count)), formatNumber(int64(result.ScannedCount)))
+	dropped := result.ScannedCount - result.Count
+	if result.ScannedCount >= filterWasteMinScanned && float64(dropped) >= filterWasteShare*float64(result.ScannedCount) {
+		text += fmt.Sprintf(" [#121212:#ffd60a] filter dropped %d%% of the items read [-:-]", dropped*100/result.ScannedCount)
+	}
+	return text
+}
+
+// candidateFields are common attribute names shown as extra result columns
+var candidateFields = []string{"title", "Title", "name", "Name", "displayName", "description", "Description", "email", "Email"}
+
+// DetectColumns picks up to two descriptive attributes present on the first
+// item, shown after the keys when no columns were chosen
+func DetectColumns(tableInfo aws.TableInfo, items []map[string]interface{}) []string {
+	var additionalFields []string
+	if len(items) == 0 {
+		return nil
+	}
+	firstItem := items[0]
+	for _, field := range candidateFields {
+		if _, exists := firstItem[field]; exists {
+			// Skip if it's already a key field
+			if field != tableInfo.PartitionKey && field != tableInfo.SortKey {
+				additionalFields = append(additionalFields, field)
+				if len(additionalFields) >= 2 {
+					break
+				}
+			}
+		}
+	}
+	return additionalFields
+}
+
+// HydrateFromBaseTable fetches the full base table items for a page of index
+// results. Index items whose base item no longer exists are kept as they are.
+func HydrateFromBaseTable(ctx context.Context, client Reader, tableInfo aws.TableInfo, page aws.QueryResult) (aws.QueryResult, error) {
+	keys := make([]map[string]interface{}, len(page.RawItems))
+	for i, rawItem := range page.RawItems {
+		keys[i] = map[string]interface{}{tableInfo.PartitionKey: rawItem[tableInfo.PartitionKey]}
+		if tableInfo.SortKey != "" {
+			keys[i][tableInfo.SortKey] = rawItem[tableInfo.SortKey]
+		}
+	}
+
+	fullItems, err := client.BatchGetItems(ctx, tableInfo.Name, keys)
+	if err != nil {
+		return aws.QueryResult{}, err
+	}
+
+	hydrated := page
+	hydrated.ConsumedCapacity += fullItems.ConsumedCapacity
+	hydrated.Requests = append(append([]aws.RequestDiagnostics(nil), page.Requests...), fullItems.Requests...)
+	hydrated.Items = append([]map[string]interface{}(nil), page.Items...)
+	hydrated.RawItems = append([]map[string]interface{}(nil), page.RawItems...)
+	for i := range fullItems.Items {
+		if fullItems.Items[i] != nil {
+			hydrated.Items[i] = fullItems.Items[i]
+			hydrated.RawItems[i] = fullItems.RawItems[i]
+		}
+	}
+	return hydrated, nil
+}
+
+// ResultsView is a results page: a page of items with pagination, sorting
+// and grouping of the loaded pages, and item drill-down. The application
+// adds its own features around it through the Set functions, AddPane and
+// ScrollAlong, before calling Show.
+type ResultsView struct {
+	h         *Host
+	tableInfo aws.TableInfo
+	opts      ResultsOptions
+
+	result      aws.QueryResult   // In view: a page, or the loaded pages sorted or grouped
+	pageHistory []aws.QueryResult // Pages read so far
+	currentPage int
+	loading     bool // The last page is still being read
+
+	// The cursor the first page of the history was read after, when the
+	// read was resumed or a page was read from a cursor entered by hand
+	firstStart *aws.PageToken
+
+	// Columns after the keys: chosen ones, otherwise detected descriptive
+	// fields (title, name, etc.)
+	columns []string
+
+	// The loaded pages shown as one result from memory, sorted by an
+	// attribute with o or narrowed to a group, or both; viewPositions maps
+	// its rows back to the pages
+	sortAttr             string
+	sortDescending       bool
+	groupAttr, groupName string
+	groupKeep            func(rawItem map[string]interface{}) bool
+	viewPositions        []itemPosition
+
+	// The current column, moved with ←/→ or h/l, is the one v shows in full
+	// and +/- widen or narrow. Its header is highlighted, and the columns
+	// after the keys scroll to keep it in view.
+	currentColumn int // Index into ShownFields
+	keyColumns    int // Partition and sort key
+	fixedColumns  int // Key columns and the source column of merged views
+	headerCells   []*tview.TableCell
+	content       *virtualRows
+
+	// Widths of the columns after the keys while auto-fit is on, by
+	// attribute, fitted to a table fittedTo wide
+	fitted   map[string]int
+	fittedTo int
+
+	rawShown bool // Settings.RawValues the table was last filled with
+
+	// Item preview next to the results, for the preview Enter action and
+	// the split view
+	previewShown                bool
+	previewPage, previewRow     int
+	previewItem, previewRawItem map[string]interface{}
+
+	// Features of the application, see the Set functions
+	rowColor    func(page int, rawItem map[string]interface{}) (tcell.Color, bool)
+	headerNote  func() string
+	changed     func()
+	capture     func(event *tcell.EventKey) *tcell.EventKey
+	panes       []tview.Primitive
+	scrollAlong []*tview.Table
+
+	flex          *tview.Flex
+	header        *tview.TextView
+	notice        *tview.TextView
+	names         *tview.TextView // Full names of the columns shown shortened or renamed
+	table         *tview.Table
+	body          *tview.Flex
+	preview       *tview.Table
+	previewHeader *tview.TextView
+	previewFlex   *tview.Flex
+	prevButton    *tview.Button
+	nextButton    *tview.Button
+}
+
+// NewResultsView lays out a results page of a read's first page. A first
+// page still being read is passed with loading set and kept current through
+// Update.
+func NewResultsView(h *Host, tableInfo aws.TableInfo, opts ResultsOptions, result aws.QueryResult, loading bool) *ResultsView {
+	v := &ResultsView{
+		h:           h,
+		tableInfo:   tableInfo,
+		opts:        opts,
+		result:      result,
+		pageHistory: []aws.QueryResult{result},
+		currentPage: 1,
+		loading:     loading,
+		firstStart:  opts.Start,
+		columns:     DetectColumns(tableInfo, result.Items),
+		rawShown:    h.Settings.RawValues,
+	}
+	v.table = h.dataTable()
+
+	// The header and the key columns, after the source column of merged
+	// views, stay in view while the other columns scroll sideways
+	v.keyColumns = 1
+	if tableInfo.SortKey != "" {
+		v.keyColumns++
+	}
+	v.fixedColumns = v.keyColumns
+	if opts.SourceColumn != "" {
+		v.fixedColumns++
+	}
+	v.table.SetFixed(1, v.fixedColumns)
+
+	v.header = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	v.notice = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter).
+		SetDynamicColors(true)
+	v.names = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+
+	v.table.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		if h.Settings.AutoFit && width != v.fittedTo {
+			v.fitColumns(width)
+		}
+		return x, y, width, height
+	})
+	v.table.SetSelectionChangedFunc(func(row, column int) {
+		v.syncSplit()
+	})
+	// Follow a toggle made in the item view when coming back to the table
+	v.table.SetFocusFunc(func() {
+		if v.rawShown != h.Settings.RawValues {
+			v.rawShown = h.Settings.RawValues
+			v.Redraw()
+		}
+	})
+
+	v.preview = h.dataTable()
+	v.previewHeader = tview.NewTextView().
+		SetTextAlign(tview.AlignCenter)
+	v.previewFlex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.previewHeader, 1, 0, false).
+		AddItem(v.preview, 0, 1, false)
+	v.body = tview.NewFlex().
+		AddItem(v.table, 0, 1, true)
+
+	btnStyle := tcell.StyleDefault.Background(h.Theme.Orange).Foreground(tcell.NewHexColor(0x121212))
+	disabledStyle := tcell.StyleDefault.Background(h.Theme.BgSecondary).Foreground(h.Theme.TextSecondary)
+	newButton := func(label string, selected func()) *tview.Button {
+		button := tview.NewButton(label)
+		button.SetSelectedFunc(selected)
+		button.SetStyle(btnStyle)
+		button.SetDisabledStyle(disabledStyle)
+		button.SetMouseCapture(ClickWithoutFocus(button.Box))
+		return button
+	}
+	v.prevButton = newButton(fmt.Sprintf("< Previous (%s)", h.KeyLabel("results.previousPage")), v.goToPrevious)
+	v.nextButton = newButton(fmt.Sprintf("Next > (%s)", h.KeyLabel("results.nextPage")), v.goToNext)
+	return v
+}
+
+// SetRowColorFunc colors the rows of items, of the given page of the read,
+// for which color returns true; the others keep the text color
+func (v *ResultsView) SetRowColorFunc(color func(page int, rawItem map[string]interface{}) (tcell.Color, bool)) {
+	v.rowColor = color
+}
+
+// SetHeaderNoteFunc adds the text note returns, when not empty, to the
+// header
+func (v *ResultsView) SetHeaderNoteFunc(note func() string) {
+	v.headerNote = note
+}
+
+// SetChangedFunc calls changed whenever the items in view were shown again,
+// after they changed or were redrawn
+func (v *ResultsView) SetChangedFunc(changed func()) {
+	v.changed = changed
+}
+
+// SetInputCapture handles keys before the view does, which gets the keys
+// capture returns
+func (v *ResultsView) SetInputCapture(capture func(event *tcell.EventKey) *tcell.EventKey) {
+	v.capture = capture
+}
+
+// AddPane adds a primitive between the header and the results, hidden until
+// ResizePane gives it a height
+func (v *ResultsView) AddPane(pane tview.Primitive) {
+	v.panes = append(v.panes, pane)
+}
+
+// ResizePane sets the height of a pane, 0 to hide it
+func (v *ResultsView) ResizePane(pane tview.Primitive, height int) {
+	v.flex.ResizeItem(pane, height, 0)
+}
+
+// ScrollAlong scrolls a table's columns along with those of the results,
+// for tables showing the same columns after a first one of their own
+func (v *ResultsView) ScrollAlong(table *tview.Table) {
+	v.scrollAlong = append(v.scrollAlong, table)
+}
+
+// Show adds the results page, in place of any page of the same name, and
+// focuses the results
+func (v *ResultsView) Show() {
+	v.flex = tview.NewFlex().SetDirection(tview.FlexRow)
+	v.flex.AddItem(v.header, 1, 0, false)
+	if v.opts.Notice != "" || v.opts.MorePagesNotice != "" {
+		v.flex.AddItem(v.notice, 1, 0, false)
+	}
+	for _, pane := range v.panes {
+		v.flex.AddItem(pane, 0, 0, false) // Sized by the application
+	}
+	v.flex.AddItem(v.body, 0, 1, true)
+	v.flex.AddItem(v.names, 0, 0, false) // Sized by updateNames
+	navFlex := tview.NewFlex().SetDirection(tview.FlexColumn)
+	navFlex.AddItem(v.prevButton, 0, 1, false)
+	if v.opts.FetchNext != nil {
+		navFlex.AddItem(v.nextButton, 0, 1, false)
+	}
+	v.flex.AddItem(navFlex, 1, 0, false)
+	v.flex.SetInputCapture(v.handleKey)
+
+	v.show(v.result, 1)
+	v.updateNavButtons()
+
+	v.h.Pages.RemovePage(v.opts.PageName) // Remove any existing results
+	v.h.Pages.AddPage(v.opts.PageName, v.flex, true, true)
+	v.h.App.SetFocus(v.table)
+}
+
+// Update shows a later state of the last page, which is the one shown while
+// it loads, keeping the selection in place
+func (v *ResultsView) Update(result aws.QueryResult, loading bool) {
+	v.loading = loading
+	v.pageHistory[len(v.pageHistory)-1] = result
+	v.currentPage = len(v.pageHistory)
+	v.result = result
+	v.Redraw()
+	v.updateNavButtons()
+}
+
+// Result is the items in view: a page, or the loaded pages sorted or grouped
+func (v *ResultsView) Result() aws.QueryResult {
+	return v.result
+}
+
+// Page is the number of the page in view, from 1
+func (v *ResultsView) Page() int {
+	return v.currentPage
+}
+
+// PageStart is the cursor the page in view was read after, nil for the
+// first page of the read
+func (v *ResultsView) PageStart() *aws.PageToken {
+	if v.currentPage == 1 {
+		return v.firstStart
+	}
+	return v.pageHistory[v.currentPage-2].NextPage
+}
+
+// LoadedPages are the pages read so far, in the order they were read
+func (v *ResultsView) LoadedPages() []aws.QueryResult {
+	return v.pageHistory
+}
+
+// Loading reports whether the last page, then in view, is still being read
+func (v *ResultsView) Loading() bool {
+	return v.loading
+}
+
+// Viewing reports whether the loaded pages are shown sorted or grouped
+func (v *ResultsView) Viewing() bool {
+	return v.sortAttr != "" || v.groupAttr != ""
+}
+
+// Columns are the columns shown after the keys
+func (v *ResultsView) Columns() []string {
+	return v.columns
+}
+
+// SetColumns replaces the columns shown after the keys
+func (v *ResultsView) SetColumns(columns []string) {
+	v.columns = columns
+	if v.content != nil {
+		v.Redraw()
+	}
+}
+
+// ShownFields are the attributes shown as columns, after the source column
+// of merged views
+func (v *ResultsView) ShownFields() []string {
+	fields := []string{v.tableInfo.PartitionKey}
+	if v.tableInfo.SortKey != "" {
+		fields = append(fields, v.tableInfo.SortKey)
+	}
+	return append(fields, v.columns...)
+}
+
+// KeyColumns is the number of key columns, which come first among
+// ShownFields
+func (v *ResultsView) KeyColumns() int {
+	return v.keyColumns
+}
+
+// CurrentColumn is the index into ShownFields of the current column
+func (v *ResultsView) CurrentColumn() int {
+	return v.currentColumn
+}
+
+// Selected is the index into Result of the selected item, false when no
+// item is selected
+func (v *ResultsView) Selected() (int, bool) {
+	row, _ := v.table.GetSelection()
+	if row < 1 || row > len(v.result.Items) {
+		return 0, false
+	}
+	return row - 1, true
+}
+
+// Focus gives the focus back to the results
+func (v *ResultsView) Focus() {
+	v.h.App.SetFocus(v.table)
+}
+
+// Redraw shows the items in view again, keeping the selection in place
+func (v *ResultsView) Redraw() {
+	row, col := v.table.GetSelection()
+	rowOffset, colOffset := v.table.GetOffset()
+	v.show(v.result, v.currentPage)
+	v.table.Select(row, col)
+	v.table.SetOffset(rowOffset, colOffset)
+}
+
+// Reread shows a new read of the first page in place of the pages loaded
+// so far, keeping the selection in place
+func (v *ResultsView) Reread(first aws.QueryResult) {
+	v.pageHistory = []aws.QueryResult{first}
+	v.firstStart = nil
+	v.result = first
+	v.Redraw()
+	v.updateNavButtons()
+}
+
+// JumpTo shows a page read from a cursor as the first page, dropping the
+// pages loaded so far and any sort or group
+func (v *ResultsView) JumpTo(start *aws.PageToken, page aws.QueryResult) {
+	v.sortAttr, v.groupAttr, v.viewPositions = "", "", nil
+	v.pageHistory = []aws.QueryResult{page}
+	v.firstStart = start
+	v.show(page, 1)
+	v.updateNavButtons()
+	v.Focus()
+}
+
+// Group is the attribute the view is narrowed to a group of, empty when it
+// isn't
+func (v *ResultsView) Group() string {
+	return v.groupAttr
+}
+
+// SetGroup narrows the view to the loaded items keep accepts, described as
+// those where attr is name
+func (v *ResultsView) SetGroup(attr, name string, keep func(rawItem map[string]interface{}) bool) {
+	v.groupAttr, v.groupName, v.groupKeep = attr, name, keep
+	v.showView()
+	v.Focus()
+}
+
+// RefreshItem reads item index of the items in view again, by its key in
+// tableName, and shows it as written, for example after it was edited. The
+// view keeps the item as it was read when reading fails or the page changed
+// in between.
+func (v *ResultsView) RefreshItem(tableName string, key map[string]interface{}, index int) {
+	page := v.currentPage
+	var written aws.QueryResult
+	v.h.Read(context.Background(), func(ctx context.Context) error {
+		var err error
+		written, err = v.h.Client.GetItem(ctx, tableName, key)
+		return err
+	}, func(err error) {
+		if err != nil || len(written.Items) == 0 || page != v.currentPage || index >= len(v.result.Items) {
+			return
+		}
+		v.result.Items[index], v.result.RawItems[index] = written.Items[0], written.RawItems[0]
+		if v.Viewing() {
+			// The page the item was read on keeps it too
+			pos := v.viewPositions[index]
+			v.pageHistory[pos.page].Items[pos.index], v.pageHistory[pos.page].RawItems[pos.index] = written.Items[0], written.RawItems[0]
+		}
+		v.Redraw()
+	})
+}
+
+// updateNames lists the full names of the columns shown shortened or
+// renamed under the results
+func (v *ResultsView) updateNames(fields []string) {
+	var notes []string
+	for _, field := range fields {
+		if note := v.h.fullNameNote(v.tableInfo.Name, field); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) > maxNameLines {
+		notes = append(notes[:maxNameLines-1], fmt.Sprintf("[#b8b8b8]%d more, listed with %s[white]", len(notes)-maxNameLines+1, v.h.KeyLabel("results.columns")))
+	}
+	v.names.SetText(strings.Join(notes, "\n"))
+	if v.flex != nil {
+		v.flex.ResizeItem(v.names, len(notes), 0)
+	}
+}
+
+// highlightColumn colors the header of the current column
+func (v *ResultsView) highlightColumn() {
+	for col, cell := range v.headerCells {
+		if col == v.fixedColumns-v.keyColumns+v.currentColumn {
+			cell.SetTextColor(v.h.Theme.Orange)
+		} else {
+			cell.SetTextColor(tview.Styles.SecondaryTextColor)
+		}
+	}
+}
+
+// columnWidth is how wide a column of the results is drawn: its widest
+// cell among the header and the rows on screen
+func (v *ResultsView) columnWidth(col int) int {
+	rowOffset, _ := v.table.GetOffset()
+	_, _, _, height := v.table.GetInnerRect()
+	width := 0
+	for row := 0; row <= height; row++ {
+		cell := v.table.GetCell(row, col)
+		if row > 0 {
+			cell = v.table.GetCell(rowOffset+row, col)
+		}
+		w := tview.TaggedStringWidth(cell.Text)
+		if cell.MaxWidth > 0 {
+			w = min(w, cell.MaxWidth)
+		}
+		width = max(width, w)
+	}
+	return width
+}
+
+// moveColumn makes the column delta columns away the current one
+func (v *ResultsView) moveColumn(delta int) {
+	v.currentColumn = max(0, min(len(v.ShownFields())-1, v.currentColumn+delta))
+	v.highlightColumn()
+	scrolled := v.currentColumn - v.keyColumns // Among the columns that scroll
+	if scrolled < 0 {
+		return
+	}
+	_, offset := v.table.GetOffset()
+	if scrolled < offset {
+		offset = scrolled
+	} else {
+		// Scroll until the column fits right of the fixed ones
+		_, _, width, _ := v.table.GetInnerRect()
+		for ; offset < scrolled; offset++ {
+			used := 1
+			for col := 0; col < v.fixedColumns; col++ {
+				used += v.columnWidth(col) + 1
+			}
+			for col := v.fixedColumns + offset; col <= v.fixedColumns+scrolled; col++ {
+				used += v.columnWidth(col) + 1
+			}
+			if used <= width {
+				break
+			}
+		}
+	}
+	for _, table := range append([]*tview.Table{v.table}, v.scrollAlong...) {
+		row, _ := table.GetOffset()
+		table.SetOffset(row, offset)
+	}
+}
+
+// fitColumns fits the columns to a table width wide. The key and source
+// columns are shown in full, the others share the width left.
+func (v *ResultsView) fitColumns(width int) {
+	v.fittedTo = width
+	result := v.result
+	raw := v.h.Settings.RawValues
+	widest := func(header string, value func(i int) string, limit int) int {
+		widest := tview.TaggedStringWidth(header)
+		for i := range result.Items {
+			widest = max(widest, tview.TaggedStringWidth(value(i)))
+			if widest >= limit {
+				return limit
+			}
+		}
+		return widest
+	}
+	available := width - len(v.headerCells) - 1 // Less the borders between columns
+	for col, field := range v.ShownFields()[:v.keyColumns] {
+		available -= widest(v.headerCells[v.fixedColumns-v.keyColumns+col].Text, func(i int) string {
+			return CellValue(result.Items[i], result.RawItems[i], field, raw)
+		}, math.MaxInt)
+	}
+	if v.opts.SourceColumn != "" {
+		available -= widest(v.headerCells[0].Text, func(i int) string {
+			return v.opts.SourceOf(result, i).Value
+		}, math.MaxInt)
+	}
+	natural := make([]int, len(v.columns))
+	for j, field := range v.columns {
+		natural[j] = widest(v.headerCells[v.fixedColumns+j].Text, func(i int) string {
+			return CellValue(result.Items[i], result.RawItems[i], field, raw)
+		}, v.h.CellWidth(v.tableInfo.Name, field))
+	}
+	widths := fitCellWidths(natural, available)
+	v.fitted = make(map[string]int, len(v.columns))
+	for j, field := range v.columns {
+		v.fitted[field] = widths[j]
+		v.headerCells[v.fixedColumns+j].SetMaxWidth(widths[j])
+	}
+	v.content.invalidate()
+}
+
+// show fills the table with the items of a page, or of the loaded pages
+// sorted or grouped, shown as page
+func (v *ResultsView) show(newResult aws.QueryResult, page int) {
+	h, tableInfo := v.h, v.tableInfo
+	raw := h.Settings.RawValues
+
+	// Headers
+	var headers []string
+	if v.opts.SourceColumn != "" {
+		headers = append(headers, v.opts.SourceColumn)
+	}
+	for _, field := range v.ShownFields() {
+		header := tview.Escape(h.ColumnTitle(tableInfo.Name, field))
+		switch {
+		case field == v.sortAttr && v.sortDescending:
+			header += " ↓"
+		case field == v.sortAttr:
+			header += " ↑"
+		}
+		headers = append(headers, header)
+	}
+	v.updateNames(v.ShownFields())
+	v.currentColumn = min(v.currentColumn, len(v.ShownFields())-1)
+	v.fitted, v.fittedTo = nil, 0 // Fitted again on the next draw
+
+	v.headerCells = make([]*tview.TableCell, len(headers))
+	for col, header := range headers {
+		v.headerCells[col] = tview.NewTableCell(header).
+			SetTextColor(tview.Styles.SecondaryTextColor).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter)
+	}
+	v.highlightColumn()
+
+	// Data, made into cells only for the rows on screen
+	rowCells := func(i int) []*tview.TableCell {
+		item, rawItem := newResult.Items[i], newResult.RawItems[i]
+		color := tview.Styles.PrimaryTextColor
+		if v.rowColor != nil {
+			if c, ok := v.rowColor(page, rawItem); ok {
+				color = c
+			}
+		}
+		cells := make([]*tview.TableCell, 0, len(headers))
+		prefix := h.rowPrefix("Item", i+1, len(newResult.Items))
+		if v.opts.SourceColumn != "" {
+			source := v.opts.SourceOf(newResult, i)
+			cells = append(cells, tview.NewTableCell(prefix+h.labeled(source.Column, source.Value)).
+				SetTextColor(h.Theme.Teal))
+			prefix = ""
+		}
+		cells = append(cells, tview.NewTableCell(prefix+h.labeled(tableInfo.PartitionKey, CellValue(item, rawItem, tableInfo.PartitionKey, raw))).
+			SetTextColor(color))
+		if tableInfo.SortKey != "" {
+			cells = append(cells, tview.NewTableCell(h.labeled(tableInfo.SortKey, CellValue(item, rawItem, tableInfo.SortKey, raw))).
+				SetTextColor(color))
+		}
+		// Add additional fields
+		for _, field := range v.columns {
+			value := CellValue(item, rawItem, field, raw)
+			// Truncate if too long, unless showing exact values
+			if !raw && !IsMarker(value) {
+				width, ok := v.fitted[field]
+				if !ok {
+					width = h.CellWidth(tableInfo.Name, field)
+				}
+				value = TruncateCell(value, width)
+			}
+			cells = append(cells, tview.NewTableCell(h.labeled(field, value)).
+				SetTextColor(color))
+		}
+		return cells
+	}
+	empty := tview.NewTableCell("No items found.").
+		SetTextColor(tview.Styles.PrimaryTextColor)
+	v.content = newVirtualRows(v.headerCells, len(newResult.Items), empty, rowCells)
+	v.table.SetContent(v.content)
+	if len(newResult.Items) > 0 {
+		// Back to the first row, with the columns scrolled as they were
+		_, column := v.table.GetOffset()
+		v.table.SetOffset(0, column)
+	}
+
+	// Update result reference
+	v.result = newResult
+	v.currentPage = page
+
+	// Update page header
+	mode := ""
+	if raw {
+		mode = " - raw values"
+	}
+	if newResult.Duplicates > 0 {
+		mode += fmt.Sprintf(" - %s duplicates dropped", h.FormatNumber(int64(newResult.Duplicates)))
+	}
+	if v.loading && page == len(v.pageHistory) {
+		mode += fmt.Sprintf(" - loading, %s items so far...", h.FormatNumber(int64(len(newResult.Items))))
+	}
+	switch {
+	case v.Viewing() && len(v.pageHistory) == 1:
+		mode += " - the loaded page"
+	case v.Viewing():
+		mode += fmt.Sprintf(" - %d loaded pages", len(v.pageHistory))
+	}
+	if v.groupAttr != "" {
+		mode += fmt.Sprintf(" where %s is %s", h.ColumnTitle(tableInfo.Name, v.groupAttr), v.groupName)
+	}
+	if v.sortAttr != "" {
+		order := "ascending"
+		if v.sortDescending {
+			order = "descending"
+		}
+		mode += fmt.Sprintf(" sorted by %s, %s", h.ColumnTitle(tableInfo.Name, v.sortAttr), order)
+	}
+	if v.headerNote != nil {
+		if note := v.headerNote(); note != "" {
+			mode += " - " + note
+		}
+	}
+	switch h.Settings.enterAction() {
+	case EnterJSON:
+		mode += " - Enter: JSON"
+	case EnterPreview:
+		mode += " - Enter: preview"
+	}
+	v.header.SetText(tview.Escape(v.opts.Title) + readCounts(newResult, h.FormatNumber) + tview.Escape(mode))
+	if v.opts.OnPage != nil {
+		v.opts.OnPage(v.PageStart())
+	}
+	text := v.opts.Notice
+	if text == "" && newResult.NextPage != nil {
+		text = v.opts.MorePagesNotice
+	}
+	v.notice.SetText(text)
+	if v.changed != nil {
+		v.changed()
+	}
+	v.syncSplit()
+}
+
+// updateNavButtons disables the page buttons that can't be used
+func (v *ResultsView) updateNavButtons() {
+	v.prevButton.SetDisabled(v.loading || v.currentPage == 1)
+	v.nextButton.SetDisabled(v.loading || v.currentPage == len(v.pageHistory) && v.pageHistory[len(v.pageHistory)-1].NextPage == nil)
+}
+
+// showView shows the loaded pages sorted or grouped as chosen, or the page
+// in view before that when neither is
+func (v *ResultsView) showView() {
+	if !v.Viewing() {
+		v.viewPositions = nil
+		v.show(v.pageHistory[v.currentPage-1], v.currentPage)
+		return
+	}
+	var keep func(rawItem map[string]interface{}) bool
+	if v.groupAttr != "" {
+		keep = v.groupKeep
+	}
+	var view aws.QueryResult
+	view, v.viewPositions = pagesView(v.pageHistory, keep, v.sortAttr, v.sortDescending)
+	v.show(view, v.currentPage)
+	v.table.Select(1, 0)
+}
+
+// endView drops the sort and the group, showing the page in view before them
+func (v *ResultsView) endView() {
+	if v.Viewing() {
+		v.sortAttr, v.groupAttr = "", ""
+		v.showView()
+	}
+}
+
+// Paging ends a sort, going on from the page in view before it
+func (v *ResultsView) goToPrevious() {
+	if v.loading {
+		return
+	}
+	v.endView()
+	if v.currentPage > 1 {
+		v.show(v.pageHistory[v.currentPage-2], v.currentPage-1)
+		v.updateNavButtons()
+	}
+}
+
+func (v *ResultsView) goToNext() {
+	if v.loading {
+		return
+	}
+	v.endView()
+	// Revisit pages that were already loaded
+	if v.currentPage < len(v.pageHistory) {
+		v.show(v.pageHistory[v.currentPage], v.currentPage+1)
+		v.updateNavButtons()
+		return
+	}
+	if v.result.NextPage == nil || v.opts.FetchNext == nil {
+		return
+	}
+
+	h := v.h
+	ctx := h.Loading("loadingpage", "Loading next page...")
+	nextPage := v.result.NextPage
+	var nextResult aws.QueryResult
+	streamed := false // Whether the page is already shown while it loads
+	h.Read(ctx, func(ctx context.Context) error {
+		var err error
+		nextResult, err = v.opts.FetchNext(ctx, nextPage, func(partial aws.QueryResult) {
+			h.App.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if !streamed {
+					streamed = true
+					h.Pages.RemovePage("loadingpage")
+					v.pageHistory = append(v.pageHistory, aws.QueryResult{})
+					v.Update(partial, true)
+					v.table.ScrollToBeginning()
+					v.Focus()
+					return
+				}
+				v.Update(partial, true)
+			})
+		})
+		return err
+	}, func(err error) {
+		h.Pages.RemovePage("loadingpage")
+		if err != nil {
+			if streamed {
+				// Go back to the page the read started from
+				v.loading = false
+				v.pageHistory = v.pageHistory[:len(v.pageHistory)-1]
+				v.show(v.pageHistory[len(v.pageHistory)-1], len(v.pageHistory))
+				v.updateNavButtons()
+			}
+			h.Message("pageerror", fmt.Sprintf("Error loading next page: %v", err))
+			return
+		}
+		if streamed {
+			v.Update(nextResult, false)
+			return
+		}
+		v.pageHistory = append(v.pageHistory, nextResult)
+		v.show(nextResult, len(v.pageHistory))
+		v.updateNavButtons()
+		v.Focus()
+	})
+}
+
+// hydrate replaces the page in view with the full items of the base table
+func (v *ResultsView) hydrate() {
+	h := v.h
+	v.endView()
+	ctx := h.Loading("hydrating", "Fetching full items...")
+
+	page := v.currentPage
+	var hydrated aws.QueryResult
+	h.Read(ctx, func(ctx context.Context) error {
+		var err error
+		hydrated, err = HydrateFromBaseTable(ctx, h.Client, v.tableInfo, v.result)
+		return err
+	}, func(err error) {
+		h.Pages.RemovePage("hydrating")
+		if err != nil {
+			h.Message("hydrateerror", fmt.Sprintf("Hydrate error: %v", err))
+			return
+		}
+		v.pageHistory[page-1] = hydrated
+		v.show(hydrated, page)
+		v.notice.SetText(fmt.Sprintf("[#30d158]Page %d hydrated from %s[white]", page, v.tableInfo.Name))
+		v.Focus()
+	})
+}
+
+// showPreview shows the item of a row next to the results
+func (v *ResultsView) showPreview(row int) {
+	v.previewPage, v.previewRow = v.currentPage, row
+	v.previewItem, v.previewRawItem = v.result.Items[row-1], v.result.RawItems[row-1]
+	fillItemTable(v.h, v.preview, v.tableInfo, v.previewItem, v.previewRawItem)
+	v.preview.ScrollToBeginning()
+	if v.h.Settings.SplitResults {
+		v.previewHeader.SetText(fmt.Sprintf("Item %d of page %d (Enter: item view | %s: close split view)", row, v.currentPage, v.h.KeyLabel("results.split")))
+	} else {
+		v.previewHeader.SetText(fmt.Sprintf("Item %d of page %d (Enter again: item view | ESC: close preview)", row, v.currentPage))
+	}
+	if !v.previewShown {
+		v.body.AddItem(v.previewFlex, 0, 1, false)
+		v.previewShown = true
+	}
+}
+
+func (v *ResultsView) hidePreview() {
+	if v.previewShown {
+		v.body.RemoveItem(v.previewFlex)
+		v.previewShown = false
+	}
+}
+
+// syncSplit shows the selected row next to the results in the split view
+func (v *ResultsView) syncSplit() {
+	if !v.h.Settings.SplitResults {
+		return
+	}
+	row, _ := v.table.GetSelection()
+	if row < 1 || row > len(v.result.Items) {
+		v.hidePreview()
+		return
+	}
+	if !v.previewShown || v.previewPage != v.currentPage || v.previewRow != row {
+		v.showPreview(row)
+	}
+}
+
+// openRow runs the configured Enter action on a results row
+func (v *ResultsView) openRow(row int) {
+	item, rawItem := v.result.Items[row-1], v.result.RawItems[row-1]
+	switch v.h.Settings.enterAction() {
+	case EnterJSON:
+		name := fmt.Sprintf("%v", rawItem[v.tableInfo.PartitionKey])
+		if v.tableInfo.SortKey != "" {
+			name += fmt.Sprintf(" / %v", rawItem[v.tableInfo.SortKey])
+		}
+		ShowJSONView(v.h, name, rawItem)
+		return
+	case EnterPreview:
+		// A second Enter on the previewed row opens the item view
+		if !v.previewShown || v.previewPage != v.currentPage || v.previewRow != row {
+			v.showPreview(row)
+			return
+		}
+	}
+	ShowItemDetail(v.h, v.tableInfo, item, rawItem, v.opts.SourceOf(v.result, row-1))
+}
+
+// saveSettings writes a changed view setting, which otherwise lasts for
+// the session
+func (v *ResultsView) saveSettings(what string) bool {
+	if err := v.h.SaveSettings(); err != nil {
+		v.h.Message("configerror", fmt.Sprintf("%s changed for this session, but saving it failed: %v", what, err))
+		return false
+	}
+	return true
+}
+
+func (v *ResultsView) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if v.capture != nil {
+		if event = v.capture(event); event == nil {
+			return nil
+		}
+	}
+	h := v.h
+	if event.Key() == tcell.KeyESC {
+		if v.previewShown && !h.Settings.SplitResults {
+			v.hidePreview()
+			return nil
+		}
+		if v.groupAttr != "" {
+			// Back to all the items, still sorted if they were
+			v.groupAttr = ""
+			v.showView()
+			return nil
+		}
+		h.Pages.RemovePage(v.opts.PageName)
+	} else if h.KeyPressed(event, "results.previousPage") {
+		// Go back to previous page
+		v.goToPrevious()
+		return nil
+	} else if h.KeyPressed(event, "results.nextPage") {
+		// Load next page with Ctrl+N
+		v.goToNext()
+		return nil
+	} else if h.KeyPressed(event, "results.hydrate") && v.opts.Hydrate && !v.loading {
+		// Replace the current page with the full items
+		v.hydrate()
+		return nil
+	} else if h.KeyPressed(event, "results.rawValues") {
+		h.Settings.RawValues = !h.Settings.RawValues
+		v.rawShown = h.Settings.RawValues
+		v.Redraw()
+		if v.previewShown {
+			fillItemTable(h, v.preview, v.tableInfo, v.previewItem, v.previewRawItem)
+		}
+		return nil
+	} else if h.KeyPressed(event, "results.pageJSON") && !v.loading {
+		// The whole page as stored, in the JSON viewer
+		if len(v.result.RawItems) == 0 {
+			h.Message("jsonerror", "There are no items on this page.")
+			return nil
+		}
+		items := make([]interface{}, len(v.result.RawItems))
+		for i, rawItem := range v.result.RawItems {
+			items[i] = rawItem
+		}
+		ShowJSONView(h, fmt.Sprintf("%s page %d", v.tableInfo.Name, v.currentPage), items)
+		return nil
+	} else if h.KeyPressed(event, "results.copyItem") {
+		// The selected item as JSON
+		if i, ok := v.Selected(); ok {
+			CopyItemAsJSON(h, v.result.RawItems[i], v.opts.SourceOf(v.result, i))
+		}
+		return nil
+	} else if h.KeyPressed(event, "results.split") {
+		// Results on the left, the selected item on the right, and remember it
+		h.Settings.SplitResults = !h.Settings.SplitResults
+		v.hidePreview()
+		v.syncSplit()
+		v.saveSettings("Split view")
+		return nil
+	} else if h.KeyPressed(event, "results.enterAction") {
+		// Cycle what Enter does and remember it
+		next := (slices.Index(EnterActions, h.Settings.enterAction()) + 1) % len(EnterActions)
+		h.Settings.ResultsEnter = EnterActions[next]
+		if h.Settings.ResultsEnter != EnterPreview && !h.Settings.SplitResults {
+			v.hidePreview()
+		}
+		v.Redraw()
+		v.saveSettings("Enter action")
+		return nil
+	} else if h.KeyPressed(event, "results.columnLeft") {
+		v.moveColumn(-1)
+		return nil
+	} else if h.KeyPressed(event, "results.columnRight") {
+		v.moveColumn(1)
+		return nil
+	} else if h.KeyPressed(event, "results.sort") && !v.loading {
+		// Sort the loaded pages by the current column: ascending,
+		// descending, then back to the pages as read
+		field := v.ShownFields()[v.currentColumn]
+		switch {
+		case v.sortAttr != field:
+			v.sortAttr, v.sortDescending = field, false
+		case !v.sortDescending:
+			v.sortDescending = true
+		default:
+			v.sortAttr = ""
+		}
+		v.showView()
+		return nil
+	} else if h.KeyPressed(event, "results.autoFit") {
+		// Fit the columns to the terminal, or show them at their widths, and remember it
+		h.Settings.AutoFit = !h.Settings.AutoFit
+		v.Redraw()
+		if !v.saveSettings("Auto-fit") {
+			return nil
+		}
+		if h.Settings.AutoFit {
+			h.Notify("Columns fitted to the terminal")
+		} else {
+			h.Notify("Columns shown at their set widths")
+		}
+		return nil
+	} else if event.Key() == tcell.KeyEnter {
+		row, _ := v.table.GetSelection()
+		if row > 0 && row <= len(v.result.Items) {
+			v.openRow(row)
+		}
+	}
+	return event
+}
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// testKeys are the keys the test host binds to the actions the views use
+var testKeys = map[string]*tcell.EventKey{
+	"results.previousPage": tcell.NewEventKey(tcell.KeyCtrlB, 0, tcell.ModCtrl),
+	"results.nextPage":     tcell.NewEventKey(tcell.KeyCtrlN, 0, tcell.ModCtrl),
+	"results.hydrate":      tcell.NewEventKey(tcell.KeyCtrlG, 0, tcell.ModCtrl),
+	"results.rawValues":    tcell.NewEventKey(tcell.KeyCtrlT, 0, tcell.ModCtrl),
+	"results.columnLeft":   tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone),
+	"results.columnRight":  tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone),
+	"results.sort":         tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+	"item.rawValues":       tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+}
+
+// testHost is a Host reading from client, with reads made right away on
+// the calling goroutine and messages kept for the test to look at
+type testHost struct {
+	Host
+	messages []string
+	reads    int
+}
+
+func newTestHost(client Reader) *testHost {
+	h := &testHost{}
+	h.Host = Host{
+		App:      tview.NewApplication(),
+		Pages:    tview.NewPages(),
+		Client:   client,
+		Settings: &Settings{},
+		KeyPressed: func(event *tcell.EventKey, action string) bool {
+			key, ok := testKeys[action]
+			return ok && event.Key() == key.Key() && (key.Key() != tcell.KeyRune || event.Rune() == key.Rune())
+		},
+		KeyLabel: func(action string) string { return action },
+		Read: func(ctx context.Context, read func(ctx context.Context) error, done func(err error)) {
+			h.reads++
+			done(read(ctx))
+		},
+		Loading: func(pageName, text string) context.Context { return context.Background() },
+		Message: func(pageName, text string) {
+			h.messages = append(h.messages, text)
+		},
+		Notify:       func(text string) {},
+		Copy:         func(text string) bool { return true },
+		SaveSettings: func() error { return nil },
+		ColumnTitle:  func(table, attr string) string { return attr },
+		CellWidth:    func(table, attr string) int { return MaxCellLength },
+		FormatNumber: func(n int64) string { return strconv.FormatInt(n, 10) },
+		TTLText:      func(v interface{}) (string, bool) { return "", false },
+		ShowBinary: func(tableInfo aws.TableInfo, rawItem map[string]interface{}, attr, title string) bool {
+			return false
+		},
+	}
+	return h
+}
+
+// press sends the key bound to action to a view's key handler
+func press(handle func(event *tcell.EventKey) *tcell.EventKey, action string) *tcell.EventKey {
+	return handle(testKeys[action])
+}
+
+// devicesClient is a client over one device's five readings, scanned two
+// at a time. Readings 2 and 4 have no name.
+func devicesClient(t *testing.T) *aws.Client {
+	t.Helper()
+	var items []map[string]interface{}
+	for ts := int64(1); ts <= 5; ts++ {
+		item := map[string]interface{}{"device": "a", "ts": ts, "reading": 10 - ts}
+		if ts%2 == 1 {
+			item["name"] = fmt.Sprintf("reading %d", ts)
+		}
+		items = append(items, item)
+	}
+	client, err := aws.NewFakeClient("test", []aws.FakeTable{{
+		Name:           "devices",
+		PartitionKey:   "device",
+		SortKey:        "ts",
+		AttributeTypes: map[string]string{"ts": "N"},
+		Indexes:        []aws.FakeIndex{{Name: "by-name", PartitionKey: "name"}},
+		Items:          items,
+	}}, aws.WithPageSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// scanResults shows the first page of a scan of the devices table,
+// counting the pages read after it in fetches
+func scanResults(t *testing.T, h *testHost, client *aws.Client, fetches *int) *ResultsView {
+	t.Helper()
+	ctx := context.Background()
+	tableInfo, err := client.DescribeTable(ctx, "devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := aws.ScanParams{TableName: "devices"}
+	first, err := client.Scan(ctx, params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewResultsView(&h.Host, tableInfo, ResultsOptions{
+		PageName: "results",
+		Title:    "Scan Results for devices",
+		FetchNext: func(ctx context.Context, page *aws.PageToken, partial func(aws.QueryResult)) (aws.QueryResult, error) {
+			*fetches++
+			return client.Scan(ctx, params, page)
+		},
+	}, first, false)
+	v.Show()
+	return v
+}
+
+// timestamps are the sort keys of the items in view
+func timestamps(v *ResultsView) []string {
+	var ts []string
+	for _, rawItem := range v.Result().RawItems {
+		ts = append(ts, fmt.Sprint(rawItem["ts"]))
+	}
+	return ts
+}
+
+func TestResultsViewPagesThroughTheRead(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	fetches := 0
+	v := scanResults(t, h, client, &fetches)
+	if fmt.Sprint(timestamps(v)) != "[1 2]" || !v.prevButton.IsDisabled() || v.nextButton.IsDisabled() {
+		t.Fatalf("first page shows %v, want readings 1 and 2 with only Next enabled", timestamps(v))
+	}
+
+	press(v.handleKey, "results.nextPage")
+	press(v.handleKey, "results.nextPage")
+	if v.Page() != 3 || fmt.Sprint(timestamps(v)) != "[5]" || !v.nextButton.IsDisabled() {
+		t.Fatalf("page %d shows %v, want page 3 with reading 5 and Next disabled", v.Page(), timestamps(v))
+	}
+	press(v.handleKey, "results.nextPage")
+	if v.Page() != 3 || fetches != 2 {
+		t.Errorf("Next on the last page went to page %d after %d reads, want to stay on 3 after 2", v.Page(), fetches)
+	}
+
+	// Pages already read are shown again without reading them
+	press(v.handleKey, "results.previousPage")
+	press(v.handleKey, "results.nextPage")
+	if v.Page() != 3 || fetches != 2 || len(v.LoadedPages()) != 3 {
+		t.Errorf("back and forth ended on page %d of %d after %d reads, want page 3 of 3 after 2", v.Page(), len(v.LoadedPages()), fetches)
+	}
+	if v.PageStart() != v.LoadedPages()[1].NextPage {
+		t.Errorf("page 3 starts at %v, want the cursor after page 2", v.PageStart())
+	}
+	if len(h.messages) > 0 {
+		t.Errorf("showed %q", h.messages)
+	}
+}
+
+func TestResultsViewSortsTheLoadedPages(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	fetches := 0
+	v := scanResults(t, h, client, &fetches)
+	press(v.handleKey, "results.nextPage")
+	press(v.handleKey, "results.nextPage")
+	press(v.handleKey, "results.previousPage")
+
+	// By reading, which goes down as the readings go on
+	v.SetColumns([]string{"reading"})
+	for range 2 {
+		press(v.handleKey, "results.columnRight")
+	}
+	if field := v.ShownFields()[v.CurrentColumn()]; field != "reading" {
+		t.Fatalf("moved to %s, want the reading column", field)
+	}
+	press(v.handleKey, "results.sort")
+	if !v.Viewing() || fmt.Sprint(timestamps(v)) != "[5 4 3 2 1]" {
+		t.Errorf("sorted by reading shows %v, want every loaded item from reading 5 back", timestamps(v))
+	}
+	press(v.handleKey, "results.sort")
+	if fmt.Sprint(timestamps(v)) != "[1 2 3 4 5]" {
+		t.Errorf("sorted descending shows %v, want every loaded item from reading 1 on", timestamps(v))
+	}
+	press(v.handleKey, "results.sort")
+	if v.Viewing() || v.Page() != 2 || fmt.Sprint(timestamps(v)) != "[3 4]" {
+		t.Errorf("a third sort shows %v of page %d, want page 2 back as read", timestamps(v), v.Page())
+	}
+
+	// Paging ends a sort, going on from the page before it
+	press(v.handleKey, "results.sort")
+	press(v.handleKey, "results.nextPage")
+	if v.Viewing() || v.Page() != 3 || fetches != 2 {
+		t.Errorf("Next while sorted went to page %d after %d reads, want page 3 after 2", v.Page(), fetches)
+	}
+}
+
+func TestResultsViewHydratesFromTheBaseTable(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	tableInfo, err := client.DescribeTable(context.Background(), "devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A page of an index projecting only the keys, with an item since
+	// deleted from the table
+	keys := func(ts int64) map[string]interface{} {
+		return map[string]interface{}{"device": "a", "ts": ts, "name": fmt.Sprintf("reading %d", ts)}
+	}
+	page := aws.QueryResult{
+		Items:    []map[string]interface{}{keys(3), keys(7)},
+		RawItems: []map[string]interface{}{keys(3), keys(7)},
+	}
+	v := NewResultsView(&h.Host, tableInfo, ResultsOptions{PageName: "results", Hydrate: true}, page, false)
+	v.Show()
+
+	press(v.handleKey, "results.hydrate")
+	if len(h.messages) > 0 {
+		t.Fatalf("hydrating showed %q", h.messages)
+	}
+	hydrated := v.Result().RawItems
+	if _, ok := hydrated[0]["reading"]; !ok {
+		t.Errorf("hydrated item %v, want the base table's attributes", hydrated[0])
+	}
+	if _, ok := hydrated[1]["reading"]; ok || hydrated[1]["ts"] != int64(7) {
+		t.Errorf("hydrated deleted item %v, want it kept as the index had it", hydrated[1])
+	}
+	if v.LoadedPages()[0].RawItems[0]["reading"] == nil {
+		t.Error("kept the index page in the pages read, want the hydrated one")
+	}
+}
+
+func TestResultsViewRefreshesAnItem(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	fetches := 0
+	v := scanResults(t, h, client, &fetches)
+	press(v.handleKey, "results.nextPage")
+	press(v.handleKey, "results.columnRight")
+	press(v.handleKey, "results.sort")
+
+	// The view shows the item as it was read, since edited
+	i := 1
+	stale := map[string]interface{}{"device": "a", "ts": v.Result().RawItems[i]["ts"], "reading": int64(0)}
+	pos := v.viewPositions[i]
+	v.result.Items[i], v.result.RawItems[i] = stale, stale
+	v.pageHistory[pos.page].Items[pos.index], v.pageHistory[pos.page].RawItems[pos.index] = stale, stale
+
+	v.RefreshItem("devices", map[string]interface{}{"device": "a", "ts": stale["ts"]}, i)
+	if h.reads != 2 {
+		t.Fatalf("made %d reads, want the page and the item", h.reads)
+	}
+	written := v.Result().RawItems[i]
+	if fmt.Sprint(written["reading"]) == "0" {
+		t.Errorf("item in view is %v, want it as stored", written)
+	}
+	if page := v.LoadedPages()[pos.page].RawItems[pos.index]; fmt.Sprint(page["reading"]) == "0" {
+		t.Errorf("item on the page it was read on is %v, want it as stored", page)
+	}
+}
+
+func TestResultsViewShowsRawValues(t *testing.T) {
+	client := devicesClient(t)
+	h := newTestHost(client)
+	fetches := 0
+	v := scanResults(t, h, client, &fetches)
+	v.SetColumns([]string{"name"})
+	if text := v.table.GetCell(2, 2).Text; text != MissingMarker {
+		t.Errorf("name of reading 2 is %q, want the missing marker", text)
+	}
+
+	press(v.handleKey, "results.rawValues")
+	if !h.Settings.RawValues {
+		t.Fatal("raw values are off after toggling them")
+	}
+	if text := v.table.GetCell(1, 2).Text; text != "reading 1" {
+		t.Errorf("raw name of reading 1 is %q", text)
+	}
+}
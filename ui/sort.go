@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"bytes"
+	"cmp"
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// itemPosition is where an item of a view of the loaded pages is in the
+// pages read
+type itemPosition struct {
+	page  int // Index into the page history
+	index int
+}
+
+// pagesView is the items of the pages read as one result: those keep
+// accepts, or all of them when it is nil, ordered by an attribute unless
+// attr is empty. Counts, capacity and requests are those of all the pages,
+// and there is no next page.
+func pagesView(pageHistory []aws.QueryResult, keep func(rawItem map[string]interface{}) bool, attr string, descending bool) (aws.QueryResult, []itemPosition) {
+	var view aws.QueryResult
+	var positions []itemPosition
+	sources := false
+	for p, page := range pageHistory {
+		for i, rawItem := range page.RawItems {
+			if keep == nil || keep(rawItem) {
+				positions = append(positions, itemPosition{page: p, index: i})
+			}
+		}
+		view.SizeBytes += page.SizeBytes
+		view.ConsumedCapacity += page.ConsumedCapacity
+		view.Duplicates += page.Duplicates
+		view.Count += page.Count
+		view.ScannedCount += page.ScannedCount
+		view.Requests = append(view.Requests, page.Requests...)
+		sources = sources || len(page.Sources) > 0
+	}
+	raw := func(pos itemPosition) map[string]interface{} {
+		return pageHistory[pos.page].RawItems[pos.index]
+	}
+	if attr != "" {
+		slices.SortStableFunc(positions, func(a, b itemPosition) int {
+			va, okA := raw(a)[attr]
+			vb, okB := raw(b)[attr]
+			// Items without the attribute come last either way
+			switch {
+			case !okA || !okB:
+				return compareBools(!okA, !okB)
+			case descending:
+				return compareValues(vb, va)
+			}
+			return compareValues(va, vb)
+		})
+	}
+	for _, pos := range positions {
+		page := pageHistory[pos.page]
+		view.Items = append(view.Items, page.Items[pos.index])
+		view.RawItems = append(view.RawItems, page.RawItems[pos.index])
+		if sources {
+			source := ""
+			if pos.index < len(page.Sources) {
+				source = page.Sources[pos.index]
+			}
+			view.Sources = append(view.Sources, source)
+		}
+	}
+	return view, positions
+}
+
+// compareValues orders two raw attribute values: numbers by value, strings
+// by their UTF-8 bytes as DynamoDB orders sort keys, binary values byte by
+// byte, false before true. Values of different types go numbers, strings,
+// binary values, booleans, then anything else, with NULL last.
+func compareValues(a, b interface{}) int {
+	rankA, rankB := valueRank(a), valueRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+		return compareNumbers(a, b)
+	case json.Number:
+		return compareNumbers(a, b)
+	case string:
+		return strings.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	case bool:
+		return compareBools(a, b.(bool))
+	case nil:
+		return 0
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// valueRank is the place of a value's type in the order of compareValues
+func valueRank(v interface{}) int {
+	switch v.(type) {
+	case int64, json.Number:
+		return 0
+	case string:
+		return 1
+	case []byte:
+		return 2
+	case bool:
+		return 3
+	case nil:
+		return 5
+	}
+	return 4
+}
+
+// compareNumbers compares numbers exactly, json.Number holding up to the 38
+// digits DynamoDB keeps
+func compareNumbers(a, b interface{}) int {
+	return bigNumber(a).Cmp(bigNumber(b))
+}
+
+// bigNumber is a number value as a big.Float, zero if it isn't one
+func bigNumber(v interface{}) *big.Float {
+	text := ""
+	switch n := v.(type) {
+	case int64:
+		text = strconv.FormatInt(n, 10)
+	case json.Number:
+		text = n.String()
+	}
+	f, ok := new(big.Float).SetPrec(200).SetString(text)
+	if !ok {
+		return new(big.Float)
+	}
+	return f
+}
+
+// compareBools orders false before true
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
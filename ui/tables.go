@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// NewDataTable creates a selectable table. Screen reader mode drops the
+// box-drawing borders so each row is read as a single line of text.
+func NewDataTable(screenReader bool) *tview.Table {
+	table := tview.NewTable().
+		SetBorders(!screenReader).
+		SetSelectable(true, false)
+	SelectRowsOnClick(table)
+	return table
+}
+
+// RowPrefix describes a row's position for screen readers, e.g. "Item 3 of 15: "
+func RowPrefix(screenReader bool, noun string, row, total int) string {
+	if !screenReader {
+		return ""
+	}
+	return fmt.Sprintf("%s %d of %d: ", noun, row, total)
+}
+
+// Labeled prefixes a cell value with its column name in screen reader mode
+func Labeled(screenReader bool, column, value string) string {
+	if !screenReader {
+		return value
+	}
+	return column + " " + value
+}
+
+// FullNameNote is the status line text naming an attribute shown under
+// another title, or empty when the title is the name
+func FullNameNote(title, attr string) string {
+	if title == attr {
+		return ""
+	}
+	return fmt.Sprintf("[#b8b8b8]%s:[white] %s", tview.Escape(title), tview.Escape(attr))
+}
+
+// ClickWithoutFocus is the mouse capture of buttons and tabs that act on a
+// click without taking the focus, so the keys keep working where they were
+func ClickWithoutFocus(box *tview.Box) func(tview.MouseAction, *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+	return func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftDown && box.InRect(event.Position()) {
+			return tview.MouseConsumed, nil
+		}
+		return action, event
+	}
+}
+
+// SelectRowsOnClick keeps clicks on a table's header, on rows that can't be
+// selected and below the last row from selecting them, which the keys
+// never do
+func SelectRowsOnClick(table *tview.Table) {
+	table.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseLeftClick || !table.InRect(event.Position()) {
+			return action, event
+		}
+		row, _ := table.CellAt(event.Position())
+		if row < 0 || row >= table.GetRowCount() || table.GetCell(row, 0).NotSelectable {
+			return action, nil
+		}
+		return action, event
+	})
+}
+
+// MaxCellLength is the longest formatted value shown in a results column,
+// unless the Host's CellWidth sets another width
+const MaxCellLength = 50
+
+// MinCellWidth is the narrowest a results column can be made: a couple of
+// characters and the "..." of a cut value
+const MinCellWidth = 5
+
+// TruncateCell cuts a value longer than width characters short, ending it
+// with "..."
+func TruncateCell(value string, width int) string {
+	if len(value) <= width {
+		return value // No more runes than bytes
+	}
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// fitCellWidths shares the available width between columns that would take
+// natural characters each. Narrow columns keep their width and the wide
+// ones split what is left evenly, none narrower than MinCellWidth, so the
+// columns fit side by side where they can.
+func fitCellWidths(natural []int, available int) []int {
+	widths := slices.Clone(natural)
+	total := 0
+	for _, width := range natural {
+		total += width
+	}
+	if total <= available {
+		return widths
+	}
+	order := make([]int, len(natural))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return natural[a] - natural[b]
+	})
+	left := available
+	for n, i := range order {
+		share := max(MinCellWidth, left/(len(order)-n))
+		widths[i] = min(natural[i], share)
+		left -= widths[i]
+	}
+	return widths
+}
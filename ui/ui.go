@@ -0,0 +1,155 @@
+// Package ui holds the views of items read from DynamoDB: the results of a
+// query or scan, the item view and the JSON viewer. They read through the
+// small Reader interface rather than the aws.Client, and reach the rest of
+// the application, such as its keymap and config file, through a Host, so
+// they can be tested against aws.NewFakeClient.
+package ui
+
+import (
+	"context"
+	"ddb-explorer/aws"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Reader reads items by key, for hydrating index results from the base
+// table and reading an item again after it was edited
+type Reader interface {
+	GetItem(ctx context.Context, tableName string, key map[string]interface{}) (aws.QueryResult, error)
+	BatchGetItems(ctx context.Context, tableName string, keys []map[string]interface{}) (aws.QueryResult, error)
+}
+
+var _ Reader = (*aws.Client)(nil)
+
+// Settings are the view preferences kept in the config file, but for
+// RawValues, which lasts for the session
+type Settings struct {
+	// RawValues shows the exact stored values in place of formatted ones,
+	// toggled with Ctrl+T
+	RawValues bool `json:"-"`
+
+	// AutoFit narrows the result columns to fit the terminal, toggled with =
+	AutoFit bool `json:"autoFit,omitempty"`
+
+	// SplitResults shows the selected item next to the results on every
+	// results page, toggled with s
+	SplitResults bool `json:"splitResults,omitempty"`
+
+	// ResultsEnter is what Enter does on a results row: detail (the
+	// default), json or preview; see EnterActions
+	ResultsEnter string `json:"resultsEnter,omitempty"`
+}
+
+// What Enter does on a results row, cycled with Ctrl+O and kept in the
+// config file
+const (
+	EnterDetail  = "detail"  // Open the item view
+	EnterJSON    = "json"    // Open the whole item in the JSON viewer
+	EnterPreview = "preview" // Show the item in a pane next to the results
+)
+
+// EnterActions are the values of Settings.ResultsEnter, in the order Ctrl+O
+// cycles through them
+var EnterActions = []string{EnterDetail, EnterJSON, EnterPreview}
+
+// enterAction returns the configured Enter action
+func (s *Settings) enterAction() string {
+	if s.ResultsEnter == "" {
+		return EnterDetail
+	}
+	return s.ResultsEnter
+}
+
+// Theme is the palette of the views, set once the application chose its
+// theme
+type Theme struct {
+	Orange        tcell.Color // Highlighted column, buttons and borders
+	Teal          tcell.Color // Key attributes and entries that can be opened
+	TextSecondary tcell.Color
+	BgSecondary   tcell.Color // Disabled buttons
+}
+
+// Host is the application the views are shown in: where they add their
+// pages, what they read with, and the services of the application they use.
+// Every field is set; the views call them from the UI goroutine.
+type Host struct {
+	App      *tview.Application
+	Pages    *tview.Pages
+	Client   Reader
+	Theme    Theme
+	Settings *Settings
+
+	// ScreenReader drops table borders and labels cells for screen readers
+	ScreenReader bool
+
+	// KeyPressed reports whether a key is bound to an action of the keymap,
+	// e.g. "results.nextPage", and KeyLabel writes its keys for hints
+	KeyPressed func(event *tcell.EventKey, action string) bool
+	KeyLabel   func(action string) string
+
+	// Read calls read away from the UI goroutine, then done on it, signing
+	// in again and retrying when the credentials expired
+	Read func(ctx context.Context, read func(ctx context.Context) error, done func(err error))
+
+	// Loading shows a loading modal under pageName, which cancels the
+	// returned context when dismissed; the caller removes the page
+	Loading func(pageName, text string) context.Context
+
+	// Message shows a modal with an OK button under pageName
+	Message func(pageName, text string)
+
+	// Notify shows a short notice in the status bar
+	Notify func(text string)
+
+	// Copy puts text on the clipboard, false when it isn't available
+	Copy func(text string) bool
+
+	// SaveSettings writes the config file after Settings changed
+	SaveSettings func() error
+
+	// ColumnTitle is how an attribute of a table is shown, and CellWidth
+	// how many characters of its values a results column shows
+	ColumnTitle func(table, attr string) string
+	CellWidth   func(table, attr string) int
+
+	// FormatNumber writes a count with the locale's digit grouping
+	FormatNumber func(n int64) string
+
+	// TTLText renders a value of a table's TTL attribute as the date the
+	// item expires; ok is false for values that aren't a number
+	TTLText func(v interface{}) (text string, ok bool)
+
+	// ShowBinary opens a binary attribute of an item in the binary viewer,
+	// false when the attribute isn't binary
+	ShowBinary func(tableInfo aws.TableInfo, rawItem map[string]interface{}, attr, title string) bool
+}
+
+// dataTable creates a selectable table for the host's screen reader mode
+func (h *Host) dataTable() *tview.Table {
+	return NewDataTable(h.ScreenReader)
+}
+
+// rowPrefix describes a row's position in screen reader mode
+func (h *Host) rowPrefix(noun string, row, total int) string {
+	return RowPrefix(h.ScreenReader, noun, row, total)
+}
+
+// labeled prefixes a cell value with its column name in screen reader mode
+func (h *Host) labeled(column, value string) string {
+	return Labeled(h.ScreenReader, column, value)
+}
+
+// fullNameNote names an attribute of a table shown under another title
+func (h *Host) fullNameNote(table, attr string) string {
+	return FullNameNote(h.ColumnTitle(table, attr), attr)
+}
+
+// copy puts text on the clipboard and confirms it with notice
+func (h *Host) copy(pageName, text, notice string) {
+	if !h.Copy(text) {
+		h.Message(pageName, "The clipboard isn't available yet, try again.")
+		return
+	}
+	h.Notify(notice)
+}
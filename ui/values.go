@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"bytes"
+	"ddb-explorer/aws"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// RawValueText renders a raw item value exactly: strings as stored, numbers
+// with every stored digit and anything else as compact JSON
+func RawValueText(v interface{}) string {
+	return tview.Escape(RawValueString(v))
+}
+
+// RawValueString is RawValueText without escaping, for text that isn't drawn
+func RawValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Indicators for values that would otherwise render as blank text or read
+// like a string, so a NULL, an empty string and an attribute that isn't set
+// can be told apart
+const (
+	NullMarker        = "[#b8b8b8]NULL[-]"
+	EmptyStringMarker = `[#b8b8b8]"" (empty string)[-]`
+	MissingMarker     = "[#b8b8b8](missing)[-]"
+)
+
+// IsMarker reports whether a cell shows one of the markers, which are never
+// cut short since that would break their color tags
+func IsMarker(value string) bool {
+	return value == NullMarker || value == EmptyStringMarker || value == MissingMarker
+}
+
+// CellValue is the text shown for an attribute of a result item, formatted
+// or exactly as stored when raw is set, and a marker when it is NULL, empty
+// or not set
+func CellValue(item, rawItem map[string]interface{}, field string, raw bool) string {
+	if v, ok := rawItem[field]; ok {
+		switch v {
+		case nil:
+			return NullMarker
+		case "":
+			return EmptyStringMarker
+		}
+	}
+	if raw {
+		v, ok := rawItem[field]
+		if !ok {
+			return MissingMarker
+		}
+		return RawValueText(v)
+	}
+	v, ok := item[field]
+	if !ok {
+		return MissingMarker
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// provenanceAttribute is added to items saved from a merged view, holding
+// the source column and value the item came from, e.g. {"Index": "by-email"}
+const provenanceAttribute = "_source"
+
+// ItemSource is where an item of a merged view came from: the view's source
+// column and the item's value in it. Empty for views of a single source.
+type ItemSource struct {
+	Column string
+	Value  string
+}
+
+// withProvenance returns a copy of rawItem carrying its source, or rawItem
+// itself when it came from a single-source view
+func withProvenance(rawItem map[string]interface{}, source ItemSource) map[string]interface{} {
+	if source.Column == "" {
+		return rawItem
+	}
+	tagged := make(map[string]interface{}, len(rawItem)+1)
+	for k, v := range rawItem {
+		tagged[k] = v
+	}
+	tagged[provenanceAttribute] = map[string]interface{}{source.Column: source.Value}
+	return tagged
+}
+
+// setType names the type of a string, number or binary set
+func setType(v interface{}) string {
+	switch v.(type) {
+	case aws.StringSet:
+		return "String Set"
+	case aws.NumberSet:
+		return "Number Set"
+	}
+	return "Binary Set"
+}
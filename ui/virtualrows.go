@@ -0,0 +1,71 @@
+package ui
+
+import "github.com/rivo/tview"
+
+// maxCachedRows bounds the rows of cells kept between draws. A screen shows
+// far fewer; the cache is dropped whole when it fills up.
+const maxCachedRows = 512
+
+// virtualRows is the content of a table with a header row above rows that
+// are only turned into cells when tview draws or selects them. Setting every
+// cell of a page of thousands of items up front made large --page-size
+// values slow to show and to scroll.
+type virtualRows struct {
+	tview.TableContentReadOnly
+	header []*tview.TableCell
+	rows   int
+	empty  *tview.TableCell                 // Shown in place of the rows when there are none
+	row    func(row int) []*tview.TableCell // Cells of a row, from 0 to rows-1
+	cache  map[int][]*tview.TableCell
+}
+
+// newVirtualRows creates the content of a table. row is called lazily, at
+// most once per row until the cache is dropped, so it may do costly work
+// such as decoding attributes.
+func newVirtualRows(header []*tview.TableCell, rows int, empty *tview.TableCell, row func(row int) []*tview.TableCell) *virtualRows {
+	return &virtualRows{header: header, rows: rows, empty: empty, row: row, cache: make(map[int][]*tview.TableCell)}
+}
+
+// invalidate drops the cells made so far, for the rows to be made again
+// when next drawn
+func (v *virtualRows) invalidate() {
+	clear(v.cache)
+}
+
+func (v *virtualRows) GetCell(row, column int) *tview.TableCell {
+	if row == 0 {
+		if column < len(v.header) {
+			return v.header[column]
+		}
+		return nil
+	}
+	if v.rows == 0 {
+		if row == 1 && column == 0 {
+			return v.empty
+		}
+		return nil
+	}
+	if row > v.rows {
+		return nil
+	}
+	cells, ok := v.cache[row]
+	if !ok {
+		if len(v.cache) >= maxCachedRows {
+			clear(v.cache)
+		}
+		cells = v.row(row - 1)
+		v.cache[row] = cells
+	}
+	if column < len(cells) {
+		return cells[column]
+	}
+	return nil
+}
+
+func (v *virtualRows) GetRowCount() int {
+	return 1 + max(v.rows, 1)
+}
+
+func (v *virtualRows) GetColumnCount() int {
+	return len(v.header)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// addUnionForm fills the form of the Union tab, one partition key value per
+// target
+func (p *tableActionPage) addUnionForm() {
+	// One partition key input per target; empty inputs are skipped
+	type unionTarget struct {
+		index aws.IndexInfo
+		field *tview.InputField
+	}
+	targets := []unionTarget{{index: aws.IndexInfo{PartitionKey: p.tableInfo.PartitionKey, ProjectionType: "ALL"}}}
+	for _, idx := range p.tableInfo.GlobalIndexes() {
+		targets = append(targets, unionTarget{index: idx})
+	}
+	for i := range targets {
+		name := targets[i].index.Name
+		if name == "" {
+			name = "Table"
+		}
+		p.form.AddInputField(fmt.Sprintf("%s (%s)", name, targets[i].index.PartitionKey), "", 20, nil, nil)
+		targets[i].field = p.form.GetFormItem(p.form.GetFormItemCount() - 1).(*tview.InputField)
+	}
+
+	p.form.AddButton("Union Query", func() {
+		var queries []aws.QueryParams
+		hydratable := false
+		for _, t := range targets {
+			value := t.field.GetText()
+			if value == "" {
+				continue
+			}
+			if msg := keyValueError(t.index.PartitionKey, p.tableInfo.AttributeTypes[t.index.PartitionKey], value, ""); msg != "" {
+				p.formError.SetText(msg)
+				return
+			}
+			queries = append(queries, aws.QueryParams{
+				TableName:        p.tableInfo.Name,
+				IndexName:        t.index.Name,
+				PartitionKey:     t.index.PartitionKey,
+				PartitionValue:   value,
+				PartitionKeyType: p.tableInfo.AttributeTypes[t.index.PartitionKey],
+			})
+			if !t.index.ProjectsAll() {
+				hydratable = true
+			}
+		}
+		if len(queries) == 0 {
+			showMessageModal(p.pages, "unionerror", "Enter a value for at least one index")
+			return
+		}
+
+		// Show loading modal
+		ctx := showLoadingModal(p.pages, "loadingunion", fmt.Sprintf("Running %d queries...", len(queries)))
+
+		primaryKey := []string{p.tableInfo.PartitionKey}
+		if p.tableInfo.SortKey != "" {
+			primaryKey = append(primaryKey, p.tableInfo.SortKey)
+		}
+
+		var result aws.QueryResult
+		runWithReauth(ctx, p.app, p.pages, p.client, func(ctx context.Context) error {
+			var err error
+			result, err = p.client.UnionQuery(ctx, queries, primaryKey, unionMaxItems)
+			return err
+		}, func(err error) {
+			p.pages.RemovePage("loadingunion")
+			if err != nil {
+				showMessageModal(p.pages, "unionerror", fmt.Sprintf("Union query error: %v", err))
+				return
+			}
+
+			opts := ui.ResultsOptions{
+				PageName:     "unionresult",
+				Title:        fmt.Sprintf("Union Results for %s (%d indexes)", p.tableInfo.Name, len(queries)),
+				SourceColumn: "Index",
+			}
+			if hydratable {
+				opts.Notice = "[#ffd60a]Some indexes don't project all attributes (" + keyLabel("results.hydrate") + ": hydrate from base table)[white]"
+				opts.Hydrate = true
+			}
+			showResultsPage(p.app, p.pages, p.client, p.tableInfo, opts, result, false)
+		})
+	})
+
+	// Set focus to form itself
+	p.app.SetFocus(p.form)
+}
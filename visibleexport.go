@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"ddb-explorer/aws"
+	"ddb-explorer/ui"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// visibleRows are the rows of a results page as shown, for exporting them:
+// the page's items restricted to the columns in view
+type visibleRows struct {
+	columns []string
+	text    [][]string               // As displayed, untruncated and without markup
+	values  []map[string]interface{} // Stored values of the columns each item has
+}
+
+// newVisibleRows collects the shown fields of a results page, after the
+// source column of merged views
+func newVisibleRows(opts ui.ResultsOptions, result aws.QueryResult, fields []string) visibleRows {
+	var rows visibleRows
+	if opts.SourceColumn != "" {
+		rows.columns = append(rows.columns, opts.SourceColumn)
+	}
+	rows.columns = append(rows.columns, fields...)
+	for i, item := range result.Items {
+		rawItem := result.RawItems[i]
+		var text []string
+		values := make(map[string]interface{}, len(rows.columns))
+		if opts.SourceColumn != "" {
+			source := opts.SourceOf(result, i).Value
+			text = append(text, source)
+			values[opts.SourceColumn] = source
+		}
+		for _, field := range fields {
+			text = append(text, plainCellValue(item, rawItem, field))
+			if v, ok := rawItem[field]; ok {
+				values[field] = v
+			}
+		}
+		rows.text = append(rows.text, text)
+		rows.values = append(rows.values, values)
+	}
+	return rows
+}
+
+// plainCellValue is ui.CellValue as plain text: NULL spelled out and the empty
+// string left empty
+func plainCellValue(item, rawItem map[string]interface{}, field string) string {
+	if v, ok := rawItem[field]; ok && v == nil {
+		return "NULL"
+	}
+	if userSettings.RawValues {
+		v, ok := rawItem[field]
+		if !ok {
+			return ""
+		}
+		return ui.RawValueString(v)
+	}
+	v, ok := item[field]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// csv renders the rows with a header line
+func (r visibleRows) csv() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(r.columns)
+	w.WriteAll(r.text) // Flushes
+	return buf.String(), w.Error()
+}
+
+// json renders the rows as an array of objects with the stored values,
+// keeping the column order; missing attributes are left out
+func (r visibleRows) json() (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("[\n")
+	for i, values := range r.values {
+		buf.WriteString("  {")
+		first := true
+		for _, column := range r.columns {
+			v, ok := values[column]
+			if !ok {
+				continue
+			}
+			key, err := json.Marshal(column)
+			if err != nil {
+				return "", err
+			}
+			value, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			if !first {
+				buf.WriteString(", ")
+			}
+			first = false
+			fmt.Fprintf(&buf, "%s: %s", key, value)
+		}
+		buf.WriteString("}")
+		if i < len(r.values)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	return buf.String(), nil
+}
+
+// showVisibleExport offers to copy the visible rows to the clipboard or save
+// them in the working directory as name.csv or name.json
+func showVisibleExport(pages *tview.Pages, name string, rows visibleRows) {
+	summary := fmt.Sprintf("%s rows, %d columns", formatNumber(int64(len(rows.text))), len(rows.columns))
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Export the rows and columns in view (%s)", summary)).
+		AddButtons([]string{"Copy CSV", "Copy JSON", "Save CSV", "Save JSON", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage("visibleexport")
+			if buttonLabel == "Cancel" || buttonLabel == "" {
+				return
+			}
+			format := strings.ToLower(strings.Fields(buttonLabel)[1])
+			var text string
+			var err error
+			if format == "csv" {
+				text, err = rows.csv()
+			} else {
+				text, err = rows.json()
+			}
+			if err != nil {
+				showMessageModal(pages, "exporterror", fmt.Sprintf("Export error: %v", err))
+				return
+			}
+			if strings.HasPrefix(buttonLabel, "Copy") {
+				if !copyToClipboard(text) {
+					showMessageModal(pages, "exporterror", "The clipboard isn't available yet, try again.")
+					return
+				}
+				status.notify(fmt.Sprintf("Copied %s as %s", summary, strings.ToUpper(format)), noticeDuration)
+				return
+			}
+			filename := strings.NewReplacer("/", "_", " ", "_", ":", "_").Replace(name) + "." + format
+			if err := os.WriteFile(filename, []byte(text), 0644); err != nil {
+				showMessageModal(pages, "exporterror", fmt.Sprintf("Error writing file: %v", err))
+				return
+			}
+			status.notify(fmt.Sprintf("Saved %s to %s", summary, tview.Escape(filename)), noticeDuration)
+		})
+	pages.AddPage("visibleexport", modal, true, true)
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"ddb-explorer/aws"
+)
+
+// Exit codes of the watch-table subcommand
+const (
+	watchExitUnchanged = 0
+	watchExitChanged   = 1
+	watchExitError     = 2
+)
+
+// snapshot holds the raw items of a query result keyed by their primary key
+type snapshot map[string]map[string]interface{}
+
+// snapshotDiff lists the primary keys that changed between two snapshots
+type snapshotDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+func (d snapshotDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// runWatchTable implements the headless watch-table subcommand. It snapshots
+// a query result every interval and reports items that were added, removed or
+// modified since the previous snapshot.
+func runWatchTable(args []string) int {
+	fs := flag.NewFlagSet("watch-table", flag.ContinueOnError)
+	profile := fs.String("profile", "dev", "AWS profile to use")
+	roleARN := fs.String("role-arn", "", "IAM role ARN to assume for cross-account access")
+	mfaSerial := fs.String("mfa-serial", "", "MFA device serial (ARN) required to assume the role")
+	index := fs.String("index", "", "Secondary index to query")
+	pkValue := fs.String("pk", "", "Partition key value to query (required)")
+	skValue := fs.String("sk", "", "Sort key value to filter on")
+	condition := fs.String("condition", "=", "Sort key condition: =, begins_with, <, <=, >, >=")
+	interval := fs.Duration("interval", 5*time.Minute, "Time between snapshots")
+	count := fs.Int("count", 0, "Number of comparisons to run before exiting (0 runs until interrupted)")
+	maxItems := fs.Int("max-items", 1000, "Maximum number of items per snapshot")
+	output := fs.String("output", "", "Append diff reports to this file instead of stdout")
+	exitOnChange := fs.Bool("exit-on-change", false, "Exit with status 1 as soon as a change is detected")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ddb-explorer watch-table [OPTIONS] TABLE")
+		fs.PrintDefaults()
+	}
+
+	// Accept options after the table name as well
+	var tableName string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return watchExitError
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		if tableName != "" {
+			fs.Usage()
+			return watchExitError
+		}
+		tableName = fs.Arg(0)
+		args = fs.Args()[1:]
+	}
+	if tableName == "" || *pkValue == "" {
+		fs.Usage()
+		return watchExitError
+	}
+
+	client, err := newHeadlessClient(*profile, *roleARN, *mfaSerial)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create AWS client: %v\n", err)
+		return watchExitError
+	}
+
+	// Ctrl+C stops the watch, abandoning any request in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tableInfo, err := client.DescribeTable(ctx, tableName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to describe %s: %v\n", tableName, err)
+		return watchExitError
+	}
+
+	params := aws.QueryParams{
+		TableName:      tableName,
+		IndexName:      *index,
+		PartitionKey:   tableInfo.PartitionKey,
+		PartitionValue: *pkValue,
+		SortKey:        tableInfo.SortKey,
+		SortValue:      *skValue,
+		Condition:      *condition,
+	}
+	if *index != "" {
+		idx, ok := tableInfo.Index(*index)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Table %s has no index %s\n", tableName, *index)
+			return watchExitError
+		}
+		params.PartitionKey, params.SortKey = idx.PartitionKey, idx.SortKey
+	}
+	params.PartitionKeyType = tableInfo.AttributeTypes[params.PartitionKey]
+	params.SortKeyType = tableInfo.AttributeTypes[params.SortKey]
+	primaryKey := tablePrimaryKey(tableInfo)
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", *output, err)
+			return watchExitError
+		}
+		defer f.Close()
+		out = f
+	}
+
+	takeSnapshot := func() (snapshot, error) {
+		result, err := client.QueryUpTo(ctx, params, *maxItems)
+		if err != nil {
+			return nil, err
+		}
+		snap := make(snapshot, len(result.RawItems))
+		for _, item := range result.RawItems {
+			snap[itemKey(item, primaryKey)] = item
+		}
+		return snap, nil
+	}
+
+	previous, err := takeSnapshot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to query %s: %v\n", tableName, err)
+		return watchExitError
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s (%d items), checking every %s\n", tableName, len(previous), *interval)
+
+	exitCode := watchExitUnchanged
+	for i := 0; *count == 0 || i < *count; i++ {
+		select {
+		case <-ctx.Done():
+			return exitCode
+		case <-time.After(*interval):
+		}
+
+		current, err := takeSnapshot()
+		if ctx.Err() != nil {
+			return exitCode
+		}
+		if err != nil {
+			// A failed poll is reported but doesn't end the watch
+			fmt.Fprintf(os.Stderr, "%s: failed to query %s: %v\n", time.Now().Format(time.RFC3339), tableName, err)
+			continue
+		}
+
+		diff := diffSnapshots(previous, current)
+		if !diff.empty() {
+			writeDiffReport(out, tableName, previous, current, diff)
+			if *exitOnChange {
+				return watchExitChanged
+			}
+			exitCode = watchExitChanged
+		}
+		previous = current
+	}
+	return exitCode
+}
+
+// tablePrimaryKey lists the key attributes of a table's items
+func tablePrimaryKey(tableInfo aws.TableInfo) []string {
+	primaryKey := []string{tableInfo.PartitionKey}
+	if tableInfo.SortKey != "" {
+		primaryKey = append(primaryKey, tableInfo.SortKey)
+	}
+	return primaryKey
+}
+
+// Changes of a row between two reads of a watched results page
+const (
+	watchAdded   = "added"
+	watchChanged = "changed"
+)
+
+// pageChanges compares two reads of a results page by primary key. It
+// returns the change of each item that was added or modified, by itemKey,
+// and how many items are gone.
+func pageChanges(before, after []map[string]interface{}, primaryKey []string) (map[string]string, int) {
+	snap := func(items []map[string]interface{}) snapshot {
+		s := make(snapshot, len(items))
+		for _, item := range items {
+			s[itemKey(item, primaryKey)] = item
+		}
+		return s
+	}
+	diff := diffSnapshots(snap(before), snap(after))
+	changes := make(map[string]string, len(diff.Added)+len(diff.Modified))
+	for _, key := range diff.Added {
+		changes[key] = watchAdded
+	}
+	for _, key := range diff.Modified {
+		changes[key] = watchChanged
+	}
+	return changes, len(diff.Removed)
+}
+
+// itemKey renders the primary key attributes of an item as "attr=value" pairs
+func itemKey(item map[string]interface{}, primaryKey []string) string {
+	parts := make([]string, len(primaryKey))
+	for i, attr := range primaryKey {
+		parts[i] = fmt.Sprintf("%s=%v", attr, item[attr])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffSnapshots compares two snapshots, returning the changed keys in sorted order
+func diffSnapshots(before, after snapshot) snapshotDiff {
+	var diff snapshotDiff
+	for key, item := range after {
+		old, ok := before[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+		} else if len(changedAttributes(old, item)) > 0 {
+			diff.Modified = append(diff.Modified, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff
+}
+
+// changedAttributes returns the sorted names of attributes whose values differ
+func changedAttributes(before, after map[string]interface{}) []string {
+	var changed []string
+	for attr, v := range after {
+		if old, ok := before[attr]; !ok || jsonString(old) != jsonString(v) {
+			changed = append(changed, attr)
+		}
+	}
+	for attr := range before {
+		if _, ok := after[attr]; !ok {
+			changed = append(changed, attr)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// jsonString renders a value as compact JSON for comparison and display
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// writeDiffReport prints a human readable report of the changes between two snapshots
+func writeDiffReport(w io.Writer, tableName string, before, after snapshot, diff snapshotDiff) {
+	fmt.Fprintf(w, "%s %s: %d added, %d removed, %d modified\n", time.Now().Format(time.RFC3339),
+		tableName, len(diff.Added), len(diff.Removed), len(diff.Modified))
+	for _, key := range diff.Added {
+		fmt.Fprintf(w, "+ %s\n", key)
+	}
+	for _, key := range diff.Removed {
+		fmt.Fprintf(w, "- %s\n", key)
+	}
+	for _, key := range diff.Modified {
+		fmt.Fprintf(w, "~ %s\n", key)
+		for _, attr := range changedAttributes(before[key], after[key]) {
+			old, hadOld := before[key][attr]
+			cur, hasCur := after[key][attr]
+			switch {
+			case !hadOld:
+				fmt.Fprintf(w, "    %s: (absent) -> %s\n", attr, jsonString(cur))
+			case !hasCur:
+				fmt.Fprintf(w, "    %s: %s -> (absent)\n", attr, jsonString(old))
+			default:
+				fmt.Fprintf(w, "    %s: %s -> %s\n", attr, jsonString(old), jsonString(cur))
+			}
+		}
+	}
+	fmt.Fprintln(w)
+}